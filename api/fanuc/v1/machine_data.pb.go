@@ -0,0 +1,1268 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/fanuc/v1/machine_data.proto
+
+package fanucv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MachineDataEnvelope wraps a single polling snapshot published to Kafka.
+// It mirrors docs/schemas/machine_data.v1.json. Timestamps are Unix milliseconds.
+// Keep this message first in the file: Confluent framing refers to it by index 0.
+type MachineDataEnvelope struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion    string                 `protobuf:"bytes,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	ServiceVersion   string                 `protobuf:"bytes,2,opt,name=service_version,json=serviceVersion,proto3" json:"service_version,omitempty"`
+	MachineId        string                 `protobuf:"bytes,3,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`                        // uuid of the machine record
+	AdapterMachineId string                 `protobuf:"bytes,4,opt,name=adapter_machine_id,json=adapterMachineId,proto3" json:"adapter_machine_id,omitempty"` // id reported by fanucAdapter
+	Endpoint         string                 `protobuf:"bytes,5,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Model            string                 `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`
+	Series           string                 `protobuf:"bytes,7,opt,name=series,proto3" json:"series,omitempty"`
+	Labels           map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Sequence         uint64                 `protobuf:"varint,9,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	PollStartedAtMs  int64                  `protobuf:"varint,10,opt,name=poll_started_at_ms,json=pollStartedAtMs,proto3" json:"poll_started_at_ms,omitempty"`
+	PollFinishedAtMs int64                  `protobuf:"varint,11,opt,name=poll_finished_at_ms,json=pollFinishedAtMs,proto3" json:"poll_finished_at_ms,omitempty"`
+	PollLatencyMs    int64                  `protobuf:"varint,12,opt,name=poll_latency_ms,json=pollLatencyMs,proto3" json:"poll_latency_ms,omitempty"`
+	Data             *AggregatedData        `protobuf:"bytes,13,opt,name=data,proto3" json:"data,omitempty"`
+	Reads            []*DataReading         `protobuf:"bytes,14,rep,name=reads,proto3" json:"reads,omitempty"`       // addresses configured with polling
+	Position         *ProgramPosition       `protobuf:"bytes,15,opt,name=position,proto3" json:"position,omitempty"` // executing block, with program tracking
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MachineDataEnvelope) Reset() {
+	*x = MachineDataEnvelope{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineDataEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineDataEnvelope) ProtoMessage() {}
+
+func (x *MachineDataEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineDataEnvelope.ProtoReflect.Descriptor instead.
+func (*MachineDataEnvelope) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MachineDataEnvelope) GetSchemaVersion() string {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetServiceVersion() string {
+	if x != nil {
+		return x.ServiceVersion
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetAdapterMachineId() string {
+	if x != nil {
+		return x.AdapterMachineId
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *MachineDataEnvelope) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *MachineDataEnvelope) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *MachineDataEnvelope) GetPollStartedAtMs() int64 {
+	if x != nil {
+		return x.PollStartedAtMs
+	}
+	return 0
+}
+
+func (x *MachineDataEnvelope) GetPollFinishedAtMs() int64 {
+	if x != nil {
+		return x.PollFinishedAtMs
+	}
+	return 0
+}
+
+func (x *MachineDataEnvelope) GetPollLatencyMs() int64 {
+	if x != nil {
+		return x.PollLatencyMs
+	}
+	return 0
+}
+
+func (x *MachineDataEnvelope) GetData() *AggregatedData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *MachineDataEnvelope) GetReads() []*DataReading {
+	if x != nil {
+		return x.Reads
+	}
+	return nil
+}
+
+func (x *MachineDataEnvelope) GetPosition() *ProgramPosition {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+type AggregatedData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	MachineId          string                 `protobuf:"bytes,1,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	TimestampMs        int64                  `protobuf:"varint,2,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	IsEnabled          bool                   `protobuf:"varint,3,opt,name=is_enabled,json=isEnabled,proto3" json:"is_enabled,omitempty"`
+	IsEmergency        bool                   `protobuf:"varint,4,opt,name=is_emergency,json=isEmergency,proto3" json:"is_emergency,omitempty"`
+	MachineState       string                 `protobuf:"bytes,5,opt,name=machine_state,json=machineState,proto3" json:"machine_state,omitempty"`
+	ProgramMode        string                 `protobuf:"bytes,6,opt,name=program_mode,json=programMode,proto3" json:"program_mode,omitempty"`
+	TmMode             string                 `protobuf:"bytes,7,opt,name=tm_mode,json=tmMode,proto3" json:"tm_mode,omitempty"`
+	AxisMovementStatus string                 `protobuf:"bytes,8,opt,name=axis_movement_status,json=axisMovementStatus,proto3" json:"axis_movement_status,omitempty"`
+	MstbStatus         string                 `protobuf:"bytes,9,opt,name=mstb_status,json=mstbStatus,proto3" json:"mstb_status,omitempty"`
+	EmergencyStatus    string                 `protobuf:"bytes,10,opt,name=emergency_status,json=emergencyStatus,proto3" json:"emergency_status,omitempty"`
+	AlarmStatus        string                 `protobuf:"bytes,11,opt,name=alarm_status,json=alarmStatus,proto3" json:"alarm_status,omitempty"`
+	EditStatus         string                 `protobuf:"bytes,12,opt,name=edit_status,json=editStatus,proto3" json:"edit_status,omitempty"`
+	AxisInfos          []*AxisInfo            `protobuf:"bytes,13,rep,name=axis_infos,json=axisInfos,proto3" json:"axis_infos,omitempty"`
+	HasAlarms          bool                   `protobuf:"varint,14,opt,name=has_alarms,json=hasAlarms,proto3" json:"has_alarms,omitempty"`
+	Alarms             []*AlarmDetail         `protobuf:"bytes,15,rep,name=alarms,proto3" json:"alarms,omitempty"`
+	CurrentProgram     *CurrentProgramInfo    `protobuf:"bytes,16,opt,name=current_program,json=currentProgram,proto3" json:"current_program,omitempty"`
+	SpindleInfos       []*SpindleInfo         `protobuf:"bytes,17,rep,name=spindle_infos,json=spindleInfos,proto3" json:"spindle_infos,omitempty"`
+	ContourFeedRate    int32                  `protobuf:"varint,18,opt,name=contour_feed_rate,json=contourFeedRate,proto3" json:"contour_feed_rate,omitempty"`
+	ActualFeedRate     int32                  `protobuf:"varint,19,opt,name=actual_feed_rate,json=actualFeedRate,proto3" json:"actual_feed_rate,omitempty"`
+	FeedOverride       int32                  `protobuf:"varint,20,opt,name=feed_override,json=feedOverride,proto3" json:"feed_override,omitempty"`
+	JogOverride        int32                  `protobuf:"varint,21,opt,name=jog_override,json=jogOverride,proto3" json:"jog_override,omitempty"`
+	PartsCount         int64                  `protobuf:"varint,22,opt,name=parts_count,json=partsCount,proto3" json:"parts_count,omitempty"`
+	PowerOnTime        string                 `protobuf:"bytes,23,opt,name=power_on_time,json=powerOnTime,proto3" json:"power_on_time,omitempty"`
+	OperatingTime      string                 `protobuf:"bytes,24,opt,name=operating_time,json=operatingTime,proto3" json:"operating_time,omitempty"`
+	CycleTime          string                 `protobuf:"bytes,25,opt,name=cycle_time,json=cycleTime,proto3" json:"cycle_time,omitempty"`
+	CuttingTime        string                 `protobuf:"bytes,26,opt,name=cutting_time,json=cuttingTime,proto3" json:"cutting_time,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *AggregatedData) Reset() {
+	*x = AggregatedData{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregatedData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregatedData) ProtoMessage() {}
+
+func (x *AggregatedData) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregatedData.ProtoReflect.Descriptor instead.
+func (*AggregatedData) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AggregatedData) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetIsEnabled() bool {
+	if x != nil {
+		return x.IsEnabled
+	}
+	return false
+}
+
+func (x *AggregatedData) GetIsEmergency() bool {
+	if x != nil {
+		return x.IsEmergency
+	}
+	return false
+}
+
+func (x *AggregatedData) GetMachineState() string {
+	if x != nil {
+		return x.MachineState
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetProgramMode() string {
+	if x != nil {
+		return x.ProgramMode
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetTmMode() string {
+	if x != nil {
+		return x.TmMode
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetAxisMovementStatus() string {
+	if x != nil {
+		return x.AxisMovementStatus
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetMstbStatus() string {
+	if x != nil {
+		return x.MstbStatus
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetEmergencyStatus() string {
+	if x != nil {
+		return x.EmergencyStatus
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetAlarmStatus() string {
+	if x != nil {
+		return x.AlarmStatus
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetEditStatus() string {
+	if x != nil {
+		return x.EditStatus
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetAxisInfos() []*AxisInfo {
+	if x != nil {
+		return x.AxisInfos
+	}
+	return nil
+}
+
+func (x *AggregatedData) GetHasAlarms() bool {
+	if x != nil {
+		return x.HasAlarms
+	}
+	return false
+}
+
+func (x *AggregatedData) GetAlarms() []*AlarmDetail {
+	if x != nil {
+		return x.Alarms
+	}
+	return nil
+}
+
+func (x *AggregatedData) GetCurrentProgram() *CurrentProgramInfo {
+	if x != nil {
+		return x.CurrentProgram
+	}
+	return nil
+}
+
+func (x *AggregatedData) GetSpindleInfos() []*SpindleInfo {
+	if x != nil {
+		return x.SpindleInfos
+	}
+	return nil
+}
+
+func (x *AggregatedData) GetContourFeedRate() int32 {
+	if x != nil {
+		return x.ContourFeedRate
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetActualFeedRate() int32 {
+	if x != nil {
+		return x.ActualFeedRate
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetFeedOverride() int32 {
+	if x != nil {
+		return x.FeedOverride
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetJogOverride() int32 {
+	if x != nil {
+		return x.JogOverride
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetPartsCount() int64 {
+	if x != nil {
+		return x.PartsCount
+	}
+	return 0
+}
+
+func (x *AggregatedData) GetPowerOnTime() string {
+	if x != nil {
+		return x.PowerOnTime
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetOperatingTime() string {
+	if x != nil {
+		return x.OperatingTime
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetCycleTime() string {
+	if x != nil {
+		return x.CycleTime
+	}
+	return ""
+}
+
+func (x *AggregatedData) GetCuttingTime() string {
+	if x != nil {
+		return x.CuttingTime
+	}
+	return ""
+}
+
+type AxisInfo struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Position         float64                `protobuf:"fixed64,2,opt,name=position,proto3" json:"position,omitempty"`
+	LoadPercent      float64                `protobuf:"fixed64,3,opt,name=load_percent,json=loadPercent,proto3" json:"load_percent,omitempty"`
+	ServoTemperature int32                  `protobuf:"varint,4,opt,name=servo_temperature,json=servoTemperature,proto3" json:"servo_temperature,omitempty"`
+	CoderTemperature int32                  `protobuf:"varint,5,opt,name=coder_temperature,json=coderTemperature,proto3" json:"coder_temperature,omitempty"`
+	PowerConsumption int32                  `protobuf:"varint,6,opt,name=power_consumption,json=powerConsumption,proto3" json:"power_consumption,omitempty"`
+	Diag_301         float64                `protobuf:"fixed64,7,opt,name=diag_301,json=diag301,proto3" json:"diag_301,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AxisInfo) Reset() {
+	*x = AxisInfo{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AxisInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AxisInfo) ProtoMessage() {}
+
+func (x *AxisInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AxisInfo.ProtoReflect.Descriptor instead.
+func (*AxisInfo) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AxisInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AxisInfo) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *AxisInfo) GetLoadPercent() float64 {
+	if x != nil {
+		return x.LoadPercent
+	}
+	return 0
+}
+
+func (x *AxisInfo) GetServoTemperature() int32 {
+	if x != nil {
+		return x.ServoTemperature
+	}
+	return 0
+}
+
+func (x *AxisInfo) GetCoderTemperature() int32 {
+	if x != nil {
+		return x.CoderTemperature
+	}
+	return 0
+}
+
+func (x *AxisInfo) GetPowerConsumption() int32 {
+	if x != nil {
+		return x.PowerConsumption
+	}
+	return 0
+}
+
+func (x *AxisInfo) GetDiag_301() float64 {
+	if x != nil {
+		return x.Diag_301
+	}
+	return 0
+}
+
+type AlarmDetail struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ErrorCode            string                 `protobuf:"bytes,1,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorTypeDescription string                 `protobuf:"bytes,2,opt,name=error_type_description,json=errorTypeDescription,proto3" json:"error_type_description,omitempty"`
+	ErrorMessage         string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AlarmDetail) Reset() {
+	*x = AlarmDetail{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlarmDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlarmDetail) ProtoMessage() {}
+
+func (x *AlarmDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlarmDetail.ProtoReflect.Descriptor instead.
+func (*AlarmDetail) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AlarmDetail) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *AlarmDetail) GetErrorTypeDescription() string {
+	if x != nil {
+		return x.ErrorTypeDescription
+	}
+	return ""
+}
+
+func (x *AlarmDetail) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type CurrentProgramInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProgramName   string                 `protobuf:"bytes,1,opt,name=program_name,json=programName,proto3" json:"program_name,omitempty"`
+	ProgramNumber int64                  `protobuf:"varint,2,opt,name=program_number,json=programNumber,proto3" json:"program_number,omitempty"`
+	GCodeLine     string                 `protobuf:"bytes,3,opt,name=g_code_line,json=gCodeLine,proto3" json:"g_code_line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CurrentProgramInfo) Reset() {
+	*x = CurrentProgramInfo{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrentProgramInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrentProgramInfo) ProtoMessage() {}
+
+func (x *CurrentProgramInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrentProgramInfo.ProtoReflect.Descriptor instead.
+func (*CurrentProgramInfo) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CurrentProgramInfo) GetProgramName() string {
+	if x != nil {
+		return x.ProgramName
+	}
+	return ""
+}
+
+func (x *CurrentProgramInfo) GetProgramNumber() int64 {
+	if x != nil {
+		return x.ProgramNumber
+	}
+	return 0
+}
+
+func (x *CurrentProgramInfo) GetGCodeLine() string {
+	if x != nil {
+		return x.GCodeLine
+	}
+	return ""
+}
+
+type SpindleInfo struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Number           int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	SpeedRpm         int32                  `protobuf:"varint,2,opt,name=speed_rpm,json=speedRpm,proto3" json:"speed_rpm,omitempty"`
+	LoadPercent      float64                `protobuf:"fixed64,3,opt,name=load_percent,json=loadPercent,proto3" json:"load_percent,omitempty"`
+	OverridePercent  int32                  `protobuf:"varint,4,opt,name=override_percent,json=overridePercent,proto3" json:"override_percent,omitempty"`
+	PowerConsumption int32                  `protobuf:"varint,5,opt,name=power_consumption,json=powerConsumption,proto3" json:"power_consumption,omitempty"`
+	Diag_411Value    int32                  `protobuf:"varint,6,opt,name=diag_411_value,json=diag411Value,proto3" json:"diag_411_value,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SpindleInfo) Reset() {
+	*x = SpindleInfo{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpindleInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpindleInfo) ProtoMessage() {}
+
+func (x *SpindleInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpindleInfo.ProtoReflect.Descriptor instead.
+func (*SpindleInfo) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SpindleInfo) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *SpindleInfo) GetSpeedRpm() int32 {
+	if x != nil {
+		return x.SpeedRpm
+	}
+	return 0
+}
+
+func (x *SpindleInfo) GetLoadPercent() float64 {
+	if x != nil {
+		return x.LoadPercent
+	}
+	return 0
+}
+
+func (x *SpindleInfo) GetOverridePercent() int32 {
+	if x != nil {
+		return x.OverridePercent
+	}
+	return 0
+}
+
+func (x *SpindleInfo) GetPowerConsumption() int32 {
+	if x != nil {
+		return x.PowerConsumption
+	}
+	return 0
+}
+
+func (x *SpindleInfo) GetDiag_411Value() int32 {
+	if x != nil {
+		return x.Diag_411Value
+	}
+	return 0
+}
+
+// DataAddress selects count consecutive values of one data class starting at
+// number: parameter, macro variable or diagnostic numbers, or the byte address
+// within a PMC area.
+type DataAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Class         string                 `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"` // parameter, macro, pmc, diagnosis
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"` // default 1
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`    // byte, word, dword, real
+	Axis          int32                  `protobuf:"varint,5,opt,name=axis,proto3" json:"axis,omitempty"`   // parameter and diagnosis, 0 - none
+	Area          string                 `protobuf:"bytes,6,opt,name=area,proto3" json:"area,omitempty"`    // PMC area: G, F, Y, X, A, R, T, K, C, D, E
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataAddress) Reset() {
+	*x = DataAddress{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataAddress) ProtoMessage() {}
+
+func (x *DataAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataAddress.ProtoReflect.Descriptor instead.
+func (*DataAddress) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DataAddress) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *DataAddress) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *DataAddress) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *DataAddress) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DataAddress) GetAxis() int32 {
+	if x != nil {
+		return x.Axis
+	}
+	return 0
+}
+
+func (x *DataAddress) GetArea() string {
+	if x != nil {
+		return x.Area
+	}
+	return ""
+}
+
+type DataValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Vacant        bool                   `protobuf:"varint,3,opt,name=vacant,proto3" json:"vacant,omitempty"` // macro variable without a value
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataValue) Reset() {
+	*x = DataValue{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataValue) ProtoMessage() {}
+
+func (x *DataValue) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataValue.ProtoReflect.Descriptor instead.
+func (*DataValue) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DataValue) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *DataValue) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *DataValue) GetVacant() bool {
+	if x != nil {
+		return x.Vacant
+	}
+	return false
+}
+
+// DataReading is the result of reading a DataAddress. In a polling snapshot an
+// address that could not be read carries error instead of values.
+type DataReading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       *DataAddress           `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Values        []*DataValue           `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataReading) Reset() {
+	*x = DataReading{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataReading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataReading) ProtoMessage() {}
+
+func (x *DataReading) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataReading.ProtoReflect.Descriptor instead.
+func (*DataReading) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DataReading) GetAddress() *DataAddress {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *DataReading) GetValues() []*DataValue {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *DataReading) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ProgramPosition is where a machine is in the program it executes: the
+// executing block located in the cached program text with the lines around it.
+type ProgramPosition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`     // executing program
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`   // stored version of the cached text
+	Sequence      int32                  `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"` // N number executed last as the CNC reports it
+	Block         string                 `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`        // executing block as the CNC reports it
+	Line          int32                  `protobuf:"varint,5,opt,name=line,proto3" json:"line,omitempty"`         // 0 if the block is not found in the text
+	Matched       string                 `protobuf:"bytes,6,opt,name=matched,proto3" json:"matched,omitempty"`    // block or, by the N number only, sequence
+	Lines         []*ProgramLine         `protobuf:"bytes,7,rep,name=lines,proto3" json:"lines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgramPosition) Reset() {
+	*x = ProgramPosition{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgramPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgramPosition) ProtoMessage() {}
+
+func (x *ProgramPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgramPosition.ProtoReflect.Descriptor instead.
+func (*ProgramPosition) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProgramPosition) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *ProgramPosition) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ProgramPosition) GetSequence() int32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ProgramPosition) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *ProgramPosition) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ProgramPosition) GetMatched() string {
+	if x != nil {
+		return x.Matched
+	}
+	return ""
+}
+
+func (x *ProgramPosition) GetLines() []*ProgramLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+type ProgramLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Current       bool                   `protobuf:"varint,3,opt,name=current,proto3" json:"current,omitempty"` // the executing block
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgramLine) Reset() {
+	*x = ProgramLine{}
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProgramLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProgramLine) ProtoMessage() {}
+
+func (x *ProgramLine) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_machine_data_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProgramLine.ProtoReflect.Descriptor instead.
+func (*ProgramLine) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_machine_data_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProgramLine) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ProgramLine) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ProgramLine) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+var File_api_fanuc_v1_machine_data_proto protoreflect.FileDescriptor
+
+const file_api_fanuc_v1_machine_data_proto_rawDesc = "" +
+	"\n" +
+	"\x1fapi/fanuc/v1/machine_data.proto\x12\bfanuc.v1\"\xac\x05\n" +
+	"\x13MachineDataEnvelope\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\tR\rschemaVersion\x12'\n" +
+	"\x0fservice_version\x18\x02 \x01(\tR\x0eserviceVersion\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x03 \x01(\tR\tmachineId\x12,\n" +
+	"\x12adapter_machine_id\x18\x04 \x01(\tR\x10adapterMachineId\x12\x1a\n" +
+	"\bendpoint\x18\x05 \x01(\tR\bendpoint\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12\x16\n" +
+	"\x06series\x18\a \x01(\tR\x06series\x12A\n" +
+	"\x06labels\x18\b \x03(\v2).fanuc.v1.MachineDataEnvelope.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bsequence\x18\t \x01(\x04R\bsequence\x12+\n" +
+	"\x12poll_started_at_ms\x18\n" +
+	" \x01(\x03R\x0fpollStartedAtMs\x12-\n" +
+	"\x13poll_finished_at_ms\x18\v \x01(\x03R\x10pollFinishedAtMs\x12&\n" +
+	"\x0fpoll_latency_ms\x18\f \x01(\x03R\rpollLatencyMs\x12,\n" +
+	"\x04data\x18\r \x01(\v2\x18.fanuc.v1.AggregatedDataR\x04data\x12+\n" +
+	"\x05reads\x18\x0e \x03(\v2\x15.fanuc.v1.DataReadingR\x05reads\x125\n" +
+	"\bposition\x18\x0f \x01(\v2\x19.fanuc.v1.ProgramPositionR\bposition\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\b\n" +
+	"\x0eAggregatedData\x12\x1d\n" +
+	"\n" +
+	"machine_id\x18\x01 \x01(\tR\tmachineId\x12!\n" +
+	"\ftimestamp_ms\x18\x02 \x01(\x03R\vtimestampMs\x12\x1d\n" +
+	"\n" +
+	"is_enabled\x18\x03 \x01(\bR\tisEnabled\x12!\n" +
+	"\fis_emergency\x18\x04 \x01(\bR\visEmergency\x12#\n" +
+	"\rmachine_state\x18\x05 \x01(\tR\fmachineState\x12!\n" +
+	"\fprogram_mode\x18\x06 \x01(\tR\vprogramMode\x12\x17\n" +
+	"\atm_mode\x18\a \x01(\tR\x06tmMode\x120\n" +
+	"\x14axis_movement_status\x18\b \x01(\tR\x12axisMovementStatus\x12\x1f\n" +
+	"\vmstb_status\x18\t \x01(\tR\n" +
+	"mstbStatus\x12)\n" +
+	"\x10emergency_status\x18\n" +
+	" \x01(\tR\x0femergencyStatus\x12!\n" +
+	"\falarm_status\x18\v \x01(\tR\valarmStatus\x12\x1f\n" +
+	"\vedit_status\x18\f \x01(\tR\n" +
+	"editStatus\x121\n" +
+	"\n" +
+	"axis_infos\x18\r \x03(\v2\x12.fanuc.v1.AxisInfoR\taxisInfos\x12\x1d\n" +
+	"\n" +
+	"has_alarms\x18\x0e \x01(\bR\thasAlarms\x12-\n" +
+	"\x06alarms\x18\x0f \x03(\v2\x15.fanuc.v1.AlarmDetailR\x06alarms\x12E\n" +
+	"\x0fcurrent_program\x18\x10 \x01(\v2\x1c.fanuc.v1.CurrentProgramInfoR\x0ecurrentProgram\x12:\n" +
+	"\rspindle_infos\x18\x11 \x03(\v2\x15.fanuc.v1.SpindleInfoR\fspindleInfos\x12*\n" +
+	"\x11contour_feed_rate\x18\x12 \x01(\x05R\x0fcontourFeedRate\x12(\n" +
+	"\x10actual_feed_rate\x18\x13 \x01(\x05R\x0eactualFeedRate\x12#\n" +
+	"\rfeed_override\x18\x14 \x01(\x05R\ffeedOverride\x12!\n" +
+	"\fjog_override\x18\x15 \x01(\x05R\vjogOverride\x12\x1f\n" +
+	"\vparts_count\x18\x16 \x01(\x03R\n" +
+	"partsCount\x12\"\n" +
+	"\rpower_on_time\x18\x17 \x01(\tR\vpowerOnTime\x12%\n" +
+	"\x0eoperating_time\x18\x18 \x01(\tR\roperatingTime\x12\x1d\n" +
+	"\n" +
+	"cycle_time\x18\x19 \x01(\tR\tcycleTime\x12!\n" +
+	"\fcutting_time\x18\x1a \x01(\tR\vcuttingTime\"\xff\x01\n" +
+	"\bAxisInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x01R\bposition\x12!\n" +
+	"\fload_percent\x18\x03 \x01(\x01R\vloadPercent\x12+\n" +
+	"\x11servo_temperature\x18\x04 \x01(\x05R\x10servoTemperature\x12+\n" +
+	"\x11coder_temperature\x18\x05 \x01(\x05R\x10coderTemperature\x12+\n" +
+	"\x11power_consumption\x18\x06 \x01(\x05R\x10powerConsumption\x12\x19\n" +
+	"\bdiag_301\x18\a \x01(\x01R\adiag301\"\x87\x01\n" +
+	"\vAlarmDetail\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x01 \x01(\tR\terrorCode\x124\n" +
+	"\x16error_type_description\x18\x02 \x01(\tR\x14errorTypeDescription\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"~\n" +
+	"\x12CurrentProgramInfo\x12!\n" +
+	"\fprogram_name\x18\x01 \x01(\tR\vprogramName\x12%\n" +
+	"\x0eprogram_number\x18\x02 \x01(\x03R\rprogramNumber\x12\x1e\n" +
+	"\vg_code_line\x18\x03 \x01(\tR\tgCodeLine\"\xe3\x01\n" +
+	"\vSpindleInfo\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x1b\n" +
+	"\tspeed_rpm\x18\x02 \x01(\x05R\bspeedRpm\x12!\n" +
+	"\fload_percent\x18\x03 \x01(\x01R\vloadPercent\x12)\n" +
+	"\x10override_percent\x18\x04 \x01(\x05R\x0foverridePercent\x12+\n" +
+	"\x11power_consumption\x18\x05 \x01(\x05R\x10powerConsumption\x12$\n" +
+	"\x0ediag_411_value\x18\x06 \x01(\x05R\fdiag411Value\"\x8d\x01\n" +
+	"\vDataAddress\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x12\n" +
+	"\x04axis\x18\x05 \x01(\x05R\x04axis\x12\x12\n" +
+	"\x04area\x18\x06 \x01(\tR\x04area\"Q\n" +
+	"\tDataValue\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06vacant\x18\x03 \x01(\bR\x06vacant\"\x81\x01\n" +
+	"\vDataReading\x12/\n" +
+	"\aaddress\x18\x01 \x01(\v2\x15.fanuc.v1.DataAddressR\aaddress\x12+\n" +
+	"\x06values\x18\x02 \x03(\v2\x13.fanuc.v1.DataValueR\x06values\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xd0\x01\n" +
+	"\x0fProgramPosition\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x05R\bsequence\x12\x14\n" +
+	"\x05block\x18\x04 \x01(\tR\x05block\x12\x12\n" +
+	"\x04line\x18\x05 \x01(\x05R\x04line\x12\x18\n" +
+	"\amatched\x18\x06 \x01(\tR\amatched\x12+\n" +
+	"\x05lines\x18\a \x03(\v2\x15.fanuc.v1.ProgramLineR\x05lines\"O\n" +
+	"\vProgramLine\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x18\n" +
+	"\acurrent\x18\x03 \x01(\bR\acurrentB6Z4github.com/iwtcode/fanucService/api/fanuc/v1;fanucv1b\x06proto3"
+
+var (
+	file_api_fanuc_v1_machine_data_proto_rawDescOnce sync.Once
+	file_api_fanuc_v1_machine_data_proto_rawDescData []byte
+)
+
+func file_api_fanuc_v1_machine_data_proto_rawDescGZIP() []byte {
+	file_api_fanuc_v1_machine_data_proto_rawDescOnce.Do(func() {
+		file_api_fanuc_v1_machine_data_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_fanuc_v1_machine_data_proto_rawDesc), len(file_api_fanuc_v1_machine_data_proto_rawDesc)))
+	})
+	return file_api_fanuc_v1_machine_data_proto_rawDescData
+}
+
+var file_api_fanuc_v1_machine_data_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_api_fanuc_v1_machine_data_proto_goTypes = []any{
+	(*MachineDataEnvelope)(nil), // 0: fanuc.v1.MachineDataEnvelope
+	(*AggregatedData)(nil),      // 1: fanuc.v1.AggregatedData
+	(*AxisInfo)(nil),            // 2: fanuc.v1.AxisInfo
+	(*AlarmDetail)(nil),         // 3: fanuc.v1.AlarmDetail
+	(*CurrentProgramInfo)(nil),  // 4: fanuc.v1.CurrentProgramInfo
+	(*SpindleInfo)(nil),         // 5: fanuc.v1.SpindleInfo
+	(*DataAddress)(nil),         // 6: fanuc.v1.DataAddress
+	(*DataValue)(nil),           // 7: fanuc.v1.DataValue
+	(*DataReading)(nil),         // 8: fanuc.v1.DataReading
+	(*ProgramPosition)(nil),     // 9: fanuc.v1.ProgramPosition
+	(*ProgramLine)(nil),         // 10: fanuc.v1.ProgramLine
+	nil,                         // 11: fanuc.v1.MachineDataEnvelope.LabelsEntry
+}
+var file_api_fanuc_v1_machine_data_proto_depIdxs = []int32{
+	11, // 0: fanuc.v1.MachineDataEnvelope.labels:type_name -> fanuc.v1.MachineDataEnvelope.LabelsEntry
+	1,  // 1: fanuc.v1.MachineDataEnvelope.data:type_name -> fanuc.v1.AggregatedData
+	8,  // 2: fanuc.v1.MachineDataEnvelope.reads:type_name -> fanuc.v1.DataReading
+	9,  // 3: fanuc.v1.MachineDataEnvelope.position:type_name -> fanuc.v1.ProgramPosition
+	2,  // 4: fanuc.v1.AggregatedData.axis_infos:type_name -> fanuc.v1.AxisInfo
+	3,  // 5: fanuc.v1.AggregatedData.alarms:type_name -> fanuc.v1.AlarmDetail
+	4,  // 6: fanuc.v1.AggregatedData.current_program:type_name -> fanuc.v1.CurrentProgramInfo
+	5,  // 7: fanuc.v1.AggregatedData.spindle_infos:type_name -> fanuc.v1.SpindleInfo
+	6,  // 8: fanuc.v1.DataReading.address:type_name -> fanuc.v1.DataAddress
+	7,  // 9: fanuc.v1.DataReading.values:type_name -> fanuc.v1.DataValue
+	10, // 10: fanuc.v1.ProgramPosition.lines:type_name -> fanuc.v1.ProgramLine
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_api_fanuc_v1_machine_data_proto_init() }
+func file_api_fanuc_v1_machine_data_proto_init() {
+	if File_api_fanuc_v1_machine_data_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_fanuc_v1_machine_data_proto_rawDesc), len(file_api_fanuc_v1_machine_data_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_fanuc_v1_machine_data_proto_goTypes,
+		DependencyIndexes: file_api_fanuc_v1_machine_data_proto_depIdxs,
+		MessageInfos:      file_api_fanuc_v1_machine_data_proto_msgTypes,
+	}.Build()
+	File_api_fanuc_v1_machine_data_proto = out.File
+	file_api_fanuc_v1_machine_data_proto_goTypes = nil
+	file_api_fanuc_v1_machine_data_proto_depIdxs = nil
+}
@@ -0,0 +1,24 @@
+// Package fanucv1 contains the message definitions fanucService publishes
+// to Kafka and its gRPC API. The Go code is generated from machine_data.proto
+// and service.proto:
+//
+//	protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative api/fanuc/v1/machine_data.proto
+//	protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative \
+//		--go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/fanuc/v1/service.proto
+package fanucv1
+
+import _ "embed"
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative api/fanuc/v1/machine_data.proto
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/fanuc/v1/service.proto
+
+// MachineDataProto is the source of machine_data.proto, registered in the
+// schema registry when the Protobuf encoding is used.
+//
+//go:embed machine_data.proto
+var MachineDataProto string
+
+// MachineDataAvro is the Avro schema of MachineDataEnvelope.
+//
+//go:embed machine_data.avsc
+var MachineDataAvro string
@@ -0,0 +1,1757 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/fanuc/v1/service.proto
+
+package fanucv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Machine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // ip:port
+	Timeout       int32                  `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`  // ms
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`
+	Series        string                 `protobuf:"bytes,5,opt,name=series,proto3" json:"series,omitempty"`
+	Interval      int32                  `protobuf:"varint,6,opt,name=interval,proto3" json:"interval,omitempty"` // polling interval, ms
+	Labels        map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // connected / reconnecting
+	Mode          string                 `protobuf:"bytes,9,opt,name=mode,proto3" json:"mode,omitempty"`     // static / polling
+	CreatedAtMs   int64                  `protobuf:"varint,10,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	UpdatedAtMs   int64                  `protobuf:"varint,11,opt,name=updated_at_ms,json=updatedAtMs,proto3" json:"updated_at_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Machine) Reset() {
+	*x = Machine{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Machine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Machine) ProtoMessage() {}
+
+func (x *Machine) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Machine.ProtoReflect.Descriptor instead.
+func (*Machine) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Machine) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Machine) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *Machine) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Machine) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Machine) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *Machine) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *Machine) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Machine) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Machine) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Machine) GetCreatedAtMs() int64 {
+	if x != nil {
+		return x.CreatedAtMs
+	}
+	return 0
+}
+
+func (x *Machine) GetUpdatedAtMs() int64 {
+	if x != nil {
+		return x.UpdatedAtMs
+	}
+	return 0
+}
+
+type CreateConnectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint      string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // ip:port
+	Timeout       int32                  `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`  // ms, default 5000
+	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Series        string                 `protobuf:"bytes,4,opt,name=series,proto3" json:"series,omitempty"` // "0i", "31i"
+	Labels        map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateConnectionRequest) Reset() {
+	*x = CreateConnectionRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateConnectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateConnectionRequest) ProtoMessage() {}
+
+func (x *CreateConnectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateConnectionRequest.ProtoReflect.Descriptor instead.
+func (*CreateConnectionRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateConnectionRequest) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *CreateConnectionRequest) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *CreateConnectionRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *CreateConnectionRequest) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *CreateConnectionRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ListConnectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsRequest) Reset() {
+	*x = ListConnectionsRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsRequest) ProtoMessage() {}
+
+func (x *ListConnectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsRequest.ProtoReflect.Descriptor instead.
+func (*ListConnectionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{2}
+}
+
+type ListConnectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Machines      []*Machine             `protobuf:"bytes,1,rep,name=machines,proto3" json:"machines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectionsResponse) Reset() {
+	*x = ListConnectionsResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectionsResponse) ProtoMessage() {}
+
+func (x *ListConnectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectionsResponse.ProtoReflect.Descriptor instead.
+func (*ListConnectionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListConnectionsResponse) GetMachines() []*Machine {
+	if x != nil {
+		return x.Machines
+	}
+	return nil
+}
+
+type CheckConnectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckConnectionRequest) Reset() {
+	*x = CheckConnectionRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckConnectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckConnectionRequest) ProtoMessage() {}
+
+func (x *CheckConnectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckConnectionRequest.ProtoReflect.Descriptor instead.
+func (*CheckConnectionRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckConnectionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteConnectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteConnectionRequest) Reset() {
+	*x = DeleteConnectionRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConnectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConnectionRequest) ProtoMessage() {}
+
+func (x *DeleteConnectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConnectionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteConnectionRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteConnectionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteConnectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteConnectionResponse) Reset() {
+	*x = DeleteConnectionResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConnectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConnectionResponse) ProtoMessage() {}
+
+func (x *DeleteConnectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConnectionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteConnectionResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{6}
+}
+
+type StartPollingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Interval      int32                  `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`                             // ms, default 5000
+	Reads         []*DataAddress         `protobuf:"bytes,3,rep,name=reads,proto3" json:"reads,omitempty"`                                    // read with every poll into the snapshot
+	WatchOffsets  bool                   `protobuf:"varint,4,opt,name=watch_offsets,json=watchOffsets,proto3" json:"watch_offsets,omitempty"` // publish tool and work offset changes found by polling
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPollingRequest) Reset() {
+	*x = StartPollingRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPollingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPollingRequest) ProtoMessage() {}
+
+func (x *StartPollingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPollingRequest.ProtoReflect.Descriptor instead.
+func (*StartPollingRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StartPollingRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StartPollingRequest) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *StartPollingRequest) GetReads() []*DataAddress {
+	if x != nil {
+		return x.Reads
+	}
+	return nil
+}
+
+func (x *StartPollingRequest) GetWatchOffsets() bool {
+	if x != nil {
+		return x.WatchOffsets
+	}
+	return false
+}
+
+type StartPollingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPollingResponse) Reset() {
+	*x = StartPollingResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPollingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPollingResponse) ProtoMessage() {}
+
+func (x *StartPollingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPollingResponse.ProtoReflect.Descriptor instead.
+func (*StartPollingResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{8}
+}
+
+type StopPollingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopPollingRequest) Reset() {
+	*x = StopPollingRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopPollingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopPollingRequest) ProtoMessage() {}
+
+func (x *StopPollingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopPollingRequest.ProtoReflect.Descriptor instead.
+func (*StopPollingRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StopPollingRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StopPollingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopPollingResponse) Reset() {
+	*x = StopPollingResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopPollingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopPollingResponse) ProtoMessage() {}
+
+func (x *StopPollingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopPollingResponse.ProtoReflect.Descriptor instead.
+func (*StopPollingResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{10}
+}
+
+type GetProgramRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProgramRequest) Reset() {
+	*x = GetProgramRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProgramRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProgramRequest) ProtoMessage() {}
+
+func (x *GetProgramRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProgramRequest.ProtoReflect.Descriptor instead.
+func (*GetProgramRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetProgramRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetProgramResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Program       string                 `protobuf:"bytes,1,opt,name=program,proto3" json:"program,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProgramResponse) Reset() {
+	*x = GetProgramResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProgramResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProgramResponse) ProtoMessage() {}
+
+func (x *GetProgramResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProgramResponse.ProtoReflect.Descriptor instead.
+func (*GetProgramResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetProgramResponse) GetProgram() string {
+	if x != nil {
+		return x.Program
+	}
+	return ""
+}
+
+type ReadDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address       *DataAddress           `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDataRequest) Reset() {
+	*x = ReadDataRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDataRequest) ProtoMessage() {}
+
+func (x *ReadDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDataRequest.ProtoReflect.Descriptor instead.
+func (*ReadDataRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReadDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReadDataRequest) GetAddress() *DataAddress {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type WriteDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Class         string                 `protobuf:"bytes,2,opt,name=class,proto3" json:"class,omitempty"`    // macro, pmc
+	Number        int32                  `protobuf:"varint,3,opt,name=number,proto3" json:"number,omitempty"` // first number or PMC address
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`      // PMC value type, default byte
+	Area          string                 `protobuf:"bytes,5,opt,name=area,proto3" json:"area,omitempty"`      // PMC area
+	Values        []float64              `protobuf:"fixed64,6,rep,packed,name=values,proto3" json:"values,omitempty"`
+	DryRun        bool                   `protobuf:"varint,7,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // check and read the current values without writing
+	Override      bool                   `protobuf:"varint,8,opt,name=override,proto3" json:"override,omitempty"`           // write even if the machine is in automatic operation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteDataRequest) Reset() {
+	*x = WriteDataRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteDataRequest) ProtoMessage() {}
+
+func (x *WriteDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteDataRequest.ProtoReflect.Descriptor instead.
+func (*WriteDataRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WriteDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WriteDataRequest) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *WriteDataRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *WriteDataRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WriteDataRequest) GetArea() string {
+	if x != nil {
+		return x.Area
+	}
+	return ""
+}
+
+func (x *WriteDataRequest) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *WriteDataRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *WriteDataRequest) GetOverride() bool {
+	if x != nil {
+		return x.Override
+	}
+	return false
+}
+
+type WriteDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       *DataAddress           `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Previous      []*DataValue           `protobuf:"bytes,2,rep,name=previous,proto3" json:"previous,omitempty"`
+	Values        []*DataValue           `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"` // read back, or to be written on a dry run
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Overridden    bool                   `protobuf:"varint,5,opt,name=overridden,proto3" json:"overridden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteDataResponse) Reset() {
+	*x = WriteDataResponse{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteDataResponse) ProtoMessage() {}
+
+func (x *WriteDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteDataResponse.ProtoReflect.Descriptor instead.
+func (*WriteDataResponse) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WriteDataResponse) GetAddress() *DataAddress {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *WriteDataResponse) GetPrevious() []*DataValue {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *WriteDataResponse) GetValues() []*DataValue {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *WriteDataResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *WriteDataResponse) GetOverridden() bool {
+	if x != nil {
+		return x.Overridden
+	}
+	return false
+}
+
+type ReadOffsetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadOffsetsRequest) Reset() {
+	*x = ReadOffsetsRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadOffsetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadOffsetsRequest) ProtoMessage() {}
+
+func (x *ReadOffsetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadOffsetsRequest.ProtoReflect.Descriptor instead.
+func (*ReadOffsetsRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReadOffsetsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ToolOffsets struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Turning       bool                   `protobuf:"varint,1,opt,name=turning,proto3" json:"turning,omitempty"`
+	Memory        string                 `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"` // A, B or C on a mill
+	Types         []string               `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	Offsets       []*ToolOffset          `protobuf:"bytes,4,rep,name=offsets,proto3" json:"offsets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolOffsets) Reset() {
+	*x = ToolOffsets{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolOffsets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolOffsets) ProtoMessage() {}
+
+func (x *ToolOffsets) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolOffsets.ProtoReflect.Descriptor instead.
+func (*ToolOffsets) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ToolOffsets) GetTurning() bool {
+	if x != nil {
+		return x.Turning
+	}
+	return false
+}
+
+func (x *ToolOffsets) GetMemory() string {
+	if x != nil {
+		return x.Memory
+	}
+	return ""
+}
+
+func (x *ToolOffsets) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ToolOffsets) GetOffsets() []*ToolOffset {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+type ToolOffset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Values        map[string]float64     `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // by offset type
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolOffset) Reset() {
+	*x = ToolOffset{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolOffset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolOffset) ProtoMessage() {}
+
+func (x *ToolOffset) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolOffset.ProtoReflect.Descriptor instead.
+func (*ToolOffset) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ToolOffset) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *ToolOffset) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type WorkOffsets struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Axes          []string               `protobuf:"bytes,1,rep,name=axes,proto3" json:"axes,omitempty"`
+	Offsets       []*WorkOffset          `protobuf:"bytes,2,rep,name=offsets,proto3" json:"offsets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkOffsets) Reset() {
+	*x = WorkOffsets{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkOffsets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkOffsets) ProtoMessage() {}
+
+func (x *WorkOffsets) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkOffsets.ProtoReflect.Descriptor instead.
+func (*WorkOffsets) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WorkOffsets) GetAxes() []string {
+	if x != nil {
+		return x.Axes
+	}
+	return nil
+}
+
+func (x *WorkOffsets) GetOffsets() []*WorkOffset {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+type WorkOffset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"` // 0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Values        map[string]float64     `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // by axis
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkOffset) Reset() {
+	*x = WorkOffset{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkOffset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkOffset) ProtoMessage() {}
+
+func (x *WorkOffset) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkOffset.ProtoReflect.Descriptor instead.
+func (*WorkOffset) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WorkOffset) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *WorkOffset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WorkOffset) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type WriteToolOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Incremental   bool                   `protobuf:"varint,5,opt,name=incremental,proto3" json:"incremental,omitempty"` // add value to the current value
+	Override      bool                   `protobuf:"varint,6,opt,name=override,proto3" json:"override,omitempty"`       // write even if the machine is in automatic operation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteToolOffsetRequest) Reset() {
+	*x = WriteToolOffsetRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteToolOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteToolOffsetRequest) ProtoMessage() {}
+
+func (x *WriteToolOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteToolOffsetRequest.ProtoReflect.Descriptor instead.
+func (*WriteToolOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WriteToolOffsetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WriteToolOffsetRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *WriteToolOffsetRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WriteToolOffsetRequest) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *WriteToolOffsetRequest) GetIncremental() bool {
+	if x != nil {
+		return x.Incremental
+	}
+	return false
+}
+
+func (x *WriteToolOffsetRequest) GetOverride() bool {
+	if x != nil {
+		return x.Override
+	}
+	return false
+}
+
+type WriteWorkOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number        int32                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	Axis          string                 `protobuf:"bytes,3,opt,name=axis,proto3" json:"axis,omitempty"`
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Incremental   bool                   `protobuf:"varint,5,opt,name=incremental,proto3" json:"incremental,omitempty"` // add value to the current value
+	Override      bool                   `protobuf:"varint,6,opt,name=override,proto3" json:"override,omitempty"`       // write even if the machine is in automatic operation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteWorkOffsetRequest) Reset() {
+	*x = WriteWorkOffsetRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteWorkOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteWorkOffsetRequest) ProtoMessage() {}
+
+func (x *WriteWorkOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteWorkOffsetRequest.ProtoReflect.Descriptor instead.
+func (*WriteWorkOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WriteWorkOffsetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WriteWorkOffsetRequest) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *WriteWorkOffsetRequest) GetAxis() string {
+	if x != nil {
+		return x.Axis
+	}
+	return ""
+}
+
+func (x *WriteWorkOffsetRequest) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *WriteWorkOffsetRequest) GetIncremental() bool {
+	if x != nil {
+		return x.Incremental
+	}
+	return false
+}
+
+func (x *WriteWorkOffsetRequest) GetOverride() bool {
+	if x != nil {
+		return x.Override
+	}
+	return false
+}
+
+type OffsetChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`     // work offset name
+	Offset        string                 `protobuf:"bytes,3,opt,name=offset,proto3" json:"offset,omitempty"` // tool offset type or axis
+	Previous      float64                `protobuf:"fixed64,4,opt,name=previous,proto3" json:"previous,omitempty"`
+	Value         float64                `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	Overridden    bool                   `protobuf:"varint,6,opt,name=overridden,proto3" json:"overridden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OffsetChange) Reset() {
+	*x = OffsetChange{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OffsetChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OffsetChange) ProtoMessage() {}
+
+func (x *OffsetChange) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OffsetChange.ProtoReflect.Descriptor instead.
+func (*OffsetChange) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *OffsetChange) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *OffsetChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OffsetChange) GetOffset() string {
+	if x != nil {
+		return x.Offset
+	}
+	return ""
+}
+
+func (x *OffsetChange) GetPrevious() float64 {
+	if x != nil {
+		return x.Previous
+	}
+	return 0
+}
+
+func (x *OffsetChange) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *OffsetChange) GetOverridden() bool {
+	if x != nil {
+		return x.Overridden
+	}
+	return false
+}
+
+type WatchMachineDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMachineDataRequest) Reset() {
+	*x = WatchMachineDataRequest{}
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMachineDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMachineDataRequest) ProtoMessage() {}
+
+func (x *WatchMachineDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_fanuc_v1_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMachineDataRequest.ProtoReflect.Descriptor instead.
+func (*WatchMachineDataRequest) Descriptor() ([]byte, []int) {
+	return file_api_fanuc_v1_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WatchMachineDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_api_fanuc_v1_service_proto protoreflect.FileDescriptor
+
+const file_api_fanuc_v1_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1aapi/fanuc/v1/service.proto\x12\bfanuc.v1\x1a\x1fapi/fanuc/v1/machine_data.proto\"\xff\x02\n" +
+	"\aMachine\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\x05R\atimeout\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x16\n" +
+	"\x06series\x18\x05 \x01(\tR\x06series\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\x05R\binterval\x125\n" +
+	"\x06labels\x18\a \x03(\v2\x1d.fanuc.v1.Machine.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x12\n" +
+	"\x04mode\x18\t \x01(\tR\x04mode\x12\"\n" +
+	"\rcreated_at_ms\x18\n" +
+	" \x01(\x03R\vcreatedAtMs\x12\"\n" +
+	"\rupdated_at_ms\x18\v \x01(\x03R\vupdatedAtMs\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xff\x01\n" +
+	"\x17CreateConnectionRequest\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x18\n" +
+	"\atimeout\x18\x02 \x01(\x05R\atimeout\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x16\n" +
+	"\x06series\x18\x04 \x01(\tR\x06series\x12E\n" +
+	"\x06labels\x18\x05 \x03(\v2-.fanuc.v1.CreateConnectionRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x18\n" +
+	"\x16ListConnectionsRequest\"H\n" +
+	"\x17ListConnectionsResponse\x12-\n" +
+	"\bmachines\x18\x01 \x03(\v2\x11.fanuc.v1.MachineR\bmachines\"(\n" +
+	"\x16CheckConnectionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\")\n" +
+	"\x17DeleteConnectionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1a\n" +
+	"\x18DeleteConnectionResponse\"\x93\x01\n" +
+	"\x13StartPollingRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\x05R\binterval\x12+\n" +
+	"\x05reads\x18\x03 \x03(\v2\x15.fanuc.v1.DataAddressR\x05reads\x12#\n" +
+	"\rwatch_offsets\x18\x04 \x01(\bR\fwatchOffsets\"\x16\n" +
+	"\x14StartPollingResponse\"$\n" +
+	"\x12StopPollingRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13StopPollingResponse\"#\n" +
+	"\x11GetProgramRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\".\n" +
+	"\x12GetProgramResponse\x12\x18\n" +
+	"\aprogram\x18\x01 \x01(\tR\aprogram\"R\n" +
+	"\x0fReadDataRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\aaddress\x18\x02 \x01(\v2\x15.fanuc.v1.DataAddressR\aaddress\"\xc5\x01\n" +
+	"\x10WriteDataRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05class\x18\x02 \x01(\tR\x05class\x12\x16\n" +
+	"\x06number\x18\x03 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x12\n" +
+	"\x04area\x18\x05 \x01(\tR\x04area\x12\x16\n" +
+	"\x06values\x18\x06 \x03(\x01R\x06values\x12\x17\n" +
+	"\adry_run\x18\a \x01(\bR\x06dryRun\x12\x1a\n" +
+	"\boverride\x18\b \x01(\bR\boverride\"\xdb\x01\n" +
+	"\x11WriteDataResponse\x12/\n" +
+	"\aaddress\x18\x01 \x01(\v2\x15.fanuc.v1.DataAddressR\aaddress\x12/\n" +
+	"\bprevious\x18\x02 \x03(\v2\x13.fanuc.v1.DataValueR\bprevious\x12+\n" +
+	"\x06values\x18\x03 \x03(\v2\x13.fanuc.v1.DataValueR\x06values\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12\x1e\n" +
+	"\n" +
+	"overridden\x18\x05 \x01(\bR\n" +
+	"overridden\"$\n" +
+	"\x12ReadOffsetsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x85\x01\n" +
+	"\vToolOffsets\x12\x18\n" +
+	"\aturning\x18\x01 \x01(\bR\aturning\x12\x16\n" +
+	"\x06memory\x18\x02 \x01(\tR\x06memory\x12\x14\n" +
+	"\x05types\x18\x03 \x03(\tR\x05types\x12.\n" +
+	"\aoffsets\x18\x04 \x03(\v2\x14.fanuc.v1.ToolOffsetR\aoffsets\"\x99\x01\n" +
+	"\n" +
+	"ToolOffset\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x128\n" +
+	"\x06values\x18\x02 \x03(\v2 .fanuc.v1.ToolOffset.ValuesEntryR\x06values\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"Q\n" +
+	"\vWorkOffsets\x12\x12\n" +
+	"\x04axes\x18\x01 \x03(\tR\x04axes\x12.\n" +
+	"\aoffsets\x18\x02 \x03(\v2\x14.fanuc.v1.WorkOffsetR\aoffsets\"\xad\x01\n" +
+	"\n" +
+	"WorkOffset\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x128\n" +
+	"\x06values\x18\x03 \x03(\v2 .fanuc.v1.WorkOffset.ValuesEntryR\x06values\x1a9\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xa8\x01\n" +
+	"\x16WriteToolOffsetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12 \n" +
+	"\vincremental\x18\x05 \x01(\bR\vincremental\x12\x1a\n" +
+	"\boverride\x18\x06 \x01(\bR\boverride\"\xa8\x01\n" +
+	"\x16WriteWorkOffsetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04axis\x18\x03 \x01(\tR\x04axis\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12 \n" +
+	"\vincremental\x18\x05 \x01(\bR\vincremental\x12\x1a\n" +
+	"\boverride\x18\x06 \x01(\bR\boverride\"\xa4\x01\n" +
+	"\fOffsetChange\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\tR\x06offset\x12\x1a\n" +
+	"\bprevious\x18\x04 \x01(\x01R\bprevious\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x01R\x05value\x12\x1e\n" +
+	"\n" +
+	"overridden\x18\x06 \x01(\bR\n" +
+	"overridden\")\n" +
+	"\x17WatchMachineDataRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\xbd\b\n" +
+	"\fFanucService\x12H\n" +
+	"\x10CreateConnection\x12!.fanuc.v1.CreateConnectionRequest\x1a\x11.fanuc.v1.Machine\x12V\n" +
+	"\x0fListConnections\x12 .fanuc.v1.ListConnectionsRequest\x1a!.fanuc.v1.ListConnectionsResponse\x12F\n" +
+	"\x0fCheckConnection\x12 .fanuc.v1.CheckConnectionRequest\x1a\x11.fanuc.v1.Machine\x12Y\n" +
+	"\x10DeleteConnection\x12!.fanuc.v1.DeleteConnectionRequest\x1a\".fanuc.v1.DeleteConnectionResponse\x12M\n" +
+	"\fStartPolling\x12\x1d.fanuc.v1.StartPollingRequest\x1a\x1e.fanuc.v1.StartPollingResponse\x12J\n" +
+	"\vStopPolling\x12\x1c.fanuc.v1.StopPollingRequest\x1a\x1d.fanuc.v1.StopPollingResponse\x12G\n" +
+	"\n" +
+	"GetProgram\x12\x1b.fanuc.v1.GetProgramRequest\x1a\x1c.fanuc.v1.GetProgramResponse\x12<\n" +
+	"\bReadData\x12\x19.fanuc.v1.ReadDataRequest\x1a\x15.fanuc.v1.DataReading\x12D\n" +
+	"\tWriteData\x12\x1a.fanuc.v1.WriteDataRequest\x1a\x1b.fanuc.v1.WriteDataResponse\x12F\n" +
+	"\x0fReadToolOffsets\x12\x1c.fanuc.v1.ReadOffsetsRequest\x1a\x15.fanuc.v1.ToolOffsets\x12F\n" +
+	"\x0fReadWorkOffsets\x12\x1c.fanuc.v1.ReadOffsetsRequest\x1a\x15.fanuc.v1.WorkOffsets\x12K\n" +
+	"\x0fWriteToolOffset\x12 .fanuc.v1.WriteToolOffsetRequest\x1a\x16.fanuc.v1.OffsetChange\x12K\n" +
+	"\x0fWriteWorkOffset\x12 .fanuc.v1.WriteWorkOffsetRequest\x1a\x16.fanuc.v1.OffsetChange\x12V\n" +
+	"\x10WatchMachineData\x12!.fanuc.v1.WatchMachineDataRequest\x1a\x1d.fanuc.v1.MachineDataEnvelope0\x01B6Z4github.com/iwtcode/fanucService/api/fanuc/v1;fanucv1b\x06proto3"
+
+var (
+	file_api_fanuc_v1_service_proto_rawDescOnce sync.Once
+	file_api_fanuc_v1_service_proto_rawDescData []byte
+)
+
+func file_api_fanuc_v1_service_proto_rawDescGZIP() []byte {
+	file_api_fanuc_v1_service_proto_rawDescOnce.Do(func() {
+		file_api_fanuc_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_fanuc_v1_service_proto_rawDesc), len(file_api_fanuc_v1_service_proto_rawDesc)))
+	})
+	return file_api_fanuc_v1_service_proto_rawDescData
+}
+
+var file_api_fanuc_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_api_fanuc_v1_service_proto_goTypes = []any{
+	(*Machine)(nil),                  // 0: fanuc.v1.Machine
+	(*CreateConnectionRequest)(nil),  // 1: fanuc.v1.CreateConnectionRequest
+	(*ListConnectionsRequest)(nil),   // 2: fanuc.v1.ListConnectionsRequest
+	(*ListConnectionsResponse)(nil),  // 3: fanuc.v1.ListConnectionsResponse
+	(*CheckConnectionRequest)(nil),   // 4: fanuc.v1.CheckConnectionRequest
+	(*DeleteConnectionRequest)(nil),  // 5: fanuc.v1.DeleteConnectionRequest
+	(*DeleteConnectionResponse)(nil), // 6: fanuc.v1.DeleteConnectionResponse
+	(*StartPollingRequest)(nil),      // 7: fanuc.v1.StartPollingRequest
+	(*StartPollingResponse)(nil),     // 8: fanuc.v1.StartPollingResponse
+	(*StopPollingRequest)(nil),       // 9: fanuc.v1.StopPollingRequest
+	(*StopPollingResponse)(nil),      // 10: fanuc.v1.StopPollingResponse
+	(*GetProgramRequest)(nil),        // 11: fanuc.v1.GetProgramRequest
+	(*GetProgramResponse)(nil),       // 12: fanuc.v1.GetProgramResponse
+	(*ReadDataRequest)(nil),          // 13: fanuc.v1.ReadDataRequest
+	(*WriteDataRequest)(nil),         // 14: fanuc.v1.WriteDataRequest
+	(*WriteDataResponse)(nil),        // 15: fanuc.v1.WriteDataResponse
+	(*ReadOffsetsRequest)(nil),       // 16: fanuc.v1.ReadOffsetsRequest
+	(*ToolOffsets)(nil),              // 17: fanuc.v1.ToolOffsets
+	(*ToolOffset)(nil),               // 18: fanuc.v1.ToolOffset
+	(*WorkOffsets)(nil),              // 19: fanuc.v1.WorkOffsets
+	(*WorkOffset)(nil),               // 20: fanuc.v1.WorkOffset
+	(*WriteToolOffsetRequest)(nil),   // 21: fanuc.v1.WriteToolOffsetRequest
+	(*WriteWorkOffsetRequest)(nil),   // 22: fanuc.v1.WriteWorkOffsetRequest
+	(*OffsetChange)(nil),             // 23: fanuc.v1.OffsetChange
+	(*WatchMachineDataRequest)(nil),  // 24: fanuc.v1.WatchMachineDataRequest
+	nil,                              // 25: fanuc.v1.Machine.LabelsEntry
+	nil,                              // 26: fanuc.v1.CreateConnectionRequest.LabelsEntry
+	nil,                              // 27: fanuc.v1.ToolOffset.ValuesEntry
+	nil,                              // 28: fanuc.v1.WorkOffset.ValuesEntry
+	(*DataAddress)(nil),              // 29: fanuc.v1.DataAddress
+	(*DataValue)(nil),                // 30: fanuc.v1.DataValue
+	(*DataReading)(nil),              // 31: fanuc.v1.DataReading
+	(*MachineDataEnvelope)(nil),      // 32: fanuc.v1.MachineDataEnvelope
+}
+var file_api_fanuc_v1_service_proto_depIdxs = []int32{
+	25, // 0: fanuc.v1.Machine.labels:type_name -> fanuc.v1.Machine.LabelsEntry
+	26, // 1: fanuc.v1.CreateConnectionRequest.labels:type_name -> fanuc.v1.CreateConnectionRequest.LabelsEntry
+	0,  // 2: fanuc.v1.ListConnectionsResponse.machines:type_name -> fanuc.v1.Machine
+	29, // 3: fanuc.v1.StartPollingRequest.reads:type_name -> fanuc.v1.DataAddress
+	29, // 4: fanuc.v1.ReadDataRequest.address:type_name -> fanuc.v1.DataAddress
+	29, // 5: fanuc.v1.WriteDataResponse.address:type_name -> fanuc.v1.DataAddress
+	30, // 6: fanuc.v1.WriteDataResponse.previous:type_name -> fanuc.v1.DataValue
+	30, // 7: fanuc.v1.WriteDataResponse.values:type_name -> fanuc.v1.DataValue
+	18, // 8: fanuc.v1.ToolOffsets.offsets:type_name -> fanuc.v1.ToolOffset
+	27, // 9: fanuc.v1.ToolOffset.values:type_name -> fanuc.v1.ToolOffset.ValuesEntry
+	20, // 10: fanuc.v1.WorkOffsets.offsets:type_name -> fanuc.v1.WorkOffset
+	28, // 11: fanuc.v1.WorkOffset.values:type_name -> fanuc.v1.WorkOffset.ValuesEntry
+	1,  // 12: fanuc.v1.FanucService.CreateConnection:input_type -> fanuc.v1.CreateConnectionRequest
+	2,  // 13: fanuc.v1.FanucService.ListConnections:input_type -> fanuc.v1.ListConnectionsRequest
+	4,  // 14: fanuc.v1.FanucService.CheckConnection:input_type -> fanuc.v1.CheckConnectionRequest
+	5,  // 15: fanuc.v1.FanucService.DeleteConnection:input_type -> fanuc.v1.DeleteConnectionRequest
+	7,  // 16: fanuc.v1.FanucService.StartPolling:input_type -> fanuc.v1.StartPollingRequest
+	9,  // 17: fanuc.v1.FanucService.StopPolling:input_type -> fanuc.v1.StopPollingRequest
+	11, // 18: fanuc.v1.FanucService.GetProgram:input_type -> fanuc.v1.GetProgramRequest
+	13, // 19: fanuc.v1.FanucService.ReadData:input_type -> fanuc.v1.ReadDataRequest
+	14, // 20: fanuc.v1.FanucService.WriteData:input_type -> fanuc.v1.WriteDataRequest
+	16, // 21: fanuc.v1.FanucService.ReadToolOffsets:input_type -> fanuc.v1.ReadOffsetsRequest
+	16, // 22: fanuc.v1.FanucService.ReadWorkOffsets:input_type -> fanuc.v1.ReadOffsetsRequest
+	21, // 23: fanuc.v1.FanucService.WriteToolOffset:input_type -> fanuc.v1.WriteToolOffsetRequest
+	22, // 24: fanuc.v1.FanucService.WriteWorkOffset:input_type -> fanuc.v1.WriteWorkOffsetRequest
+	24, // 25: fanuc.v1.FanucService.WatchMachineData:input_type -> fanuc.v1.WatchMachineDataRequest
+	0,  // 26: fanuc.v1.FanucService.CreateConnection:output_type -> fanuc.v1.Machine
+	3,  // 27: fanuc.v1.FanucService.ListConnections:output_type -> fanuc.v1.ListConnectionsResponse
+	0,  // 28: fanuc.v1.FanucService.CheckConnection:output_type -> fanuc.v1.Machine
+	6,  // 29: fanuc.v1.FanucService.DeleteConnection:output_type -> fanuc.v1.DeleteConnectionResponse
+	8,  // 30: fanuc.v1.FanucService.StartPolling:output_type -> fanuc.v1.StartPollingResponse
+	10, // 31: fanuc.v1.FanucService.StopPolling:output_type -> fanuc.v1.StopPollingResponse
+	12, // 32: fanuc.v1.FanucService.GetProgram:output_type -> fanuc.v1.GetProgramResponse
+	31, // 33: fanuc.v1.FanucService.ReadData:output_type -> fanuc.v1.DataReading
+	15, // 34: fanuc.v1.FanucService.WriteData:output_type -> fanuc.v1.WriteDataResponse
+	17, // 35: fanuc.v1.FanucService.ReadToolOffsets:output_type -> fanuc.v1.ToolOffsets
+	19, // 36: fanuc.v1.FanucService.ReadWorkOffsets:output_type -> fanuc.v1.WorkOffsets
+	23, // 37: fanuc.v1.FanucService.WriteToolOffset:output_type -> fanuc.v1.OffsetChange
+	23, // 38: fanuc.v1.FanucService.WriteWorkOffset:output_type -> fanuc.v1.OffsetChange
+	32, // 39: fanuc.v1.FanucService.WatchMachineData:output_type -> fanuc.v1.MachineDataEnvelope
+	26, // [26:40] is the sub-list for method output_type
+	12, // [12:26] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_api_fanuc_v1_service_proto_init() }
+func file_api_fanuc_v1_service_proto_init() {
+	if File_api_fanuc_v1_service_proto != nil {
+		return
+	}
+	file_api_fanuc_v1_machine_data_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_fanuc_v1_service_proto_rawDesc), len(file_api_fanuc_v1_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   29,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_fanuc_v1_service_proto_goTypes,
+		DependencyIndexes: file_api_fanuc_v1_service_proto_depIdxs,
+		MessageInfos:      file_api_fanuc_v1_service_proto_msgTypes,
+	}.Build()
+	File_api_fanuc_v1_service_proto = out.File
+	file_api_fanuc_v1_service_proto_goTypes = nil
+	file_api_fanuc_v1_service_proto_depIdxs = nil
+}
@@ -0,0 +1,653 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: api/fanuc/v1/service.proto
+
+package fanucv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FanucService_CreateConnection_FullMethodName = "/fanuc.v1.FanucService/CreateConnection"
+	FanucService_ListConnections_FullMethodName  = "/fanuc.v1.FanucService/ListConnections"
+	FanucService_CheckConnection_FullMethodName  = "/fanuc.v1.FanucService/CheckConnection"
+	FanucService_DeleteConnection_FullMethodName = "/fanuc.v1.FanucService/DeleteConnection"
+	FanucService_StartPolling_FullMethodName     = "/fanuc.v1.FanucService/StartPolling"
+	FanucService_StopPolling_FullMethodName      = "/fanuc.v1.FanucService/StopPolling"
+	FanucService_GetProgram_FullMethodName       = "/fanuc.v1.FanucService/GetProgram"
+	FanucService_ReadData_FullMethodName         = "/fanuc.v1.FanucService/ReadData"
+	FanucService_WriteData_FullMethodName        = "/fanuc.v1.FanucService/WriteData"
+	FanucService_ReadToolOffsets_FullMethodName  = "/fanuc.v1.FanucService/ReadToolOffsets"
+	FanucService_ReadWorkOffsets_FullMethodName  = "/fanuc.v1.FanucService/ReadWorkOffsets"
+	FanucService_WriteToolOffset_FullMethodName  = "/fanuc.v1.FanucService/WriteToolOffset"
+	FanucService_WriteWorkOffset_FullMethodName  = "/fanuc.v1.FanucService/WriteWorkOffset"
+	FanucService_WatchMachineData_FullMethodName = "/fanuc.v1.FanucService/WatchMachineData"
+)
+
+// FanucServiceClient is the client API for FanucService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FanucService mirrors the REST API under /api/v1. Every call requires the
+// API key in the "x-api-key" metadata. Timestamps are Unix milliseconds.
+type FanucServiceClient interface {
+	CreateConnection(ctx context.Context, in *CreateConnectionRequest, opts ...grpc.CallOption) (*Machine, error)
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	CheckConnection(ctx context.Context, in *CheckConnectionRequest, opts ...grpc.CallOption) (*Machine, error)
+	DeleteConnection(ctx context.Context, in *DeleteConnectionRequest, opts ...grpc.CallOption) (*DeleteConnectionResponse, error)
+	StartPolling(ctx context.Context, in *StartPollingRequest, opts ...grpc.CallOption) (*StartPollingResponse, error)
+	StopPolling(ctx context.Context, in *StopPollingRequest, opts ...grpc.CallOption) (*StopPollingResponse, error)
+	GetProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*GetProgramResponse, error)
+	// ReadData reads parameters, macro variables, PMC addresses or diagnostic
+	// numbers of the machine.
+	ReadData(ctx context.Context, in *ReadDataRequest, opts ...grpc.CallOption) (*DataReading, error)
+	// WriteData writes macro variables or PMC values allowed by the write rules
+	// of the machine. It requires the write scope, fails with FAILED_PRECONDITION
+	// while the CNC is in automatic operation unless override is set, and with
+	// UNAVAILABLE if its audit entry cannot be stored.
+	WriteData(ctx context.Context, in *WriteDataRequest, opts ...grpc.CallOption) (*WriteDataResponse, error)
+	// ReadToolOffsets and ReadWorkOffsets read the offset tables of the machine.
+	ReadToolOffsets(ctx context.Context, in *ReadOffsetsRequest, opts ...grpc.CallOption) (*ToolOffsets, error)
+	ReadWorkOffsets(ctx context.Context, in *ReadOffsetsRequest, opts ...grpc.CallOption) (*WorkOffsets, error)
+	// WriteToolOffset and WriteWorkOffset set one offset value. They require the
+	// write scope and fail with UNAVAILABLE if their audit entry cannot be
+	// stored. Like WriteData they follow the write rules of the machine and fail
+	// with FAILED_PRECONDITION in automatic operation unless override is set.
+	WriteToolOffset(ctx context.Context, in *WriteToolOffsetRequest, opts ...grpc.CallOption) (*OffsetChange, error)
+	WriteWorkOffset(ctx context.Context, in *WriteWorkOffsetRequest, opts ...grpc.CallOption) (*OffsetChange, error)
+	// WatchMachineData streams every polling snapshot of the machine while the
+	// call is open. Polling has to be started separately; snapshots are dropped
+	// for a client that cannot keep up.
+	WatchMachineData(ctx context.Context, in *WatchMachineDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MachineDataEnvelope], error)
+}
+
+type fanucServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFanucServiceClient(cc grpc.ClientConnInterface) FanucServiceClient {
+	return &fanucServiceClient{cc}
+}
+
+func (c *fanucServiceClient) CreateConnection(ctx context.Context, in *CreateConnectionRequest, opts ...grpc.CallOption) (*Machine, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Machine)
+	err := c.cc.Invoke(ctx, FanucService_CreateConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, FanucService_ListConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) CheckConnection(ctx context.Context, in *CheckConnectionRequest, opts ...grpc.CallOption) (*Machine, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Machine)
+	err := c.cc.Invoke(ctx, FanucService_CheckConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) DeleteConnection(ctx context.Context, in *DeleteConnectionRequest, opts ...grpc.CallOption) (*DeleteConnectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteConnectionResponse)
+	err := c.cc.Invoke(ctx, FanucService_DeleteConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) StartPolling(ctx context.Context, in *StartPollingRequest, opts ...grpc.CallOption) (*StartPollingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartPollingResponse)
+	err := c.cc.Invoke(ctx, FanucService_StartPolling_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) StopPolling(ctx context.Context, in *StopPollingRequest, opts ...grpc.CallOption) (*StopPollingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopPollingResponse)
+	err := c.cc.Invoke(ctx, FanucService_StopPolling_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) GetProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*GetProgramResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProgramResponse)
+	err := c.cc.Invoke(ctx, FanucService_GetProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) ReadData(ctx context.Context, in *ReadDataRequest, opts ...grpc.CallOption) (*DataReading, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DataReading)
+	err := c.cc.Invoke(ctx, FanucService_ReadData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) WriteData(ctx context.Context, in *WriteDataRequest, opts ...grpc.CallOption) (*WriteDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteDataResponse)
+	err := c.cc.Invoke(ctx, FanucService_WriteData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) ReadToolOffsets(ctx context.Context, in *ReadOffsetsRequest, opts ...grpc.CallOption) (*ToolOffsets, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ToolOffsets)
+	err := c.cc.Invoke(ctx, FanucService_ReadToolOffsets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) ReadWorkOffsets(ctx context.Context, in *ReadOffsetsRequest, opts ...grpc.CallOption) (*WorkOffsets, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorkOffsets)
+	err := c.cc.Invoke(ctx, FanucService_ReadWorkOffsets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) WriteToolOffset(ctx context.Context, in *WriteToolOffsetRequest, opts ...grpc.CallOption) (*OffsetChange, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OffsetChange)
+	err := c.cc.Invoke(ctx, FanucService_WriteToolOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) WriteWorkOffset(ctx context.Context, in *WriteWorkOffsetRequest, opts ...grpc.CallOption) (*OffsetChange, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OffsetChange)
+	err := c.cc.Invoke(ctx, FanucService_WriteWorkOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fanucServiceClient) WatchMachineData(ctx context.Context, in *WatchMachineDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MachineDataEnvelope], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FanucService_ServiceDesc.Streams[0], FanucService_WatchMachineData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMachineDataRequest, MachineDataEnvelope]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FanucService_WatchMachineDataClient = grpc.ServerStreamingClient[MachineDataEnvelope]
+
+// FanucServiceServer is the server API for FanucService service.
+// All implementations must embed UnimplementedFanucServiceServer
+// for forward compatibility.
+//
+// FanucService mirrors the REST API under /api/v1. Every call requires the
+// API key in the "x-api-key" metadata. Timestamps are Unix milliseconds.
+type FanucServiceServer interface {
+	CreateConnection(context.Context, *CreateConnectionRequest) (*Machine, error)
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	CheckConnection(context.Context, *CheckConnectionRequest) (*Machine, error)
+	DeleteConnection(context.Context, *DeleteConnectionRequest) (*DeleteConnectionResponse, error)
+	StartPolling(context.Context, *StartPollingRequest) (*StartPollingResponse, error)
+	StopPolling(context.Context, *StopPollingRequest) (*StopPollingResponse, error)
+	GetProgram(context.Context, *GetProgramRequest) (*GetProgramResponse, error)
+	// ReadData reads parameters, macro variables, PMC addresses or diagnostic
+	// numbers of the machine.
+	ReadData(context.Context, *ReadDataRequest) (*DataReading, error)
+	// WriteData writes macro variables or PMC values allowed by the write rules
+	// of the machine. It requires the write scope, fails with FAILED_PRECONDITION
+	// while the CNC is in automatic operation unless override is set, and with
+	// UNAVAILABLE if its audit entry cannot be stored.
+	WriteData(context.Context, *WriteDataRequest) (*WriteDataResponse, error)
+	// ReadToolOffsets and ReadWorkOffsets read the offset tables of the machine.
+	ReadToolOffsets(context.Context, *ReadOffsetsRequest) (*ToolOffsets, error)
+	ReadWorkOffsets(context.Context, *ReadOffsetsRequest) (*WorkOffsets, error)
+	// WriteToolOffset and WriteWorkOffset set one offset value. They require the
+	// write scope and fail with UNAVAILABLE if their audit entry cannot be
+	// stored. Like WriteData they follow the write rules of the machine and fail
+	// with FAILED_PRECONDITION in automatic operation unless override is set.
+	WriteToolOffset(context.Context, *WriteToolOffsetRequest) (*OffsetChange, error)
+	WriteWorkOffset(context.Context, *WriteWorkOffsetRequest) (*OffsetChange, error)
+	// WatchMachineData streams every polling snapshot of the machine while the
+	// call is open. Polling has to be started separately; snapshots are dropped
+	// for a client that cannot keep up.
+	WatchMachineData(*WatchMachineDataRequest, grpc.ServerStreamingServer[MachineDataEnvelope]) error
+	mustEmbedUnimplementedFanucServiceServer()
+}
+
+// UnimplementedFanucServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFanucServiceServer struct{}
+
+func (UnimplementedFanucServiceServer) CreateConnection(context.Context, *CreateConnectionRequest) (*Machine, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateConnection not implemented")
+}
+func (UnimplementedFanucServiceServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListConnections not implemented")
+}
+func (UnimplementedFanucServiceServer) CheckConnection(context.Context, *CheckConnectionRequest) (*Machine, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckConnection not implemented")
+}
+func (UnimplementedFanucServiceServer) DeleteConnection(context.Context, *DeleteConnectionRequest) (*DeleteConnectionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteConnection not implemented")
+}
+func (UnimplementedFanucServiceServer) StartPolling(context.Context, *StartPollingRequest) (*StartPollingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartPolling not implemented")
+}
+func (UnimplementedFanucServiceServer) StopPolling(context.Context, *StopPollingRequest) (*StopPollingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopPolling not implemented")
+}
+func (UnimplementedFanucServiceServer) GetProgram(context.Context, *GetProgramRequest) (*GetProgramResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetProgram not implemented")
+}
+func (UnimplementedFanucServiceServer) ReadData(context.Context, *ReadDataRequest) (*DataReading, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadData not implemented")
+}
+func (UnimplementedFanucServiceServer) WriteData(context.Context, *WriteDataRequest) (*WriteDataResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteData not implemented")
+}
+func (UnimplementedFanucServiceServer) ReadToolOffsets(context.Context, *ReadOffsetsRequest) (*ToolOffsets, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadToolOffsets not implemented")
+}
+func (UnimplementedFanucServiceServer) ReadWorkOffsets(context.Context, *ReadOffsetsRequest) (*WorkOffsets, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadWorkOffsets not implemented")
+}
+func (UnimplementedFanucServiceServer) WriteToolOffset(context.Context, *WriteToolOffsetRequest) (*OffsetChange, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteToolOffset not implemented")
+}
+func (UnimplementedFanucServiceServer) WriteWorkOffset(context.Context, *WriteWorkOffsetRequest) (*OffsetChange, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteWorkOffset not implemented")
+}
+func (UnimplementedFanucServiceServer) WatchMachineData(*WatchMachineDataRequest, grpc.ServerStreamingServer[MachineDataEnvelope]) error {
+	return status.Error(codes.Unimplemented, "method WatchMachineData not implemented")
+}
+func (UnimplementedFanucServiceServer) mustEmbedUnimplementedFanucServiceServer() {}
+func (UnimplementedFanucServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeFanucServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FanucServiceServer will
+// result in compilation errors.
+type UnsafeFanucServiceServer interface {
+	mustEmbedUnimplementedFanucServiceServer()
+}
+
+func RegisterFanucServiceServer(s grpc.ServiceRegistrar, srv FanucServiceServer) {
+	// If the following call panics, it indicates UnimplementedFanucServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FanucService_ServiceDesc, srv)
+}
+
+func _FanucService_CreateConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).CreateConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_CreateConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).CreateConnection(ctx, req.(*CreateConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).ListConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_ListConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).ListConnections(ctx, req.(*ListConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_CheckConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).CheckConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_CheckConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).CheckConnection(ctx, req.(*CheckConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_DeleteConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).DeleteConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_DeleteConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).DeleteConnection(ctx, req.(*DeleteConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_StartPolling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartPollingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).StartPolling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_StartPolling_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).StartPolling(ctx, req.(*StartPollingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_StopPolling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopPollingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).StopPolling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_StopPolling_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).StopPolling(ctx, req.(*StopPollingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_GetProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).GetProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_GetProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).GetProgram(ctx, req.(*GetProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_ReadData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).ReadData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_ReadData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).ReadData(ctx, req.(*ReadDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_WriteData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).WriteData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_WriteData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).WriteData(ctx, req.(*WriteDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_ReadToolOffsets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadOffsetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).ReadToolOffsets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_ReadToolOffsets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).ReadToolOffsets(ctx, req.(*ReadOffsetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_ReadWorkOffsets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadOffsetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).ReadWorkOffsets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_ReadWorkOffsets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).ReadWorkOffsets(ctx, req.(*ReadOffsetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_WriteToolOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteToolOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).WriteToolOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_WriteToolOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).WriteToolOffset(ctx, req.(*WriteToolOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_WriteWorkOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteWorkOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FanucServiceServer).WriteWorkOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FanucService_WriteWorkOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FanucServiceServer).WriteWorkOffset(ctx, req.(*WriteWorkOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FanucService_WatchMachineData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMachineDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FanucServiceServer).WatchMachineData(m, &grpc.GenericServerStream[WatchMachineDataRequest, MachineDataEnvelope]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FanucService_WatchMachineDataServer = grpc.ServerStreamingServer[MachineDataEnvelope]
+
+// FanucService_ServiceDesc is the grpc.ServiceDesc for FanucService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FanucService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fanuc.v1.FanucService",
+	HandlerType: (*FanucServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateConnection",
+			Handler:    _FanucService_CreateConnection_Handler,
+		},
+		{
+			MethodName: "ListConnections",
+			Handler:    _FanucService_ListConnections_Handler,
+		},
+		{
+			MethodName: "CheckConnection",
+			Handler:    _FanucService_CheckConnection_Handler,
+		},
+		{
+			MethodName: "DeleteConnection",
+			Handler:    _FanucService_DeleteConnection_Handler,
+		},
+		{
+			MethodName: "StartPolling",
+			Handler:    _FanucService_StartPolling_Handler,
+		},
+		{
+			MethodName: "StopPolling",
+			Handler:    _FanucService_StopPolling_Handler,
+		},
+		{
+			MethodName: "GetProgram",
+			Handler:    _FanucService_GetProgram_Handler,
+		},
+		{
+			MethodName: "ReadData",
+			Handler:    _FanucService_ReadData_Handler,
+		},
+		{
+			MethodName: "WriteData",
+			Handler:    _FanucService_WriteData_Handler,
+		},
+		{
+			MethodName: "ReadToolOffsets",
+			Handler:    _FanucService_ReadToolOffsets_Handler,
+		},
+		{
+			MethodName: "ReadWorkOffsets",
+			Handler:    _FanucService_ReadWorkOffsets_Handler,
+		},
+		{
+			MethodName: "WriteToolOffset",
+			Handler:    _FanucService_WriteToolOffset_Handler,
+		},
+		{
+			MethodName: "WriteWorkOffset",
+			Handler:    _FanucService_WriteWorkOffset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMachineData",
+			Handler:       _FanucService_WatchMachineData_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/fanuc/v1/service.proto",
+}
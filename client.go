@@ -3,11 +3,17 @@ package fanucService
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ClientAPI interface {
@@ -19,10 +25,47 @@ type ClientAPI interface {
 
 	// Polling methods
 	StartPolling(ctx context.Context, machineID string, intervalMs int) error
+	StartPollingWithReads(ctx context.Context, machineID string, intervalMs int, reads []DataAddress) error
+	StartPollingWithOptions(ctx context.Context, req StartPollingRequest) error
 	StopPolling(ctx context.Context, machineID string) error
 
+	// Data methods
+	ReadData(ctx context.Context, machineID string, addr DataAddress) (*DataReading, error)
+	WriteData(ctx context.Context, req WriteDataRequest) (*WriteResult, error)
+	SetWriteRules(ctx context.Context, machineID string, rules []WriteRule) (*MachineDTO, error)
+
+	// Offset methods
+	GetToolOffsets(ctx context.Context, machineID string) (*ToolOffsets, error)
+	GetWorkOffsets(ctx context.Context, machineID string) (*WorkOffsets, error)
+	WriteToolOffset(ctx context.Context, req ToolOffsetWriteRequest) (*OffsetChange, error)
+	WriteWorkOffset(ctx context.Context, req WorkOffsetWriteRequest) (*OffsetChange, error)
+
 	// Program methods
 	GetControlProgram(ctx context.Context, machineID string) (string, error)
+	ListPrograms(ctx context.Context, machineID string) (*ProgramDirectory, error)
+	DownloadProgram(ctx context.Context, machineID string, number int) (string, error)
+	OpenProgram(ctx context.Context, machineID string, number int, from ProgramRange) (*ProgramStream, error)
+	UploadProgram(ctx context.Context, req ProgramUploadRequest) (*ProgramInfo, error)
+	DeleteProgram(ctx context.Context, machineID string, number int) error
+	SelectMainProgram(ctx context.Context, machineID string, number int) error
+	AnalyzeProgram(ctx context.Context, machineID string, number int) (*ProgramAnalysis, error)
+	AnalyzeProgramText(ctx context.Context, program string) (*ProgramAnalysis, error)
+	GetProgramPosition(ctx context.Context, machineID string, lines int) (*ProgramPosition, error)
+
+	// Program version methods
+	ListProgramVersions(ctx context.Context, machineID string, number int) ([]ProgramVersion, error)
+	GetProgramVersion(ctx context.Context, version uint) (string, error)
+	DiffProgramVersions(ctx context.Context, from, to uint) (string, error)
+	ApproveProgramVersion(ctx context.Context, version uint) (*ProgramVersion, error)
+	CheckProgramDrift(ctx context.Context, machineID string) (*ProgramDrift, error)
+
+	// Job methods
+	CreateJob(ctx context.Context, req JobRequest) (*Job, error)
+	GetJob(ctx context.Context, jobID string) (*Job, error)
+	GetJobOutput(ctx context.Context, jobID string) (string, error)
+	CancelJob(ctx context.Context, jobID string) (*Job, error)
+	ListJobs(ctx context.Context, machineID, status string) ([]Job, error)
+	WaitJob(ctx context.Context, jobID string, interval time.Duration) (*Job, error)
 }
 
 // Client реализует ClientAPI.
@@ -32,13 +75,67 @@ type Client struct {
 	http    *http.Client
 }
 
+// ClientOption настраивает клиента при создании.
+type ClientOption func(*Client)
+
+// WithHTTPClient заменяет HTTP-клиент по умолчанию.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithTLSConfig задает настройки TLS для HTTPS-подключения к сервису
+// (собственный CA, клиентский сертификат для mTLS).
+func WithTLSConfig(tlsCfg *tls.Config) ClientOption {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		c.http = &http.Client{Transport: transport}
+	}
+}
+
 // NewClient создает новый экземпляр клиента.
-func NewClient(baseURL, apiKey string) *Client {
-	return &Client{
+// При mTLS-аутентификации apiKey можно оставить пустым.
+func NewClient(baseURL, apiKey string, opts ...ClientOption) *Client {
+	c := &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		http:    &http.Client{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewTLSConfig собирает настройки TLS клиента из PEM-файлов.
+// caFile — CA сервера (пусто — системные корневые сертификаты),
+// certFile/keyFile — клиентский сертификат для mTLS (пусто — без него).
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s contains no certificates", caFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
 }
 
 // --- Внутренние структуры для распаковки JSON-ответов API ---
@@ -78,7 +175,9 @@ func (c *Client) do(ctx context.Context, method, path string, body interface{},
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", c.apiKey)
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -143,10 +242,21 @@ func (c *Client) DeleteConnection(ctx context.Context, machineID string) error {
 }
 
 func (c *Client) StartPolling(ctx context.Context, machineID string, intervalMs int) error {
-	req := StartPollingRequest{
+	return c.StartPollingWithReads(ctx, machineID, intervalMs, nil)
+}
+
+// StartPollingWithReads запускает опрос и добавляет в каждый снимок значения reads.
+func (c *Client) StartPollingWithReads(ctx context.Context, machineID string, intervalMs int, reads []DataAddress) error {
+	return c.StartPollingWithOptions(ctx, StartPollingRequest{
 		ID:       machineID,
 		Interval: intervalMs,
-	}
+		Reads:    reads,
+	})
+}
+
+// StartPollingWithOptions запускает опрос с полным набором параметров, в том
+// числе с отслеживанием изменений коррекций (WatchOffsets).
+func (c *Client) StartPollingWithOptions(ctx context.Context, req StartPollingRequest) error {
 	return c.do(ctx, http.MethodPost, "/api/v1/polling/start", req, nil)
 }
 
@@ -157,17 +267,389 @@ func (c *Client) StopPolling(ctx context.Context, machineID string) error {
 	return c.do(ctx, http.MethodPost, "/api/v1/polling/stop", req, nil)
 }
 
+// ReadData читает параметры, макропеременные, адреса PMC или диагностику станка.
+func (c *Client) ReadData(ctx context.Context, machineID string, addr DataAddress) (*DataReading, error) {
+	query := url.Values{}
+	query.Set("id", machineID)
+	query.Set("class", addr.Class)
+	query.Set("number", strconv.Itoa(addr.Number))
+	if addr.Count > 0 {
+		query.Set("count", strconv.Itoa(addr.Count))
+	}
+	if addr.Type != "" {
+		query.Set("type", addr.Type)
+	}
+	if addr.Axis != 0 {
+		query.Set("axis", strconv.Itoa(addr.Axis))
+	}
+	if addr.Area != "" {
+		query.Set("area", addr.Area)
+	}
+
+	var resp struct {
+		baseResponse
+		Data DataReading `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/read?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// WriteData записывает макропеременные или данные PMC, разрешенные правилами записи станка.
+func (c *Client) WriteData(ctx context.Context, req WriteDataRequest) (*WriteResult, error) {
+	var resp struct {
+		baseResponse
+		Data WriteResult `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/write", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// SetWriteRules заменяет правила записи станка; пустой список запрещает запись.
+func (c *Client) SetWriteRules(ctx context.Context, machineID string, rules []WriteRule) (*MachineDTO, error) {
+	var resp struct {
+		baseResponse
+		Data MachineDTO `json:"data"`
+	}
+	req := WriteRulesRequest{ID: machineID, Rules: rules}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/write/rules", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetToolOffsets читает таблицу коррекций инструмента станка.
+func (c *Client) GetToolOffsets(ctx context.Context, machineID string) (*ToolOffsets, error) {
+	var resp struct {
+		baseResponse
+		Data ToolOffsets `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/offsets/tool?id="+url.QueryEscape(machineID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetWorkOffsets читает таблицу смещений нуля (EXT, G54-G59, G54.1) станка.
+func (c *Client) GetWorkOffsets(ctx context.Context, machineID string) (*WorkOffsets, error) {
+	var resp struct {
+		baseResponse
+		Data WorkOffsets `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/offsets/work?id="+url.QueryEscape(machineID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// WriteToolOffset записывает одно значение коррекции инструмента.
+func (c *Client) WriteToolOffset(ctx context.Context, req ToolOffsetWriteRequest) (*OffsetChange, error) {
+	var resp struct {
+		baseResponse
+		Data OffsetChange `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/offsets/tool", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// WriteWorkOffset записывает значение одной оси смещения нуля.
+func (c *Client) WriteWorkOffset(ctx context.Context, req WorkOffsetWriteRequest) (*OffsetChange, error) {
+	var resp struct {
+		baseResponse
+		Data OffsetChange `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/offsets/work", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
 func (c *Client) GetControlProgram(ctx context.Context, machineID string) (string, error) {
-	path := fmt.Sprintf("/api/v1/program?id=%s", url.QueryEscape(machineID))
-	fullURL := c.baseURL + path
+	return c.getText(ctx, fmt.Sprintf("/api/v1/program?id=%s", url.QueryEscape(machineID)))
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+// ListPrograms читает каталог программ в памяти ЧПУ.
+func (c *Client) ListPrograms(ctx context.Context, machineID string) (*ProgramDirectory, error) {
+	var resp struct {
+		baseResponse
+		Data ProgramDirectory `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/programs?id="+url.QueryEscape(machineID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DownloadProgram скачивает текст программы по ее номеру.
+func (c *Client) DownloadProgram(ctx context.Context, machineID string, number int) (string, error) {
+	return c.getText(ctx, fmt.Sprintf("/api/v1/programs/download?id=%s&number=%d", url.QueryEscape(machineID), number))
+}
+
+// OpenProgram открывает текст программы для чтения по мере выгрузки из ЧПУ,
+// без буферизации в памяти; number 0 - выполняемая программа. С from.Offset
+// чтение продолжается с этого байта, если программа не изменилась с from.ETag;
+// иначе поток начинается сначала, что видно по Offset. Поток нужно закрыть.
+func (c *Client) OpenProgram(ctx context.Context, machineID string, number int, from ProgramRange) (*ProgramStream, error) {
+	path := "/api/v1/program?id=" + url.QueryEscape(machineID)
+	if number != 0 {
+		path = fmt.Sprintf("/api/v1/programs/download?id=%s&number=%d", url.QueryEscape(machineID), number)
+	}
+	header := http.Header{}
+	if from.Offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", from.Offset))
+		if from.ETag != "" {
+			header.Set("If-Range", from.ETag)
+		}
+	}
+
+	resp, err := c.open(ctx, path, header)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	stream := &ProgramStream{
+		ReadCloser: resp.Body,
+		Length:     resp.ContentLength,
+		Size:       resp.ContentLength,
+		ETag:       resp.Header.Get("ETag"),
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		stream.Offset = from.Offset
+		stream.Size = -1
+		if _, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/"); ok {
+			if size, err := strconv.ParseInt(total, 10, 64); err == nil {
+				stream.Size = size
+			}
+		}
+		return stream, nil
 	}
-	req.Header.Set("X-API-Key", c.apiKey)
 
-	resp, err := c.http.Do(req)
+	// The server sent the whole text: skip to the offset while it is the same.
+	if from.Offset > 0 && (from.ETag == "" || from.ETag == stream.ETag) {
+		if _, err := io.CopyN(io.Discard, resp.Body, from.Offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		stream.Offset = from.Offset
+		if stream.Length >= 0 {
+			stream.Length -= from.Offset
+		}
+	}
+	return stream, nil
+}
+
+// UploadProgram записывает программу в память ЧПУ под номером из ее первой строки.
+func (c *Client) UploadProgram(ctx context.Context, req ProgramUploadRequest) (*ProgramInfo, error) {
+	var resp struct {
+		baseResponse
+		Data ProgramInfo `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/programs", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DeleteProgram удаляет программу из памяти ЧПУ.
+func (c *Client) DeleteProgram(ctx context.Context, machineID string, number int) error {
+	path := fmt.Sprintf("/api/v1/programs?id=%s&number=%d", url.QueryEscape(machineID), number)
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// SelectMainProgram делает программу основной для автоматической работы.
+func (c *Client) SelectMainProgram(ctx context.Context, machineID string, number int) error {
+	req := ProgramSelectRequest{ID: machineID, Number: number}
+	return c.do(ctx, http.MethodPost, "/api/v1/programs/select", req, nil)
+}
+
+// AnalyzeProgram анализирует программу станка; number 0 - выполняемая программа.
+func (c *Client) AnalyzeProgram(ctx context.Context, machineID string, number int) (*ProgramAnalysis, error) {
+	path := "/api/v1/programs/analysis?id=" + url.QueryEscape(machineID)
+	if number != 0 {
+		path += fmt.Sprintf("&number=%d", number)
+	}
+
+	var resp struct {
+		baseResponse
+		Data ProgramAnalysis `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// AnalyzeProgramText анализирует текст программы, которой нет на станке.
+func (c *Client) AnalyzeProgramText(ctx context.Context, program string) (*ProgramAnalysis, error) {
+	var resp struct {
+		baseResponse
+		Data ProgramAnalysis `json:"data"`
+	}
+	req := ProgramAnalyzeRequest{Program: program}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/programs/analyze", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// GetProgramPosition возвращает выполняемый кадр программы станка с lines
+// строками контекста до и после; lines < 0 - значение по умолчанию сервиса.
+func (c *Client) GetProgramPosition(ctx context.Context, machineID string, lines int) (*ProgramPosition, error) {
+	path := "/api/v1/programs/position?id=" + url.QueryEscape(machineID)
+	if lines >= 0 {
+		path += fmt.Sprintf("&lines=%d", lines)
+	}
+
+	var resp struct {
+		baseResponse
+		Data ProgramPosition `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ListProgramVersions возвращает сохраненные версии программ станка, новые
+// первыми; number 0 - все программы.
+func (c *Client) ListProgramVersions(ctx context.Context, machineID string, number int) ([]ProgramVersion, error) {
+	path := "/api/v1/programs/versions?id=" + url.QueryEscape(machineID)
+	if number != 0 {
+		path += fmt.Sprintf("&number=%d", number)
+	}
+
+	var resp struct {
+		baseResponse
+		Data []ProgramVersion `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// GetProgramVersion возвращает текст сохраненной версии программы.
+func (c *Client) GetProgramVersion(ctx context.Context, version uint) (string, error) {
+	return c.getText(ctx, fmt.Sprintf("/api/v1/programs/versions/content?version=%d", version))
+}
+
+// DiffProgramVersions возвращает unified diff между версиями; to 0 - последняя
+// версия той же программы.
+func (c *Client) DiffProgramVersions(ctx context.Context, from, to uint) (string, error) {
+	path := fmt.Sprintf("/api/v1/programs/versions/diff?from=%d", from)
+	if to != 0 {
+		path += fmt.Sprintf("&to=%d", to)
+	}
+	return c.getText(ctx, path)
+}
+
+// ApproveProgramVersion делает версию одобренной для ее программы на станке.
+func (c *Client) ApproveProgramVersion(ctx context.Context, version uint) (*ProgramVersion, error) {
+	var resp struct {
+		baseResponse
+		Data ProgramVersion `json:"data"`
+	}
+	req := ProgramApproveRequest{Version: version}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/programs/versions/approve", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// CheckProgramDrift сравнивает выполняемую программу с одобренной версией.
+func (c *Client) CheckProgramDrift(ctx context.Context, machineID string) (*ProgramDrift, error) {
+	var resp struct {
+		baseResponse
+		Data ProgramDrift `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/programs/drift?id="+url.QueryEscape(machineID), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// CreateJob ставит длительную операцию в очередь и сразу возвращает задачу.
+func (c *Client) CreateJob(ctx context.Context, req JobRequest) (*Job, error) {
+	return c.job(ctx, http.MethodPost, "/api/v1/jobs", req)
+}
+
+// GetJob возвращает статус, прогресс и результат задачи.
+func (c *Client) GetJob(ctx context.Context, jobID string) (*Job, error) {
+	return c.job(ctx, http.MethodGet, "/api/v1/jobs/"+url.PathEscape(jobID), nil)
+}
+
+// GetJobOutput возвращает текст программы, прочитанной задачей program_download.
+func (c *Client) GetJobOutput(ctx context.Context, jobID string) (string, error) {
+	return c.getText(ctx, "/api/v1/jobs/"+url.PathEscape(jobID)+"/output")
+}
+
+// CancelJob отменяет задачу в очереди или выполняемую задачу.
+func (c *Client) CancelJob(ctx context.Context, jobID string) (*Job, error) {
+	return c.job(ctx, http.MethodPost, "/api/v1/jobs/"+url.PathEscape(jobID)+"/cancel", nil)
+}
+
+// ListJobs возвращает задачи, новые первыми; пустые machineID и status не
+// фильтруют.
+func (c *Client) ListJobs(ctx context.Context, machineID, status string) ([]Job, error) {
+	query := url.Values{}
+	if machineID != "" {
+		query.Set("id", machineID)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+	path := "/api/v1/jobs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp struct {
+		baseResponse
+		Data []Job `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// WaitJob опрашивает задачу с интервалом interval, пока она не завершится или
+// не истечет ctx.
+func (c *Client) WaitJob(ctx context.Context, jobID string, interval time.Duration) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetJob(ctx, jobID)
+		if err != nil || job.Finished() {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) job(ctx context.Context, method, path string, body interface{}) (*Job, error) {
+	var resp struct {
+		baseResponse
+		Data Job `json:"data"`
+	}
+	if err := c.do(ctx, method, path, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// getText выполняет GET-запрос с текстовым ответом.
+func (c *Client) getText(ctx context.Context, path string) (string, error) {
+	resp, err := c.open(ctx, path, nil)
 	if err != nil {
 		return "", err
 	}
@@ -177,14 +659,41 @@ func (c *Client) GetControlProgram(ctx context.Context, machineID string) (strin
 	if err != nil {
 		return "", err
 	}
+	return string(bodyBytes), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		var errResp baseResponse
-		if jsonErr := json.Unmarshal(bodyBytes, &errResp); jsonErr == nil && errResp.Message != "" {
-			return "", fmt.Errorf("api error: %s", errResp.Message)
-		}
-		return "", fmt.Errorf("api returned status %d", resp.StatusCode)
+// open выполняет GET-запрос и возвращает ответ с непрочитанным телом, если
+// сервер ответил 200 или 206.
+func (c *Client) open(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	fullURL := c.baseURL + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
 	}
 
-	return string(bodyBytes), nil
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var errResp baseResponse
+	if jsonErr := json.Unmarshal(bodyBytes, &errResp); jsonErr == nil && errResp.Message != "" {
+		return nil, fmt.Errorf("api error: %s", errResp.Message)
+	}
+	return nil, fmt.Errorf("api returned status %d", resp.StatusCode)
 }
@@ -10,6 +10,10 @@ import (
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description OIDC access token: "Bearer <token>" (AUTH_MODE=jwt or both)
 // @BasePath /
 func main() {
 	app.New().Run()
@@ -2,21 +2,121 @@ package fanucService
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	App      AppConfig
+	TLS      TLSConfig
+	Auth     AuthConfig
+	Limits   LimitsConfig
+	Focas    FocasConfig
+	Programs ProgramsConfig
+	Jobs     JobsConfig
+	Cluster  ClusterConfig
 	Database DatabaseConfig
 	Kafka    KafkaConfig
 	Logger   LoggerConfig
 }
 
 type AppConfig struct {
-	Port    string
-	GinMode string
-	APIKey  string
+	Port           string
+	GRPCPort       string // empty disables the gRPC server
+	GinMode        string
+	APIKey         string
+	RequestTimeout time.Duration // deadline of an API request, 0 disables
+
+	// ShutdownTimeout bounds the whole ordered shutdown: API servers, pollers,
+	// Kafka flush and FOCAS handles.
+	ShutdownTimeout time.Duration
+}
+
+// FocasConfig bounds calls into the FOCAS driver, which cannot be interrupted:
+// a call past its deadline is abandoned and keeps running in the background.
+type FocasConfig struct {
+	CallTimeout  time.Duration // deadline of a single driver call
+	MaxHungCalls int           // abandoned calls still running before new calls are refused
+}
+
+// ProgramsConfig controls the program version store and program tracking.
+type ProgramsConfig struct {
+	SnapshotInterval time.Duration // how often polling stores the executing program, 0 disables
+	TrackPosition    bool          // locate the executing block in the program with every poll
+	ContextLines     int           // lines before and after the executing block in a position
+
+	MaxSize         int64         // largest program downloaded or uploaded in bytes, 0 for no limit
+	VersionMaxSize  int64         // largest download stored as a version in bytes, 0 for no limit
+	TransferTimeout time.Duration // deadline of a program download or upload instead of the call and request timeouts
+}
+
+// JobsConfig controls background jobs: long operations that answer with a job
+// ID at once and report their progress and result later.
+type JobsConfig struct {
+	Workers   int           // jobs run at once; jobs of one machine never run together
+	QueueSize int           // queued jobs before new ones are refused, 0 for no limit
+	Timeout   time.Duration // deadline of a job, 0 for none
+	Retention time.Duration // how long finished jobs and their results are kept
+
+	// OutputMaxSize bounds the text a job stores in its row, the program of a
+	// program_download job, in bytes; 0 for no limit.
+	OutputMaxSize int64
+}
+
+// TLSConfig enables HTTPS (and TLS for gRPC) when CertFile and KeyFile are set.
+type TLSConfig struct {
+	CertFile       string
+	KeyFile        string
+	ReloadInterval time.Duration // how often the files are checked for changes
+
+	ClientCAFile string // CA for client certificates (mTLS)
+	ClientAuth   string // none, request, require
+	ClientRoles  string // "CN:hmi-line-a=read+control,OU:plant-admins=admin"
+}
+
+// ClusterConfig coordinates several replicas through the database: every
+// machine is served by exactly one live instance.
+type ClusterConfig struct {
+	Enabled    bool
+	InstanceID string        // defaults to the host name
+	Address    string        // base URL other instances use to reach this one
+	Heartbeat  time.Duration // heartbeat and rebalance period
+	LeaseTTL   time.Duration // an instance silent for this long loses its machines
+	Forward    string        // proxy or redirect
+
+	// TLS between instances: CAFile verifies the other instances, CertFile and
+	// KeyFile are presented to them, PeerSubject ("CN:name" or "OU:unit")
+	// recognizes their client certificates.
+	CAFile      string
+	CertFile    string
+	KeyFile     string
+	PeerSubject string
+}
+
+// LimitsConfig protects controllers from request floods. Zero disables a limit.
+type LimitsConfig struct {
+	MachineConcurrency int     // parallel CNC calls per machine
+	MachineRate        float64 // CNC calls per second per machine
+	MachineBurst       int
+	KeyRate            float64 // API requests per second per caller
+	KeyBurst           int
+}
+
+type AuthConfig struct {
+	Mode string // api_key, jwt, both
+
+	JWKSFile    string
+	JWKSURL     string
+	JWKSRefresh time.Duration // how often a JWKS URL is re-fetched
+	Issuer      string        // expected iss, empty skips the check
+	Audience    string        // expected aud, required for JWT auth
+
+	SubjectClaim string // claim used as the caller identity, falls back to sub
+	RolesClaim   string // dotted path to the roles, e.g. realm_access.roles
+	RoleMapping  string // "idp-role=scope+scope,...", unmapped roles grant nothing
 }
 
 type DatabaseConfig struct {
@@ -28,8 +128,50 @@ type DatabaseConfig struct {
 }
 
 type KafkaConfig struct {
-	Broker string
-	Topic  string
+	Brokers  []string
+	ClientID string
+
+	Topic        string // machine data
+	AlarmTopic   string // alarm raised/cleared events, empty disables them
+	StatusTopic  string // status and mode change events, empty disables them
+	AuditTopic   string // audit entries, empty disables publishing them
+	OffsetTopic  string // tool and work offset change events, empty disables them
+	ProgramTopic string // program version events, empty disables them
+	TopicRoutes  string // label routing for data, e.g. "line=A:fanuc_line_a,shop=2:fanuc_shop_2"
+
+	CommandTopic  string // control commands, empty disables the consumer
+	ResponseTopic string // replies to commands
+	CommandGroup  string // consumer group of the command consumer
+
+	TLSEnabled            bool
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSInsecureSkipVerify bool
+
+	SASLMechanism string // PLAIN, SCRAM-SHA-256, SCRAM-SHA-512; empty disables SASL
+	SASLUsername  string
+	SASLPassword  string
+
+	Encoding               string // json, protobuf, avro
+	SchemaRegistryURL      string
+	SchemaRegistryUser     string
+	SchemaRegistryPassword string
+
+	BufferDir        string        // empty disables the disk buffer
+	BufferMaxBytes   int64         // 0 - unlimited
+	BufferMaxAge     time.Duration // 0 - unlimited
+	BufferDropPolicy string        // drop_oldest, drop_newest
+
+	QueueSize    int           // per-machine send queue capacity
+	QueuePolicy  string        // drop_oldest, drop_newest, block
+	BatchSize    int           // max messages per produce request
+	Linger       time.Duration // how long the writer waits to fill a batch
+	Compression  string        // none, gzip, snappy, lz4, zstd
+	RequiredAcks string        // all, one, none
+	Idempotent   bool          // forces acks=all and one in-flight batch per machine
+	SendTimeout  time.Duration
+	MaxAttempts  int
 }
 
 type LoggerConfig struct {
@@ -42,9 +184,76 @@ func LoadConfig() *Config {
 
 	return &Config{
 		App: AppConfig{
-			Port:    getEnv("APP_PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
-			APIKey:  getEnv("API_KEY"),
+			Port:     getEnv("APP_PORT", "8080"),
+			GRPCPort: getEnv("GRPC_PORT", "9090"),
+			GinMode:  getEnv("GIN_MODE", "debug"),
+			APIKey:   getEnv("API_KEY"),
+
+			RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", time.Minute),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		},
+		Focas: FocasConfig{
+			CallTimeout:  getEnvDuration("FOCAS_CALL_TIMEOUT", 30*time.Second),
+			MaxHungCalls: int(getEnvInt64("FOCAS_MAX_HUNG_CALLS", 32)),
+		},
+		Programs: ProgramsConfig{
+			SnapshotInterval: getEnvDuration("PROGRAM_SNAPSHOT_INTERVAL", 10*time.Minute),
+			TrackPosition:    getEnvBool("PROGRAM_TRACK_POSITION", true),
+			ContextLines:     int(getEnvInt64("PROGRAM_CONTEXT_LINES", 3)),
+
+			MaxSize:         getEnvInt64("PROGRAM_MAX_SIZE", 16<<20),
+			VersionMaxSize:  getEnvInt64("PROGRAM_VERSION_MAX_SIZE", 1<<20),
+			TransferTimeout: getEnvDuration("PROGRAM_TRANSFER_TIMEOUT", 10*time.Minute),
+		},
+		Jobs: JobsConfig{
+			Workers:   int(getEnvInt64("JOB_WORKERS", 4)),
+			QueueSize: int(getEnvInt64("JOB_QUEUE_SIZE", 100)),
+			Timeout:   getEnvDuration("JOB_TIMEOUT", 30*time.Minute),
+			Retention: getEnvDuration("JOB_RETENTION", 24*time.Hour),
+
+			OutputMaxSize: getEnvInt64("JOB_OUTPUT_MAX_SIZE", 4<<20),
+		},
+		TLS: TLSConfig{
+			CertFile:       getEnv("TLS_CERT_FILE"),
+			KeyFile:        getEnv("TLS_KEY_FILE"),
+			ReloadInterval: getEnvDuration("TLS_RELOAD_INTERVAL", time.Minute),
+
+			ClientCAFile: getEnv("TLS_CLIENT_CA_FILE"),
+			ClientAuth:   getEnv("TLS_CLIENT_AUTH", "none"),
+			ClientRoles:  getEnv("TLS_CLIENT_ROLES"),
+		},
+		Auth: AuthConfig{
+			Mode: getEnv("AUTH_MODE", "api_key"),
+
+			JWKSFile:    getEnv("JWT_JWKS_FILE"),
+			JWKSURL:     getEnv("JWT_JWKS_URL"),
+			JWKSRefresh: getEnvDuration("JWT_JWKS_REFRESH", 10*time.Minute),
+			Issuer:      getEnv("JWT_ISSUER"),
+			Audience:    getEnv("JWT_AUDIENCE"),
+
+			SubjectClaim: getEnv("JWT_SUBJECT_CLAIM", "preferred_username"),
+			RolesClaim:   getEnv("JWT_ROLES_CLAIM", "roles"),
+			RoleMapping:  getEnv("JWT_ROLE_MAPPING"),
+		},
+		Cluster: ClusterConfig{
+			Enabled:    getEnvBool("CLUSTER_ENABLED", false),
+			InstanceID: getEnv("CLUSTER_INSTANCE_ID", hostname()),
+			Address:    getEnv("CLUSTER_ADDRESS"),
+			Heartbeat:  getEnvDuration("CLUSTER_HEARTBEAT", 5*time.Second),
+			LeaseTTL:   getEnvDuration("CLUSTER_LEASE_TTL", 20*time.Second),
+			Forward:    getEnv("CLUSTER_FORWARD", "proxy"),
+
+			CAFile:      getEnv("CLUSTER_CA_FILE"),
+			CertFile:    getEnv("CLUSTER_CERT_FILE"),
+			KeyFile:     getEnv("CLUSTER_KEY_FILE"),
+			PeerSubject: getEnv("CLUSTER_PEER_SUBJECT"),
+		},
+		Limits: LimitsConfig{
+			MachineConcurrency: int(getEnvInt64("LIMIT_MACHINE_CONCURRENCY", 2)),
+			MachineRate:        getEnvFloat("LIMIT_MACHINE_RATE", 5),
+			MachineBurst:       int(getEnvInt64("LIMIT_MACHINE_BURST", 0)),
+			KeyRate:            getEnvFloat("LIMIT_KEY_RATE", 20),
+			KeyBurst:           int(getEnvInt64("LIMIT_KEY_BURST", 0)),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -54,8 +263,50 @@ func LoadConfig() *Config {
 			Name:     getEnv("DB_NAME", "fanuc_db"),
 		},
 		Kafka: KafkaConfig{
-			Broker: getEnv("KAFKA_BROKER"),
-			Topic:  getEnv("KAFKA_TOPIC"),
+			Brokers:  getEnvList("KAFKA_BROKERS", getEnv("KAFKA_BROKER")),
+			ClientID: getEnv("KAFKA_CLIENT_ID", "fanucService"),
+
+			Topic:        getEnv("KAFKA_TOPIC"),
+			AlarmTopic:   getEnv("KAFKA_ALARM_TOPIC"),
+			StatusTopic:  getEnv("KAFKA_STATUS_TOPIC"),
+			AuditTopic:   getEnv("KAFKA_AUDIT_TOPIC"),
+			OffsetTopic:  getEnv("KAFKA_OFFSET_TOPIC"),
+			ProgramTopic: getEnv("KAFKA_PROGRAM_TOPIC"),
+			TopicRoutes:  getEnv("KAFKA_TOPIC_ROUTES"),
+
+			CommandTopic:  getEnv("KAFKA_COMMAND_TOPIC"),
+			ResponseTopic: getEnv("KAFKA_RESPONSE_TOPIC"),
+			CommandGroup:  getEnv("KAFKA_COMMAND_GROUP", "fanucService"),
+
+			TLSEnabled:            getEnvBool("KAFKA_TLS_ENABLED", false),
+			TLSCAFile:             getEnv("KAFKA_TLS_CA_FILE"),
+			TLSCertFile:           getEnv("KAFKA_TLS_CERT_FILE"),
+			TLSKeyFile:            getEnv("KAFKA_TLS_KEY_FILE"),
+			TLSInsecureSkipVerify: getEnvBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false),
+
+			SASLMechanism: getEnv("KAFKA_SASL_MECHANISM"),
+			SASLUsername:  getEnv("KAFKA_SASL_USERNAME"),
+			SASLPassword:  getEnv("KAFKA_SASL_PASSWORD"),
+
+			Encoding:               getEnv("KAFKA_ENCODING", "json"),
+			SchemaRegistryURL:      getEnv("KAFKA_SCHEMA_REGISTRY_URL"),
+			SchemaRegistryUser:     getEnv("KAFKA_SCHEMA_REGISTRY_USER"),
+			SchemaRegistryPassword: getEnv("KAFKA_SCHEMA_REGISTRY_PASSWORD"),
+
+			BufferDir:        getEnv("KAFKA_BUFFER_DIR", "data/kafka_buffer"),
+			BufferMaxBytes:   getEnvInt64("KAFKA_BUFFER_MAX_BYTES", 1<<30),
+			BufferMaxAge:     getEnvDuration("KAFKA_BUFFER_MAX_AGE", 24*time.Hour),
+			BufferDropPolicy: getEnv("KAFKA_BUFFER_DROP_POLICY", "drop_oldest"),
+
+			QueueSize:    int(getEnvInt64("KAFKA_QUEUE_SIZE", 100)),
+			QueuePolicy:  getEnv("KAFKA_QUEUE_POLICY", "drop_oldest"),
+			BatchSize:    int(getEnvInt64("KAFKA_BATCH_SIZE", 100)),
+			Linger:       getEnvDuration("KAFKA_LINGER", 10*time.Millisecond),
+			Compression:  getEnv("KAFKA_COMPRESSION", "none"),
+			RequiredAcks: getEnv("KAFKA_REQUIRED_ACKS", "all"),
+			Idempotent:   getEnvBool("KAFKA_IDEMPOTENT", false),
+			SendTimeout:  getEnvDuration("KAFKA_SEND_TIMEOUT", 10*time.Second),
+			MaxAttempts:  int(getEnvInt64("KAFKA_MAX_ATTEMPTS", 3)),
 		},
 		Logger: LoggerConfig{
 			AdapterLevel: getEnv("ADAPTER_LOG_LEVEL", "info"),
@@ -64,6 +315,14 @@ func LoadConfig() *Config {
 	}
 }
 
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "fanucService"
+	}
+	return name
+}
+
 func getEnv(key string, fallback ...string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -75,3 +334,70 @@ func getEnv(key string, fallback ...string) string {
 
 	return ""
 }
+
+func getEnvInt64(key string, fallback int64) int64 {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
+
+// getEnvList splits a comma separated value, skipping empty items.
+func getEnvList(key string, fallback ...string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, fallback...), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -15,13 +15,101 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/connect": {
+        "/api/v1/audit": {
             "get": {
+                "description": "Returns audit entries of control actions, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Query the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key name or token subject",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action (connect, delete, polling_start, polling_stop, program_read, key_create, key_rotate, key_revoke)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max entries, default 100, max 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.AuditEntry"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/api/v1/connect": {
+            "get": {
                 "description": "If 'id' is provided, checks health of specific connection. If not, lists all connections.",
                 "produces": [
                     "application/json"
@@ -44,15 +132,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.APIResponse"
                         }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Connects to a Fanuc machine",
                 "consumes": [
                     "application/json"
@@ -94,14 +191,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.APIResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
+                ]
+            },
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
@@ -125,43 +225,67 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.APIResponse"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/polling/start": {
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
-                "description": "Starts periodic data collection for a specific machine session",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/api/v1/jobs": {
+            "get": {
+                "description": "Returns the jobs of the caller, of all callers for admins, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Polling"
+                    "Jobs"
                 ],
-                "summary": "Start polling for a machine",
+                "summary": "List jobs",
                 "parameters": [
                     {
-                        "description": "Polling Config",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.StartPollingRequest"
-                        }
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (queued, running, succeeded, failed, cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max jobs, default 100, max 1000",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Job"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -169,24 +293,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.APIResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.APIResponse"
-                        }
                     }
-                }
-            }
-        },
-        "/api/v1/polling/stop": {
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
-                "description": "Stops periodic data collection",
+                ]
+            },
+            "post": {
+                "description": "Queues a long operation and answers with its ID at once. program_download reads program number (0 - the executing one) of machine id; connection_check and program_drift cover ids, all machines of the caller if empty. The job needs the scope of the calls it makes: program for program_download and program_drift, read for connection_check.",
                 "consumes": [
                     "application/json"
                 ],
@@ -194,25 +313,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Polling"
+                    "Jobs"
                 ],
-                "summary": "Stop polling for a machine",
+                "summary": "Start a background job",
                 "parameters": [
                     {
-                        "description": "Polling Config",
+                        "description": "Job",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.StopPollingRequest"
+                            "$ref": "#/definitions/models.JobRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/models.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.Job"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -221,99 +352,3335 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.APIResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such machine",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Job queue full, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.APIResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service is stopping",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/api/v1/program": {
+        "/api/v1/jobs/{id}": {
             "get": {
+                "description": "Returns the status, progress (done of total bytes or machines) and result of a job. Finished jobs are kept for JOB_RETENTION.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "No such job",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
                     }
-                ],
-                "description": "Returns the raw text of the current executing program (G-Code)",
+                ]
+            }
+        },
+        "/api/v1/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancels a queued or running job. A job run by another instance is cancelled by it within a few seconds; cancel_requested is set until then.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Program"
+                    "Jobs"
                 ],
-                "summary": "Get full control program",
+                "summary": "Cancel a job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Machine ID",
+                        "description": "Job ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Program content",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.Job"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "No such job",
                         "schema": {
                             "$ref": "#/definitions/models.APIResponse"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/jobs/{id}/output": {
+            "get": {
+                "description": "Returns the program text read by a succeeded program_download job",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get the output of a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Program content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No such job or no output",
                         "schema": {
                             "$ref": "#/definitions/models.APIResponse"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "models.APIResponse": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "message": {
-                    "type": "string"
                 },
-                "status": {
-                    "type": "string"
-                }
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/kafka/buffer": {
+            "get": {
+                "description": "Returns the number and size of messages waiting on disk for delivery to Kafka",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kafka"
+                ],
+                "summary": "Get Kafka disk buffer state",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.KafkaBufferStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/kafka/queues": {
+            "get": {
+                "description": "Returns the per-machine send queues waiting for delivery to Kafka",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Kafka"
+                ],
+                "summary": "Get Kafka send queues",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.KafkaQueueStats"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/keys": {
+            "get": {
+                "description": "Lists all keys including revoked ones. Secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.APIKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Creates a named key with scopes (read, control, program, program_write, write, admin) and optional machine/label restrictions. The key is returned only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Key Data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.APIKeyCreated"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/keys/rotate": {
+            "post": {
+                "description": "Issues a new secret for the key; the old one stops working immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Rotate an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.APIKeyCreated"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/offsets/tool": {
+            "get": {
+                "description": "Reads all tool offsets of a machine. The offset types depend on the machine: x/z/radius/tip/y wear and geometry on a lathe, offset (memory A), wear and geometry (B) or radius and length wear and geometry (C) on a mill.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offsets"
+                ],
+                "summary": "Read the tool offset table",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ToolOffsets"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Sets one tool offset value, or adds to it when incremental is set. The value must be allowed by a tool_offset write rule; refused with 409 while the CNC is in automatic operation unless override is set. Returns the value before the write and the value read back. The audit entry is stored before the write; the write is refused with 503 if it cannot be.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offsets"
+                ],
+                "summary": "Write a tool offset",
+                "parameters": [
+                    {
+                        "description": "Tool offset",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ToolOffsetWriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.OffsetChange"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Offset or value not allowed by the write rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Machine is in automatic operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/offsets/work": {
+            "get": {
+                "description": "Reads the external work offset (EXT), G54-G59 and the extended work coordinate systems (G54.1 P1...) of a machine by axis.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offsets"
+                ],
+                "summary": "Read the work offset table",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WorkOffsets"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Sets the value of one axis of a work coordinate system (0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on), or adds to it when incremental is set. The value must be allowed by a work_offset write rule; refused with 409 while the CNC is in automatic operation unless override is set. Returns the value before the write and the value read back. The audit entry is stored before the write; the write is refused with 503 if it cannot be.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Offsets"
+                ],
+                "summary": "Write a work offset",
+                "parameters": [
+                    {
+                        "description": "Work offset",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WorkOffsetWriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.OffsetChange"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Offset or value not allowed by the write rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Machine is in automatic operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/polling/start": {
+            "post": {
+                "description": "Starts periodic data collection for a specific machine session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Polling"
+                ],
+                "summary": "Start polling for a machine",
+                "parameters": [
+                    {
+                        "description": "Polling Config",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StartPollingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/polling/stop": {
+            "post": {
+                "description": "Stops periodic data collection",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Polling"
+                ],
+                "summary": "Stop polling for a machine",
+                "parameters": [
+                    {
+                        "description": "Polling Config",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StopPollingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/program": {
+            "get": {
+                "description": "Streams the raw text of the current executing program (G-Code) while the CNC uploads it. Content-Length, ETag and byte ranges are available once the unchanged program was downloaded in full before; a program over PROGRAM_MAX_SIZE is refused with 413.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Get full control program",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=1024-",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the caller has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Program content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Program unchanged"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No program executing",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Program exceeds PROGRAM_MAX_SIZE",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Range outside the program"
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs": {
+            "get": {
+                "description": "Returns the program directory (number, size, comment, modification date) with the numbers of the running and the main program",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "List programs in CNC memory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProgramDirectory"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Stores an NC program in CNC memory under the O number it starts with. An existing program is replaced only with overwrite, and not while it is the main or running program of a machine in automatic operation; if the CNC refuses the new text, the old program is written back. The audit entry is stored before the upload; the upload is refused with 503 if it cannot be.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Upload a program",
+                "parameters": [
+                    {
+                        "description": "Program",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgramUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProgramInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Program exists or is in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body exceeds twice PROGRAM_MAX_SIZE plus 64 KiB",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Deletes a program from CNC memory. The main or running program is not deleted while the machine is in automatic operation. The audit entry is stored before the deletion; it is refused with 503 if it cannot be.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Delete a program",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Program number (O number)",
+                        "name": "number",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such program",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Program is in use",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/analysis": {
+            "get": {
+                "description": "Downloads a program (without number the executing one) and returns its tools, work offsets, subprogram calls, feed and speed ranges, estimated path length and syntax warnings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Analyze a program on a machine",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Program number (O number), default the executing program",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the parsed blocks",
+                        "name": "blocks",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/gcode.Analysis"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such program",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/analyze": {
+            "post": {
+                "description": "Analyzes a program that is not on a machine, e.g. a CAM output before upload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Analyze a program text",
+                "parameters": [
+                    {
+                        "description": "Program",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgramAnalyzeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/gcode.Analysis"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body exceeds twice PROGRAM_MAX_SIZE plus 64 KiB",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/download": {
+            "get": {
+                "description": "Streams the raw text of a program in CNC memory while the CNC uploads it. Content-Length, ETag and byte ranges are available once the unchanged program was downloaded in full before; a program over PROGRAM_MAX_SIZE is refused with 413.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Download a program by number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Program number (O number)",
+                        "name": "number",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=1024-",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the caller has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Program content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte range",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Program unchanged"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such program",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Program exceeds PROGRAM_MAX_SIZE",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Range outside the program"
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/drift": {
+            "get": {
+                "description": "Reads the program the machine executes, records it as a version and reports whether it differs from the approved version of its number, with the diff",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program versions"
+                ],
+                "summary": "Compare the executing program with the approved version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProgramDrift"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/position": {
+            "get": {
+                "description": "Returns the line of the program text the machine executes, found by the sequence number and the block text the CNC reports, with the lines around it. While polling with program tracking the last poll answers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Locate the executing block in the program",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lines of context before and after, default PROGRAM_CONTEXT_LINES, max 100",
+                        "name": "lines",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ProgramPosition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No program executing",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/select": {
+            "post": {
+                "description": "Makes a program in CNC memory the main program for automatic operation. Refused with 409 while the machine is in automatic operation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program"
+                ],
+                "summary": "Select the main program",
+                "parameters": [
+                    {
+                        "description": "Program",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgramSelectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such program",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Machine is in automatic operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/versions": {
+            "get": {
+                "description": "Returns the versions of the programs of a machine recorded on download, upload and polling snapshots, newest first, without their text",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program versions"
+                ],
+                "summary": "List recorded program versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Program number (O number)",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max versions, default 100, max 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.ProgramVersion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/versions/approve": {
+            "post": {
+                "description": "Makes a version the approved version of its program on its machine; the previously approved one loses the mark",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Program versions"
+                ],
+                "summary": "Approve a program version",
+                "parameters": [
+                    {
+                        "description": "Version",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgramApproveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.ProgramVersion"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such version",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/versions/content": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Program versions"
+                ],
+                "summary": "Get the text of a program version",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Version ID",
+                        "name": "version",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Program content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such version",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/programs/versions/diff": {
+            "get": {
+                "description": "Returns the unified diff from one version to another, empty if their texts are equal. Without to the latest version of the same program is taken.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Program versions"
+                ],
+                "summary": "Diff two program versions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Version ID",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version ID, default the latest version of the program",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unified diff",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No such version",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/read": {
+            "get": {
+                "description": "Reads consecutive parameters, custom macro variables, PMC addresses or diagnostic numbers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Data"
+                ],
+                "summary": "Read CNC data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Machine ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data class (parameter, macro, pmc, diagnosis)",
+                        "name": "class",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "First number, or byte address for pmc",
+                        "name": "number",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of values, default 1, max 100",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Value type (byte, word, dword, real), required for parameter and diagnosis, default byte for pmc",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Axis of a parameter or diagnosis, 0 - none",
+                        "name": "axis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PMC area (G, F, Y, X, A, R, T, K, C, D, E)",
+                        "name": "area",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.DataReading"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/write": {
+            "post": {
+                "description": "Writes consecutive macro variables or PMC values allowed by the write rules of the machine. Refused with 409 while the CNC is in automatic operation (MEM, RMT or a running program) unless override is set. A dry run makes all checks and returns the current values without writing. The audit entry is stored before the write; the write is refused with 503 if it cannot be.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Data"
+                ],
+                "summary": "Write macro variables or PMC data",
+                "parameters": [
+                    {
+                        "description": "Write request",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteDataRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.WriteResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Address or value not allowed by the write rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Machine is in automatic operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Machine busy or rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Audit entry could not be stored",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/write/rules": {
+            "put": {
+                "description": "Replaces the allowlist of macro variables and PMC addresses that may be written, with their value ranges. An empty list forbids all writes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Data"
+                ],
+                "summary": "Set the write rules of a machine",
+                "parameters": [
+                    {
+                        "description": "Write rules",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteRulesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entities.Machine"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "entities.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "uuid",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "станки со всеми этими метками",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "machine_ids": {
+                    "description": "пусто - все станки",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "начало ключа для опознания в списках",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "имя ключа или пользователь из токена",
+                    "type": "string"
+                },
+                "auth_method": {
+                    "description": "api_key / jwt",
+                    "type": "string"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "description": "id API-ключа",
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "сокращенное тело запроса",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "ok / error / pending",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "HTTP-статус или код gRPC",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "transport": {
+                    "description": "http / grpc / kafka",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.DataAddress": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "PMC area: G, F, Y, X, A, R, T, K, C, D, E",
+                    "type": "string"
+                },
+                "axis": {
+                    "description": "axis of a parameter or diagnosis, 0 - none",
+                    "type": "integer"
+                },
+                "class": {
+                    "description": "parameter, macro, pmc, diagnosis",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "default 1",
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "first number or PMC address",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "byte, word, dword, real",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Job": {
+            "type": "object",
+            "properties": {
+                "cancel_requested": {
+                    "description": "отмена запрошена, задача еще завершается",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "имя ключа или пользователь из токена",
+                    "type": "string"
+                },
+                "done": {
+                    "description": "обработано: байт программы или станков",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "instance": {
+                    "description": "экземпляр, выполняющий задачу",
+                    "type": "string"
+                },
+                "machine_id": {
+                    "description": "задача одного станка",
+                    "type": "string"
+                },
+                "machine_ids": {
+                    "description": "станки задачи по нескольким станкам",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "number": {
+                    "description": "program_download, 0 - выполняемая программа",
+                    "type": "integer"
+                },
+                "result": {
+                    "$ref": "#/definitions/entities.JobResult"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "queued / running / succeeded / failed / cancelled",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "0 - неизвестно",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.JobMachineResult": {
+            "type": "object",
+            "properties": {
+                "drifted": {
+                    "description": "program_drift: отличается от одобренной версии",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "program_drift: выполняемая программа",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "connection_check: статус станка после проверки",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.JobProgram": {
+            "type": "object",
+            "properties": {
+                "length": {
+                    "description": "bytes",
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "0 - программа без номера O",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.JobResult": {
+            "type": "object",
+            "properties": {
+                "machines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.JobMachineResult"
+                    }
+                },
+                "program": {
+                    "$ref": "#/definitions/entities.JobProgram"
+                }
+            }
+        },
+        "entities.Machine": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "description": "ip:port",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "uuid",
+                    "type": "string"
+                },
+                "interval": {
+                    "description": "Интервал опроса в мс",
+                    "type": "integer"
+                },
+                "labels": {
+                    "description": "произвольные метки (цех, линия и т.д.)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "mode": {
+                    "description": "static / polling",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "Human readable model name",
+                    "type": "string"
+                },
+                "reads": {
+                    "description": "данные, читаемые при каждом опросе",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.DataAddress"
+                    }
+                },
+                "series": {
+                    "description": "\"0i\", \"31i\"",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "connected / reconnecting",
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "таймаут в мс",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "watch_offsets": {
+                    "description": "сравнивать коррекции при каждом опросе",
+                    "type": "boolean"
+                },
+                "write_rules": {
+                    "description": "разрешенные адреса и значения записи",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.WriteRule"
+                    }
+                }
+            }
+        },
+        "entities.ProgramVersion": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "не более одной одобренной версии на программу станка",
+                    "type": "boolean"
+                },
+                "approved_at": {
+                    "description": "время одобрения",
+                    "type": "string"
+                },
+                "approved_by": {
+                    "description": "имя ключа, одобрившего версию",
+                    "type": "string"
+                },
+                "content": {
+                    "description": "текст без обрамляющих '%'",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "когда текст прочитан впервые",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "sha256 текста",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "номер O",
+                    "type": "integer"
+                },
+                "seen_at": {
+                    "description": "когда текст прочитан последний раз",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "размер текста в байтах",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "download / upload / snapshot",
+                    "type": "string"
+                }
+            }
+        },
+        "entities.WriteRule": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "PMC area",
+                    "type": "string"
+                },
+                "class": {
+                    "description": "macro, pmc, tool_offset, work_offset",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "default 1",
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "number": {
+                    "description": "first number or PMC address",
+                    "type": "integer"
+                },
+                "offset": {
+                    "description": "tool offset type or axis, empty allows all",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "PMC value type, default byte",
+                    "type": "string"
+                }
+            }
+        },
+        "gcode.Analysis": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "integer"
+                },
+                "calls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.Call"
+                    }
+                },
+                "feed": {
+                    "$ref": "#/definitions/gcode.Range"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "path": {
+                    "$ref": "#/definitions/gcode.PathLength"
+                },
+                "program": {
+                    "description": "parsed blocks, on request",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/gcode.Program"
+                        }
+                    ]
+                },
+                "speed": {
+                    "$ref": "#/definitions/gcode.Range"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.ToolUse"
+                    }
+                },
+                "units": {
+                    "description": "mm or inch, as selected by G20/G21",
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.Warning"
+                    }
+                },
+                "work_offsets": {
+                    "description": "e.g. G54, G54.1 P3, in order of first use",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "gcode.Block": {
+            "type": "object",
+            "properties": {
+                "call": {
+                    "$ref": "#/definitions/gcode.Call"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "feed": {
+                    "type": "number"
+                },
+                "g": {
+                    "description": "e.g. G01, G54.1",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "description": "1-based line in the text",
+                    "type": "integer"
+                },
+                "m": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "macro": {
+                    "description": "custom macro statement, words are not parsed",
+                    "type": "boolean"
+                },
+                "sequence": {
+                    "description": "N number",
+                    "type": "integer"
+                },
+                "skip": {
+                    "description": "optional block skip '/'",
+                    "type": "boolean"
+                },
+                "speed": {
+                    "type": "number"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "tool": {
+                    "description": "T word as written, e.g. T0101",
+                    "type": "string"
+                },
+                "words": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.Word"
+                    }
+                }
+            }
+        },
+        "gcode.Call": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "macro": {
+                    "description": "G65",
+                    "type": "boolean"
+                },
+                "program": {
+                    "type": "integer"
+                },
+                "repeat": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gcode.PathLength": {
+            "type": "object",
+            "properties": {
+                "feed": {
+                    "type": "number"
+                },
+                "rapid": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "gcode.Program": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.Block"
+                    }
+                },
+                "number": {
+                    "description": "O number, 0 if none",
+                    "type": "integer"
+                },
+                "warnings": {
+                    "description": "syntax problems",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gcode.Warning"
+                    }
+                }
+            }
+        },
+        "gcode.Range": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                }
+            }
+        },
+        "gcode.ToolUse": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "first call",
+                    "type": "integer"
+                },
+                "tool": {
+                    "description": "T word as written",
+                    "type": "string"
+                }
+            }
+        },
+        "gcode.Warning": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "gcode.Word": {
+            "type": "object",
+            "properties": {
+                "expr": {
+                    "type": "boolean"
+                },
+                "letter": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.APIKeyCreated": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "uuid",
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "станки со всеми этими метками",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "machine_ids": {
+                    "description": "пусто - все станки",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "начало ключа для опознания в списках",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "labels": {
+                    "description": "restrict to machines with all these labels",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "machine_ids": {
+                    "description": "restrict to these machines",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "read, control, program, program_write, write, admin",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.APIResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
             }
         },
         "models.ConnectionRequest": {
             "type": "object",
             "required": [
-                "endpoint"
+                "endpoint"
+            ],
+            "properties": {
+                "endpoint": {
+                    "description": "ip:port",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "e.g. {\"line\": \"A\", \"shop\": \"2\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "description": "Human readable name",
+                    "type": "string"
+                },
+                "series": {
+                    "description": "\"0i\", \"31i\"",
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "ms, default 5000",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DataReading": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "PMC area: G, F, Y, X, A, R, T, K, C, D, E",
+                    "type": "string"
+                },
+                "axis": {
+                    "description": "axis of a parameter or diagnosis, 0 - none",
+                    "type": "integer"
+                },
+                "class": {
+                    "description": "parameter, macro, pmc, diagnosis",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "default 1",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "first number or PMC address",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "byte, word, dword, real",
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DataValue"
+                    }
+                }
+            }
+        },
+        "models.DataValue": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "integer"
+                },
+                "vacant": {
+                    "description": "macro variable without a value",
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.JobRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "number": {
+                    "description": "0 - the executing program",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "program_download, connection_check, program_drift",
+                    "type": "string"
+                }
+            }
+        },
+        "models.KafkaBufferStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "dir": {
+                    "type": "string"
+                },
+                "drop_policy": {
+                    "type": "string"
+                },
+                "dropped": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "max_age": {
+                    "type": "string"
+                },
+                "max_bytes": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "integer"
+                },
+                "oldest_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.KafkaQueueStats": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OffsetChange": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "work offset name",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "description": "tool offset type or axis",
+                    "type": "string"
+                },
+                "overridden": {
+                    "description": "written despite automatic operation",
+                    "type": "boolean"
+                },
+                "previous": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ProgramAnalyzeRequest": {
+            "type": "object",
+            "required": [
+                "program"
             ],
             "properties": {
-                "endpoint": {
-                    "description": "ip:port",
+                "blocks": {
+                    "description": "include the parsed blocks",
+                    "type": "boolean"
+                },
+                "program": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProgramApproveRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramDirectory": {
+            "type": "object",
+            "properties": {
+                "main": {
+                    "description": "main program selected for automatic operation",
+                    "type": "integer"
+                },
+                "programs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProgramInfo"
+                    }
+                },
+                "running": {
+                    "description": "number of the running program, 0 if none",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramDrift": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "none approved yet",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ProgramVersion"
+                        }
+                    ]
+                },
+                "current": {
+                    "$ref": "#/definitions/entities.ProgramVersion"
+                },
+                "diff": {
+                    "description": "unified diff approved -\u003e current",
                     "type": "string"
                 },
-                "model": {
-                    "description": "Human readable name",
+                "drifted": {
+                    "description": "an approved version exists and differs",
+                    "type": "boolean"
+                },
+                "machine_id": {
                     "type": "string"
                 },
-                "series": {
-                    "description": "\"0i\", \"31i\"",
+                "number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramInfo": {
+            "type": "object",
+            "properties": {
+                "comment": {
                     "type": "string"
                 },
-                "timeout": {
-                    "description": "ms, default 5000",
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "e.g. O1234",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramLine": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "description": "the executing block",
+                    "type": "boolean"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProgramPosition": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "description": "executing block as the CNC reports it",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "0 if the block is not found in the text",
+                    "type": "integer"
+                },
+                "lines": {
+                    "description": "context around Line",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProgramLine"
+                    }
+                },
+                "machine_id": {
+                    "type": "string"
+                },
+                "matched": {
+                    "description": "block or, by the N number only, sequence",
+                    "type": "string"
+                },
+                "number": {
+                    "description": "executing program",
+                    "type": "integer"
+                },
+                "sequence": {
+                    "description": "N number executed last as the CNC reports it",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "stored version of the cached text",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramSelectRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "number"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "number": {
                     "type": "integer"
                 }
             }
         },
+        "models.ProgramUploadRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "program"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "overwrite": {
+                    "description": "replace a program with the same number",
+                    "type": "boolean"
+                },
+                "program": {
+                    "description": "program text, e.g. \"O1234(PART)\\nG0X0\\nM30\"",
+                    "type": "string"
+                }
+            }
+        },
         "models.StartPollingRequest": {
             "type": "object",
             "required": [
@@ -324,8 +3691,19 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "interval": {
-                    "description": "ms, default 10000",
+                    "description": "ms, default 5000",
                     "type": "integer"
+                },
+                "reads": {
+                    "description": "read with every poll into the snapshot",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.DataAddress"
+                    }
+                },
+                "watch_offsets": {
+                    "description": "publish tool and work offset changes found by polling",
+                    "type": "boolean"
                 }
             }
         },
@@ -339,6 +3717,251 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.ToolOffset": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "models.ToolOffsetWriteRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "number",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "incremental": {
+                    "description": "add Value to the current value",
+                    "type": "boolean"
+                },
+                "number": {
+                    "description": "tool offset number, from 1",
+                    "type": "integer"
+                },
+                "override": {
+                    "description": "write even if the machine is in automatic operation",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "mm or inch, the tip direction for tip types",
+                    "type": "number"
+                }
+            }
+        },
+        "models.ToolOffsets": {
+            "type": "object",
+            "properties": {
+                "memory": {
+                    "description": "A, B or C on a mill",
+                    "type": "string"
+                },
+                "offsets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ToolOffset"
+                    }
+                },
+                "turning": {
+                    "type": "boolean"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.WorkOffset": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "models.WorkOffsetWriteRequest": {
+            "type": "object",
+            "required": [
+                "axis",
+                "id"
+            ],
+            "properties": {
+                "axis": {
+                    "description": "axis name, e.g. X",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "incremental": {
+                    "description": "add Value to the current value",
+                    "type": "boolean"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "override": {
+                    "description": "write even if the machine is in automatic operation",
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.WorkOffsets": {
+            "type": "object",
+            "properties": {
+                "axes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "offsets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WorkOffset"
+                    }
+                }
+            }
+        },
+        "models.WriteDataRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "id",
+                "values"
+            ],
+            "properties": {
+                "area": {
+                    "description": "PMC area",
+                    "type": "string"
+                },
+                "class": {
+                    "description": "macro, pmc",
+                    "type": "string"
+                },
+                "dry_run": {
+                    "description": "check and read the current values without writing",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "number": {
+                    "description": "first number or PMC address",
+                    "type": "integer"
+                },
+                "override": {
+                    "description": "write even if the machine is in automatic operation",
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "PMC value type, default byte",
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.WriteResult": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "PMC area: G, F, Y, X, A, R, T, K, C, D, E",
+                    "type": "string"
+                },
+                "axis": {
+                    "description": "axis of a parameter or diagnosis, 0 - none",
+                    "type": "integer"
+                },
+                "class": {
+                    "description": "parameter, macro, pmc, diagnosis",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "default 1",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "number": {
+                    "description": "first number or PMC address",
+                    "type": "integer"
+                },
+                "overridden": {
+                    "description": "written despite automatic operation",
+                    "type": "boolean"
+                },
+                "previous": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DataValue"
+                    }
+                },
+                "type": {
+                    "description": "byte, word, dword, real",
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DataValue"
+                    }
+                }
+            }
+        },
+        "models.WriteRulesRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "rules": {
+                    "description": "empty forbids all writes",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.WriteRule"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -346,6 +3969,12 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "X-API-Key",
             "in": "header"
+        },
+        "BearerAuth": {
+            "description": "OIDC access token: \"Bearer \u003ctoken\u003e\" (AUTH_MODE=jwt or both)",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
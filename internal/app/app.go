@@ -2,44 +2,96 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/grpcapi"
 	"github.com/iwtcode/fanucService/internal/handlers"
 	"github.com/iwtcode/fanucService/internal/interfaces"
 	"github.com/iwtcode/fanucService/internal/repository"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
 	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/iwtcode/fanucService/internal/services/jobs"
 	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
 	"github.com/iwtcode/fanucService/internal/usecases"
 	"github.com/sirupsen/logrus"
 
 	"go.uber.org/fx"
+	"google.golang.org/grpc"
 )
 
 func New() *fx.App {
+	cfg := fanucService.LoadConfig()
+
 	return fx.New(
+		fx.Supply(cfg),
+		fx.StopTimeout(cfg.App.ShutdownTimeout),
 		fx.Provide(
-			fanucService.LoadConfig,
 			NewLogger,
 			kafka.NewProducer,
+			repository.NewDB,
 			repository.NewRepository,
+			repository.NewAPIKeyRepository,
+			repository.NewAuditRepository,
+			repository.NewClusterRepository,
+			repository.NewProgramVersionRepository,
+			repository.NewJobRepository,
+			cluster.NewMembership,
+			cluster.NewCoordinator,
 			fanuc.NewService,
+			jobs.NewRunner,
 			usecases.NewConnectionUsecase,
 			usecases.NewRestoreUsecase,
 			usecases.NewPollingUsecase,
 			usecases.NewProgramUsecase,
+			usecases.NewProgramVersionUsecase,
+			usecases.NewDataUsecase,
+			usecases.NewOffsetUsecase,
+			usecases.NewKafkaUsecase,
+			usecases.NewAPIKeyUsecase,
+			ratelimit.NewLimiter,
+			auth.NewJWTVerifier,
+			auth.NewCertMapper,
+			auth.NewServerTLSConfig,
+			auth.NewClusterPeers,
+			usecases.NewAuthUsecase,
+			usecases.NewAuditUsecase,
+			usecases.NewJobUsecase,
+			kafka.NewCommandConsumer,
 			handlers.NewConnectionHandler,
 			handlers.NewPollingHandler,
 			handlers.NewProgramHandler,
+			handlers.NewProgramVersionHandler,
+			handlers.NewDataHandler,
+			handlers.NewOffsetHandler,
+			handlers.NewKafkaHandler,
+			handlers.NewAPIKeyHandler,
+			handlers.NewAuditHandler,
+			handlers.NewJobHandler,
 			handlers.NewRouter,
+			grpcapi.NewServer,
+			grpcapi.NewGRPCServer,
 		),
+		// Hooks stop in reverse order: the command consumer and the API servers
+		// first, then the jobs, then registerShutdown stops pollers, Kafka and FOCAS handles,
+		// and last the instance leaves the cluster.
 		fx.Invoke(
+			bootstrapAPIKeys,
+			startCluster,
+			registerShutdown,
+			startJobs,
 			startServer,
+			startGRPCServer,
 			restoreConnections,
-			registerHooks,
+			startCommandConsumer,
 		),
 	)
 }
@@ -66,10 +118,100 @@ func NewLogger(cfg *fanucService.Config) *logrus.Logger {
 	return logger
 }
 
-func registerHooks(lifecycle fx.Lifecycle, producer *kafka.Producer) {
+// bootstrapAPIKeys runs before the servers start listening and aborts the
+// startup when no API key is configured.
+func bootstrapAPIKeys(lifecycle fx.Lifecycle, keys interfaces.APIKeyUsecase) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return keys.Bootstrap(ctx)
+		},
+	})
+}
+
+// startCluster joins the cluster before the API starts; it leaves last, once
+// the machines are no longer served.
+func startCluster(lifecycle fx.Lifecycle, coordinator *cluster.Coordinator) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			coordinator.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return coordinator.Stop()
+		},
+	})
+}
+
+// registerShutdown stops the data path once no API requests are served:
+// pollers are cancelled and waited for, the producer flushes what they sent,
+// and only then the FOCAS handles are closed. Machine modes are kept so the
+// next start restores polling. Each step gets the rest of SHUTDOWN_TIMEOUT.
+func registerShutdown(lifecycle fx.Lifecycle, service interfaces.FanucService, producer *kafka.Producer, logger *logrus.Logger) {
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Shutting down: stopping polling routines")
+			if err := service.StopAllPolling(ctx); err != nil {
+				logger.Errorf("Shutdown: %v", err)
+			}
+
+			logger.Info("Shutting down: flushing Kafka producer")
+			if err := closeWithin(ctx, producer.Close); err != nil {
+				logger.Errorf("Shutdown: failed to flush Kafka producer: %v", err)
+			}
+
+			logger.Info("Shutting down: closing FOCAS connections")
+			return service.CloseConnections(ctx)
+		},
+	})
+}
+
+// startJobs starts the job workers once the instance joined the cluster and
+// stops them before the FOCAS handles their jobs use are closed.
+func startJobs(lifecycle fx.Lifecycle, runner *jobs.Runner) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return runner.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			return runner.Stop(ctx)
+		},
+	})
+}
+
+// apiStopContext bounds the stop of an API server to half of the shutdown
+// time left, so a request that does not end in time leaves the rest to the
+// pollers, Kafka and FOCAS handles stopped after the servers.
+func apiStopContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/2)
+}
+
+// closeWithin runs fn, giving up waiting when ctx ends.
+func closeWithin(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startCommandConsumer is invoked after registerShutdown, so on shutdown the
+// consumer stops before the producer that carries its replies is closed.
+func startCommandConsumer(lifecycle fx.Lifecycle, consumer *kafka.CommandConsumer) {
 	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			consumer.Start()
+			return nil
+		},
 		OnStop: func(ctx context.Context) error {
-			return producer.Close()
+			return consumer.Stop()
 		},
 	})
 }
@@ -83,24 +225,80 @@ func restoreConnections(lifecycle fx.Lifecycle, usecase interfaces.RestoreUsecas
 	})
 }
 
-func startServer(lifecycle fx.Lifecycle, r *gin.Engine, cfg *fanucService.Config, logger *logrus.Logger) {
+func startServer(lifecycle fx.Lifecycle, r *gin.Engine, cfg *fanucService.Config, tlsCfg *tls.Config, logger *logrus.Logger) {
 	srv := &http.Server{
-		Addr:    ":" + cfg.App.Port,
-		Handler: r,
+		Addr:      ":" + cfg.App.Port,
+		Handler:   r,
+		TLSConfig: tlsCfg,
 	}
 
 	lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			go func() {
-				logger.Infof("Starting server on %s", srv.Addr)
-				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				var err error
+				if tlsCfg != nil {
+					logger.Infof("Starting HTTPS server on %s", srv.Addr)
+					err = srv.ListenAndServeTLS("", "")
+				} else {
+					logger.Infof("Starting server on %s", srv.Addr)
+					err = srv.ListenAndServe()
+				}
+				if err != nil && err != http.ErrServerClosed {
 					logger.Errorf("Server error: %v", err)
 				}
 			}()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			return srv.Shutdown(ctx)
+			ctx, cancel := apiStopContext(ctx)
+			defer cancel()
+			if err := srv.Shutdown(ctx); err != nil {
+				logger.Warnf("Shutdown: closing HTTP connections still open: %v", err)
+				return srv.Close()
+			}
+			return nil
+		},
+	})
+}
+
+// startGRPCServer stops the server gracefully once the watch streams, which
+// only end with their client, were closed.
+func startGRPCServer(lifecycle fx.Lifecycle, srv *grpc.Server, service interfaces.FanucService, cfg *fanucService.Config, logger *logrus.Logger) {
+	if cfg.App.GRPCPort == "" {
+		return
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			lis, err := net.Listen("tcp", ":"+cfg.App.GRPCPort)
+			if err != nil {
+				return err
+			}
+			go func() {
+				logger.Infof("Starting gRPC server on %s", lis.Addr())
+				if err := srv.Serve(lis); err != nil {
+					logger.Errorf("gRPC server error: %v", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			service.CloseWatchers()
+
+			ctx, cancel := apiStopContext(ctx)
+			defer cancel()
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				logger.Warn("Shutdown: cancelling gRPC calls still running")
+				srv.Stop()
+			}
+			return nil
 		},
 	})
 }
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+)
+
+const (
+	// Scopes - права API-ключа; admin включает все остальные
+	ScopeRead         = "read"
+	ScopeControl      = "control"
+	ScopeProgram      = "program"
+	ScopeProgramWrite = "program_write"
+	ScopeWrite        = "write"
+	ScopeAdmin        = "admin"
+
+	// BootstrapKeyName - ключ из переменной API_KEY
+	BootstrapKeyName = "bootstrap"
+)
+
+type APIKey struct {
+	ID     string `gorm:"primaryKey;type:uuid" json:"id"` // uuid
+	Name   string `gorm:"index;not null" json:"name"`
+	Prefix string `json:"prefix"`                        // начало ключа для опознания в списках
+	Hash   string `gorm:"uniqueIndex;not null" json:"-"` // sha256 ключа, сам ключ не хранится
+
+	Scopes     []string          `gorm:"serializer:json" json:"scopes"`
+	MachineIDs []string          `gorm:"serializer:json" json:"machine_ids,omitempty"` // пусто - все станки
+	Labels     map[string]string `gorm:"serializer:json" json:"labels,omitempty"`      // станки со всеми этими метками
+
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
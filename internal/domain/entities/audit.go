@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Audit actions - изменяющие операции и чтение программ
+	AuditConnect        = "connect"
+	AuditDelete         = "delete"
+	AuditPollingStart   = "polling_start"
+	AuditPollingStop    = "polling_stop"
+	AuditProgramRead    = "program_read"
+	AuditProgramUpload  = "program_upload"
+	AuditProgramDelete  = "program_delete"
+	AuditProgramSelect  = "program_select"
+	AuditProgramApprove = "program_approve"
+	AuditDataWrite      = "data_write"
+	AuditWriteRules     = "write_rules"
+	AuditToolOffset     = "tool_offset_write"
+	AuditWorkOffset     = "work_offset_write"
+	AuditKeyCreate      = "key_create"
+	AuditKeyRotate      = "key_rotate"
+	AuditKeyRevoke      = "key_revoke"
+	AuditJobCreate      = "job_create"
+	AuditJobCancel      = "job_cancel"
+
+	// Audit results
+	AuditResultOK      = "ok"
+	AuditResultError   = "error"
+	AuditResultPending = "pending" // stored before the operation, the result is not known yet
+
+	// MaxAuditPayload - длина сохраняемого тела запроса, остаток отбрасывается
+	MaxAuditPayload = 1024
+)
+
+type AuditEntry struct {
+	ID        string    `gorm:"primaryKey;type:uuid" json:"id"`
+	Timestamp time.Time `gorm:"index;not null" json:"timestamp"`
+
+	Actor      string `gorm:"index" json:"actor"`    // имя ключа или пользователь из токена
+	AuthMethod string `json:"auth_method,omitempty"` // api_key / jwt
+	KeyID      string `json:"key_id,omitempty"`      // id API-ключа
+	ClientIP   string `json:"client_ip,omitempty"`
+	Transport  string `json:"transport"` // http / grpc / kafka
+
+	Action    string `gorm:"index;not null" json:"action"`
+	MachineID string `gorm:"index" json:"machine_id,omitempty"`
+	Payload   string `json:"payload,omitempty"` // сокращенное тело запроса
+
+	Result string `json:"result"`           // ok / error / pending
+	Status int    `json:"status,omitempty"` // HTTP-статус или код gRPC
+	Error  string `json:"error,omitempty"`
+}
+
+// TruncatePayload shortens a request summary to MaxAuditPayload, cutting
+// before a rune that does not fit. Invalid UTF-8 is replaced, as Postgres
+// refuses it in a text column.
+func TruncatePayload(summary string) string {
+	if len(summary) > MaxAuditPayload {
+		cut := MaxAuditPayload
+		for cut > 0 && !utf8.RuneStart(summary[cut]) {
+			cut--
+		}
+		summary = summary[:cut] + "..."
+	}
+	return strings.ToValidUTF8(summary, "\uFFFD")
+}
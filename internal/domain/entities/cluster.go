@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+)
+
+// Instance is a running replica of the service. Replicas whose heartbeat is
+// older than the lease TTL are considered gone.
+type Instance struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	Address     string    `json:"address"` // базовый URL API для проксирования, например http://10.0.0.5:8080
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `gorm:"index" json:"heartbeat_at"`
+}
+
+// MachineLease grants one instance the right to talk to a machine (poll it,
+// hold its FOCAS handle) until ExpiresAt. The owner renews it on every heartbeat.
+type MachineLease struct {
+	MachineID string    `gorm:"primaryKey" json:"machine_id"`
+	OwnerID   string    `gorm:"index;not null" json:"owner_id"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
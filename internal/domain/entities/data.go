@@ -0,0 +1,51 @@
+package entities
+
+// Data classes that can be read from the CNC with a DataAddress.
+const (
+	DataParameter = "parameter"
+	DataMacro     = "macro"
+	DataPMC       = "pmc"
+	DataDiagnosis = "diagnosis"
+)
+
+// Offset classes of write rules. Offsets are written one value at a time, not
+// with a DataAddress.
+const (
+	DataToolOffset = "tool_offset"
+	DataWorkOffset = "work_offset"
+)
+
+// Data types of parameters, diagnostics and PMC addresses. Macro variables
+// are always real.
+const (
+	DataByte  = "byte"
+	DataWord  = "word"
+	DataDWord = "dword"
+	DataReal  = "real"
+)
+
+// DataAddress selects Count consecutive values of one data class starting at
+// Number: parameter, macro variable or diagnostic numbers, or the byte address
+// within a PMC area.
+type DataAddress struct {
+	Class  string `json:"class" form:"class"`           // parameter, macro, pmc, diagnosis
+	Number int    `json:"number" form:"number"`         // first number or PMC address
+	Count  int    `json:"count,omitempty" form:"count"` // default 1
+	Type   string `json:"type,omitempty" form:"type"`   // byte, word, dword, real
+	Axis   int    `json:"axis,omitempty" form:"axis"`   // axis of a parameter or diagnosis, 0 - none
+	Area   string `json:"area,omitempty" form:"area"`   // PMC area: G, F, Y, X, A, R, T, K, C, D, E
+}
+
+// WriteRule allows writing Count consecutive macro variables, PMC values of
+// Type or tool or work offset numbers starting at Number, with values between
+// Min and Max inclusive. A machine accepts no writes without rules.
+type WriteRule struct {
+	Class  string  `json:"class"`            // macro, pmc, tool_offset, work_offset
+	Number int     `json:"number"`           // first number or PMC address
+	Count  int     `json:"count,omitempty"`  // default 1
+	Type   string  `json:"type,omitempty"`   // PMC value type, default byte
+	Area   string  `json:"area,omitempty"`   // PMC area
+	Offset string  `json:"offset,omitempty"` // tool offset type or axis, empty allows all
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"time"
+)
+
+const (
+	// Job types
+	JobProgramDownload = "program_download" // чтение программы станка
+	JobConnectionCheck = "connection_check" // проверка связи со станками
+	JobProgramDrift    = "program_drift"    // сравнение выполняемых программ с одобренными
+
+	// Job statuses
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long operation run in the background. It is kept with its result
+// for JOB_RETENTION after it finished.
+type Job struct {
+	ID         string     `gorm:"primaryKey;type:uuid" json:"id"`
+	Type       string     `gorm:"not null" json:"type"`
+	MachineID  string     `gorm:"index" json:"machine_id,omitempty"`            // задача одного станка
+	MachineIDs []string   `gorm:"serializer:json" json:"machine_ids,omitempty"` // станки задачи по нескольким станкам
+	Number     int        `json:"number,omitempty"`                             // program_download, 0 - выполняемая программа
+	Owner      string     `gorm:"index" json:"-"`                               // CallerID создателя
+	CreatedBy  string     `json:"created_by,omitempty"`                         // имя ключа или пользователь из токена
+	Instance   string     `gorm:"index" json:"instance,omitempty"`              // экземпляр, выполняющий задачу
+	Status     string     `gorm:"index;not null" json:"status"`                 // queued / running / succeeded / failed / cancelled
+	Done       int64      `json:"done"`                                         // обработано: байт программы или станков
+	Total      int64      `json:"total"`                                        // 0 - неизвестно
+	Result     *JobResult `gorm:"serializer:json" json:"result,omitempty"`
+	Output     string     `gorm:"type:text" json:"-"` // текст программы, GET /jobs/{id}/output
+	Error      string     `json:"error,omitempty"`
+
+	CancelRequested bool `json:"cancel_requested,omitempty"` // отмена запрошена, задача еще завершается
+
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `gorm:"index" json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job ended and its status no longer changes.
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCancelled
+}
+
+// JobResult is what a job produced; the fields of its type are set.
+type JobResult struct {
+	Program  *JobProgram        `json:"program,omitempty"`
+	Machines []JobMachineResult `json:"machines,omitempty"`
+}
+
+// JobProgram describes a downloaded program text, which is the job output.
+type JobProgram struct {
+	Number int    `json:"number"` // 0 - программа без номера O
+	Length int64  `json:"length"` // bytes
+	SHA256 string `json:"sha256"`
+}
+
+// JobMachineResult is the outcome of a job for one of its machines.
+type JobMachineResult struct {
+	MachineID string `json:"machine_id"`
+	Status    string `json:"status,omitempty"`  // connection_check: статус станка после проверки
+	Number    int    `json:"number,omitempty"`  // program_drift: выполняемая программа
+	Drifted   *bool  `json:"drifted,omitempty"` // program_drift: отличается от одобренной версии
+	Error     string `json:"error,omitempty"`
+}
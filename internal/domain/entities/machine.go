@@ -22,6 +22,13 @@ type Machine struct {
 	Series   string `json:"series"`                               // "0i", "31i"
 	Interval int    `json:"interval"`                             // Интервал опроса в мс
 
+	Labels map[string]string `gorm:"serializer:json" json:"labels,omitempty"` // произвольные метки (цех, линия и т.д.)
+	Reads  []DataAddress     `gorm:"serializer:json" json:"reads,omitempty"`  // данные, читаемые при каждом опросе
+
+	WatchOffsets bool `json:"watch_offsets,omitempty"` // сравнивать коррекции при каждом опросе
+
+	WriteRules []WriteRule `gorm:"serializer:json" json:"write_rules,omitempty"` // разрешенные адреса и значения записи
+
 	Status string `gorm:"not null;default:'reconnecting'" json:"status"` // connected / reconnecting
 	Mode   string `gorm:"not null;default:'static'" json:"mode"`         // static / polling
 
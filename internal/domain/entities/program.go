@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"time"
+)
+
+const (
+	// Source - откуда получен текст версии программы
+	ProgramSourceDownload = "download" // прочитана через API
+	ProgramSourceUpload   = "upload"   // загружена на станок через API
+	ProgramSourceSnapshot = "snapshot" // периодический снимок при опросе
+)
+
+// ProgramVersion is a distinct text of a program seen on a machine. A text
+// read again unchanged only moves SeenAt of the latest version.
+type ProgramVersion struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	MachineID string `gorm:"index:idx_program_versions_program;not null" json:"machine_id"`
+	Number    int    `gorm:"index:idx_program_versions_program;not null" json:"number"` // номер O
+	Hash      string `gorm:"index;not null" json:"hash"`                                // sha256 текста
+	Size      int    `json:"size"`                                                      // размер текста в байтах
+	Source    string `json:"source"`                                                    // download / upload / snapshot
+	Content   string `gorm:"type:text" json:"content,omitempty"`                        // текст без обрамляющих '%'
+
+	Approved   bool       `gorm:"index" json:"approved"` // не более одной одобренной версии на программу станка
+	ApprovedBy string     `json:"approved_by,omitempty"` // имя ключа, одобрившего версию
+	ApprovedAt *time.Time `json:"approved_at,omitempty"` // время одобрения
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"` // когда текст прочитан впервые
+	SeenAt    time.Time `json:"seen_at"`                 // когда текст прочитан последний раз
+}
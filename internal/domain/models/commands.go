@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+)
+
+// Command types accepted on the Kafka command topic.
+const (
+	CommandConnect      = "connect"
+	CommandDelete       = "delete"
+	CommandStartPolling = "start_polling"
+	CommandStopPolling  = "stop_polling"
+	CommandGetProgram   = "get_program"
+)
+
+// HeaderCorrelationID carries the command ID when the command body has none.
+const HeaderCorrelationID = "correlation-id"
+
+// Command is a control message read from the Kafka command topic.
+type Command struct {
+	ID           string                 `json:"id"` // correlation id, echoed in the reply
+	Type         string                 `json:"type"`
+	MachineID    string                 `json:"machine_id,omitempty"`    // delete, start_polling, stop_polling, get_program
+	Interval     int                    `json:"interval,omitempty"`      // start_polling, ms
+	Reads        []entities.DataAddress `json:"reads,omitempty"`         // start_polling
+	WatchOffsets bool                   `json:"watch_offsets,omitempty"` // start_polling
+	Connection   *ConnectionRequest     `json:"connection,omitempty"`    // connect
+}
+
+// CommandReply is written to the response topic for every processed command.
+type CommandReply struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Status    string      `json:"status"` // ok, error
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+)
+
+// DataSchemaVersion is the version of the MachineDataEnvelope layout.
+// Bump it on any incompatible change and update docs/schemas accordingly.
+const DataSchemaVersion = "1"
+
+// Kafka header names carried on every polling message.
+const (
+	HeaderSchemaVersion    = "schema-version"
+	HeaderServiceVersion   = "service-version"
+	HeaderMachineID        = "machine-id"
+	HeaderAdapterMachineID = "adapter-machine-id"
+	HeaderEndpoint         = "endpoint"
+	HeaderModel            = "model"
+	HeaderSeries           = "series"
+	HeaderSequence         = "sequence"
+	HeaderPollStartedAt    = "poll-started-at"
+	HeaderPollFinishedAt   = "poll-finished-at"
+	HeaderContentType      = "content-type"
+	HeaderLabelPrefix      = "label."
+)
+
+// MachineDataEnvelope wraps a single polling snapshot published to Kafka.
+// The JSON Schema is published in docs/schemas/machine_data.v1.json.
+type MachineDataEnvelope struct {
+	SchemaVersion    string                        `json:"schema_version"`
+	ServiceVersion   string                        `json:"service_version"`
+	MachineID        string                        `json:"machine_id"`         // uuid of the machine record
+	AdapterMachineID string                        `json:"adapter_machine_id"` // id reported by fanucAdapter
+	Endpoint         string                        `json:"endpoint"`
+	Model            string                        `json:"model"`
+	Series           string                        `json:"series"`
+	Labels           map[string]string             `json:"labels,omitempty"`
+	Sequence         uint64                        `json:"sequence"` // per-machine, starts at 1 for every polling run
+	PollStartedAt    time.Time                     `json:"poll_started_at"`
+	PollFinishedAt   time.Time                     `json:"poll_finished_at"`
+	PollLatencyMs    int64                         `json:"poll_latency_ms"`
+	Data             *adapterModels.AggregatedData `json:"data"`
+	Reads            []DataReading                 `json:"reads,omitempty"`    // addresses configured with polling
+	Position         *ProgramPosition              `json:"position,omitempty"` // executing block, with program tracking
+}
+
+// Headers returns the envelope metadata as Kafka message headers.
+func (e *MachineDataEnvelope) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderSchemaVersion:    e.SchemaVersion,
+		HeaderServiceVersion:   e.ServiceVersion,
+		HeaderMachineID:        e.MachineID,
+		HeaderAdapterMachineID: e.AdapterMachineID,
+		HeaderEndpoint:         e.Endpoint,
+		HeaderModel:            e.Model,
+		HeaderSeries:           e.Series,
+		HeaderSequence:         strconv.FormatUint(e.Sequence, 10),
+		HeaderPollStartedAt:    e.PollStartedAt.UTC().Format(time.RFC3339Nano),
+		HeaderPollFinishedAt:   e.PollFinishedAt.UTC().Format(time.RFC3339Nano),
+	}
+	for k, v := range e.Labels {
+		headers[HeaderLabelPrefix+k] = v
+	}
+	return headers
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var (
@@ -10,8 +11,31 @@ var (
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrInternal      = errors.New("internal server error")
 	ErrBadRequest    = errors.New("bad request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrRateLimited   = errors.New("rate limit exceeded")
+	ErrNotOwner      = errors.New("machine is served by another instance")
+	ErrInterlocked   = errors.New("machine is in automatic operation")
+	ErrAuditFailed   = errors.New("audit entry could not be stored")
+	ErrTooLarge      = errors.New("too large")
+	ErrUnavailable   = errors.New("service unavailable")
 )
 
+// RateLimitError rejects a request that exceeded a rate or concurrency limit.
+// It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Reason, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
 type AppError struct {
 	Code    int
 	Message string
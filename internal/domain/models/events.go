@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+)
+
+// Alarm event types.
+const (
+	AlarmRaised  = "raised"
+	AlarmCleared = "cleared"
+)
+
+// Status event types.
+const (
+	StatusChanged = "status_changed"
+	ModeChanged   = "mode_changed"
+)
+
+// Offset event types.
+const (
+	ToolOffsetChanged = "tool_offset_changed"
+	WorkOffsetChanged = "work_offset_changed"
+)
+
+// Program event types.
+const (
+	ProgramVersionAdded = "program_version_added"
+	ProgramChanged      = "program_changed"
+	ProgramRestarted    = "program_restarted"
+)
+
+// AlarmEvent is published to the alarm topic when an alarm appears on or
+// disappears from the machine between two polls.
+type AlarmEvent struct {
+	Type      string                    `json:"type"`
+	MachineID string                    `json:"machine_id"`
+	Endpoint  string                    `json:"endpoint"`
+	Labels    map[string]string         `json:"labels,omitempty"`
+	Alarm     adapterModels.AlarmDetail `json:"alarm"`
+	Timestamp time.Time                 `json:"timestamp"`
+}
+
+// StatusEvent is published to the status topic on connection status and
+// polling mode transitions.
+type StatusEvent struct {
+	Type      string            `json:"type"`
+	MachineID string            `json:"machine_id"`
+	Endpoint  string            `json:"endpoint"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Previous  string            `json:"previous"`
+	Current   string            `json:"current"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// OffsetEvent is published to the offset topic when polling with offset
+// watching finds a tool or work offset value changed since the previous poll.
+type OffsetEvent struct {
+	Type      string            `json:"type"`
+	MachineID string            `json:"machine_id"`
+	Endpoint  string            `json:"endpoint"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Number    int               `json:"number"`
+	Name      string            `json:"name,omitempty"` // work offset name, e.g. G54
+	Offset    string            `json:"offset"`         // tool offset type or axis
+	Previous  float64           `json:"previous"`
+	Current   float64           `json:"current"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// ProgramEvent is published to the program topic when a program text not seen
+// before on a machine is recorded in the version store, and by program
+// tracking when the executing program changes or execution goes back to its
+// start.
+type ProgramEvent struct {
+	Type           string            `json:"type"`
+	MachineID      string            `json:"machine_id"`
+	Endpoint       string            `json:"endpoint"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Number         int               `json:"number"`
+	Version        uint              `json:"version,omitempty"`
+	Hash           string            `json:"hash"`
+	Previous       string            `json:"previous,omitempty"`        // hash of the previous version or program
+	PreviousNumber int               `json:"previous_number,omitempty"` // program executed before, program_changed
+	Approved       string            `json:"approved,omitempty"`        // hash of the approved version
+	Drifted        bool              `json:"drifted"`                   // an approved version exists and differs
+	Source         string            `json:"source,omitempty"`          // download, upload, snapshot
+	Line           int               `json:"line,omitempty"`            // located line, program_restarted
+	Sequence       int               `json:"sequence,omitempty"`        // N number, program_restarted
+	Timestamp      time.Time         `json:"timestamp"`
+}
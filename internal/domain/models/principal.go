@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"slices"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+)
+
+// Authentication methods of a principal.
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+	AuthMethodMTLS   = "mtls"
+)
+
+// Principal is the authenticated caller of an API request: an API key, the
+// subject of a bearer token or of a client certificate.
+type Principal struct {
+	KeyID      string // empty for bearer tokens
+	Name       string // key name, token subject or certificate CN
+	AuthMethod string
+	Scopes     []string
+	MachineIDs []string
+	Labels     map[string]string
+}
+
+// HasScope reports whether the caller was granted the scope; admin grants all.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == entities.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CallerID identifies the caller across requests: the key ID of an API key,
+// otherwise the authentication method and name.
+func (p *Principal) CallerID() string {
+	if p.KeyID != "" {
+		return p.KeyID
+	}
+	return p.AuthMethod + ":" + p.Name
+}
+
+// Restricted reports whether the caller may only access some machines.
+func (p *Principal) Restricted() bool {
+	return len(p.MachineIDs) > 0 || len(p.Labels) > 0
+}
+
+// CanAccess reports whether the caller may access a machine with the given
+// ID and labels: it must be listed in MachineIDs or carry all Labels.
+func (p *Principal) CanAccess(machineID string, labels map[string]string) bool {
+	if !p.Restricted() {
+		return true
+	}
+	for _, id := range p.MachineIDs {
+		if id == machineID {
+			return true
+		}
+	}
+	return len(p.Labels) > 0 && matchLabels(p.Labels, labels)
+}
+
+// Grants reports whether the caller holds every right of a key with the given
+// scopes and restrictions, so that handing such a key out escalates nothing.
+// A restricted caller may only hand out keys limited to machines it lists, or
+// to labels that include all of its own.
+func (p *Principal) Grants(scopes []string, machineIDs []string, labels map[string]string) bool {
+	for _, scope := range scopes {
+		if !p.HasScope(scope) {
+			return false
+		}
+	}
+	if !p.Restricted() {
+		return true
+	}
+	if len(machineIDs) == 0 && len(labels) == 0 {
+		return false
+	}
+	for _, id := range machineIDs {
+		if !slices.Contains(p.MachineIDs, id) {
+			return false
+		}
+	}
+	if len(labels) > 0 && (len(p.Labels) == 0 || !matchLabels(p.Labels, labels)) {
+		return false
+	}
+	return true
+}
+
+func matchLabels(want, have map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller, or nil for internal calls
+// (restore on startup, Kafka commands) that are not subject to API auth.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
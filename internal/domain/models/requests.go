@@ -1,17 +1,148 @@
 package models
 
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+)
+
 type ConnectionRequest struct {
 	Endpoint string `json:"endpoint" binding:"required"` // ip:port
 	Timeout  int    `json:"timeout"`                     // ms, default 5000
 	Model    string `json:"model"`                       // Human readable name
 	Series   string `json:"series"`                      // "0i", "31i"
+
+	Labels map[string]string `json:"labels"` // e.g. {"line": "A", "shop": "2"}
 }
 
 type StartPollingRequest struct {
 	ID       string `json:"id" binding:"required"`
 	Interval int    `json:"interval"` // ms, default 5000
+
+	Reads        []entities.DataAddress `json:"reads"`         // read with every poll into the snapshot
+	WatchOffsets bool                   `json:"watch_offsets"` // publish tool and work offset changes found by polling
+}
+
+// WriteDataRequest writes Values to consecutive macro variables or PMC
+// addresses starting at Number.
+type WriteDataRequest struct {
+	ID     string    `json:"id" binding:"required"`
+	Class  string    `json:"class" binding:"required"` // macro, pmc
+	Number int       `json:"number"`                   // first number or PMC address
+	Type   string    `json:"type"`                     // PMC value type, default byte
+	Area   string    `json:"area"`                     // PMC area
+	Values []float64 `json:"values" binding:"required"`
+
+	DryRun   bool `json:"dry_run"`  // check and read the current values without writing
+	Override bool `json:"override"` // write even if the machine is in automatic operation
+}
+
+type WriteRulesRequest struct {
+	ID    string               `json:"id" binding:"required"`
+	Rules []entities.WriteRule `json:"rules"` // empty forbids all writes
+}
+
+// ToolOffsetWriteRequest sets one value of a tool offset. Type is one of the
+// offset types of ToolOffsets, e.g. length_wear on a mill with memory C or
+// x_geometry on a lathe.
+type ToolOffsetWriteRequest struct {
+	ID          string  `json:"id" binding:"required"`
+	Number      int     `json:"number" binding:"required"` // tool offset number, from 1
+	Type        string  `json:"type" binding:"required"`
+	Value       float64 `json:"value"`       // mm or inch, the tip direction for tip types
+	Incremental bool    `json:"incremental"` // add Value to the current value
+	Override    bool    `json:"override"`    // write even if the machine is in automatic operation
+}
+
+// WorkOffsetWriteRequest sets the value of one axis of a work coordinate
+// system: 0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on.
+type WorkOffsetWriteRequest struct {
+	ID          string  `json:"id" binding:"required"`
+	Number      int     `json:"number"`
+	Axis        string  `json:"axis" binding:"required"` // axis name, e.g. X
+	Value       float64 `json:"value"`
+	Incremental bool    `json:"incremental"` // add Value to the current value
+	Override    bool    `json:"override"`    // write even if the machine is in automatic operation
+}
+
+// ProgramUploadRequest stores an NC program in CNC memory. The program number
+// is the O number at the start of Program.
+type ProgramUploadRequest struct {
+	ID        string `json:"id" binding:"required"`
+	Program   string `json:"program" binding:"required"` // program text, e.g. "O1234(PART)\nG0X0\nM30"
+	Overwrite bool   `json:"overwrite"`                  // replace a program with the same number
+}
+
+// ProgramAnalyzeRequest analyzes a program text that is not on a machine.
+type ProgramAnalyzeRequest struct {
+	Program string `json:"program" binding:"required"`
+	Blocks  bool   `json:"blocks"` // include the parsed blocks
+}
+
+// ProgramSelectRequest makes a program the main program of a machine.
+type ProgramSelectRequest struct {
+	ID     string `json:"id" binding:"required"`
+	Number int    `json:"number" binding:"required"`
+}
+
+// ProgramApproveRequest marks a recorded program version as the approved one
+// of its program on its machine.
+type ProgramApproveRequest struct {
+	Version uint `json:"version" binding:"required"`
+}
+
+// JobRequest starts a background job. A program_download job reads program
+// Number of machine ID; connection_check and program_drift jobs cover IDs, all
+// machines of the caller if empty.
+type JobRequest struct {
+	Type   string   `json:"type" binding:"required"` // program_download, connection_check, program_drift
+	ID     string   `json:"id"`
+	Number int      `json:"number"` // 0 - the executing program
+	IDs    []string `json:"ids"`
 }
 
 type StopPollingRequest struct {
 	ID string `json:"id" binding:"required"`
 }
+
+type APIKeyRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	Scopes     []string          `json:"scopes" binding:"required"` // read, control, program, program_write, write, admin
+	MachineIDs []string          `json:"machine_ids"`               // restrict to these machines
+	Labels     map[string]string `json:"labels"`                    // restrict to machines with all these labels
+}
+
+// Credentials are the authentication data of a request: an API key, an OIDC
+// bearer token and/or a verified TLS client certificate.
+type Credentials struct {
+	APIKey            string
+	BearerToken       string
+	ClientCertificate *x509.Certificate
+}
+
+type AuditFilter struct {
+	MachineID string    `form:"machine_id"`
+	Actor     string    `form:"actor"`
+	Action    string    `form:"action"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int       `form:"limit"` // default 100, max 1000
+}
+
+// ProgramVersionFilter selects the recorded versions of the programs of a
+// machine, newest first.
+type ProgramVersionFilter struct {
+	MachineID string `form:"id" binding:"required"`
+	Number    int    `form:"number"` // 0 - all programs
+	Limit     int    `form:"limit"`  // default 100, max 1000
+}
+
+// JobFilter selects jobs, newest first. Callers see their own jobs, admins
+// all.
+type JobFilter struct {
+	MachineID string `form:"id"`
+	Status    string `form:"status"`
+	Limit     int    `form:"limit"` // default 100, max 1000
+	Owner     string `form:"-"`     // CallerID of the creator, empty for all
+}
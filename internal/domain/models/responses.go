@@ -1,5 +1,11 @@
 package models
 
+import (
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+)
+
 type APIResponse struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message,omitempty"`
@@ -11,3 +17,154 @@ type MachineResponse struct {
 	Endpoint string `json:"endpoint"`
 	Status   string `json:"status"`
 }
+
+type KafkaBufferStats struct {
+	Enabled    bool       `json:"enabled"`
+	Dir        string     `json:"dir,omitempty"`
+	Messages   int        `json:"messages"`
+	Bytes      int64      `json:"bytes"`
+	OldestAt   *time.Time `json:"oldest_at,omitempty"`
+	Dropped    uint64     `json:"dropped"`
+	MaxBytes   int64      `json:"max_bytes"`
+	MaxAge     string     `json:"max_age"`
+	DropPolicy string     `json:"drop_policy"`
+}
+
+type KafkaQueueStats struct {
+	Key      string `json:"key"`
+	Length   int    `json:"length"`
+	Capacity int    `json:"capacity"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// APIKeyCreated carries the plain key. It is returned only on create and
+// rotate; afterwards only the hash is stored.
+type APIKeyCreated struct {
+	*entities.APIKey
+	Key string `json:"key"`
+}
+
+// DataReading is the result of reading a DataAddress. In a polling snapshot an
+// address that could not be read carries Error instead of Values.
+type DataReading struct {
+	entities.DataAddress
+	Values []DataValue `json:"values"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// WriteResult reports a write: the values before it and the values read back
+// after it, or the values to be written on a dry run.
+type WriteResult struct {
+	entities.DataAddress
+	Previous   []DataValue `json:"previous"`
+	Values     []DataValue `json:"values"`
+	DryRun     bool        `json:"dry_run,omitempty"`
+	Overridden bool        `json:"overridden,omitempty"` // written despite automatic operation
+}
+
+// DataValue is one value of a DataReading, numbered like the address: by
+// parameter, variable or diagnostic number, or by PMC byte address.
+type DataValue struct {
+	Number int     `json:"number"`
+	Value  float64 `json:"value"`
+	Vacant bool    `json:"vacant,omitempty"` // macro variable without a value
+}
+
+// ToolOffsets is the tool offset table of a machine. The offset types depend
+// on the machine: a lathe has X, Z, radius and tip values, a mill those of its
+// tool offset memory (A, B or C).
+type ToolOffsets struct {
+	Turning bool         `json:"turning"`
+	Memory  string       `json:"memory,omitempty"` // A, B or C on a mill
+	Types   []string     `json:"types"`
+	Offsets []ToolOffset `json:"offsets"`
+}
+
+// ToolOffset holds the values of one tool offset number by offset type.
+type ToolOffset struct {
+	Number int                `json:"number"`
+	Values map[string]float64 `json:"values"`
+}
+
+// WorkOffsets is the work coordinate system table of a machine.
+type WorkOffsets struct {
+	Axes    []string     `json:"axes"`
+	Offsets []WorkOffset `json:"offsets"`
+}
+
+// WorkOffset holds the values of one work coordinate system by axis. Number 0
+// is the external offset (EXT), 1-6 are G54-G59 and 7 on G54.1 P1 on.
+type WorkOffset struct {
+	Number int                `json:"number"`
+	Name   string             `json:"name"`
+	Values map[string]float64 `json:"values"`
+}
+
+// OffsetChange reports a tool or work offset write: the value before it and
+// the value read back after it.
+type OffsetChange struct {
+	Number   int     `json:"number"`
+	Name     string  `json:"name,omitempty"` // work offset name
+	Offset   string  `json:"offset"`         // tool offset type or axis
+	Previous float64 `json:"previous"`
+	Value    float64 `json:"value"`
+
+	Overridden bool `json:"overridden,omitempty"` // written despite automatic operation
+}
+
+// ProgramDirectory lists the programs in CNC memory.
+type ProgramDirectory struct {
+	Running  int           `json:"running"` // number of the running program, 0 if none
+	Main     int           `json:"main"`    // main program selected for automatic operation
+	Programs []ProgramInfo `json:"programs"`
+}
+
+// ProgramInfo is a program in CNC memory.
+type ProgramInfo struct {
+	Number     int        `json:"number"`
+	Name       string     `json:"name"` // e.g. O1234
+	Size       int        `json:"size"` // bytes
+	Comment    string     `json:"comment,omitempty"`
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// ProgramStat describes a program text before it is streamed. Length and ETag
+// are known once the unchanged program was downloaded in full before.
+type ProgramStat struct {
+	Number int    // 0 for an executing program without an O number
+	Length int64  // bytes of the framed text, -1 if unknown
+	ETag   string // quoted SHA-256 of the framed text, empty if unknown
+}
+
+// ProgramDrift compares the program a machine executes with the approved
+// version of that program. Versions are listed without their text.
+type ProgramDrift struct {
+	MachineID string                   `json:"machine_id"`
+	Number    int                      `json:"number"`
+	Current   *entities.ProgramVersion `json:"current"`
+	Approved  *entities.ProgramVersion `json:"approved,omitempty"` // none approved yet
+	Drifted   bool                     `json:"drifted"`            // an approved version exists and differs
+	Diff      string                   `json:"diff,omitempty"`     // unified diff approved -> current
+}
+
+// ProgramPosition is where a machine is in the program it executes: the
+// executing block located in the cached program text with the lines around
+// it. Lines count from the first line of the text as the CNC returns it.
+type ProgramPosition struct {
+	MachineID string        `json:"machine_id"`
+	Number    int           `json:"number"`             // executing program
+	Version   uint          `json:"version,omitempty"`  // stored version of the cached text
+	Sequence  int           `json:"sequence,omitempty"` // N number executed last as the CNC reports it
+	Block     string        `json:"block,omitempty"`    // executing block as the CNC reports it
+	Line      int           `json:"line,omitempty"`     // 0 if the block is not found in the text
+	Matched   string        `json:"matched,omitempty"`  // block or, by the N number only, sequence
+	Lines     []ProgramLine `json:"lines,omitempty"`    // context around Line
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ProgramLine is a line of a program text.
+type ProgramLine struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Current bool   `json:"current,omitempty"` // the executing block
+}
@@ -0,0 +1,398 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements fanucv1.FanucServiceServer on top of the same usecases
+// as the REST handlers.
+type Server struct {
+	fanucv1.UnimplementedFanucServiceServer
+
+	connections interfaces.ConnectionUsecase
+	polling     interfaces.PollingUsecase
+	programs    interfaces.ProgramUsecase
+	data        interfaces.DataUsecase
+	offsets     interfaces.OffsetUsecase
+}
+
+func NewServer(connections interfaces.ConnectionUsecase, polling interfaces.PollingUsecase, programs interfaces.ProgramUsecase, data interfaces.DataUsecase, offsets interfaces.OffsetUsecase) *Server {
+	return &Server{connections: connections, polling: polling, programs: programs, data: data, offsets: offsets}
+}
+
+// methodScopes is the scope each RPC requires, matching the REST routes.
+var methodScopes = map[string]string{
+	fanucv1.FanucService_CreateConnection_FullMethodName: entities.ScopeControl,
+	fanucv1.FanucService_ListConnections_FullMethodName:  entities.ScopeRead,
+	fanucv1.FanucService_CheckConnection_FullMethodName:  entities.ScopeRead,
+	fanucv1.FanucService_DeleteConnection_FullMethodName: entities.ScopeControl,
+	fanucv1.FanucService_StartPolling_FullMethodName:     entities.ScopeControl,
+	fanucv1.FanucService_StopPolling_FullMethodName:      entities.ScopeControl,
+	fanucv1.FanucService_GetProgram_FullMethodName:       entities.ScopeProgram,
+	fanucv1.FanucService_ReadData_FullMethodName:         entities.ScopeRead,
+	fanucv1.FanucService_WriteData_FullMethodName:        entities.ScopeWrite,
+	fanucv1.FanucService_ReadToolOffsets_FullMethodName:  entities.ScopeRead,
+	fanucv1.FanucService_ReadWorkOffsets_FullMethodName:  entities.ScopeRead,
+	fanucv1.FanucService_WriteToolOffset_FullMethodName:  entities.ScopeWrite,
+	fanucv1.FanucService_WriteWorkOffset_FullMethodName:  entities.ScopeWrite,
+	fanucv1.FanucService_WatchMachineData_FullMethodName: entities.ScopeRead,
+}
+
+// methodActions is the audit action of each audited RPC, matching the REST routes.
+var methodActions = map[string]string{
+	fanucv1.FanucService_CreateConnection_FullMethodName: entities.AuditConnect,
+	fanucv1.FanucService_DeleteConnection_FullMethodName: entities.AuditDelete,
+	fanucv1.FanucService_StartPolling_FullMethodName:     entities.AuditPollingStart,
+	fanucv1.FanucService_StopPolling_FullMethodName:      entities.AuditPollingStop,
+	fanucv1.FanucService_GetProgram_FullMethodName:       entities.AuditProgramRead,
+	fanucv1.FanucService_WriteData_FullMethodName:        entities.AuditDataWrite,
+	fanucv1.FanucService_WriteToolOffset_FullMethodName:  entities.AuditToolOffset,
+	fanucv1.FanucService_WriteWorkOffset_FullMethodName:  entities.AuditWorkOffset,
+}
+
+// requiredAudits are the RPCs refused when their audit entry cannot be stored.
+var requiredAudits = map[string]bool{
+	fanucv1.FanucService_WriteData_FullMethodName:       true,
+	fanucv1.FanucService_WriteToolOffset_FullMethodName: true,
+	fanucv1.FanucService_WriteWorkOffset_FullMethodName: true,
+}
+
+// NewGRPCServer creates the gRPC server with auth and audit interceptors and
+// registers the service on it.
+func NewGRPCServer(auth interfaces.AuthUsecase, audit interfaces.AuditUsecase, limiter *ratelimit.Limiter, srv *Server, tlsCfg *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryAuth(auth, methodScopes),
+			middleware.UnaryRateLimit(limiter),
+			middleware.UnaryAudit(audit, methodActions, requiredAudits),
+		),
+		grpc.ChainStreamInterceptor(middleware.StreamAuth(auth, methodScopes), middleware.StreamRateLimit(limiter)),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	server := grpc.NewServer(opts...)
+	fanucv1.RegisterFanucServiceServer(server, srv)
+	return server
+}
+
+func (s *Server) CreateConnection(ctx context.Context, req *fanucv1.CreateConnectionRequest) (*fanucv1.Machine, error) {
+	if req.GetEndpoint() == "" {
+		return nil, status.Error(codes.InvalidArgument, "endpoint is required")
+	}
+
+	machine, err := s.connections.Create(ctx, models.ConnectionRequest{
+		Endpoint: req.GetEndpoint(),
+		Timeout:  int(req.GetTimeout()),
+		Model:    req.GetModel(),
+		Series:   req.GetSeries(),
+		Labels:   req.GetLabels(),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return machineToProto(machine), nil
+}
+
+func (s *Server) ListConnections(ctx context.Context, _ *fanucv1.ListConnectionsRequest) (*fanucv1.ListConnectionsResponse, error) {
+	machines, err := s.connections.List(ctx)
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	resp := &fanucv1.ListConnectionsResponse{Machines: make([]*fanucv1.Machine, 0, len(machines))}
+	for i := range machines {
+		resp.Machines = append(resp.Machines, machineToProto(&machines[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) CheckConnection(ctx context.Context, req *fanucv1.CheckConnectionRequest) (*fanucv1.Machine, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	machine, err := s.connections.Check(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err, codes.Unavailable)
+	}
+	return machineToProto(machine), nil
+}
+
+func (s *Server) DeleteConnection(ctx context.Context, req *fanucv1.DeleteConnectionRequest) (*fanucv1.DeleteConnectionResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := s.connections.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &fanucv1.DeleteConnectionResponse{}, nil
+}
+
+func (s *Server) StartPolling(ctx context.Context, req *fanucv1.StartPollingRequest) (*fanucv1.StartPollingResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	reads := make([]entities.DataAddress, 0, len(req.GetReads()))
+	for _, addr := range req.GetReads() {
+		reads = append(reads, addressFromProto(addr))
+	}
+
+	err := s.polling.Start(ctx, models.StartPollingRequest{ID: req.GetId(), Interval: int(req.GetInterval()), Reads: reads, WatchOffsets: req.GetWatchOffsets()})
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &fanucv1.StartPollingResponse{}, nil
+}
+
+func (s *Server) StopPolling(ctx context.Context, req *fanucv1.StopPollingRequest) (*fanucv1.StopPollingResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := s.polling.Stop(ctx, models.StopPollingRequest{ID: req.GetId()}); err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &fanucv1.StopPollingResponse{}, nil
+}
+
+func (s *Server) GetProgram(ctx context.Context, req *fanucv1.GetProgramRequest) (*fanucv1.GetProgramResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	program, err := s.programs.GetProgram(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &fanucv1.GetProgramResponse{Program: program}, nil
+}
+
+func (s *Server) ReadData(ctx context.Context, req *fanucv1.ReadDataRequest) (*fanucv1.DataReading, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.GetAddress() == nil {
+		return nil, status.Error(codes.InvalidArgument, "address is required")
+	}
+
+	reading, err := s.data.Read(ctx, req.GetId(), addressFromProto(req.GetAddress()))
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return kafka.DataReadingToProto(reading), nil
+}
+
+func (s *Server) WriteData(ctx context.Context, req *fanucv1.WriteDataRequest) (*fanucv1.WriteDataResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	result, err := s.data.Write(ctx, models.WriteDataRequest{
+		ID:       req.GetId(),
+		Class:    req.GetClass(),
+		Number:   int(req.GetNumber()),
+		Type:     req.GetType(),
+		Area:     req.GetArea(),
+		Values:   req.GetValues(),
+		DryRun:   req.GetDryRun(),
+		Override: req.GetOverride(),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	after := kafka.DataReadingToProto(&models.DataReading{DataAddress: result.DataAddress, Values: result.Values})
+	before := kafka.DataReadingToProto(&models.DataReading{DataAddress: result.DataAddress, Values: result.Previous})
+	return &fanucv1.WriteDataResponse{
+		Address:    after.GetAddress(),
+		Previous:   before.GetValues(),
+		Values:     after.GetValues(),
+		DryRun:     result.DryRun,
+		Overridden: result.Overridden,
+	}, nil
+}
+
+func (s *Server) ReadToolOffsets(ctx context.Context, req *fanucv1.ReadOffsetsRequest) (*fanucv1.ToolOffsets, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	offsets, err := s.offsets.ToolOffsets(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	resp := &fanucv1.ToolOffsets{
+		Turning: offsets.Turning,
+		Memory:  offsets.Memory,
+		Types:   offsets.Types,
+		Offsets: make([]*fanucv1.ToolOffset, 0, len(offsets.Offsets)),
+	}
+	for _, o := range offsets.Offsets {
+		resp.Offsets = append(resp.Offsets, &fanucv1.ToolOffset{Number: int32(o.Number), Values: o.Values})
+	}
+	return resp, nil
+}
+
+func (s *Server) ReadWorkOffsets(ctx context.Context, req *fanucv1.ReadOffsetsRequest) (*fanucv1.WorkOffsets, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	offsets, err := s.offsets.WorkOffsets(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+
+	resp := &fanucv1.WorkOffsets{Axes: offsets.Axes, Offsets: make([]*fanucv1.WorkOffset, 0, len(offsets.Offsets))}
+	for _, o := range offsets.Offsets {
+		resp.Offsets = append(resp.Offsets, &fanucv1.WorkOffset{Number: int32(o.Number), Name: o.Name, Values: o.Values})
+	}
+	return resp, nil
+}
+
+func (s *Server) WriteToolOffset(ctx context.Context, req *fanucv1.WriteToolOffsetRequest) (*fanucv1.OffsetChange, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	change, err := s.offsets.WriteToolOffset(ctx, models.ToolOffsetWriteRequest{
+		ID:          req.GetId(),
+		Number:      int(req.GetNumber()),
+		Type:        req.GetType(),
+		Value:       req.GetValue(),
+		Incremental: req.GetIncremental(),
+		Override:    req.GetOverride(),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return offsetChangeToProto(change), nil
+}
+
+func (s *Server) WriteWorkOffset(ctx context.Context, req *fanucv1.WriteWorkOffsetRequest) (*fanucv1.OffsetChange, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	change, err := s.offsets.WriteWorkOffset(ctx, models.WorkOffsetWriteRequest{
+		ID:          req.GetId(),
+		Number:      int(req.GetNumber()),
+		Axis:        req.GetAxis(),
+		Value:       req.GetValue(),
+		Incremental: req.GetIncremental(),
+		Override:    req.GetOverride(),
+	})
+	if err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return offsetChangeToProto(change), nil
+}
+
+func (s *Server) WatchMachineData(req *fanucv1.WatchMachineDataRequest, stream fanucv1.FanucService_WatchMachineDataServer) error {
+	if req.GetId() == "" {
+		return status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	ctx := stream.Context()
+	updates, err := s.polling.Watch(ctx, req.GetId())
+	if err != nil {
+		return toStatus(err, codes.NotFound)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case env, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(kafka.EnvelopeToProto(env)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps usecase errors to gRPC codes, falling back to code.
+func toStatus(err error, code codes.Code) error {
+	switch {
+	case errors.Is(err, models.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, models.ErrNotOwner), errors.Is(err, models.ErrAuditFailed), errors.Is(err, models.ErrUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, models.ErrInterlocked):
+		code = codes.FailedPrecondition
+	case errors.Is(err, models.ErrTooLarge):
+		code = codes.ResourceExhausted
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, models.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, models.ErrBadRequest):
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, err.Error())
+}
+
+func machineToProto(m *entities.Machine) *fanucv1.Machine {
+	if m == nil {
+		return nil
+	}
+
+	return &fanucv1.Machine{
+		Id:          m.ID,
+		Endpoint:    m.Endpoint,
+		Timeout:     int32(m.Timeout),
+		Model:       m.Model,
+		Series:      m.Series,
+		Interval:    int32(m.Interval),
+		Labels:      m.Labels,
+		Status:      m.Status,
+		Mode:        m.Mode,
+		CreatedAtMs: m.CreatedAt.UnixMilli(),
+		UpdatedAtMs: m.UpdatedAt.UnixMilli(),
+	}
+}
+
+func addressFromProto(a *fanucv1.DataAddress) entities.DataAddress {
+	return entities.DataAddress{
+		Class:  a.GetClass(),
+		Number: int(a.GetNumber()),
+		Count:  int(a.GetCount()),
+		Type:   a.GetType(),
+		Axis:   int(a.GetAxis()),
+		Area:   a.GetArea(),
+	}
+}
+
+func offsetChangeToProto(c *models.OffsetChange) *fanucv1.OffsetChange {
+	return &fanucv1.OffsetChange{
+		Number:     int32(c.Number),
+		Name:       c.Name,
+		Offset:     c.Offset,
+		Previous:   c.Previous,
+		Value:      c.Value,
+		Overridden: c.Overridden,
+	}
+}
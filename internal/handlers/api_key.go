@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+type APIKeyHandler struct {
+	usecase interfaces.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(usecase interfaces.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{usecase: usecase}
+}
+
+// Create
+// @Summary Create an API key
+// @Description Creates a named key with scopes (read, control, program, program_write, write, admin) and optional machine/label restrictions. The key is returned only once.
+// @Tags Keys
+// @Accept json
+// @Produce json
+// @Param input body models.APIKeyRequest true "Key Data"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.APIKeyCreated}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/v1/keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req models.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := h.usecase.Create(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, key)
+}
+
+// List
+// @Summary List API keys
+// @Description Lists all keys including revoked ones. Secrets are never returned.
+// @Tags Keys
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=[]entities.APIKey}
+// @Failure 403 {object} models.APIResponse
+// @Router /api/v1/keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.usecase.List(c.Request.Context())
+	if err != nil {
+		RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondSuccess(c, keys)
+}
+
+// Rotate
+// @Summary Rotate an API key
+// @Description Issues a new secret for the key; the old one stops working immediately
+// @Tags Keys
+// @Produce json
+// @Param id query string true "Key ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.APIKeyCreated}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/v1/keys/rotate [post]
+func (h *APIKeyHandler) Rotate(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	key, err := h.usecase.Rotate(c.Request.Context(), id)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, key)
+}
+
+// Revoke
+// @Summary Revoke an API key
+// @Tags Keys
+// @Produce json
+// @Param id query string true "Key ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/v1/keys [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	if err := h.usecase.Revoke(c.Request.Context(), id); err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondMessage(c, fmt.Sprintf("API key %s revoked", id))
+}
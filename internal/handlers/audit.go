@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+type AuditHandler struct {
+	usecase interfaces.AuditUsecase
+}
+
+func NewAuditHandler(usecase interfaces.AuditUsecase) *AuditHandler {
+	return &AuditHandler{usecase: usecase}
+}
+
+// List
+// @Summary Query the audit log
+// @Description Returns audit entries of control actions, newest first
+// @Tags Audit
+// @Produce json
+// @Param machine_id query string false "Machine ID"
+// @Param actor query string false "Key name or token subject"
+// @Param action query string false "Action (connect, delete, polling_start, polling_stop, program_read, key_create, key_rotate, key_revoke)"
+// @Param from query string false "From, RFC 3339"
+// @Param to query string false "To, RFC 3339"
+// @Param limit query int false "Max entries, default 100, max 1000"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=[]entities.AuditEntry}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	var filter models.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.usecase.List(c.Request.Context(), filter)
+	if err != nil {
+		RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondSuccess(c, entries)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/iwtcode/fanucService/internal/domain/models"
 	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
 )
 
 type ConnectionHandler struct {
@@ -25,6 +26,7 @@ func NewConnectionHandler(usecase interfaces.ConnectionUsecase) *ConnectionHandl
 // @Produce json
 // @Param input body models.ConnectionRequest true "Connection Data"
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
@@ -38,9 +40,10 @@ func (h *ConnectionHandler) Create(c *gin.Context) {
 
 	machine, err := h.usecase.Create(c.Request.Context(), req)
 	if err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, http.StatusInternalServerError)
 		return
 	}
+	middleware.SetAuditMachine(c, machine.ID)
 
 	RespondSuccess(c, machine)
 }
@@ -52,7 +55,9 @@ func (h *ConnectionHandler) Create(c *gin.Context) {
 // @Produce json
 // @Param id query string false "Machine ID (optional)"
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
 // @Router /api/v1/connect [get]
 func (h *ConnectionHandler) Get(c *gin.Context) {
 	id := c.Query("id")
@@ -61,7 +66,7 @@ func (h *ConnectionHandler) Get(c *gin.Context) {
 	if id != "" {
 		machine, err := h.usecase.Check(c.Request.Context(), id)
 		if err != nil {
-			RespondError(c, http.StatusServiceUnavailable, err.Error(), machine)
+			RespondFailure(c, err, http.StatusServiceUnavailable, machine)
 			return
 		}
 		RespondSuccess(c, machine)
@@ -71,7 +76,7 @@ func (h *ConnectionHandler) Get(c *gin.Context) {
 	// List all
 	machines, err := h.usecase.List(c.Request.Context())
 	if err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, http.StatusInternalServerError)
 		return
 	}
 	RespondSuccess(c, machines)
@@ -83,6 +88,7 @@ func (h *ConnectionHandler) Get(c *gin.Context) {
 // @Param id query string true "Machine ID"
 // @Produce json
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Router /api/v1/connect [delete]
 func (h *ConnectionHandler) Delete(c *gin.Context) {
@@ -91,9 +97,10 @@ func (h *ConnectionHandler) Delete(c *gin.Context) {
 		RespondError(c, http.StatusBadRequest, "id is required")
 		return
 	}
+	middleware.SetAuditMachine(c, id)
 
 	if err := h.usecase.Delete(c.Request.Context(), id); err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+)
+
+type DataHandler struct {
+	usecase interfaces.DataUsecase
+}
+
+func NewDataHandler(usecase interfaces.DataUsecase) *DataHandler {
+	return &DataHandler{usecase: usecase}
+}
+
+// Read
+// @Summary Read CNC data
+// @Description Reads consecutive parameters, custom macro variables, PMC addresses or diagnostic numbers
+// @Tags Data
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Param class query string true "Data class (parameter, macro, pmc, diagnosis)"
+// @Param number query int true "First number, or byte address for pmc"
+// @Param count query int false "Number of values, default 1, max 100"
+// @Param type query string false "Value type (byte, word, dword, real), required for parameter and diagnosis, default byte for pmc"
+// @Param axis query int false "Axis of a parameter or diagnosis, 0 - none"
+// @Param area query string false "PMC area (G, F, Y, X, A, R, T, K, C, D, E)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.DataReading}
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Router /api/v1/read [get]
+func (h *DataHandler) Read(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	var addr entities.DataAddress
+	if err := c.ShouldBindQuery(&addr); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reading, err := h.usecase.Read(c.Request.Context(), id, addr)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, reading)
+}
+
+// Write
+// @Summary Write macro variables or PMC data
+// @Description Writes consecutive macro variables or PMC values allowed by the write rules of the machine. Refused with 409 while the CNC is in automatic operation (MEM, RMT or a running program) unless override is set. A dry run makes all checks and returns the current values without writing. The audit entry is stored before the write; the write is refused with 503 if it cannot be.
+// @Tags Data
+// @Accept json
+// @Produce json
+// @Param input body models.WriteDataRequest true "Write request"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.WriteResult}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Address or value not allowed by the write rules"
+// @Failure 409 {object} models.APIResponse "Machine is in automatic operation"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/write [post]
+func (h *DataHandler) Write(c *gin.Context) {
+	var req models.WriteDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
+
+	result, err := h.usecase.Write(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// SetWriteRules
+// @Summary Set the write rules of a machine
+// @Description Replaces the allowlist of macro variables and PMC addresses that may be written, with their value ranges. An empty list forbids all writes.
+// @Tags Data
+// @Accept json
+// @Produce json
+// @Param input body models.WriteRulesRequest true "Write rules"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=entities.Machine}
+// @Failure 400 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/write/rules [put]
+func (h *DataHandler) SetWriteRules(c *gin.Context) {
+	var req models.WriteRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
+
+	machine, err := h.usecase.SetWriteRules(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, machine)
+}
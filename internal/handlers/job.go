@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+)
+
+type JobHandler struct {
+	usecase interfaces.JobUsecase
+}
+
+func NewJobHandler(usecase interfaces.JobUsecase) *JobHandler {
+	return &JobHandler{usecase: usecase}
+}
+
+// Create
+// @Summary Start a background job
+// @Description Queues a long operation and answers with its ID at once. program_download reads program number (0 - the executing one) of machine id; connection_check and program_drift cover ids, all machines of the caller if empty. The job needs the scope of the calls it makes: program for program_download and program_drift, read for connection_check.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param input body models.JobRequest true "Job"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 202 {object} models.APIResponse{data=entities.Job}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such machine"
+// @Failure 429 {object} models.APIResponse "Job queue full, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Service is stopping"
+// @Router /api/v1/jobs [post]
+func (h *JobHandler) Create(c *gin.Context) {
+	var req models.JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ID != "" {
+		middleware.SetAuditMachine(c, req.ID)
+	}
+
+	job, err := h.usecase.Create(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{Status: "ok", Data: job})
+}
+
+// Get
+// @Summary Get a job
+// @Description Returns the status, progress (done of total bytes or machines) and result of a job. Finished jobs are kept for JOB_RETENTION.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=entities.Job}
+// @Failure 404 {object} models.APIResponse "No such job"
+// @Router /api/v1/jobs/{id} [get]
+func (h *JobHandler) Get(c *gin.Context) {
+	job, err := h.usecase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, job)
+}
+
+// Output
+// @Summary Get the output of a job
+// @Description Returns the program text read by a succeeded program_download job
+// @Tags Jobs
+// @Produce plain
+// @Param id path string true "Job ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {string} string "Program content"
+// @Failure 404 {object} models.APIResponse "No such job or no output"
+// @Router /api/v1/jobs/{id}/output [get]
+func (h *JobHandler) Output(c *gin.Context) {
+	output, err := h.usecase.Output(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	c.String(http.StatusOK, output)
+}
+
+// Cancel
+// @Summary Cancel a job
+// @Description Cancels a queued or running job. A job run by another instance is cancelled by it within a few seconds; cancel_requested is set until then.
+// @Tags Jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=entities.Job}
+// @Failure 404 {object} models.APIResponse "No such job"
+// @Router /api/v1/jobs/{id}/cancel [post]
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, err := h.usecase.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, job)
+}
+
+// List
+// @Summary List jobs
+// @Description Returns the jobs of the caller, of all callers for admins, newest first
+// @Tags Jobs
+// @Produce json
+// @Param id query string false "Machine ID"
+// @Param status query string false "Status (queued, running, succeeded, failed, cancelled)"
+// @Param limit query int false "Max jobs, default 100, max 1000"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=[]entities.Job}
+// @Failure 400 {object} models.APIResponse
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) List(c *gin.Context) {
+	var filter models.JobFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list, err := h.usecase.List(c.Request.Context(), filter)
+	if err != nil {
+		RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondSuccess(c, list)
+}
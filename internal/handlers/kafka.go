@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+type KafkaHandler struct {
+	usecase interfaces.KafkaUsecase
+}
+
+func NewKafkaHandler(usecase interfaces.KafkaUsecase) *KafkaHandler {
+	return &KafkaHandler{usecase: usecase}
+}
+
+// Buffer
+// @Summary Get Kafka disk buffer state
+// @Description Returns the number and size of messages waiting on disk for delivery to Kafka
+// @Tags Kafka
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.KafkaBufferStats}
+// @Router /api/v1/kafka/buffer [get]
+func (h *KafkaHandler) Buffer(c *gin.Context) {
+	RespondSuccess(c, h.usecase.BufferStats(c.Request.Context()))
+}
+
+// Queues
+// @Summary Get Kafka send queues
+// @Description Returns the per-machine send queues waiting for delivery to Kafka
+// @Tags Kafka
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=[]models.KafkaQueueStats}
+// @Router /api/v1/kafka/queues [get]
+func (h *KafkaHandler) Queues(c *gin.Context) {
+	RespondSuccess(c, h.usecase.QueueStats(c.Request.Context()))
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+)
+
+type OffsetHandler struct {
+	usecase interfaces.OffsetUsecase
+}
+
+func NewOffsetHandler(usecase interfaces.OffsetUsecase) *OffsetHandler {
+	return &OffsetHandler{usecase: usecase}
+}
+
+// ToolOffsets
+// @Summary Read the tool offset table
+// @Description Reads all tool offsets of a machine. The offset types depend on the machine: x/z/radius/tip/y wear and geometry on a lathe, offset (memory A), wear and geometry (B) or radius and length wear and geometry (C) on a mill.
+// @Tags Offsets
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.ToolOffsets}
+// @Failure 400 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/offsets/tool [get]
+func (h *OffsetHandler) ToolOffsets(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	offsets, err := h.usecase.ToolOffsets(c.Request.Context(), id)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, offsets)
+}
+
+// WorkOffsets
+// @Summary Read the work offset table
+// @Description Reads the external work offset (EXT), G54-G59 and the extended work coordinate systems (G54.1 P1...) of a machine by axis.
+// @Tags Offsets
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.WorkOffsets}
+// @Failure 400 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/offsets/work [get]
+func (h *OffsetHandler) WorkOffsets(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	offsets, err := h.usecase.WorkOffsets(c.Request.Context(), id)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, offsets)
+}
+
+// WriteToolOffset
+// @Summary Write a tool offset
+// @Description Sets one tool offset value, or adds to it when incremental is set. The value must be allowed by a tool_offset write rule; refused with 409 while the CNC is in automatic operation unless override is set. Returns the value before the write and the value read back. The audit entry is stored before the write; the write is refused with 503 if it cannot be.
+// @Tags Offsets
+// @Accept json
+// @Produce json
+// @Param input body models.ToolOffsetWriteRequest true "Tool offset"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.OffsetChange}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Offset or value not allowed by the write rules"
+// @Failure 409 {object} models.APIResponse "Machine is in automatic operation"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/offsets/tool [post]
+func (h *OffsetHandler) WriteToolOffset(c *gin.Context) {
+	var req models.ToolOffsetWriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
+
+	change, err := h.usecase.WriteToolOffset(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, change)
+}
+
+// WriteWorkOffset
+// @Summary Write a work offset
+// @Description Sets the value of one axis of a work coordinate system (0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on), or adds to it when incremental is set. The value must be allowed by a work_offset write rule; refused with 409 while the CNC is in automatic operation unless override is set. Returns the value before the write and the value read back. The audit entry is stored before the write; the write is refused with 503 if it cannot be.
+// @Tags Offsets
+// @Accept json
+// @Produce json
+// @Param input body models.WorkOffsetWriteRequest true "Work offset"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.OffsetChange}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse "Offset or value not allowed by the write rules"
+// @Failure 409 {object} models.APIResponse "Machine is in automatic operation"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/offsets/work [post]
+func (h *OffsetHandler) WriteWorkOffset(c *gin.Context) {
+	var req models.WorkOffsetWriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
+
+	change, err := h.usecase.WriteWorkOffset(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	RespondSuccess(c, change)
+}
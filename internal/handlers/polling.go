@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/iwtcode/fanucService/internal/domain/models"
 	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
 )
 
 type PollingHandler struct {
@@ -24,9 +25,11 @@ func NewPollingHandler(usecase interfaces.PollingUsecase) *PollingHandler {
 // @Produce json
 // @Param input body models.StartPollingRequest true "Polling Config"
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
 // @Router /api/v1/polling/start [post]
 func (h *PollingHandler) Start(c *gin.Context) {
 	var req models.StartPollingRequest
@@ -34,9 +37,10 @@ func (h *PollingHandler) Start(c *gin.Context) {
 		RespondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	middleware.SetAuditMachine(c, req.ID)
 
 	if err := h.usecase.Start(c.Request.Context(), req); err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -51,6 +55,7 @@ func (h *PollingHandler) Start(c *gin.Context) {
 // @Produce json
 // @Param input body models.StopPollingRequest true "Polling Config"
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {object} models.APIResponse
 // @Failure 400 {object} models.APIResponse
 // @Failure 500 {object} models.APIResponse
@@ -61,9 +66,10 @@ func (h *PollingHandler) Stop(c *gin.Context) {
 		RespondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	middleware.SetAuditMachine(c, req.ID)
 
 	if err := h.usecase.Stop(c.Request.Context(), req); err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, http.StatusInternalServerError)
 		return
 	}
 
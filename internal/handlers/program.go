@@ -1,30 +1,52 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
 	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
 )
 
 type ProgramHandler struct {
 	usecase interfaces.ProgramUsecase
+	maxBody int64 // largest request body with a program text, 0 for no limit
 }
 
-func NewProgramHandler(usecase interfaces.ProgramUsecase) *ProgramHandler {
-	return &ProgramHandler{usecase: usecase}
+func NewProgramHandler(usecase interfaces.ProgramUsecase, cfg *fanucService.Config) *ProgramHandler {
+	h := &ProgramHandler{usecase: usecase}
+	// Leave room for the JSON escapes of line breaks and the other fields.
+	if limit := cfg.Programs.MaxSize; limit > 0 {
+		h.maxBody = 2*limit + 64<<10
+	}
+	return h
 }
 
 // Get
 // @Summary Get full control program
-// @Description Returns the raw text of the current executing program (G-Code)
+// @Description Streams the raw text of the current executing program (G-Code) while the CNC uploads it. Content-Length, ETag and byte ranges are available once the unchanged program was downloaded in full before; a program over PROGRAM_MAX_SIZE is refused with 413.
 // @Tags Program
 // @Produce plain
 // @Param id query string true "Machine ID"
+// @Param Range header string false "Byte range, e.g. bytes=1024-"
+// @Param If-None-Match header string false "ETag of a copy the caller has"
 // @Security ApiKeyAuth
+// @Security BearerAuth
 // @Success 200 {string} string "Program content"
+// @Success 206 {string} string "Requested byte range"
+// @Success 304 "Program unchanged"
 // @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No program executing"
+// @Failure 413 {object} models.APIResponse "Program exceeds PROGRAM_MAX_SIZE"
+// @Failure 416 "Range outside the program"
 // @Failure 500 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
 // @Router /api/v1/program [get]
 func (h *ProgramHandler) Get(c *gin.Context) {
 	id := c.Query("id")
@@ -32,12 +54,439 @@ func (h *ProgramHandler) Get(c *gin.Context) {
 		RespondError(c, http.StatusBadRequest, "id is required")
 		return
 	}
+	middleware.SetAuditMachine(c, id)
+
+	h.stream(c, id, 0)
+}
+
+// List
+// @Summary List programs in CNC memory
+// @Description Returns the program directory (number, size, comment, modification date) with the numbers of the running and the main program
+// @Tags Program
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.ProgramDirectory}
+// @Failure 400 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs [get]
+func (h *ProgramHandler) List(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	dir, err := h.usecase.List(c.Request.Context(), id)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, dir)
+}
+
+// Download
+// @Summary Download a program by number
+// @Description Streams the raw text of a program in CNC memory while the CNC uploads it. Content-Length, ETag and byte ranges are available once the unchanged program was downloaded in full before; a program over PROGRAM_MAX_SIZE is refused with 413.
+// @Tags Program
+// @Produce plain
+// @Param id query string true "Machine ID"
+// @Param number query int true "Program number (O number)"
+// @Param Range header string false "Byte range, e.g. bytes=1024-"
+// @Param If-None-Match header string false "ETag of a copy the caller has"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {string} string "Program content"
+// @Success 206 {string} string "Requested byte range"
+// @Success 304 "Program unchanged"
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such program"
+// @Failure 413 {object} models.APIResponse "Program exceeds PROGRAM_MAX_SIZE"
+// @Failure 416 "Range outside the program"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs/download [get]
+func (h *ProgramHandler) Download(c *gin.Context) {
+	id := c.Query("id")
+	number, err := strconv.Atoi(c.Query("number"))
+	if id == "" || err != nil {
+		RespondError(c, http.StatusBadRequest, "id and number are required")
+		return
+	}
+	if number < 1 {
+		RespondError(c, http.StatusBadRequest, "number must be a program number")
+		return
+	}
+	middleware.SetAuditMachine(c, id)
+
+	h.stream(c, id, number)
+}
+
+// stream answers with the text of program number, 0 for the executing
+// program. The headers are decided before the CNC uploads anything; a
+// failure after the first byte can only cut the connection.
+func (h *ProgramHandler) stream(c *gin.Context, id string, number int) {
+	ctx := c.Request.Context()
+	stat, err := h.usecase.Stat(ctx, id, number)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Accept-Ranges", "none")
+	if stat.ETag != "" {
+		if etagMatch(c.GetHeader("If-None-Match"), stat.ETag) {
+			c.Header("ETag", stat.ETag)
+			c.Status(http.StatusNotModified)
+			return
+		}
+		header.Set("ETag", stat.ETag)
+	}
+
+	status, offset, length := http.StatusOK, int64(0), stat.Length
+	if stat.Length >= 0 {
+		header.Set("Accept-Ranges", "bytes")
+		ifRange := c.GetHeader("If-Range")
+		if r := c.GetHeader("Range"); r != "" && (ifRange == "" || ifRange == stat.ETag) {
+			var ok bool
+			if offset, length, ok = parseRange(r, stat.Length); !ok {
+				c.Header("Content-Range", fmt.Sprintf("bytes */%d", stat.Length))
+				c.Status(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			status = http.StatusPartialContent
+			header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, stat.Length))
+		}
+		header.Set("Content-Length", strconv.FormatInt(length, 10))
+	}
+
+	w := &programWriter{c: c, status: status, header: header}
+	if err := h.usecase.Stream(ctx, id, stat.Number, w, offset, length); err != nil {
+		if !w.started {
+			RespondFailure(c, err, programStatus(err))
+			return
+		}
+		abortStream(c, err)
+		return
+	}
+	if !w.started {
+		w.start()
+	}
+}
+
+// programWriter writes the response headers with the first byte of the
+// program, so a failure before it can still be answered with an error.
+type programWriter struct {
+	c       *gin.Context
+	status  int
+	header  http.Header
+	started bool
+}
+
+func (w *programWriter) start() {
+	for key, values := range w.header {
+		w.c.Writer.Header()[key] = values
+	}
+	w.c.Status(w.status)
+	w.c.Writer.WriteHeaderNow()
+	w.started = true
+}
+
+func (w *programWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.start()
+	}
+	return w.c.Writer.Write(b)
+}
+
+// abortStream cuts the connection of a response whose body is incomplete, so
+// the client sees a failed transfer instead of a short program.
+func abortStream(c *gin.Context, err error) {
+	_ = c.Error(err)
+	conn, _, hijackErr := c.Writer.Hijack()
+	if hijackErr != nil {
+		return
+	}
+	conn.Close()
+}
+
+// parseRange parses a single byte range "bytes=a-b", "bytes=a-" or
+// "bytes=-n" of a text of size bytes into an offset and a length.
+func parseRange(header string, size int64) (offset, length int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		n = min(n, size)
+		return size - n, n, true
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end - start + 1, true
+}
+
+// etagMatch reports whether an If-None-Match header lists etag.
+func etagMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Upload
+// @Summary Upload a program
+// @Description Stores an NC program in CNC memory under the O number it starts with. An existing program is replaced only with overwrite, and not while it is the main or running program of a machine in automatic operation; if the CNC refuses the new text, the old program is written back. The audit entry is stored before the upload; the upload is refused with 503 if it cannot be.
+// @Tags Program
+// @Accept json
+// @Produce json
+// @Param input body models.ProgramUploadRequest true "Program"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.ProgramInfo}
+// @Failure 400 {object} models.APIResponse
+// @Failure 409 {object} models.APIResponse "Program exists or is in use"
+// @Failure 413 {object} models.APIResponse "Request body exceeds twice PROGRAM_MAX_SIZE plus 64 KiB"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/programs [post]
+func (h *ProgramHandler) Upload(c *gin.Context) {
+	var req models.ProgramUploadRequest
+	if !h.bindProgram(c, &req) {
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
 
-	program, err := h.usecase.GetProgram(c.Request.Context(), id)
+	info, err := h.usecase.Upload(c.Request.Context(), req)
 	if err != nil {
-		RespondError(c, http.StatusInternalServerError, err.Error())
+		RespondFailure(c, err, programStatus(err))
 		return
 	}
 
-	c.String(http.StatusOK, program)
+	RespondSuccess(c, info)
+}
+
+// Delete
+// @Summary Delete a program
+// @Description Deletes a program from CNC memory. The main or running program is not deleted while the machine is in automatic operation. The audit entry is stored before the deletion; it is refused with 503 if it cannot be.
+// @Tags Program
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Param number query int true "Program number (O number)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such program"
+// @Failure 409 {object} models.APIResponse "Program is in use"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/programs [delete]
+func (h *ProgramHandler) Delete(c *gin.Context) {
+	id := c.Query("id")
+	number, err := strconv.Atoi(c.Query("number"))
+	if id == "" || err != nil {
+		RespondError(c, http.StatusBadRequest, "id and number are required")
+		return
+	}
+	middleware.SetAuditMachine(c, id)
+
+	if err := h.usecase.Delete(c.Request.Context(), id, number); err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondMessage(c, "Program deleted")
+}
+
+// Select
+// @Summary Select the main program
+// @Description Makes a program in CNC memory the main program for automatic operation. Refused with 409 while the machine is in automatic operation.
+// @Tags Program
+// @Accept json
+// @Produce json
+// @Param input body models.ProgramSelectRequest true "Program"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such program"
+// @Failure 409 {object} models.APIResponse "Machine is in automatic operation"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Failure 503 {object} models.APIResponse "Audit entry could not be stored"
+// @Router /api/v1/programs/select [post]
+func (h *ProgramHandler) Select(c *gin.Context) {
+	var req models.ProgramSelectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	middleware.SetAuditMachine(c, req.ID)
+
+	if err := h.usecase.Select(c.Request.Context(), req); err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondMessage(c, "Main program selected")
+}
+
+// Analysis
+// @Summary Analyze a program on a machine
+// @Description Downloads a program (without number the executing one) and returns its tools, work offsets, subprogram calls, feed and speed ranges, estimated path length and syntax warnings
+// @Tags Program
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Param number query int false "Program number (O number), default the executing program"
+// @Param blocks query bool false "Include the parsed blocks"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=gcode.Analysis}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such program"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs/analysis [get]
+func (h *ProgramHandler) Analysis(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+	var number int
+	if s := c.Query("number"); s != "" {
+		var err error
+		if number, err = strconv.Atoi(s); err != nil {
+			RespondError(c, http.StatusBadRequest, "number must be a program number")
+			return
+		}
+	}
+	middleware.SetAuditMachine(c, id)
+
+	analysis, err := h.usecase.Analyze(c.Request.Context(), id, number, c.Query("blocks") == "true")
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, analysis)
+}
+
+// Position
+// @Summary Locate the executing block in the program
+// @Description Returns the line of the program text the machine executes, found by the sequence number and the block text the CNC reports, with the lines around it. While polling with program tracking the last poll answers.
+// @Tags Program
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Param lines query int false "Lines of context before and after, default PROGRAM_CONTEXT_LINES, max 100"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.ProgramPosition}
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No program executing"
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs/position [get]
+func (h *ProgramHandler) Position(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+	lines := -1
+	if s := c.Query("lines"); s != "" {
+		var err error
+		if lines, err = strconv.Atoi(s); err != nil || lines < 0 {
+			RespondError(c, http.StatusBadRequest, "lines must be a non-negative number")
+			return
+		}
+	}
+
+	position, err := h.usecase.Position(c.Request.Context(), id, lines)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, position)
+}
+
+// Analyze
+// @Summary Analyze a program text
+// @Description Analyzes a program that is not on a machine, e.g. a CAM output before upload
+// @Tags Program
+// @Accept json
+// @Produce json
+// @Param input body models.ProgramAnalyzeRequest true "Program"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=gcode.Analysis}
+// @Failure 400 {object} models.APIResponse
+// @Failure 413 {object} models.APIResponse "Request body exceeds twice PROGRAM_MAX_SIZE plus 64 KiB"
+// @Router /api/v1/programs/analyze [post]
+func (h *ProgramHandler) Analyze(c *gin.Context) {
+	var req models.ProgramAnalyzeRequest
+	if !h.bindProgram(c, &req) {
+		return
+	}
+
+	RespondSuccess(c, h.usecase.AnalyzeText(c.Request.Context(), req))
+}
+
+// bindProgram binds a request with a program text, reading at most maxBody
+// bytes of it. A larger body is refused with 413 before it is read in full.
+func (h *ProgramHandler) bindProgram(c *gin.Context, req interface{}) bool {
+	if h.maxBody > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBody)
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			RespondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return false
+		}
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// programStatus maps the program errors StatusFor leaves to the fallback.
+func programStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrAlreadyExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
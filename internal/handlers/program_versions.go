@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+)
+
+type ProgramVersionHandler struct {
+	usecase interfaces.ProgramVersionUsecase
+}
+
+func NewProgramVersionHandler(usecase interfaces.ProgramVersionUsecase) *ProgramVersionHandler {
+	return &ProgramVersionHandler{usecase: usecase}
+}
+
+// List
+// @Summary List recorded program versions
+// @Description Returns the versions of the programs of a machine recorded on download, upload and polling snapshots, newest first, without their text
+// @Tags Program versions
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Param number query int false "Program number (O number)"
+// @Param limit query int false "Max versions, default 100, max 1000"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=[]entities.ProgramVersion}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs/versions [get]
+func (h *ProgramVersionHandler) List(c *gin.Context) {
+	var filter models.ProgramVersionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	versions, err := h.usecase.List(c.Request.Context(), filter)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, versions)
+}
+
+// Content
+// @Summary Get the text of a program version
+// @Tags Program versions
+// @Produce plain
+// @Param version query int true "Version ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {string} string "Program content"
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such version"
+// @Router /api/v1/programs/versions/content [get]
+func (h *ProgramVersionHandler) Content(c *gin.Context) {
+	version, err := strconv.ParseUint(c.Query("version"), 10, 0)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "version is required")
+		return
+	}
+
+	v, err := h.usecase.Get(c.Request.Context(), uint(version))
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	c.String(http.StatusOK, "%\n"+v.Content+"\n%")
+}
+
+// Diff
+// @Summary Diff two program versions
+// @Description Returns the unified diff from one version to another, empty if their texts are equal. Without to the latest version of the same program is taken.
+// @Tags Program versions
+// @Produce plain
+// @Param from query int true "Version ID"
+// @Param to query int false "Version ID, default the latest version of the program"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {string} string "Unified diff"
+// @Failure 400 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such version"
+// @Router /api/v1/programs/versions/diff [get]
+func (h *ProgramVersionHandler) Diff(c *gin.Context) {
+	from, err := strconv.ParseUint(c.Query("from"), 10, 0)
+	if err != nil {
+		RespondError(c, http.StatusBadRequest, "from is required")
+		return
+	}
+	var to uint64
+	if s := c.Query("to"); s != "" {
+		if to, err = strconv.ParseUint(s, 10, 0); err != nil {
+			RespondError(c, http.StatusBadRequest, "to must be a version ID")
+			return
+		}
+	}
+
+	text, err := h.usecase.Diff(c.Request.Context(), uint(from), uint(to))
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	c.String(http.StatusOK, text)
+}
+
+// Approve
+// @Summary Approve a program version
+// @Description Makes a version the approved version of its program on its machine; the previously approved one loses the mark
+// @Tags Program versions
+// @Accept json
+// @Produce json
+// @Param input body models.ProgramApproveRequest true "Version"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=entities.ProgramVersion}
+// @Failure 400 {object} models.APIResponse
+// @Failure 403 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "No such version"
+// @Router /api/v1/programs/versions/approve [post]
+func (h *ProgramVersionHandler) Approve(c *gin.Context) {
+	var req models.ProgramApproveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	v, err := h.usecase.Approve(c.Request.Context(), req)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+	middleware.SetAuditMachine(c, v.MachineID)
+
+	RespondSuccess(c, v)
+}
+
+// Drift
+// @Summary Compare the executing program with the approved version
+// @Description Reads the program the machine executes, records it as a version and reports whether it differs from the approved version of its number, with the diff
+// @Tags Program versions
+// @Produce json
+// @Param id query string true "Machine ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} models.APIResponse{data=models.ProgramDrift}
+// @Failure 400 {object} models.APIResponse
+// @Failure 429 {object} models.APIResponse "Machine busy or rate limit exceeded, see Retry-After"
+// @Failure 500 {object} models.APIResponse
+// @Router /api/v1/programs/drift [get]
+func (h *ProgramVersionHandler) Drift(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		RespondError(c, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	drift, err := h.usecase.Drift(c.Request.Context(), id)
+	if err != nil {
+		RespondFailure(c, err, programStatus(err))
+		return
+	}
+
+	RespondSuccess(c, drift)
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/middleware"
 )
 
 func RespondSuccess(c *gin.Context, data interface{}) {
@@ -32,3 +35,31 @@ func RespondError(c *gin.Context, code int, message string, data ...interface{})
 		Data:    d,
 	})
 }
+
+// RespondFailure responds with the status of a usecase error (see StatusFor)
+// and sets Retry-After for rate limited requests.
+func RespondFailure(c *gin.Context, err error, code int, data ...interface{}) {
+	middleware.SetRetryAfter(c, err)
+	RespondError(c, StatusFor(err, code), err.Error(), data...)
+}
+
+// StatusFor maps usecase errors to HTTP status codes, falling back to code.
+func StatusFor(err error, code int) int {
+	switch {
+	case errors.Is(err, models.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, models.ErrNotOwner), errors.Is(err, models.ErrAuditFailed), errors.Is(err, models.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, models.ErrInterlocked):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrBadRequest):
+		return http.StatusBadRequest
+	}
+	return code
+}
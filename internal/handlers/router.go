@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/iwtcode/fanucService"
 	_ "github.com/iwtcode/fanucService/docs"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/interfaces"
 	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -14,6 +22,19 @@ func NewRouter(
 	connHandler *ConnectionHandler,
 	pollHandler *PollingHandler,
 	progHandler *ProgramHandler,
+	versionHandler *ProgramVersionHandler,
+	dataHandler *DataHandler,
+	offsetHandler *OffsetHandler,
+	kafkaHandler *KafkaHandler,
+	keyHandler *APIKeyHandler,
+	auditHandler *AuditHandler,
+	jobHandler *JobHandler,
+	auth interfaces.AuthUsecase,
+	audit interfaces.AuditUsecase,
+	limiter *ratelimit.Limiter,
+	membership *cluster.Membership,
+	peers *auth.ClusterPeers,
+	logger *logrus.Logger,
 ) *gin.Engine {
 	gin.SetMode(cfg.App.GinMode)
 	r := gin.Default()
@@ -21,24 +42,98 @@ func NewRouter(
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Program transfers are bounded by PROGRAM_TRANSFER_TIMEOUT, not REQUEST_TIMEOUT.
+	transfers := map[string]time.Duration{
+		"GET /api/v1/program":           cfg.Programs.TransferTimeout,
+		"GET /api/v1/programs/download": cfg.Programs.TransferTimeout,
+		"POST /api/v1/programs":         cfg.Programs.TransferTimeout,
+	}
+
 	// API Group
 	v1 := r.Group("/api/v1")
-	v1.Use(middleware.Auth(cfg))
+	v1.Use(middleware.Timeout(cfg.App.RequestTimeout, transfers), middleware.Auth(auth), middleware.RateLimit(limiter, peers))
 	{
+		read := middleware.RequireScope(entities.ScopeRead)
+		control := middleware.RequireScope(entities.ScopeControl)
+		write := middleware.RequireScope(entities.ScopeWrite)
+		admin := middleware.RequireScope(entities.ScopeAdmin)
+		audited := func(action string) gin.HandlerFunc { return middleware.Audit(audit, action) }
+		// owned forwards calls for a machine served by another instance.
+		owned := middleware.Forward(membership, cfg.Cluster.Forward, peers, logger)
+
 		connect := v1.Group("/connect")
 		{
-			connect.POST("", connHandler.Create)
-			connect.GET("", connHandler.Get)
-			connect.DELETE("", connHandler.Delete)
+			connect.POST("", control, audited(entities.AuditConnect), connHandler.Create)
+			connect.GET("", read, owned, connHandler.Get)
+			connect.DELETE("", control, owned, audited(entities.AuditDelete), connHandler.Delete)
+		}
+
+		polling := v1.Group("/polling", control, owned)
+		{
+			polling.POST("/start", audited(entities.AuditPollingStart), pollHandler.Start)
+			polling.POST("/stop", audited(entities.AuditPollingStop), pollHandler.Stop)
+		}
+
+		v1.GET("/read", read, owned, dataHandler.Read)
+		v1.POST("/write", write, owned, middleware.RequiredAudit(audit, entities.AuditDataWrite), dataHandler.Write)
+		v1.PUT("/write/rules", admin, owned, audited(entities.AuditWriteRules), dataHandler.SetWriteRules)
+
+		offsets := v1.Group("/offsets")
+		{
+			offsets.GET("/tool", read, owned, offsetHandler.ToolOffsets)
+			offsets.GET("/work", read, owned, offsetHandler.WorkOffsets)
+			offsets.POST("/tool", write, owned, middleware.RequiredAudit(audit, entities.AuditToolOffset), offsetHandler.WriteToolOffset)
+			offsets.POST("/work", write, owned, middleware.RequiredAudit(audit, entities.AuditWorkOffset), offsetHandler.WriteWorkOffset)
 		}
 
-		polling := v1.Group("/polling")
+		program := middleware.RequireScope(entities.ScopeProgram)
+		programWrite := middleware.RequireScope(entities.ScopeProgramWrite)
+		v1.GET("/program", program, owned, audited(entities.AuditProgramRead), progHandler.Get)
+
+		programs := v1.Group("/programs")
+		{
+			programs.GET("", program, owned, progHandler.List)
+			programs.GET("/download", program, owned, audited(entities.AuditProgramRead), progHandler.Download)
+			programs.POST("", programWrite, owned, middleware.RequiredAudit(audit, entities.AuditProgramUpload), progHandler.Upload)
+			programs.DELETE("", programWrite, owned, middleware.RequiredAudit(audit, entities.AuditProgramDelete), progHandler.Delete)
+			programs.POST("/select", programWrite, owned, middleware.RequiredAudit(audit, entities.AuditProgramSelect), progHandler.Select)
+			programs.GET("/drift", program, owned, versionHandler.Drift)
+			programs.GET("/position", program, owned, progHandler.Position)
+			programs.GET("/analysis", program, owned, audited(entities.AuditProgramRead), progHandler.Analysis)
+			programs.POST("/analyze", program, progHandler.Analyze)
+
+			// Versions are kept in the database, any instance serves them.
+			programs.GET("/versions", program, versionHandler.List)
+			programs.GET("/versions/content", program, versionHandler.Content)
+			programs.GET("/versions/diff", program, versionHandler.Diff)
+			programs.POST("/versions/approve", programWrite, audited(entities.AuditProgramApprove), versionHandler.Approve)
+		}
+
+		kafka := v1.Group("/kafka", read)
 		{
-			polling.POST("/start", pollHandler.Start)
-			polling.POST("/stop", pollHandler.Stop)
+			kafka.GET("/buffer", kafkaHandler.Buffer)
+			kafka.GET("/queues", kafkaHandler.Queues)
 		}
 
-		v1.GET("/program", progHandler.Get)
+		keys := v1.Group("/keys", admin)
+		{
+			keys.POST("", audited(entities.AuditKeyCreate), keyHandler.Create)
+			keys.GET("", keyHandler.List)
+			keys.POST("/rotate", audited(entities.AuditKeyRotate), keyHandler.Rotate)
+			keys.DELETE("", audited(entities.AuditKeyRevoke), keyHandler.Revoke)
+		}
+
+		v1.GET("/audit", admin, auditHandler.List)
+
+		// Jobs check the scope of the calls they make themselves.
+		jobs := v1.Group("/jobs")
+		{
+			jobs.POST("", owned, audited(entities.AuditJobCreate), jobHandler.Create)
+			jobs.GET("", jobHandler.List)
+			jobs.GET("/:id", jobHandler.Get)
+			jobs.GET("/:id/output", jobHandler.Output)
+			jobs.POST("/:id/cancel", audited(entities.AuditJobCancel), jobHandler.Cancel)
+		}
 	}
 
 	return r
@@ -1,6 +1,11 @@
 package interfaces
 
-import "github.com/iwtcode/fanucService/internal/domain/entities"
+import (
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
 
 type Repository interface {
 	Create(machine *entities.Machine) error
@@ -10,3 +15,51 @@ type Repository interface {
 	GetByEndpoint(endpoint string) (*entities.Machine, error)
 	GetAll() ([]entities.Machine, error)
 }
+
+type APIKeyRepository interface {
+	Create(key *entities.APIKey) error
+	Update(key *entities.APIKey) error
+	GetByID(id string) (*entities.APIKey, error)
+	GetByHash(hash string) (*entities.APIKey, error)
+	GetByName(name string) (*entities.APIKey, error) // active keys only
+	GetAll() ([]entities.APIKey, error)
+	CountActive() (int64, error)
+}
+
+type AuditRepository interface {
+	Create(entry *entities.AuditEntry) error
+	Update(entry *entities.AuditEntry) error
+	Find(filter models.AuditFilter) ([]entities.AuditEntry, error)
+}
+
+type ProgramVersionRepository interface {
+	Record(version *entities.ProgramVersion) (previous *entities.ProgramVersion, err error) // stores version unless previous has its hash
+	GetByID(id uint) (*entities.ProgramVersion, error)
+	Latest(machineID string, number int) (*entities.ProgramVersion, error)
+	Approved(machineID string, number int) (*entities.ProgramVersion, error)
+	Approve(version *entities.ProgramVersion) error
+	Find(filter models.ProgramVersionFilter) ([]entities.ProgramVersion, error) // without content
+}
+
+type JobRepository interface {
+	Create(job *entities.Job) error
+	Update(job *entities.Job) error
+	GetByID(id string) (*entities.Job, error) // without output
+	Output(id string) (string, error)
+	Find(filter models.JobFilter) ([]entities.Job, error) // without output
+	RequestCancel(id string) error
+	CancelRequested(instance string) ([]string, error)
+	FailActive(instance, reason string, at time.Time) (int64, error)
+	DeleteFinished(before time.Time) (int64, error)
+}
+
+type ClusterRepository interface {
+	Heartbeat(instance *entities.Instance) error
+	LiveInstances(ttl time.Duration) ([]entities.Instance, error)
+	RemoveInstance(id string) error
+
+	AcquireLease(machineID, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(machineID, owner string) error
+	ReleaseAll(owner string) error
+	Leases() ([]entities.MachineLease, error)
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"io"
 
 	"github.com/iwtcode/fanucService/internal/domain/entities"
 	"github.com/iwtcode/fanucService/internal/domain/models"
@@ -13,9 +14,35 @@ type FanucService interface {
 	DeleteConnection(ctx context.Context, id string) error
 	CheckConnection(ctx context.Context, id string) (*entities.Machine, error)
 	RestoreConnections() error
+	StopAllPolling(ctx context.Context) error
+	CloseConnections(ctx context.Context) error
+	CloseWatchers()
 
-	StartPolling(ctx context.Context, machineID string, intervalMs int) error
+	// Cluster mode: take over, reconcile and hand over machines.
+	AdoptMachine(ctx context.Context, machine entities.Machine)
+	SyncMachine(ctx context.Context, machineID string)
+	ReleaseMachine(ctx context.Context, machineID string) error
+
+	StartPolling(ctx context.Context, req models.StartPollingRequest) error
 	StopPolling(ctx context.Context, machineID string) error
+	WatchMachineData(ctx context.Context, machineID string) (<-chan *models.MachineDataEnvelope, error)
 
 	GetControlProgram(ctx context.Context, id string) (string, error)
+	ListPrograms(ctx context.Context, machineID string) (*models.ProgramDirectory, error)
+	ReadProgram(ctx context.Context, machineID string, number int) (string, error)
+	StatProgram(ctx context.Context, machineID string, number int) (*models.ProgramStat, error)
+	StreamProgram(ctx context.Context, machineID string, number int, w io.Writer, offset, length int64) error
+	UploadProgram(ctx context.Context, req models.ProgramUploadRequest) (*models.ProgramInfo, error)
+	DeleteProgram(ctx context.Context, machineID string, number int) error
+	SelectProgram(ctx context.Context, machineID string, number int) error
+	SnapshotProgram(ctx context.Context, machineID string) (*entities.ProgramVersion, error)
+	ProgramPosition(ctx context.Context, machineID string, lines int) (*models.ProgramPosition, error)
+	ReadData(ctx context.Context, machineID string, addr entities.DataAddress) (*models.DataReading, error)
+	WriteData(ctx context.Context, req models.WriteDataRequest) (*models.WriteResult, error)
+	SetWriteRules(ctx context.Context, machineID string, rules []entities.WriteRule) (*entities.Machine, error)
+
+	ReadToolOffsets(ctx context.Context, machineID string) (*models.ToolOffsets, error)
+	ReadWorkOffsets(ctx context.Context, machineID string) (*models.WorkOffsets, error)
+	WriteToolOffset(ctx context.Context, req models.ToolOffsetWriteRequest) (*models.OffsetChange, error)
+	WriteWorkOffset(ctx context.Context, req models.WorkOffsetWriteRequest) (*models.OffsetChange, error)
 }
@@ -2,9 +2,11 @@ package interfaces
 
 import (
 	"context"
+	"io"
 
 	"github.com/iwtcode/fanucService/internal/domain/entities"
 	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/gcode"
 )
 
 type ConnectionUsecase interface {
@@ -21,8 +23,73 @@ type RestoreUsecase interface {
 type PollingUsecase interface {
 	Start(ctx context.Context, req models.StartPollingRequest) error
 	Stop(ctx context.Context, req models.StopPollingRequest) error
+	Watch(ctx context.Context, id string) (<-chan *models.MachineDataEnvelope, error)
 }
 
 type ProgramUsecase interface {
 	GetProgram(ctx context.Context, id string) (string, error)
+	List(ctx context.Context, id string) (*models.ProgramDirectory, error)
+	Download(ctx context.Context, id string, number int) (string, error)
+	Stat(ctx context.Context, id string, number int) (*models.ProgramStat, error)
+	Stream(ctx context.Context, id string, number int, w io.Writer, offset, length int64) error
+	Upload(ctx context.Context, req models.ProgramUploadRequest) (*models.ProgramInfo, error)
+	Delete(ctx context.Context, id string, number int) error
+	Select(ctx context.Context, req models.ProgramSelectRequest) error
+	Analyze(ctx context.Context, id string, number int, blocks bool) (*gcode.Analysis, error)
+	AnalyzeText(ctx context.Context, req models.ProgramAnalyzeRequest) *gcode.Analysis
+	Position(ctx context.Context, id string, lines int) (*models.ProgramPosition, error)
+}
+
+type JobUsecase interface {
+	Create(ctx context.Context, req models.JobRequest) (*entities.Job, error)
+	Get(ctx context.Context, id string) (*entities.Job, error)
+	Output(ctx context.Context, id string) (string, error)
+	Cancel(ctx context.Context, id string) (*entities.Job, error)
+	List(ctx context.Context, filter models.JobFilter) ([]entities.Job, error)
+}
+
+type ProgramVersionUsecase interface {
+	List(ctx context.Context, filter models.ProgramVersionFilter) ([]entities.ProgramVersion, error)
+	Get(ctx context.Context, version uint) (*entities.ProgramVersion, error)
+	Diff(ctx context.Context, from, to uint) (string, error)
+	Approve(ctx context.Context, req models.ProgramApproveRequest) (*entities.ProgramVersion, error)
+	Drift(ctx context.Context, id string) (*models.ProgramDrift, error)
+}
+
+type DataUsecase interface {
+	Read(ctx context.Context, id string, addr entities.DataAddress) (*models.DataReading, error)
+	Write(ctx context.Context, req models.WriteDataRequest) (*models.WriteResult, error)
+	SetWriteRules(ctx context.Context, req models.WriteRulesRequest) (*entities.Machine, error)
+}
+
+type OffsetUsecase interface {
+	ToolOffsets(ctx context.Context, id string) (*models.ToolOffsets, error)
+	WorkOffsets(ctx context.Context, id string) (*models.WorkOffsets, error)
+	WriteToolOffset(ctx context.Context, req models.ToolOffsetWriteRequest) (*models.OffsetChange, error)
+	WriteWorkOffset(ctx context.Context, req models.WorkOffsetWriteRequest) (*models.OffsetChange, error)
+}
+
+type KafkaUsecase interface {
+	BufferStats(ctx context.Context) models.KafkaBufferStats
+	QueueStats(ctx context.Context) []models.KafkaQueueStats
+}
+
+type APIKeyUsecase interface {
+	Bootstrap(ctx context.Context) error
+	Authenticate(ctx context.Context, key string) (*models.Principal, error)
+	Create(ctx context.Context, req models.APIKeyRequest) (*models.APIKeyCreated, error)
+	List(ctx context.Context) ([]entities.APIKey, error)
+	Rotate(ctx context.Context, id string) (*models.APIKeyCreated, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+type AuthUsecase interface {
+	Authenticate(ctx context.Context, creds models.Credentials) (*models.Principal, error)
+}
+
+type AuditUsecase interface {
+	Record(ctx context.Context, entry *entities.AuditEntry)
+	Begin(ctx context.Context, entry *entities.AuditEntry) error
+	Finish(ctx context.Context, entry *entities.AuditEntry)
+	List(ctx context.Context, filter models.AuditFilter) ([]entities.AuditEntry, error)
 }
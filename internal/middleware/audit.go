@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+const (
+	auditMachineKey  = "audit.machine_id"
+	maxAuditResponse = 4096
+)
+
+// SetAuditMachine attaches the machine the request operates on to its audit
+// entry. Handlers call it once they know the ID (after binding or creation).
+func SetAuditMachine(c *gin.Context, machineID string) {
+	c.Set(auditMachineKey, machineID)
+}
+
+// Audit records the request as an audit entry with the given action after the
+// handler has run. It must run after Auth so the caller is known.
+func Audit(audit interfaces.AuditUsecase, action string) gin.HandlerFunc {
+	return auditRequest(audit, action, false)
+}
+
+// RequiredAudit is Audit for operations that must not run unaudited: the
+// entry is stored before the handler runs and the request is refused with 503
+// if that fails.
+func RequiredAudit(audit interfaces.AuditUsecase, action string) gin.HandlerFunc {
+	return auditRequest(audit, action, true)
+}
+
+func auditRequest(audit interfaces.AuditUsecase, action string, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entry := &entities.AuditEntry{
+			Timestamp: time.Now(),
+			ClientIP:  c.ClientIP(),
+			Transport: "http",
+			Action:    action,
+			Payload:   requestSummary(c),
+		}
+		if required {
+			if err := audit.Begin(c.Request.Context(), entry); err != nil {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"status": "error", "message": err.Error()})
+				return
+			}
+		}
+
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		entry.MachineID = c.GetString(auditMachineKey)
+		entry.Status = writer.Status()
+		entry.Result = entities.AuditResultOK
+		if entry.Status >= http.StatusBadRequest {
+			entry.Result = entities.AuditResultError
+			entry.Error = responseMessage(writer.body.Bytes(), entry.Status)
+		}
+
+		if required {
+			audit.Finish(c.Request.Context(), entry)
+		} else {
+			audit.Record(c.Request.Context(), entry)
+		}
+	}
+}
+
+// requestSummary returns the request body, or the query without the API key
+// for bodiless requests, truncated to entities.MaxAuditPayload. Only the
+// beginning of the body is read; the handler gets it back in front of the rest.
+func requestSummary(c *gin.Context) string {
+	var summary string
+	if body := c.Request.Body; body != nil {
+		head, err := io.ReadAll(io.LimitReader(body, entities.MaxAuditPayload+1))
+		c.Request.Body = prefixedBody{Reader: io.MultiReader(bytes.NewReader(head), body), Closer: body}
+		if err == nil {
+			summary = string(head)
+		}
+	}
+	if summary == "" {
+		query := c.Request.URL.Query()
+		query.Del("api_key")
+		summary = query.Encode()
+	}
+
+	return entities.TruncatePayload(summary)
+}
+
+// prefixedBody is a request body with its already read beginning put back.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+func responseMessage(body []byte, status int) string {
+	var resp models.APIResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Message != "" {
+		return resp.Message
+	}
+	return http.StatusText(status)
+}
+
+// auditWriter keeps the beginning of the response to extract error messages.
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditWriter) keep(b []byte) {
+	if room := maxAuditResponse - w.body.Len(); room > 0 {
+		if len(b) < room {
+			room = len(b)
+		}
+		w.body.Write(b[:room])
+	}
+}
@@ -1,23 +1,66 @@
 package middleware
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
 )
 
-func Auth(cfg *fanucService.Config) gin.HandlerFunc {
+// Auth authenticates the X-API-Key header (or api_key query parameter), the
+// "Authorization: Bearer" token or the TLS client certificate, and stores the
+// caller in the request context.
+func Auth(auth interfaces.AuthUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		key := c.GetHeader("X-API-Key")
-		if key == "" {
-			key = c.Query("api_key")
+		creds := models.Credentials{
+			APIKey:      c.GetHeader("X-API-Key"),
+			BearerToken: bearerToken(c.GetHeader("Authorization")),
 		}
+		if creds.APIKey == "" {
+			creds.APIKey = c.Query("api_key")
+		}
+		creds.ClientCertificate = clientCertificate(c.Request.TLS)
 
-		if key != cfg.App.APIKey {
+		principal, err := auth.Authenticate(c.Request.Context(), creds)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "unauthorized"})
 			return
 		}
+
+		c.Request = c.Request.WithContext(models.WithPrincipal(c.Request.Context(), principal))
 		c.Next()
 	}
 }
+
+// RequireScope rejects callers whose key was not granted the scope. It must
+// run after Auth.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := models.PrincipalFromContext(c.Request.Context())
+		if principal == nil || !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"status": "error", "message": "missing scope " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientCertificate returns the leaf of the verified client certificate chain.
+func clientCertificate(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
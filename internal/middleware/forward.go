@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/sirupsen/logrus"
+)
+
+// headerForwardedBy marks a request forwarded by another instance. A request
+// of an authenticated peer is served locally; any other request carrying it
+// is never forwarded again, so a request makes at most one hop even without
+// mTLS while ownership moves.
+const headerForwardedBy = "X-Fanuc-Forwarded-By"
+
+// maxForwardBody bounds the request body read to find the machine ID.
+const maxForwardBody = 1 << 20
+
+// Forward sends requests for machines owned by another instance to that
+// instance: proxied transparently or, in redirect mode, answered with
+// 307 Temporary Redirect. The machine ID is read from the "id" query parameter
+// or the "id" field of a JSON body. It must run after authentication and
+// before audit, so the call is audited once, by the owner.
+func Forward(membership *cluster.Membership, mode string, peers *auth.ClusterPeers, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		forwardedBy := c.GetHeader(headerForwardedBy)
+		if forwardedBy != "" && peers.Trusted(c.Request.TLS) {
+			c.Next()
+			return
+		}
+		c.Request.Header.Del(headerForwardedBy)
+		if !membership.Enabled() {
+			c.Next()
+			return
+		}
+
+		id := machineID(c)
+		if id == "" {
+			c.Next()
+			return
+		}
+		owner := membership.Owner(id)
+		if owner == nil {
+			c.Next()
+			return
+		}
+		if forwardedBy != "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"status": "error", "message": "request was already forwarded, machine is served by instance " + owner.ID + ", retry later"})
+			return
+		}
+		if owner.Address == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"status": "error", "message": "machine is served by instance " + owner.ID + " that has no CLUSTER_ADDRESS"})
+			return
+		}
+
+		target, err := url.Parse(owner.Address)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"status": "error", "message": "invalid address of instance " + owner.ID})
+			return
+		}
+
+		if mode == cluster.ForwardRedirect {
+			location := *target
+			location.Path = c.Request.URL.Path
+			location.RawQuery = c.Request.URL.RawQuery
+			c.Redirect(http.StatusTemporaryRedirect, location.String())
+			c.Abort()
+			return
+		}
+
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		proxy.Transport = peers.Transport()
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Warnf("Failed to forward %s %s to instance %s: %v", r.Method, r.URL.Path, owner.ID, err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			_ = json.NewEncoder(w).Encode(gin.H{"status": "error", "message": "owner instance " + owner.ID + " is unreachable"})
+		}
+		c.Request.Header.Set(headerForwardedBy, membership.Self().ID)
+		proxy.ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}
+}
+
+// forwardedByPeer reports whether the request was forwarded by another
+// instance that proved it with its client certificate.
+func forwardedByPeer(c *gin.Context, peers *auth.ClusterPeers) bool {
+	return c.GetHeader(headerForwardedBy) != "" && peers.Trusted(c.Request.TLS)
+}
+
+// machineID returns the machine the request is about, restoring the body.
+func machineID(c *gin.Context) string {
+	if id := c.Query("id"); id != "" {
+		return id
+	}
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxForwardBody))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	var req struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(body, &req)
+	return req.ID
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryAudit records calls of the methods listed in actions. The machine ID is
+// taken from the request "id" field or, for creations, from the response.
+// Entries of required methods are stored before the call, which is refused
+// with UNAVAILABLE if that fails. It must be chained after UnaryAuth.
+func UnaryAudit(audit interfaces.AuditUsecase, actions map[string]string, required map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		action, ok := actions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		entry := &entities.AuditEntry{
+			Timestamp: time.Now(),
+			Transport: "grpc",
+			Action:    action,
+			MachineID: messageID(req),
+			Payload:   messageSummary(req),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			entry.ClientIP = p.Addr.String()
+		}
+		if required[info.FullMethod] {
+			if err := audit.Begin(ctx, entry); err != nil {
+				return nil, status.Error(codes.Unavailable, err.Error())
+			}
+		}
+
+		resp, err := handler(ctx, req)
+
+		if entry.MachineID == "" {
+			entry.MachineID = messageID(resp)
+		}
+		entry.Status = int(status.Code(err))
+		entry.Result = entities.AuditResultOK
+		if err != nil {
+			entry.Result = entities.AuditResultError
+			entry.Error = status.Convert(err).Message()
+		}
+
+		if required[info.FullMethod] {
+			audit.Finish(ctx, entry)
+		} else {
+			audit.Record(ctx, entry)
+		}
+		return resp, err
+	}
+}
+
+func messageID(msg interface{}) string {
+	if m, ok := msg.(interface{ GetId() string }); ok {
+		return m.GetId()
+	}
+	return ""
+}
+
+func messageSummary(msg interface{}) string {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return ""
+	}
+	summary, err := protojson.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return entities.TruncatePayload(string(summary))
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys carrying the credentials, the gRPC counterparts of the
+// X-API-Key and Authorization headers.
+const (
+	grpcAPIKeyHeader        = "x-api-key"
+	grpcAuthorizationHeader = "authorization"
+)
+
+// UnaryAuth authenticates the API key and checks the scope required by the
+// called method. Methods missing from scopes are denied.
+func UnaryAuth(auth interfaces.AuthUsecase, scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, auth, scopes[info.FullMethod])
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuth(auth interfaces.AuthUsecase, scopes map[string]string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), auth, scopes[info.FullMethod])
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, auth interfaces.AuthUsecase, scope string) (context.Context, error) {
+	var creds models.Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(grpcAPIKeyHeader); len(values) > 0 {
+			creds.APIKey = values[0]
+		}
+		if values := md.Get(grpcAuthorizationHeader); len(values) > 0 {
+			creds.BearerToken = bearerToken(values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			creds.ClientCertificate = clientCertificate(&info.State)
+		}
+	}
+
+	principal, err := auth.Authenticate(ctx, creds)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if scope == "" {
+		return nil, status.Error(codes.PermissionDenied, "method is not allowed")
+	}
+	if !principal.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, "missing scope "+scope)
+	}
+	return models.WithPrincipal(ctx, principal), nil
+}
+
+// authorizedStream replaces the stream context so handlers see the principal.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
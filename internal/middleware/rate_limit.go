@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const retryAfterHeader = "Retry-After"
+
+// RateLimit enforces the per-caller request rate. It must run after Auth. A
+// request forwarded by another instance was counted there already.
+func RateLimit(limiter *ratelimit.Limiter, peers *auth.ClusterPeers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := models.PrincipalFromContext(c.Request.Context())
+		if principal == nil || forwardedByPeer(c, peers) {
+			c.Next()
+			return
+		}
+
+		if err := limiter.AllowCaller(principal.CallerID()); err != nil {
+			SetRetryAfter(c, err)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"status": "error", "message": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// UnaryRateLimit is the gRPC counterpart of RateLimit; rejected calls get
+// RESOURCE_EXHAUSTED and a "retry-after" header. It must be chained after UnaryAuth.
+func UnaryRateLimit(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allowGRPCCaller(ctx, limiter); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamRateLimit(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowGRPCCaller(ss.Context(), limiter); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func allowGRPCCaller(ctx context.Context, limiter *ratelimit.Limiter) error {
+	principal := models.PrincipalFromContext(ctx)
+	if principal == nil {
+		return nil
+	}
+
+	err := limiter.AllowCaller(principal.CallerID())
+	if err == nil {
+		return nil
+	}
+	if seconds, ok := retryAfter(err); ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", seconds))
+	}
+	return status.Error(codes.ResourceExhausted, err.Error())
+}
+
+// SetRetryAfter sets the Retry-After header, in whole seconds, when err is a
+// *models.RateLimitError.
+func SetRetryAfter(c *gin.Context, err error) {
+	if seconds, ok := retryAfter(err); ok {
+		c.Header(retryAfterHeader, seconds)
+	}
+}
+
+// retryAfter formats the wait of a *models.RateLimitError in whole seconds.
+func retryAfter(err error) (string, bool) {
+	var limited *models.RateLimitError
+	if !errors.As(err, &limited) {
+		return "", false
+	}
+	return strconv.Itoa(int(math.Max(1, math.Ceil(limited.RetryAfter.Seconds())))), true
+}
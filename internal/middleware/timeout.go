@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout sets the deadline of the request context, so work on behalf of the
+// request (FOCAS calls, queued machine operations) stops when it passes. The
+// context is also cancelled when the client disconnects. Routes, keyed by
+// method and full path ("GET /api/v1/programs/download"), get their own
+// deadline instead of d, for work that outlasts an ordinary call. Zero
+// disables the deadline.
+func Timeout(d time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := d
+		if route, ok := routes[c.Request.Method+" "+c.FullPath()]; ok {
+			timeout = route
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
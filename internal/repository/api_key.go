@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) interfaces.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *entities.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) Update(key *entities.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *apiKeyRepository) GetByID(id string) (*entities.APIKey, error) {
+	var k entities.APIKey
+	err := r.db.First(&k, "id = ?", id).Error
+	if err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepository) GetByHash(hash string) (*entities.APIKey, error) {
+	var k entities.APIKey
+	err := r.db.First(&k, "hash = ?", hash).Error
+	if err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepository) GetByName(name string) (*entities.APIKey, error) {
+	var k entities.APIKey
+	err := r.db.Where("revoked_at IS NULL").First(&k, "name = ?", name).Error
+	if err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepository) GetAll() ([]entities.APIKey, error) {
+	var list []entities.APIKey
+	err := r.db.Order("created_at").Find(&list).Error
+	return list, err
+}
+
+func (r *apiKeyRepository) CountActive() (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.APIKey{}).Where("revoked_at IS NULL").Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) interfaces.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(entry *entities.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditRepository) Update(entry *entities.AuditEntry) error {
+	return r.db.Save(entry).Error
+}
+
+// Find returns matching entries, newest first.
+func (r *auditRepository) Find(filter models.AuditFilter) ([]entities.AuditEntry, error) {
+	query := r.db.Model(&entities.AuditEntry{})
+	if filter.MachineID != "" {
+		query = query.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("timestamp >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("timestamp < ?", filter.To)
+	}
+
+	var list []entities.AuditEntry
+	err := query.Order("timestamp DESC").Limit(filter.Limit).Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+type clusterRepository struct {
+	db *gorm.DB
+}
+
+func NewClusterRepository(db *gorm.DB) interfaces.ClusterRepository {
+	return &clusterRepository{db: db}
+}
+
+// Heartbeat registers the instance or refreshes its heartbeat. Database time
+// is used for heartbeats and leases so replica clocks do not matter.
+func (r *clusterRepository) Heartbeat(instance *entities.Instance) error {
+	return r.db.Exec(`
+		INSERT INTO instances (id, address, started_at, heartbeat_at) VALUES (?, ?, ?, NOW())
+		ON CONFLICT (id) DO UPDATE SET address = EXCLUDED.address, heartbeat_at = NOW()`,
+		instance.ID, instance.Address, instance.StartedAt,
+	).Error
+}
+
+// LiveInstances returns the instances with a heartbeat newer than ttl.
+func (r *clusterRepository) LiveInstances(ttl time.Duration) ([]entities.Instance, error) {
+	var list []entities.Instance
+	err := r.db.Where("heartbeat_at > NOW() - ? * INTERVAL '1 millisecond'", ttl.Milliseconds()).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *clusterRepository) RemoveInstance(id string) error {
+	return r.db.Delete(&entities.Instance{}, "id = ?", id).Error
+}
+
+// AcquireLease takes or renews the machine lease for owner. It succeeds when
+// the lease is free, expired or already held by owner.
+func (r *clusterRepository) AcquireLease(machineID, owner string, ttl time.Duration) (bool, error) {
+	res := r.db.Exec(`
+		INSERT INTO machine_leases (machine_id, owner_id, expires_at)
+		VALUES (?, ?, NOW() + ? * INTERVAL '1 millisecond')
+		ON CONFLICT (machine_id) DO UPDATE SET owner_id = EXCLUDED.owner_id, expires_at = EXCLUDED.expires_at
+		WHERE machine_leases.owner_id = EXCLUDED.owner_id OR machine_leases.expires_at < NOW()`,
+		machineID, owner, ttl.Milliseconds(),
+	)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *clusterRepository) ReleaseLease(machineID, owner string) error {
+	return r.db.Delete(&entities.MachineLease{}, "machine_id = ? AND owner_id = ?", machineID, owner).Error
+}
+
+func (r *clusterRepository) ReleaseAll(owner string) error {
+	return r.db.Delete(&entities.MachineLease{}, "owner_id = ?", owner).Error
+}
+
+// Leases returns the unexpired leases.
+func (r *clusterRepository) Leases() ([]entities.MachineLease, error) {
+	var list []entities.MachineLease
+	err := r.db.Where("expires_at > NOW()").Find(&list).Error
+	return list, err
+}
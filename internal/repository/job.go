@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+// jobColumns are listed without the output.
+var jobColumns = []string{
+	"id", "type", "machine_id", "machine_ids", "number", "owner", "created_by", "instance",
+	"status", "done", "total", "result", "error", "cancel_requested",
+	"created_at", "started_at", "finished_at",
+}
+
+// jobProgressColumns are the columns the runner changes while a job runs.
+// cancel_requested is left to RequestCancel, so a progress update does not
+// undo a cancellation requested through another instance.
+var jobProgressColumns = []string{
+	"status", "done", "total", "result", "error", "output", "started_at", "finished_at",
+}
+
+// activeJobStatuses are the statuses of jobs that did not finish.
+var activeJobStatuses = []string{entities.JobQueued, entities.JobRunning}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) interfaces.JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(job *entities.Job) error {
+	return r.db.Create(job).Error
+}
+
+func (r *jobRepository) Update(job *entities.Job) error {
+	return r.db.Model(job).Select(jobProgressColumns).Updates(job).Error
+}
+
+func (r *jobRepository) GetByID(id string) (*entities.Job, error) {
+	var job entities.Job
+	if err := r.db.Select(jobColumns).First(&job, "id = ?", id).Error; err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Output(id string) (string, error) {
+	var job entities.Job
+	if err := r.db.Select("id", "output").First(&job, "id = ?", id).Error; err != nil {
+		return "", models.ErrNotFound
+	}
+	return job.Output, nil
+}
+
+// Find returns matching jobs, newest first.
+func (r *jobRepository) Find(filter models.JobFilter) ([]entities.Job, error) {
+	query := r.db.Model(&entities.Job{}).Select(jobColumns)
+	if filter.MachineID != "" {
+		query = query.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+
+	var list []entities.Job
+	err := query.Order("created_at DESC").Limit(filter.Limit).Find(&list).Error
+	return list, err
+}
+
+// RequestCancel flags an unfinished job for its instance to cancel.
+func (r *jobRepository) RequestCancel(id string) error {
+	return r.db.Model(&entities.Job{}).
+		Where("id = ? AND status IN ?", id, activeJobStatuses).
+		Update("cancel_requested", true).Error
+}
+
+// CancelRequested returns the unfinished jobs of an instance flagged for
+// cancellation.
+func (r *jobRepository) CancelRequested(instance string) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&entities.Job{}).
+		Where("instance = ? AND cancel_requested AND status IN ?", instance, activeJobStatuses).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FailActive fails the unfinished jobs of an instance, which no longer runs
+// them.
+func (r *jobRepository) FailActive(instance, reason string, at time.Time) (int64, error) {
+	result := r.db.Model(&entities.Job{}).
+		Where("instance = ? AND status IN ?", instance, activeJobStatuses).
+		Updates(map[string]interface{}{"status": entities.JobFailed, "error": reason, "finished_at": at})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteFinished deletes the jobs that finished before a time.
+func (r *jobRepository) DeleteFinished(before time.Time) (int64, error) {
+	result := r.db.Where("finished_at < ?", before).Delete(&entities.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -17,7 +17,13 @@ type postgresRepository struct {
 	db *gorm.DB
 }
 
-func NewRepository(cfg *fanucService.Config) (interfaces.Repository, error) {
+func NewRepository(db *gorm.DB) interfaces.Repository {
+	return &postgresRepository{db: db}
+}
+
+// NewDB connects to the application database, creating it when missing, and
+// migrates all entities. Repositories share the returned connection.
+func NewDB(cfg *fanucService.Config) (*gorm.DB, error) {
 	// 1. Connect to default 'postgres' database to check/create target DB
 	dsnRoot := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable",
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password)
@@ -52,9 +58,17 @@ func NewRepository(cfg *fanucService.Config) (interfaces.Repository, error) {
 	}
 
 	// 3. Auto Migrate
-	if err := db.AutoMigrate(&entities.Machine{}); err != nil {
+	if err := db.AutoMigrate(
+		&entities.Machine{},
+		&entities.APIKey{},
+		&entities.AuditEntry{},
+		&entities.Instance{},
+		&entities.MachineLease{},
+		&entities.ProgramVersion{},
+		&entities.Job{},
+	); err != nil {
 		return nil, fmt.Errorf("migration failed: %w", err)
 	}
 
-	return &postgresRepository{db: db}, nil
+	return db, nil
 }
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"gorm.io/gorm"
+)
+
+// versionColumns are listed without the program text.
+var versionColumns = []string{
+	"id", "machine_id", "number", "hash", "size", "source",
+	"approved", "approved_by", "approved_at", "created_at", "seen_at",
+}
+
+type programVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewProgramVersionRepository(db *gorm.DB) interfaces.ProgramVersionRepository {
+	return &programVersionRepository{db: db}
+}
+
+// Record stores version as the newest version of its program unless the
+// latest version has the same hash; then only the SeenAt of the latest moves
+// and version is not stored. It returns the latest version before the call,
+// nil for the first one. The comparison and the insert run in one
+// transaction that first takes an advisory lock on the program.
+func (r *programVersionRepository) Record(version *entities.ProgramVersion) (*entities.ProgramVersion, error) {
+	var previous *entities.ProgramVersion
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?), ?)", version.MachineID, version.Number).Error; err != nil {
+			return err
+		}
+
+		var latest entities.ProgramVersion
+		err := tx.Where("machine_id = ? AND number = ?", version.MachineID, version.Number).Order("id DESC").Limit(1).Find(&latest).Error
+		if err != nil {
+			return err
+		}
+		if latest.ID == 0 {
+			return tx.Create(version).Error
+		}
+
+		previous = &latest
+		if latest.Hash != version.Hash {
+			return tx.Create(version).Error
+		}
+		latest.SeenAt = version.SeenAt
+		return tx.Model(&latest).Update("seen_at", version.SeenAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+func (r *programVersionRepository) GetByID(id uint) (*entities.ProgramVersion, error) {
+	var v entities.ProgramVersion
+	if err := r.db.First(&v, "id = ?", id).Error; err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &v, nil
+}
+
+// Latest returns the newest version of a program of a machine.
+func (r *programVersionRepository) Latest(machineID string, number int) (*entities.ProgramVersion, error) {
+	var v entities.ProgramVersion
+	err := r.db.Where("machine_id = ? AND number = ?", machineID, number).Order("id DESC").First(&v).Error
+	if err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &v, nil
+}
+
+// Approved returns the approved version of a program of a machine.
+func (r *programVersionRepository) Approved(machineID string, number int) (*entities.ProgramVersion, error) {
+	var v entities.ProgramVersion
+	err := r.db.Where("machine_id = ? AND number = ? AND approved", machineID, number).First(&v).Error
+	if err != nil {
+		return nil, models.ErrNotFound
+	}
+	return &v, nil
+}
+
+// Approve makes version the only approved version of its program.
+func (r *programVersionRepository) Approve(version *entities.ProgramVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.ProgramVersion{}).
+			Where("machine_id = ? AND number = ? AND approved AND id <> ?", version.MachineID, version.Number, version.ID).
+			Updates(map[string]interface{}{"approved": false, "approved_by": "", "approved_at": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(version).Select("approved", "approved_by", "approved_at").Updates(version).Error
+	})
+}
+
+// Find returns matching versions without their text, newest first.
+func (r *programVersionRepository) Find(filter models.ProgramVersionFilter) ([]entities.ProgramVersion, error) {
+	query := r.db.Model(&entities.ProgramVersion{}).Select(versionColumns).Where("machine_id = ?", filter.MachineID)
+	if filter.Number != 0 {
+		query = query.Where("number = ?", filter.Number)
+	}
+
+	var list []entities.ProgramVersion
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&list).Error
+	return list, err
+}
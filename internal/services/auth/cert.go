@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
+
+// CertMapper maps verified client certificates (mTLS) to API scopes by the
+// subject common name and organizational units.
+type CertMapper struct {
+	rules []certRule
+}
+
+type certRule struct {
+	attr   string // CN or OU
+	value  string
+	scopes []string
+}
+
+// NewCertMapper returns nil when client certificates are not requested.
+func NewCertMapper(cfg *fanucService.Config) (*CertMapper, error) {
+	switch cfg.TLS.ClientAuth {
+	case "", ClientAuthNone:
+		return nil, nil
+	}
+
+	roles, err := parseRoleMapping(cfg.TLS.ClientRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &CertMapper{}
+	for subject, scopes := range roles {
+		rule, err := parseSubject(subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS client role: %w", err)
+		}
+		rule.scopes = scopes
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// parseSubject parses a "CN:name" or "OU:unit" certificate subject.
+func parseSubject(subject string) (certRule, error) {
+	attr, value, ok := strings.Cut(subject, ":")
+	attr = strings.ToUpper(strings.TrimSpace(attr))
+	value = strings.TrimSpace(value)
+	if !ok || (attr != "CN" && attr != "OU") || value == "" {
+		return certRule{}, fmt.Errorf("%q: expected CN:name or OU:unit", subject)
+	}
+	return certRule{attr: attr, value: value}, nil
+}
+
+// Map returns the caller of a verified client certificate. Certificates that
+// match no rule are rejected.
+func (m *CertMapper) Map(cert *x509.Certificate) (*models.Principal, error) {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, rule := range m.rules {
+		if !rule.matches(cert) {
+			continue
+		}
+		for _, scope := range rule.scopes {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: client certificate %q is not mapped to any scope", models.ErrUnauthorized, cert.Subject.CommonName)
+	}
+
+	return &models.Principal{
+		Name:       cert.Subject.CommonName,
+		AuthMethod: models.AuthMethodMTLS,
+		Scopes:     scopes,
+	}, nil
+}
+
+func (r certRule) matches(cert *x509.Certificate) bool {
+	if r.attr == "CN" {
+		return cert.Subject.CommonName == r.value
+	}
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		if ou == r.value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ModeAPIKey = "api_key"
+	ModeJWT    = "jwt"
+	ModeBoth   = "both"
+)
+
+// clockSkew tolerates small clock differences with the identity provider.
+const clockSkew = 30 * time.Second
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTVerifier validates bearer tokens of an OIDC identity provider and maps
+// their role claims to API scopes.
+type JWTVerifier struct {
+	keys   *KeySet
+	cfg    fanucService.AuthConfig
+	roles  map[string][]string
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier returns nil when JWT auth is disabled by AUTH_MODE. JWT auth
+// requires JWT_AUDIENCE: without it a token the provider issued to any other
+// client would be accepted.
+func NewJWTVerifier(cfg *fanucService.Config, logger *logrus.Logger) (*JWTVerifier, error) {
+	switch cfg.Auth.Mode {
+	case "", ModeAPIKey:
+		return nil, nil
+	case ModeJWT, ModeBoth:
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.Auth.Mode)
+	}
+	if cfg.Auth.Audience == "" {
+		return nil, fmt.Errorf("AUTH_MODE=%s requires JWT_AUDIENCE", cfg.Auth.Mode)
+	}
+
+	roles, err := parseRoleMapping(cfg.Auth.RoleMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := NewKeySet(cfg.Auth.JWKSFile, cfg.Auth.JWKSURL, cfg.Auth.JWKSRefresh, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithAudience(cfg.Auth.Audience),
+	}
+	if cfg.Auth.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Auth.Issuer))
+	}
+
+	return &JWTVerifier{
+		keys:   keys,
+		cfg:    cfg.Auth,
+		roles:  roles,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+// Verify checks the token signature and claims and returns the caller.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*models.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrUnauthorized, err)
+	}
+
+	subject, _ := claims[v.cfg.SubjectClaim].(string)
+	if subject == "" {
+		subject, _ = claims["sub"].(string)
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", models.ErrUnauthorized)
+	}
+
+	return &models.Principal{
+		Name:       subject,
+		AuthMethod: models.AuthMethodJWT,
+		Scopes:     v.scopes(claimValues(claims, v.cfg.RolesClaim)),
+	}, nil
+}
+
+// scopes returns the scopes of the roles in JWT_ROLE_MAPPING. Other roles,
+// even ones named like a scope, grant nothing: the provider may hand them out
+// for other clients.
+func (v *JWTVerifier) scopes(roles []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(scope string) {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	for _, role := range roles {
+		for _, scope := range v.roles[role] {
+			add(scope)
+		}
+	}
+	return result
+}
+
+// claimValues resolves a dotted claim path to a list of strings. Both JSON
+// arrays and space separated strings (as in the OAuth "scope" claim) are accepted.
+func claimValues(claims jwt.MapClaims, path string) []string {
+	var value interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[part]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// parseRoleMapping parses "role=scope+scope,role=scope".
+func parseRoleMapping(mapping string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	for _, rule := range strings.Split(mapping, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		role, scopes, ok := strings.Cut(rule, "=")
+		if !ok || role == "" || scopes == "" {
+			return nil, fmt.Errorf("invalid role mapping %q: expected role=scope+scope", rule)
+		}
+		role = strings.TrimSpace(role)
+		for _, scope := range strings.Split(scopes, "+") {
+			scope = strings.TrimSpace(scope)
+			if !isScope(scope) {
+				return nil, fmt.Errorf("invalid role mapping %q: unknown scope %q", rule, scope)
+			}
+			result[role] = append(result[role], scope)
+		}
+	}
+	return result, nil
+}
+
+func isScope(s string) bool {
+	switch s {
+	case entities.ScopeRead, entities.ScopeControl, entities.ScopeProgram, entities.ScopeProgramWrite, entities.ScopeWrite, entities.ScopeAdmin:
+		return true
+	}
+	return false
+}
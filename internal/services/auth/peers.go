@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/sirupsen/logrus"
+)
+
+// ClusterPeers secures the requests instances forward to each other. The
+// transport verifies the other instances with CLUSTER_CA_FILE and presents
+// CLUSTER_CERT_FILE as the client certificate; an incoming request comes from
+// a peer when its verified client certificate matches CLUSTER_PEER_SUBJECT.
+type ClusterPeers struct {
+	transport http.RoundTripper
+	subject   *certRule // nil trusts no request
+}
+
+func NewClusterPeers(cfg *fanucService.Config, logger *logrus.Logger) (*ClusterPeers, error) {
+	c := cfg.Cluster
+	peers := &ClusterPeers{transport: http.DefaultTransport}
+
+	if c.PeerSubject != "" {
+		rule, err := parseSubject(c.PeerSubject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLUSTER_PEER_SUBJECT: %w", err)
+		}
+		peers.subject = &rule
+	}
+
+	if c.CAFile == "" && c.CertFile == "" && c.KeyFile == "" {
+		return peers, nil
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CLUSTER_CA_FILE: %w", err)
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CLUSTER_CA_FILE %s contains no certificates", c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("both CLUSTER_CERT_FILE and CLUSTER_KEY_FILE are required")
+		}
+		reloader := &certReloader{
+			certFile: c.CertFile,
+			keyFile:  c.KeyFile,
+			interval: cfg.TLS.ReloadInterval,
+			logger:   logger,
+		}
+		if err := reloader.load(); err != nil {
+			return nil, err
+		}
+		tlsCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := reloader.current()
+			return cert, nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	peers.transport = transport
+	return peers, nil
+}
+
+// Transport carries the requests forwarded to other instances.
+func (p *ClusterPeers) Transport() http.RoundTripper {
+	return p.transport
+}
+
+// Trusted reports whether the connection presented the verified client
+// certificate of another instance.
+func (p *ClusterPeers) Trusted(state *tls.ConnectionState) bool {
+	if p.subject == nil || state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return false
+	}
+	return p.subject.matches(state.VerifiedChains[0][0])
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// NewServerTLSConfig builds the TLS settings shared by the HTTP and gRPC
+// servers. It returns nil when TLS_CERT_FILE is not set. Certificate, key and
+// client CA files are reloaded on change without a restart.
+func NewServerTLSConfig(cfg *fanucService.Config, logger *logrus.Logger) (*tls.Config, error) {
+	if cfg.TLS.CertFile == "" && cfg.TLS.KeyFile == "" {
+		return nil, nil
+	}
+	if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
+		return nil, fmt.Errorf("both TLS_CERT_FILE and TLS_KEY_FILE are required")
+	}
+
+	var clientAuth tls.ClientAuthType
+	switch cfg.TLS.ClientAuth {
+	case "", ClientAuthNone:
+		clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown TLS client auth %q", cfg.TLS.ClientAuth)
+	}
+	if clientAuth != tls.NoClientCert && cfg.TLS.ClientCAFile == "" {
+		return nil, fmt.Errorf("TLS_CLIENT_AUTH=%s requires TLS_CLIENT_CA_FILE", cfg.TLS.ClientAuth)
+	}
+
+	reloader := &certReloader{
+		certFile: cfg.TLS.CertFile,
+		keyFile:  cfg.TLS.KeyFile,
+		caFile:   cfg.TLS.ClientCAFile,
+		interval: cfg.TLS.ReloadInterval,
+		logger:   logger,
+	}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	// GetConfigForClient is consulted on every handshake, so both the server
+	// certificate and the client CA pool come from the latest reload.
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := reloader.current()
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.Certificates = []tls.Certificate{*cert}
+		c.ClientCAs = pool
+		return c, nil
+	}
+	return base, nil
+}
+
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+	checked time.Time
+}
+
+// current returns the loaded certificate and CA pool, reloading them when the
+// files changed since the last check (at most once per interval).
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	due := r.interval > 0 && time.Since(r.checked) >= r.interval
+	r.mu.Unlock()
+
+	if due {
+		if err := r.load(); err != nil {
+			r.logger.Errorf("TLS certificate reload failed, keeping the previous one: %v", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.pool
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.checked = time.Now()
+	unchanged := r.cert != nil && !modTime.After(r.modTime)
+	r.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("TLS client CA file %s contains no certificates", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	reloaded := r.cert != nil
+	r.cert = &cert
+	r.pool = pool
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	if reloaded {
+		r.logger.Infof("TLS certificate reloaded from %s", r.certFile)
+	}
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
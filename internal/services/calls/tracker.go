@@ -0,0 +1,80 @@
+// Package calls runs blocking FOCAS driver calls under a context.
+package calls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrAbandoned = errors.New("FOCAS call abandoned")
+
+// Call states, see Run.
+const (
+	callRunning int32 = iota
+	callFinished
+	callAbandoned
+)
+
+// Tracker runs driver calls that cannot be interrupted: a call whose context
+// ends is abandoned, the caller returns at once and the goroutine finishes on
+// its own. Abandoned calls are counted, and new calls are refused while
+// maxHung of them are still hung. A zero maxHung disables the limit.
+type Tracker struct {
+	maxHung int
+	logger  *logrus.Logger
+	hung    atomic.Int64
+}
+
+func NewTracker(maxHung int, logger *logrus.Logger) *Tracker {
+	return &Tracker{maxHung: maxHung, logger: logger}
+}
+
+// Hung returns the number of abandoned calls that have not returned yet.
+func (t *Tracker) Hung() int64 {
+	return t.hung.Load()
+}
+
+// Run calls fn and waits for it or for ctx. cleanup, if set, runs after an
+// abandoned fn returns, to release what the late call produced.
+func (t *Tracker) Run(ctx context.Context, op string, fn func() error, cleanup func()) error {
+	if t.maxHung > 0 && t.hung.Load() >= int64(t.maxHung) {
+		return fmt.Errorf("%s refused: %d FOCAS calls are still hung", op, t.hung.Load())
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var state atomic.Int32
+	done := make(chan error, 1)
+	started := time.Now()
+
+	go func() {
+		err := fn()
+		if state.CompareAndSwap(callRunning, callFinished) {
+			done <- err
+			return
+		}
+		hung := t.hung.Add(-1)
+		t.logger.Warnf("Abandoned FOCAS call %s returned after %v (%d still hung)", op, time.Since(started).Round(time.Millisecond), hung)
+		if cleanup != nil {
+			cleanup()
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if !state.CompareAndSwap(callRunning, callAbandoned) {
+			return <-done
+		}
+		hung := t.hung.Add(1)
+		t.logger.Warnf("FOCAS call %s abandoned after %v: %v (%d hung)", op, time.Since(started).Round(time.Millisecond), ctx.Err(), hung)
+		return fmt.Errorf("%s: %w: %w", op, ErrAbandoned, ctx.Err())
+	}
+}
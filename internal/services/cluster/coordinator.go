@@ -0,0 +1,213 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/sirupsen/logrus"
+)
+
+// Coordinator assigns machines to live instances through the database. On
+// every heartbeat each instance computes the assignment by rendezvous hashing
+// over the live instances, takes or renews the leases of its machines and
+// releases the ones that moved elsewhere. A lease is only taken once the
+// previous owner released it or stopped renewing it, so a machine is never
+// polled by two instances.
+type Coordinator struct {
+	cfg        fanucService.ClusterConfig
+	repo       interfaces.ClusterRepository
+	machines   interfaces.Repository
+	service    interfaces.FanucService
+	membership *Membership
+	logger     *logrus.Logger
+
+	held     map[string]bool
+	lastBeat time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewCoordinator(
+	cfg *fanucService.Config,
+	repo interfaces.ClusterRepository,
+	machines interfaces.Repository,
+	service interfaces.FanucService,
+	membership *Membership,
+	logger *logrus.Logger,
+) *Coordinator {
+	return &Coordinator{
+		cfg:        cfg.Cluster,
+		repo:       repo,
+		machines:   machines,
+		service:    service,
+		membership: membership,
+		logger:     logger,
+		held:       make(map[string]bool),
+	}
+}
+
+// Start joins the cluster and runs the first rebalance before returning, so
+// the instance knows its machines when the API starts. It does nothing when
+// clustering is disabled.
+func (c *Coordinator) Start() {
+	if !c.membership.Enabled() {
+		return
+	}
+	if c.cfg.Address == "" {
+		c.logger.Warn("CLUSTER_ADDRESS is not set: other instances cannot forward API calls to this one")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.tick(ctx)
+	c.logger.Infof("Joined cluster as instance %s, owning %d machines", c.membership.Self().ID, len(c.held))
+
+	c.wg.Add(1)
+	go c.run(ctx)
+}
+
+// Stop leaves the cluster. It must run after polling stopped and the FOCAS
+// handles were closed: releasing the leases lets other instances take over at
+// once instead of after the lease TTL.
+func (c *Coordinator) Stop() error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	c.wg.Wait()
+
+	self := c.membership.Self().ID
+	if err := c.repo.ReleaseAll(self); err != nil {
+		return err
+	}
+	c.logger.Infof("Left cluster, released %d machines", len(c.held))
+	return c.repo.RemoveInstance(self)
+}
+
+func (c *Coordinator) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cfg.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.tick(ctx)
+		}
+	}
+}
+
+func (c *Coordinator) tick(ctx context.Context) {
+	self := c.membership.Self()
+
+	if err := c.repo.Heartbeat(&self); err != nil {
+		c.logger.Errorf("Cluster heartbeat failed: %v", err)
+		// Without heartbeats our leases expire and other instances take the
+		// machines over; stop serving them before that happens.
+		if !c.lastBeat.IsZero() && time.Since(c.lastBeat) > c.cfg.LeaseTTL-c.cfg.Heartbeat {
+			c.releaseAll(ctx)
+		}
+		return
+	}
+	c.lastBeat = time.Now()
+
+	instances, err := c.repo.LiveInstances(c.cfg.LeaseTTL)
+	if err != nil {
+		c.logger.Errorf("Failed to list cluster instances: %v", err)
+		return
+	}
+	machines, err := c.machines.GetAll()
+	if err != nil {
+		c.logger.Errorf("Failed to list machines for rebalancing: %v", err)
+		return
+	}
+	leases, err := c.repo.Leases()
+	if err != nil {
+		c.logger.Errorf("Failed to list machine leases: %v", err)
+		return
+	}
+
+	owners := make(map[string]string, len(leases))
+	for _, l := range leases {
+		owners[l.MachineID] = l.OwnerID
+	}
+
+	owned := make(map[string]bool)
+	existing := make(map[string]bool, len(machines))
+	for _, m := range machines {
+		existing[m.ID] = true
+
+		assigned := assign(instances, m.ID)
+		if assigned == nil || assigned.ID != self.ID {
+			c.release(ctx, m.ID)
+			continue
+		}
+
+		acquired, err := c.repo.AcquireLease(m.ID, self.ID, c.cfg.LeaseTTL)
+		if err != nil {
+			c.logger.Errorf("Failed to renew lease of machine %s: %v", m.ID, err)
+			owned[m.ID] = c.held[m.ID] // the lease is still valid for a while
+			continue
+		}
+		if !acquired {
+			continue // the previous owner has not released it yet
+		}
+
+		owned[m.ID] = true
+		owners[m.ID] = self.ID
+		if c.held[m.ID] {
+			c.service.SyncMachine(ctx, m.ID)
+		} else {
+			c.logger.Infof("Took over machine %s (%s)", m.ID, m.Endpoint)
+			c.service.AdoptMachine(ctx, m)
+		}
+	}
+
+	// Leases of deleted machines.
+	for id := range c.held {
+		if !existing[id] {
+			c.release(ctx, id)
+		}
+	}
+
+	c.held = owned
+	c.membership.update(owned, owners, instances)
+}
+
+// release stops serving a machine and gives up its lease. Machines that were
+// never held only drop local state, e.g. a connection created through this
+// instance's API.
+func (c *Coordinator) release(ctx context.Context, machineID string) {
+	if err := c.service.ReleaseMachine(ctx, machineID); err != nil {
+		c.logger.Warnf("Failed to release machine %s: %v", machineID, err)
+	}
+	if !c.held[machineID] {
+		return
+	}
+	if err := c.repo.ReleaseLease(machineID, c.membership.Self().ID); err != nil {
+		c.logger.Errorf("Failed to release lease of machine %s: %v", machineID, err)
+	}
+	delete(c.held, machineID)
+	c.logger.Infof("Handed over machine %s", machineID)
+}
+
+func (c *Coordinator) releaseAll(ctx context.Context) {
+	for id := range c.held {
+		if err := c.service.ReleaseMachine(ctx, id); err != nil {
+			c.logger.Warnf("Failed to release machine %s: %v", id, err)
+		}
+	}
+	if len(c.held) > 0 {
+		c.logger.Warnf("Lost contact with the database, stopped serving %d machines", len(c.held))
+	}
+	c.held = make(map[string]bool)
+	c.membership.update(c.held, nil, nil)
+}
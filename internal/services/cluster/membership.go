@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
+
+// Forwarding modes for API calls that reach a non-owner.
+const (
+	ForwardProxy    = "proxy"
+	ForwardRedirect = "redirect"
+)
+
+// Membership is this instance's view of the cluster: which machines it owns
+// and where the others are served. It is refreshed by the Coordinator on every
+// heartbeat. With clustering disabled the instance owns every machine.
+type Membership struct {
+	enabled bool
+	self    entities.Instance
+
+	mu        sync.RWMutex
+	owned     map[string]bool
+	owners    map[string]string // machine ID -> owner instance ID, from leases
+	instances []entities.Instance
+}
+
+func NewMembership(cfg *fanucService.Config) *Membership {
+	return &Membership{
+		enabled: cfg.Cluster.Enabled,
+		self: entities.Instance{
+			ID:        cfg.Cluster.InstanceID,
+			Address:   cfg.Cluster.Address,
+			StartedAt: time.Now(),
+		},
+		owned: make(map[string]bool),
+	}
+}
+
+func (m *Membership) Enabled() bool {
+	return m.enabled
+}
+
+func (m *Membership) Self() entities.Instance {
+	return m.self
+}
+
+// Owns reports whether this instance may talk to the machine.
+func (m *Membership) Owns(machineID string) bool {
+	if !m.enabled {
+		return true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.owned[machineID]
+}
+
+// Owner returns the instance serving the machine: the lease holder or, while
+// the lease is being handed over, the instance it is assigned to. It returns
+// nil when the owner is this instance or unknown.
+func (m *Membership) Owner(machineID string) *entities.Instance {
+	if !m.enabled {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.owned[machineID] {
+		return nil
+	}
+	ownerID, ok := m.owners[machineID]
+	if !ok {
+		if assigned := assign(m.instances, machineID); assigned != nil {
+			ownerID = assigned.ID
+		}
+	}
+	if ownerID == "" || ownerID == m.self.ID {
+		return nil
+	}
+	for i := range m.instances {
+		if m.instances[i].ID == ownerID {
+			owner := m.instances[i]
+			return &owner
+		}
+	}
+	return nil
+}
+
+// NotOwnerError describes the instance to ask instead.
+func (m *Membership) NotOwnerError(machineID string) error {
+	if owner := m.Owner(machineID); owner != nil {
+		return fmt.Errorf("%w: machine %s is served by instance %s (%s)", models.ErrNotOwner, machineID, owner.ID, owner.Address)
+	}
+	return fmt.Errorf("%w: machine %s has no owner yet, retry later", models.ErrNotOwner, machineID)
+}
+
+func (m *Membership) update(owned map[string]bool, owners map[string]string, instances []entities.Instance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.owned = owned
+	m.owners = owners
+	m.instances = instances
+}
+
+// assign picks the instance a machine belongs to by rendezvous hashing, so
+// only the machines of a joining or leaving instance move.
+func assign(instances []entities.Instance, machineID string) *entities.Instance {
+	var best *entities.Instance
+	var bestScore uint64
+	for i := range instances {
+		h := fnv.New64a()
+		h.Write([]byte(instances[i].ID))
+		h.Write([]byte{0})
+		h.Write([]byte(machineID))
+		if score := h.Sum64(); best == nil || score > bestScore {
+			best, bestScore = &instances[i], score
+		}
+	}
+	return best
+}
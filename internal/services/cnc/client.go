@@ -0,0 +1,126 @@
+// Package cnc is the FOCAS client of the service. It holds the same handle as
+// fanucAdapter.Client and adds the FOCAS calls the adapter does not expose.
+package cnc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	adapter "github.com/iwtcode/fanucAdapter"
+	"github.com/iwtcode/fanucAdapter/focas"
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+	"github.com/iwtcode/fanucAdapter/models"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	startupOnce sync.Once
+	startupErr  error
+)
+
+// Client is a FOCAS connection to one machine. It is not safe for concurrent
+// use; the service runs all calls of a machine on its worker.
+type Client struct {
+	adapter *focas.FocasAdapter
+}
+
+// Connect opens a FOCAS handle like fanucAdapter.New does.
+func Connect(cfg *adapter.Config) (*Client, error) {
+	startupOnce.Do(func() {
+		startupErr = focas.Startup(0, "")
+	})
+	if startupErr != nil {
+		return nil, fmt.Errorf("FOCAS startup failed: %w", startupErr)
+	}
+
+	a, err := focas.NewFocasAdapter(cfg.IP, cfg.Port, cfg.TimeoutMs, cfg.ModelSeries, newLogger(cfg.LogLevel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create focas adapter: %w", err)
+	}
+	return &Client{adapter: a}, nil
+}
+
+func newLogger(level string) *logrus.Logger {
+	logger := logrus.New()
+	if level == "off" || level == "none" {
+		logger.SetOutput(io.Discard)
+		return logger
+	}
+
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		lvl = logrus.InfoLevel
+	}
+	logger.SetLevel(lvl)
+	logger.SetOutput(os.Stdout)
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		ForceColors:     true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+	return logger
+}
+
+// Close releases the handle.
+func (c *Client) Close() {
+	c.adapter.Close()
+}
+
+// GetMachineState reads the machine state.
+func (c *Client) GetMachineState() (*models.UnifiedMachineData, error) {
+	return c.adapter.ReadMachineState()
+}
+
+// GetCurrentData reads the aggregated machine data published by polling.
+func (c *Client) GetCurrentData() (*models.AggregatedData, error) {
+	return c.adapter.AggregateAllData()
+}
+
+// GetControlProgram reads the text of the executing program.
+func (c *Client) GetControlProgram() (string, error) {
+	return c.adapter.GetControlProgram()
+}
+
+// ExecutingBlock reads the O number of the executing program and the text of
+// the block it executes, empty if the CNC does not report it.
+func (c *Client) ExecutingBlock() (int, string, error) {
+	info, err := c.adapter.ReadProgram()
+	if err != nil {
+		return 0, "", err
+	}
+	return int(info.Number), info.CurrentGCode, nil
+}
+
+// Error is a FOCAS return code other than EW_OK.
+type Error struct {
+	Func string
+	Code int16
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s failed: rc=%d", e.Func, e.Code)
+}
+
+// Rejected reports whether the CNC refused the request itself (unknown number,
+// bad range, type or length, missing option) rather than failed to answer.
+func (e *Error) Rejected() bool {
+	switch e.Code {
+	case errcode.EW_LENGTH, errcode.EW_NUMBER, errcode.EW_ATTRIB, errcode.EW_DATA, errcode.EW_NOOPT:
+		return true
+	}
+	return false
+}
+
+// call runs a FOCAS function on the handle under the adapter's library lock.
+// A broken handle is reconnected by the adapter.
+func (c *Client) call(fn string, f func(handle uint16) int16) error {
+	return c.adapter.CallWithReconnect(func(handle uint16) (int16, error) {
+		rc := f(handle)
+		if rc != errcode.EW_OK {
+			return rc, &Error{Func: fn, Code: rc}
+		}
+		return rc, nil
+	})
+}
@@ -0,0 +1,136 @@
+package cnc
+
+/*
+short cnc_rdparam(unsigned short h, short number, short axis, short length, void *out);
+short cnc_diagnoss(unsigned short h, short number, short axis, short length, void *out);
+short cnc_rdmacro(unsigned short h, short number, short length, void *out);
+short pmc_rdpmcrng(unsigned short h, short area, short type, unsigned short start, unsigned short end, unsigned short length, void *out);
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// DataType is the size and encoding of a parameter, diagnosis or PMC value.
+type DataType int
+
+const (
+	Byte DataType = iota
+	Word
+	DWord
+	Real
+)
+
+// ParseDataType parses byte, word, dword or real.
+func ParseDataType(s string) (DataType, error) {
+	switch s {
+	case "byte":
+		return Byte, nil
+	case "word":
+		return Word, nil
+	case "dword":
+		return DWord, nil
+	case "real":
+		return Real, nil
+	}
+	return 0, fmt.Errorf("unknown data type %q", s)
+}
+
+// PMCSize is the number of PMC bytes a value of type t takes.
+func (t DataType) PMCSize() int {
+	return map[DataType]int{Byte: 1, Word: 2, DWord: 4, Real: 4}[t]
+}
+
+// pmcAreas are the PMC address type codes of pmc_rdpmcrng.
+var pmcAreas = map[string]int16{
+	"G": 0, "F": 1, "Y": 2, "X": 3, "A": 4, "R": 5, "T": 6,
+	"K": 7, "C": 8, "D": 9, "E": 12,
+}
+
+// ValidPMCArea reports whether area is a PMC area letter known to the service.
+func ValidPMCArea(area string) bool {
+	_, ok := pmcAreas[area]
+	return ok
+}
+
+// Value is one value read from the CNC.
+type Value struct {
+	Number int
+	Value  float64
+	Vacant bool // macro variable without a value
+}
+
+// ReadParameters reads count parameters starting at number. axis selects the
+// axis of an axis parameter, 0 reads a non-axis parameter.
+func (c *Client) ReadParameters(number, count, axis int, t DataType) ([]Value, error) {
+	return c.readNumbered("cnc_rdparam", number, count, axis, t, func(h uint16, n, length int16, buf unsafe.Pointer) int16 {
+		return int16(C.cnc_rdparam(C.ushort(h), C.short(n), C.short(axis), C.short(length), buf))
+	})
+}
+
+// ReadDiagnoses reads count diagnostic numbers starting at number.
+func (c *Client) ReadDiagnoses(number, count, axis int, t DataType) ([]Value, error) {
+	return c.readNumbered("cnc_diagnoss", number, count, axis, t, func(h uint16, n, length int16, buf unsafe.Pointer) int16 {
+		return int16(C.cnc_diagnoss(C.ushort(h), C.short(n), C.short(axis), C.short(length), buf))
+	})
+}
+
+// readNumbered reads parameters or diagnoses one by one, see DecodeNumbered.
+func (c *Client) readNumbered(fn string, number, count, axis int, t DataType, read func(h uint16, n, length int16, buf unsafe.Pointer) int16) ([]Value, error) {
+	values := make([]Value, 0, count)
+
+	for n := number; n < number+count; n++ {
+		buf := make([]byte, numberedSize(t))
+		err := c.call(fmt.Sprintf("%s %d", fn, n), func(h uint16) int16 {
+			return read(h, int16(n), int16(len(buf)), unsafe.Pointer(&buf[0]))
+		})
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, Value{Number: n, Value: DecodeNumbered(buf, t)})
+	}
+	return values, nil
+}
+
+// ReadMacros reads count custom macro variables starting at number.
+func (c *Client) ReadMacros(number, count int) ([]Value, error) {
+	values := make([]Value, 0, count)
+
+	for n := number; n < number+count; n++ {
+		// ODBM, see DecodeMacro.
+		buf := make([]byte, 10)
+		err := c.call(fmt.Sprintf("cnc_rdmacro %d", n), func(h uint16) int16 {
+			return int16(C.cnc_rdmacro(C.ushort(h), C.short(n), C.short(len(buf)), unsafe.Pointer(&buf[0])))
+		})
+		if err != nil {
+			return nil, err
+		}
+		v, vacant := DecodeMacro(buf)
+		values = append(values, Value{Number: n, Value: v, Vacant: vacant})
+	}
+	return values, nil
+}
+
+// ReadPMC reads count values of type t from a PMC area starting at the byte
+// address start. Values are numbered by their byte address.
+func (c *Client) ReadPMC(area string, start, count int, t DataType) ([]Value, error) {
+	code, ok := pmcAreas[area]
+	if !ok {
+		return nil, fmt.Errorf("unknown PMC area %q", area)
+	}
+	typeCode := pmcTypeCode(t)
+	size := t.PMCSize()
+	end := start + count*size - 1
+
+	// IODBPMC, see DecodePMC.
+	buf := make([]byte, 8+count*size)
+	err := c.call(fmt.Sprintf("pmc_rdpmcrng %s%d-%d", area, start, end), func(h uint16) int16 {
+		return int16(C.pmc_rdpmcrng(C.ushort(h), C.short(code), C.short(typeCode), C.ushort(start), C.ushort(end), C.ushort(len(buf)), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DecodePMC(buf, start, count, t), nil
+}
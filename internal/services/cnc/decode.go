@@ -0,0 +1,199 @@
+package cnc
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+)
+
+// The FOCAS structures are decoded from little-endian byte buffers, so the
+// layouts below can be checked without a CNC.
+
+// DecodeNumbered decodes the value of a parameter or diagnosis buffer: a 4
+// byte header (number, axis) followed by the value. Real values are a
+// mantissa and a count of decimal places.
+func DecodeNumbered(buf []byte, t DataType) float64 {
+	data := buf[4:]
+	switch t {
+	case Byte:
+		return float64(data[0])
+	case Word:
+		return float64(int16(binary.LittleEndian.Uint16(data)))
+	case DWord:
+		return float64(int32(binary.LittleEndian.Uint32(data)))
+	case Real:
+		return scaled(int32(binary.LittleEndian.Uint32(data)), int32(binary.LittleEndian.Uint32(data[4:])))
+	}
+	return 0
+}
+
+// numberedSize is the size of a parameter or diagnosis buffer of type t.
+func numberedSize(t DataType) int {
+	return 4 + map[DataType]int{Byte: 1, Word: 2, DWord: 4, Real: 8}[t]
+}
+
+// DecodeMacro decodes an ODBM buffer: datano, dummy, mcr_val (4 bytes),
+// dec_val. A vacant variable has mcr_val 0 and dec_val -1.
+func DecodeMacro(buf []byte) (value float64, vacant bool) {
+	mantissa := int32(binary.LittleEndian.Uint32(buf[4:8]))
+	places := int16(binary.LittleEndian.Uint16(buf[8:10]))
+	if mantissa == 0 && places == -1 {
+		return 0, true
+	}
+	return scaled(mantissa, int32(places)), false
+}
+
+// DecodePMC decodes count values of type t from an IODBPMC buffer (type_a,
+// type_d, datano_s, datano_e, data). Values are numbered by their byte
+// address from start.
+func DecodePMC(buf []byte, start, count int, t DataType) []Value {
+	size := t.PMCSize()
+	values := make([]Value, 0, count)
+	for i := 0; i < count; i++ {
+		data := buf[8+i*size:]
+		var v float64
+		switch t {
+		case Byte:
+			v = float64(data[0])
+		case Word:
+			v = float64(int16(binary.LittleEndian.Uint16(data)))
+		case DWord:
+			v = float64(int32(binary.LittleEndian.Uint32(data)))
+		case Real:
+			v = float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+		}
+		values = append(values, Value{Number: start + i*size, Value: v})
+	}
+	return values
+}
+
+// EncodePMC builds the IODBPMC buffer that writes values of type t to the PMC
+// area code from the byte address start.
+func EncodePMC(code int16, t DataType, start int, values []float64) []byte {
+	size := t.PMCSize()
+	end := start + len(values)*size - 1
+
+	buf := make([]byte, 8+len(values)*size)
+	binary.LittleEndian.PutUint16(buf[0:], uint16(code))
+	binary.LittleEndian.PutUint16(buf[2:], uint16(pmcTypeCode(t)))
+	binary.LittleEndian.PutUint16(buf[4:], uint16(start))
+	binary.LittleEndian.PutUint16(buf[6:], uint16(end))
+	for i, v := range values {
+		data := buf[8+i*size:]
+		switch t {
+		case Byte:
+			data[0] = byte(int64(v))
+		case Word:
+			binary.LittleEndian.PutUint16(data, uint16(int16(v)))
+		case DWord:
+			binary.LittleEndian.PutUint32(data, uint32(int32(v)))
+		case Real:
+			binary.LittleEndian.PutUint32(data, math.Float32bits(float32(v)))
+		}
+	}
+	return buf
+}
+
+// pmcTypeCode is the PMC data type of pmc_rdpmcrng: 0 byte, 1 word, 2 dword,
+// 4 float.
+func pmcTypeCode(t DataType) int16 {
+	return map[DataType]int16{Byte: 0, Word: 1, DWord: 2, Real: 4}[t]
+}
+
+// DecodeStatus decodes an ODBST buffer: hdck, tmmode, aut, run, motion, mstb,
+// emergency, alarm, edit.
+func DecodeStatus(buf []byte) Status {
+	return Status{
+		TMMode: int16(binary.LittleEndian.Uint16(buf[2:4])),
+		Mode:   int16(binary.LittleEndian.Uint16(buf[4:6])),
+		Run:    int16(binary.LittleEndian.Uint16(buf[6:8])),
+	}
+}
+
+// DecodeProgramEntry decodes a PRGDIR3 entry: number, length, page,
+// comment[52] and the modification and creation dates.
+func DecodeProgramEntry(b []byte) Program {
+	return Program{
+		Number:   int(int32(binary.LittleEndian.Uint32(b[0:]))),
+		Size:     int(int32(binary.LittleEndian.Uint32(b[4:]))),
+		Comment:  programComment(b[12:64]),
+		Modified: programDate(b[64:76]),
+	}
+}
+
+// programComment trims the NUL padding and the parentheses of a comment.
+func programComment(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(string(b)), "("), ")"))
+}
+
+// programDate decodes year, month, day, hour and minute shorts.
+func programDate(b []byte) time.Time {
+	field := func(i int) int { return int(int16(binary.LittleEndian.Uint16(b[2*i:]))) }
+	if field(0) == 0 {
+		return time.Time{}
+	}
+	return time.Date(field(0), time.Month(field(1)), field(2), field(3), field(4), 0, 0, time.Local)
+}
+
+// DecodeAxes decodes the first n ODBPOS entries of cnc_rdposition. An entry
+// is four POSELM (data, dec, unit, disp, name, suff, reserve); the name and
+// decimal places are taken from the first. Unnamed axes are skipped.
+func DecodeAxes(buf []byte, n int) []Axis {
+	axes := make([]Axis, 0, n)
+	for i := 0; i < n; i++ {
+		elem := buf[i*odbposSize:]
+		if elem[10] == 0 {
+			continue
+		}
+		name := string(elem[10])
+		if elem[11] != 0 && elem[11] != ' ' {
+			name += string(elem[11])
+		}
+		axes = append(axes, Axis{Name: name, Decimals: int(int16(binary.LittleEndian.Uint16(elem[4:6])))})
+	}
+	return axes
+}
+
+// DecodeToolOffsets decodes n raw values of an IODBTO buffer: datano_s, type,
+// datano_e, 2 bytes of padding, data. Tip directions are 2 byte values, the
+// other offsets 4 bytes.
+func DecodeToolOffsets(buf []byte, n int, tip bool) []int32 {
+	values := make([]int32, 0, n)
+	for i := 0; i < n; i++ {
+		if tip {
+			values = append(values, int32(int16(binary.LittleEndian.Uint16(buf[8+2*i:]))))
+		} else {
+			values = append(values, int32(binary.LittleEndian.Uint32(buf[8+4*i:])))
+		}
+	}
+	return values
+}
+
+// DecodeWorkOffset decodes the values of axes axes of an IODBZOFS buffer:
+// datano, type, data.
+func DecodeWorkOffset(buf []byte, axes int) []int32 {
+	values := make([]int32, axes)
+	for i := range values {
+		values[i] = int32(binary.LittleEndian.Uint32(buf[4+4*i:]))
+	}
+	return values
+}
+
+// EncodeWorkOffset builds the IODBZOFS buffer of cnc_wrzofs that sets one axis
+// (1-based) of work offset number.
+func EncodeWorkOffset(number, axis int, value int32) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint16(buf[0:], uint16(number))
+	binary.LittleEndian.PutUint16(buf[2:], uint16(axis))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(value))
+	return buf
+}
+
+// scaled returns mantissa / 10^places.
+func scaled(mantissa, places int32) float64 {
+	return float64(mantissa) / math.Pow(10, float64(places))
+}
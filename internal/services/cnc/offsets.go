@@ -0,0 +1,127 @@
+package cnc
+
+/*
+short cnc_rdposition(unsigned short h, short type, short *data_num, void *out);
+short cnc_rdtofsinfo(unsigned short h, void *out);
+short cnc_rdtofsr(unsigned short h, short s_number, short type, short e_number, short length, void *out);
+short cnc_wrtofs(unsigned short h, short number, short type, short length, long data);
+short cnc_rdzofsinfo(unsigned short h, short *num);
+short cnc_rdzofs(unsigned short h, short number, short axis, short length, void *out);
+short cnc_wrzofs(unsigned short h, short length, void *buf);
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+)
+
+const (
+	maxAxes = 32
+	// odbposSize is the size of ODBPOS: absolute, machine, relative and
+	// distance POSELM of 12 bytes each.
+	odbposSize = 48
+	// toolOffsetChunk bounds the offsets read by one cnc_rdtofsr call.
+	toolOffsetChunk = 50
+)
+
+// Axis is a controlled axis with the decimal places of its input unit.
+type Axis struct {
+	Name     string
+	Decimals int
+}
+
+// Axes reads the names and decimal places of the controlled axes.
+func (c *Client) Axes() ([]Axis, error) {
+	buf := make([]byte, maxAxes*odbposSize)
+	n := C.short(maxAxes)
+	err := c.call("cnc_rdposition", func(h uint16) int16 {
+		return int16(C.cnc_rdposition(C.ushort(h), -1, &n, unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeAxes(buf, int(n)), nil
+}
+
+// ToolOffsetInfo reads the tool offset memory type (0 A, 1 B, 2 C on a mill)
+// and the number of tool offsets.
+func (c *Client) ToolOffsetInfo() (memory, count int, err error) {
+	// ODBTLINF: ofs_type, use_no.
+	buf := make([]byte, 4)
+	err = c.call("cnc_rdtofsinfo", func(h uint16) int16 {
+		return int16(C.cnc_rdtofsinfo(C.ushort(h), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(int16(binary.LittleEndian.Uint16(buf[0:]))), int(int16(binary.LittleEndian.Uint16(buf[2:]))), nil
+}
+
+// ReadToolOffsets reads count raw tool offsets of type typ starting at number
+// start. Imaginary tool tip directions (tip) are 2 byte values, the others are
+// in the least input increment.
+func (c *Client) ReadToolOffsets(typ int16, tip bool, start, count int) ([]int32, error) {
+	size := 4
+	if tip {
+		size = 2
+	}
+
+	values := make([]int32, 0, count)
+	for s := start; s < start+count; s += toolOffsetChunk {
+		e := min(s+toolOffsetChunk, start+count) - 1
+		n := e - s + 1
+
+		// IODBTO, see DecodeToolOffsets.
+		buf := make([]byte, 8+n*size)
+		err := c.call(fmt.Sprintf("cnc_rdtofsr %d-%d type %d", s, e, typ), func(h uint16) int16 {
+			return int16(C.cnc_rdtofsr(C.ushort(h), C.short(s), C.short(typ), C.short(e), C.short(6+n*size), unsafe.Pointer(&buf[0])))
+		})
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, DecodeToolOffsets(buf, n, tip)...)
+	}
+	return values, nil
+}
+
+// WriteToolOffset writes a raw tool offset of type typ.
+func (c *Client) WriteToolOffset(number int, typ int16, value int32) error {
+	return c.call(fmt.Sprintf("cnc_wrtofs %d type %d", number, typ), func(h uint16) int16 {
+		return int16(C.cnc_wrtofs(C.ushort(h), C.short(number), C.short(typ), 8, C.long(value)))
+	})
+}
+
+// WorkOffsetCount reads the number of work coordinate systems, G54-G59 and
+// the extended ones, without the external offset.
+func (c *Client) WorkOffsetCount() (int, error) {
+	var n C.short
+	err := c.call("cnc_rdzofsinfo", func(h uint16) int16 {
+		return int16(C.cnc_rdzofsinfo(C.ushort(h), &n))
+	})
+	return int(n), err
+}
+
+// ReadWorkOffset reads the raw values of work offset number for axes axes:
+// 0 is the external offset, 1-6 G54-G59 and 7 on the extended offsets.
+func (c *Client) ReadWorkOffset(number, axes int) ([]int32, error) {
+	// IODBZOFS, see DecodeWorkOffset.
+	buf := make([]byte, 4+4*axes)
+	err := c.call(fmt.Sprintf("cnc_rdzofs %d", number), func(h uint16) int16 {
+		return int16(C.cnc_rdzofs(C.ushort(h), C.short(number), -1, C.short(len(buf)), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWorkOffset(buf, axes), nil
+}
+
+// WriteWorkOffset writes the raw value of one axis (1-based) of a work offset.
+func (c *Client) WriteWorkOffset(number, axis int, value int32) error {
+	buf := EncodeWorkOffset(number, axis, value)
+	return c.call(fmt.Sprintf("cnc_wrzofs %d axis %d", number, axis), func(h uint16) int16 {
+		return int16(C.cnc_wrzofs(C.ushort(h), C.short(len(buf)), unsafe.Pointer(&buf[0])))
+	})
+}
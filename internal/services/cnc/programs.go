@@ -0,0 +1,266 @@
+package cnc
+
+/*
+short cnc_rdprogdir3(unsigned short h, short type, int *top, short *num, void *out);
+short cnc_rdprgnum(unsigned short h, void *out);
+short cnc_rdseqnum(unsigned short h, void *out);
+short cnc_upstart(unsigned short h, short number);
+short cnc_upload(unsigned short h, void *out, unsigned short *length);
+short cnc_upend(unsigned short h);
+short cnc_dwnstart3(unsigned short h, short type);
+short cnc_download3(unsigned short h, int *length, char *data);
+short cnc_dwnend3(unsigned short h);
+short cnc_delete(unsigned short h, short number);
+short cnc_search(unsigned short h, short number);
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/iwtcode/fanucAdapter/focas/errcode"
+)
+
+const (
+	// MaxProgramNumber is the highest O number of a program in CNC memory.
+	MaxProgramNumber = 9999
+	// prgdir3Size is the size of PRGDIR3: number, length, page, comment[52]
+	// and two dates of six shorts.
+	prgdir3Size = 88
+	// programDirChunk bounds the entries read by one cnc_rdprogdir3 call.
+	programDirChunk = 10
+	// bufferRetries bounds the waits for a busy upload or download buffer.
+	bufferRetries = 200
+	bufferWait    = 10 * time.Millisecond
+)
+
+// Program is an entry of the program directory.
+type Program struct {
+	Number   int
+	Size     int // bytes
+	Comment  string
+	Modified time.Time // zero if the CNC does not keep it
+}
+
+// ProgramDirectory reads the programs in CNC memory in the order of their
+// numbers.
+func (c *Client) ProgramDirectory() ([]Program, error) {
+	var programs []Program
+	top := C.int(1)
+	for top <= MaxProgramNumber {
+		buf := make([]byte, programDirChunk*prgdir3Size)
+		n := C.short(programDirChunk)
+		// Type 2 reads the comment, the size and the dates.
+		err := c.call(fmt.Sprintf("cnc_rdprogdir3 %d", top), func(h uint16) int16 {
+			return int16(C.cnc_rdprogdir3(C.ushort(h), 2, &top, &n, unsafe.Pointer(&buf[0])))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < int(n); i++ {
+			p := DecodeProgramEntry(buf[i*prgdir3Size:])
+			programs = append(programs, p)
+			top = C.int(p.Number + 1)
+		}
+		if int(n) < programDirChunk {
+			break
+		}
+	}
+	return programs, nil
+}
+
+// ProgramEntry reads the directory entry of program number; found is false
+// if the program is not in CNC memory.
+func (c *Client) ProgramEntry(number int) (p Program, found bool, err error) {
+	buf := make([]byte, prgdir3Size)
+	top, n := C.int(number), C.short(1)
+	err = c.call(fmt.Sprintf("cnc_rdprogdir3 %d", number), func(h uint16) int16 {
+		return int16(C.cnc_rdprogdir3(C.ushort(h), 2, &top, &n, unsafe.Pointer(&buf[0])))
+	})
+	if err != nil || n < 1 {
+		return Program{Number: number}, false, err
+	}
+	// The directory answers with the next program when number is missing.
+	if p = DecodeProgramEntry(buf); p.Number != number {
+		return Program{Number: number}, false, nil
+	}
+	return p, true, nil
+}
+
+// ProgramNumbers reads the number of the running program and of the main
+// program selected for automatic operation.
+func (c *Client) ProgramNumbers() (running, main int, err error) {
+	// ODBPRO: dummy[2], data, mdata.
+	buf := make([]byte, 8)
+	err = c.call("cnc_rdprgnum", func(h uint16) int16 {
+		return int16(C.cnc_rdprgnum(C.ushort(h), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(int16(binary.LittleEndian.Uint16(buf[4:]))), int(int16(binary.LittleEndian.Uint16(buf[6:]))), nil
+}
+
+// SequenceNumber reads the sequence number (N) of the block executed last, 0
+// if the program has none up to there.
+func (c *Client) SequenceNumber() (int, error) {
+	// ODBSEQ: dummy[2], data.
+	buf := make([]byte, 8)
+	err := c.call("cnc_rdseqnum", func(h uint16) int16 {
+		return int16(C.cnc_rdseqnum(C.ushort(h), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(int32(binary.LittleEndian.Uint32(buf[4:]))), nil
+}
+
+// UploadProgram uploads the text of program number from CNC memory to w chunk
+// by chunk, framed by '%' lines like GetControlProgram. The upload stops at the first error
+// of w, which is returned.
+func (c *Client) UploadProgram(number int, w io.Writer) error {
+	f := &programFramer{w: w}
+	var werr error
+	err := c.call(fmt.Sprintf("cnc_upload O%04d", number), func(h uint16) int16 {
+		if rc := int16(C.cnc_upstart(C.ushort(h), C.short(number))); rc != errcode.EW_OK {
+			return rc
+		}
+		defer C.cnc_upend(C.ushort(h))
+
+		// ODBUP: dummy[2], data[256].
+		buf := make([]byte, 4+256)
+		for retries := 0; ; {
+			length := C.ushort(256)
+			rc := int16(C.cnc_upload(C.ushort(h), unsafe.Pointer(&buf[0]), &length))
+			if rc == errcode.EW_BUFFER {
+				if retries++; retries > bufferRetries {
+					return rc
+				}
+				time.Sleep(bufferWait)
+				continue
+			}
+			if rc != errcode.EW_OK {
+				return rc
+			}
+			retries = 0
+
+			done, err := f.write(buf[4 : 4+int(length)])
+			if err != nil {
+				werr = err
+				return errcode.EW_OK
+			}
+			if length == 0 || done {
+				return errcode.EW_OK
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if werr != nil {
+		return werr
+	}
+	return f.close()
+}
+
+// programFramer writes an uploaded program text as "%\n<text>\n%": NULs, the
+// leading '%' line and the trailing blanks are dropped, and the text ends with
+// the first '%' after it.
+type programFramer struct {
+	w       io.Writer
+	started bool   // the first character of the text was written
+	blank   []byte // blanks held back until more text follows
+	done    bool
+}
+
+// write passes the text in b on and reports whether the program ended.
+func (f *programFramer) write(b []byte) (bool, error) {
+	out := make([]byte, 0, len(b)+2)
+loop:
+	for _, ch := range b {
+		switch {
+		case ch == 0:
+		case !f.started:
+			if strings.IndexByte(" \t\r\n%", ch) < 0 {
+				f.started = true
+				out = append(out, '%', '\n', ch)
+			}
+		case ch == '%':
+			f.done = true
+			break loop
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			f.blank = append(f.blank, ch)
+		default:
+			out = append(out, f.blank...)
+			out = append(out, ch)
+			f.blank = f.blank[:0]
+		}
+	}
+	if len(out) > 0 {
+		if _, err := f.w.Write(out); err != nil {
+			return f.done, err
+		}
+	}
+	return f.done, nil
+}
+
+func (f *programFramer) close() error {
+	end := "\n%"
+	if !f.started {
+		end = "%\n" + end
+	}
+	_, err := io.WriteString(f.w, end)
+	return err
+}
+
+// WriteProgram downloads an NC program to CNC memory. The program number is
+// taken from the O number at the start of text.
+func (c *Client) WriteProgram(text string) error {
+	data := []byte("\n" + strings.Trim(text, " \t\r\n%") + "\n%")
+	return c.call("cnc_download3", func(h uint16) int16 {
+		if rc := int16(C.cnc_dwnstart3(C.ushort(h), 0)); rc != errcode.EW_OK {
+			return rc
+		}
+
+		for sent, retries := 0, 0; sent < len(data); {
+			length := C.int(len(data) - sent)
+			rc := int16(C.cnc_download3(C.ushort(h), &length, (*C.char)(unsafe.Pointer(&data[sent]))))
+			if rc == errcode.EW_BUFFER {
+				if retries++; retries > bufferRetries {
+					C.cnc_dwnend3(C.ushort(h))
+					return rc
+				}
+				time.Sleep(bufferWait)
+				continue
+			}
+			if rc != errcode.EW_OK {
+				C.cnc_dwnend3(C.ushort(h))
+				return rc
+			}
+			sent += int(length)
+			retries = 0
+		}
+		// The CNC reports a bad or duplicate program when the download ends.
+		return int16(C.cnc_dwnend3(C.ushort(h)))
+	})
+}
+
+// DeleteProgram deletes program number from CNC memory.
+func (c *Client) DeleteProgram(number int) error {
+	return c.call(fmt.Sprintf("cnc_delete O%04d", number), func(h uint16) int16 {
+		return int16(C.cnc_delete(C.ushort(h), C.short(number)))
+	})
+}
+
+// SelectProgram searches program number, making it the main program.
+func (c *Client) SelectProgram(number int) error {
+	return c.call(fmt.Sprintf("cnc_search O%04d", number), func(h uint16) int16 {
+		return int16(C.cnc_search(C.ushort(h), C.short(number)))
+	})
+}
@@ -0,0 +1,134 @@
+package cnc
+
+/*
+short cnc_statinfo(unsigned short h, void *out);
+short cnc_wrmacro(unsigned short h, short number, short length, long mcr_val, short dec_val);
+short pmc_wrpmcrng(unsigned short h, short length, void *buf);
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Status is the operation state of the CNC from cnc_statinfo.
+type Status struct {
+	TMMode int16 // 0 T, 1 M
+	Mode   int16 // aut: 0 MDI, 1 MEM, 3 EDIT, 4 HND, 5 JOG, 10 RMT, ...
+	Run    int16 // run: 0 reset, 1 STOP, 2 HOLD, 3 START, 4 MSTR
+}
+
+// Automatic reports whether a program runs or can be started from memory or
+// a remote source.
+func (s Status) Automatic() bool {
+	return s.Mode == 1 || s.Mode == 10 || s.Run >= 2
+}
+
+// Turning reports whether the CNC runs as a lathe (T) rather than a mill (M).
+func (s Status) Turning() bool {
+	return s.TMMode == 0
+}
+
+func (s Status) String() string {
+	modes := map[int16]string{0: "MDI", 1: "MEM", 2: "****", 3: "EDIT", 4: "HND", 5: "JOG", 6: "T-JOG", 7: "T-HND", 8: "INC", 9: "REF", 10: "RMT"}
+	runs := map[int16]string{0: "RESET", 1: "STOP", 2: "HOLD", 3: "START", 4: "MSTR"}
+	mode, ok := modes[s.Mode]
+	if !ok {
+		mode = strconv.Itoa(int(s.Mode))
+	}
+	run, ok := runs[s.Run]
+	if !ok {
+		run = strconv.Itoa(int(s.Run))
+	}
+	return mode + "/" + run
+}
+
+// ReadStatus reads the mode and run state of the CNC.
+func (c *Client) ReadStatus() (Status, error) {
+	// ODBST, see DecodeStatus.
+	buf := make([]byte, 18)
+	err := c.call("cnc_statinfo", func(h uint16) int16 {
+		return int16(C.cnc_statinfo(C.ushort(h), unsafe.Pointer(&buf[0])))
+	})
+	if err != nil {
+		return Status{}, err
+	}
+	return DecodeStatus(buf), nil
+}
+
+// maxMacroDigits is the precision of a macro variable.
+const maxMacroDigits = 999999999
+
+// WriteMacros writes values to consecutive macro variables starting at number.
+func (c *Client) WriteMacros(number int, values []float64) error {
+	for i, v := range values {
+		n := number + i
+		mantissa, places, err := MacroValue(v)
+		if err != nil {
+			return err
+		}
+		err = c.call(fmt.Sprintf("cnc_wrmacro %d", n), func(h uint16) int16 {
+			return int16(C.cnc_wrmacro(C.ushort(h), C.short(n), 10, C.long(mantissa), C.short(places)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MacroValue splits v into the mantissa and decimal places of cnc_wrmacro. It
+// keeps the shortest decimal form of v, dropping places that do not fit nine
+// digits.
+func MacroValue(v float64) (int32, int16, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, 0, fmt.Errorf("%v is not a macro value", v)
+	}
+
+	places := 0
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		places = min(len(s)-i-1, 8)
+	}
+	for ; places >= 0; places-- {
+		if m := math.Round(v * math.Pow(10, float64(places))); math.Abs(m) <= maxMacroDigits {
+			return int32(m), int16(places), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%v does not fit a macro variable", v)
+}
+
+// WritePMC writes values of type t to a PMC area starting at the byte address
+// start.
+func (c *Client) WritePMC(area string, start int, t DataType, values []float64) error {
+	code, ok := pmcAreas[area]
+	if !ok {
+		return fmt.Errorf("unknown PMC area %q", area)
+	}
+	end := start + len(values)*t.PMCSize() - 1
+	buf := EncodePMC(code, t, start, values)
+
+	return c.call(fmt.Sprintf("pmc_wrpmcrng %s%d-%d", area, start, end), func(h uint16) int16 {
+		return int16(C.pmc_wrpmcrng(C.ushort(h), C.short(len(buf)), unsafe.Pointer(&buf[0])))
+	})
+}
+
+// PMCRange reports whether v can be written as a PMC value of type t. Bytes
+// are unsigned like ReadPMC returns them.
+func PMCRange(t DataType, v float64) bool {
+	switch t {
+	case Byte:
+		return v == math.Trunc(v) && v >= 0 && v <= math.MaxUint8
+	case Word:
+		return v == math.Trunc(v) && v >= math.MinInt16 && v <= math.MaxInt16
+	case DWord:
+		return v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32
+	case Real:
+		return !math.IsNaN(v) && math.Abs(v) <= math.MaxFloat32
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+// Package diff compares program texts line by line.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around a change.
+const DefaultContext = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op is a step of an edit script; x and y are the line indexes in a and b
+// before the step.
+type op struct {
+	kind opKind
+	x, y int
+}
+
+// Unified returns the unified diff turning text a into text b, with context
+// unchanged lines around every change. It is empty when the texts have the
+// same lines.
+func Unified(fromName, toName, a, b string, context int) string {
+	la, lb := Lines(a), Lines(b)
+	ops := edits(la, lb)
+
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		// The hunk takes the changes separated by at most 2*context equal lines.
+		start, end := max(0, i-context), i+1
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind != opEqual {
+				end = j + 1
+			} else if j-end+1 > 2*context {
+				break
+			}
+		}
+		stop := min(len(ops), end+context)
+		writeHunk(&sb, ops[start:stop], la, lb)
+		i = stop
+	}
+	return sb.String()
+}
+
+// Lines splits a text into lines, ignoring the line ending style and a final
+// line break.
+func Lines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+func writeHunk(sb *strings.Builder, ops []op, a, b []string) {
+	var lenA, lenB int
+	for _, o := range ops {
+		if o.kind != opInsert {
+			lenA++
+		}
+		if o.kind != opDelete {
+			lenB++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(ops[0].x, lenA), hunkRange(ops[0].y, lenB))
+
+	for _, o := range ops {
+		line := ""
+		if o.kind == opInsert {
+			line = b[o.y]
+		} else {
+			line = a[o.x]
+		}
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+}
+
+// hunkRange formats a 1-based line range; an empty range names the line
+// before it.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// edits returns the shortest edit script turning a into b. The common head
+// and tail are matched first, the rest with the Myers algorithm.
+func edits(a, b []string) []op {
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < head; i++ {
+		ops = append(ops, op{opEqual, i, i})
+	}
+	for _, o := range myers(a[head:len(a)-tail], b[head:len(b)-tail]) {
+		ops = append(ops, op{o.kind, o.x + head, o.y + head})
+	}
+	for i := 0; i < tail; i++ {
+		ops = append(ops, op{opEqual, len(a) - tail + i, len(b) - tail + i})
+	}
+	return ops
+}
+
+func myers(a, b []string) []op {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k; trace keeps v as
+	// it was before every round d for the backtrack.
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{opEqual, x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, op{opInsert, x, prevY})
+			} else {
+				ops = append(ops, op{opDelete, prevX, y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,49 @@
+package fanuc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/services/calls"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+)
+
+// callClient runs a driver call on the machine handle with the configured call
+// timeout. A handle whose call was abandoned is dropped from the pool and closed
+// once the call returns, so it is never used concurrently.
+func (s *Service) callClient(ctx context.Context, id string, client *cnc.Client, op string, fn func(*cnc.Client) error) error {
+	return s.callClientTimeout(ctx, id, client, op, s.cfg.Focas.CallTimeout, fn)
+}
+
+// callClientTimeout is callClient with another deadline than the call timeout,
+// for a call that moves a whole program.
+func (s *Service) callClientTimeout(ctx context.Context, id string, client *cnc.Client, op string, timeout time.Duration, fn func(*cnc.Client) error) error {
+	ctx, cancel := callContext(ctx, timeout)
+	defer cancel()
+
+	err := s.calls.Run(ctx, op, func() error { return fn(client) }, client.Close)
+	if errors.Is(err, calls.ErrAbandoned) {
+		s.clients.CompareAndDelete(id, client)
+	}
+	return err
+}
+
+// dropClient removes a handle whose call failed from the pool and closes it.
+// An abandoned handle is left to callClient, which closes it once its call
+// returns; a handle another caller removed meanwhile is closed by that caller.
+func (s *Service) dropClient(id string, client *cnc.Client, err error) {
+	if errors.Is(err, calls.ErrAbandoned) {
+		return
+	}
+	if s.clients.CompareAndDelete(id, client) {
+		client.Close()
+	}
+}
+
+func callContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,71 @@
+package fanuc
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
+)
+
+// AdoptMachine takes over a machine this instance just got the lease of, the
+// way RestoreConnections does on a single instance: polling machines start
+// polling, static ones are checked once.
+func (s *Service) AdoptMachine(ctx context.Context, machine entities.Machine) {
+	if machine.Mode == entities.ModePolling {
+		s.SyncMachine(ctx, machine.ID)
+		return
+	}
+	if _, connected := s.clients.Load(machine.ID); !connected {
+		go s.checkOneOnce(machine)
+	}
+}
+
+// SyncMachine starts or stops the poll routine of an owned machine to match
+// its persisted mode, which any instance may have changed.
+func (s *Service) SyncMachine(ctx context.Context, machineID string) {
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	machine, err := s.repo.GetByID(machineID)
+	if err != nil {
+		return
+	}
+
+	_, polling := s.pollingCancel.Load(machineID)
+	switch {
+	case machine.Mode == entities.ModePolling && !polling:
+		s.logger.Infof("Machine %s is in Polling mode. Starting polling routine...", machineID)
+		s.startPollingLocked(machineID, machine.Interval)
+	case machine.Mode != entities.ModePolling && polling:
+		s.stopPollingLocked(machineID)
+		s.logger.Infof("Polling stopped for machine %s (mode changed on another instance)", machineID)
+	}
+}
+
+// ReleaseMachine hands a machine over to another instance: it stops polling
+// without touching the persisted mode and closes the FOCAS handle.
+func (s *Service) ReleaseMachine(ctx context.Context, machineID string) error {
+	s.pollMu.Lock()
+	s.stopPollingLocked(machineID)
+	s.pollMu.Unlock()
+
+	if _, ok := s.clients.Load(machineID); !ok {
+		return nil
+	}
+	err := s.workers.Do(ctx, machineID, workers.Interactive, func() error {
+		if val, ok := s.clients.LoadAndDelete(machineID); ok {
+			val.(*cnc.Client).Close()
+		}
+		return nil
+	})
+	s.workers.Stop(machineID)
+	return err
+}
+
+// stopPollingLocked cancels the poll routine, if any. pollMu must be held.
+func (s *Service) stopPollingLocked(machineID string) {
+	if val, ok := s.pollingCancel.LoadAndDelete(machineID); ok {
+		val.(context.CancelFunc)()
+	}
+}
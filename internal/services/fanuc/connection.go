@@ -3,6 +3,7 @@ package fanuc
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	adapter "github.com/iwtcode/fanucAdapter"
 	"github.com/iwtcode/fanucService/internal/domain/entities"
 	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
 )
 
 func (s *Service) CreateConnection(ctx context.Context, req models.ConnectionRequest) (*entities.Machine, error) {
@@ -49,7 +52,7 @@ func (s *Service) CreateConnection(ctx context.Context, req models.ConnectionReq
 		LogLevel:    s.cfg.Logger.AdapterLevel,
 	}
 
-	client, err := s.connectWithTimeout(adapterCfg)
+	client, err := s.connectWithTimeout(ctx, adapterCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to machine: %w", err)
 	}
@@ -60,6 +63,7 @@ func (s *Service) CreateConnection(ctx context.Context, req models.ConnectionReq
 		Timeout:   timeout,
 		Model:     model,
 		Series:    series,
+		Labels:    req.Labels,
 		Status:    entities.StatusConnected,
 		Mode:      entities.ModeStatic,
 		CreatedAt: time.Now(),
@@ -114,11 +118,20 @@ func (s *Service) DeleteConnection(ctx context.Context, id string) error {
 		s.pollingCancel.Delete(id)
 	}
 
-	if val, ok := s.clients.Load(id); ok {
-		client := val.(*adapter.Client)
-		client.Close()
-		s.clients.Delete(id)
-	}
+	// Close the handle on the machine worker so no operation is using it.
+	_ = s.workers.Do(ctx, id, workers.Interactive, func() error {
+		if val, ok := s.clients.Load(id); ok {
+			client := val.(*cnc.Client)
+			client.Close()
+			s.clients.Delete(id)
+		}
+		return nil
+	})
+	s.workers.Stop(id)
+	s.limiter.Forget(id)
+	s.trackers.Delete(id)
+	s.forgetDownloads(id, 0)
+	s.kafkaProducer.RemoveQueue(id)
 	s.logger.Infof("Deleted connection: %s", id)
 	return s.repo.Delete(id)
 }
@@ -129,11 +142,35 @@ func (s *Service) CheckConnection(ctx context.Context, id string) (*entities.Mac
 		return nil, err
 	}
 
-	var client *adapter.Client
+	if !s.membership.Owns(id) {
+		return machine, s.membership.NotOwnerError(id)
+	}
+
+	release, err := s.limiter.AcquireMachine(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = s.workers.Do(ctx, id, workers.Interactive, func() error {
+		return s.checkMachine(ctx, machine)
+	})
+	if err != nil {
+		return machine, err
+	}
+	return machine, nil
+}
+
+// checkMachine connects if needed and reads the machine state. It must run on
+// the machine worker. A check cancelled by the caller leaves the status as is.
+func (s *Service) checkMachine(ctx context.Context, machine *entities.Machine) error {
+	id := machine.ID
+
+	var client *cnc.Client
 	var inPool bool
 
 	if val, found := s.clients.Load(id); found {
-		client = val.(*adapter.Client)
+		client = val.(*cnc.Client)
 		inPool = true
 	}
 
@@ -147,51 +184,68 @@ func (s *Service) CheckConnection(ctx context.Context, id string) (*entities.Mac
 			LogLevel:    s.cfg.Logger.AdapterLevel,
 		}
 
-		client, err = s.connectWithTimeout(cfg)
+		var err error
+		client, err = s.connectWithTimeout(ctx, cfg)
 		if err != nil {
-			s.updateStatus(machine, entities.StatusReconnecting)
-			return machine, fmt.Errorf("machine unreachable: %w", err)
+			if ctx.Err() == nil {
+				s.updateStatus(machine, entities.StatusReconnecting)
+			}
+			return fmt.Errorf("machine unreachable: %w", err)
 		}
 		s.clients.Store(id, client)
 	}
 
-	checkErrChan := make(chan error, 1)
-	go func() {
-		_, err := client.GetMachineState()
-		checkErrChan <- err
-	}()
+	checkCtx, cancel := context.WithTimeout(ctx, HardConnectionTimeout)
+	defer cancel()
 
-	select {
-	case err := <-checkErrChan:
-		if err != nil {
-			client.Close()
-			s.clients.Delete(id)
+	err := s.callClient(checkCtx, id, client, "health check "+id, func(c *cnc.Client) error {
+		_, err := c.GetMachineState()
+		return err
+	})
+	if err != nil {
+		s.dropClient(id, client, err)
+		if ctx.Err() == nil {
 			s.updateStatus(machine, entities.StatusReconnecting)
-			return machine, fmt.Errorf("health check failed: %w", err)
 		}
-	case <-time.After(HardConnectionTimeout):
-		s.updateStatus(machine, entities.StatusReconnecting)
-		return machine, fmt.Errorf("health check timed out")
+		return fmt.Errorf("health check failed: %w", err)
 	}
 
 	s.updateStatus(machine, entities.StatusConnected)
-
-	return machine, nil
+	return nil
 }
 
 func (s *Service) updateStatus(m *entities.Machine, status string) {
 	if m.Status != status {
+		previous := m.Status
 		m.Status = status
 		m.UpdatedAt = time.Now()
 		_ = s.repo.Update(m)
+		s.publishStatusEvent(m, models.StatusChanged, previous, status)
 	}
 }
 
 func (s *Service) updateMode(m *entities.Machine, mode string) {
 	if m.Mode != mode {
+		previous := m.Mode
 		m.Mode = mode
 		m.UpdatedAt = time.Now()
 		_ = s.repo.Update(m)
+		s.publishStatusEvent(m, models.ModeChanged, previous, mode)
+	}
+}
+
+func (s *Service) publishStatusEvent(m *entities.Machine, eventType, previous, current string) {
+	event := &models.StatusEvent{
+		Type:      eventType,
+		MachineID: m.ID,
+		Endpoint:  m.Endpoint,
+		Labels:    m.Labels,
+		Previous:  previous,
+		Current:   current,
+		Timestamp: m.UpdatedAt,
+	}
+	if err := s.kafkaProducer.SendStatusEvent(context.Background(), event); err != nil {
+		s.logger.Errorf("Failed to send status event to Kafka for %s: %v", m.ID, err)
 	}
 }
 
@@ -202,3 +256,12 @@ func (s *Service) updateInterval(m *entities.Machine, interval int) {
 		_ = s.repo.Update(m)
 	}
 }
+
+func (s *Service) updatePollOptions(m *entities.Machine, reads []entities.DataAddress, watchOffsets bool) {
+	if !slices.Equal(m.Reads, reads) || m.WatchOffsets != watchOffsets {
+		m.Reads = reads
+		m.WatchOffsets = watchOffsets
+		m.UpdatedAt = time.Now()
+		_ = s.repo.Update(m)
+	}
+}
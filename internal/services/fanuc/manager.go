@@ -2,7 +2,6 @@ package fanuc
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"strconv"
 	"sync"
@@ -11,7 +10,12 @@ import (
 	adapter "github.com/iwtcode/fanucAdapter"
 	"github.com/iwtcode/fanucService"
 	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/calls"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
 	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"github.com/iwtcode/fanucService/internal/services/workers"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,26 +28,57 @@ const (
 type Service struct {
 	cfg           *fanucService.Config
 	repo          interfaces.Repository
+	versions      interfaces.ProgramVersionRepository
 	kafkaProducer *kafka.Producer
+	limiter       *ratelimit.Limiter
+	membership    *cluster.Membership
 	logger        *logrus.Logger
 	clients       sync.Map
 	pollingCancel sync.Map
-}
+	watchers      watchHub
+	workers       workers.Pool
+	calls         *calls.Tracker
+	trackers      sync.Map // machine ID -> *programTracker
+	downloads     sync.Map // programKey -> programDownload
 
-type connectResult struct {
-	client *adapter.Client
-	err    error
+	pollers sync.WaitGroup
+	pollMu  sync.Mutex // serializes starting and stopping poll routines
+	closing bool       // set on shutdown, no poll routines start afterwards
 }
 
-func NewService(cfg *fanucService.Config, repo interfaces.Repository, producer *kafka.Producer, logger *logrus.Logger) interfaces.FanucService {
+func NewService(
+	cfg *fanucService.Config,
+	repo interfaces.Repository,
+	versions interfaces.ProgramVersionRepository,
+	producer *kafka.Producer,
+	limiter *ratelimit.Limiter,
+	membership *cluster.Membership,
+	logger *logrus.Logger,
+) interfaces.FanucService {
 	return &Service{
 		cfg:           cfg,
 		repo:          repo,
+		versions:      versions,
 		kafkaProducer: producer,
+		limiter:       limiter,
+		membership:    membership,
+		calls:         calls.NewTracker(cfg.Focas.MaxHungCalls, logger),
 		logger:        logger,
 	}
 }
 
+// acquireMachine reserves a CNC call slot of a known machine owned by this
+// instance. Unknown IDs are rejected before they reach the limiter.
+func (s *Service) acquireMachine(id string) (func(), error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	if !s.membership.Owns(id) {
+		return nil, s.membership.NotOwnerError(id)
+	}
+	return s.limiter.AcquireMachine(id)
+}
+
 func parseEndpoint(endpoint string) (string, uint16, error) {
 	host, portStr, err := net.SplitHostPort(endpoint)
 	if err != nil {
@@ -56,32 +91,24 @@ func parseEndpoint(endpoint string) (string, uint16, error) {
 	return host, uint16(port), nil
 }
 
-func (s *Service) connectWithTimeout(cfg *adapter.Config) (*adapter.Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), HardConnectionTimeout)
+// connectWithTimeout opens a FOCAS handle within HardConnectionTimeout or the
+// ctx deadline. A connection completed after the caller gave up is closed.
+func (s *Service) connectWithTimeout(ctx context.Context, cfg *adapter.Config) (*cnc.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, HardConnectionTimeout)
 	defer cancel()
 
-	resultCh := make(chan connectResult, 1)
-
-	go func() {
-		client, err := adapter.New(cfg)
-
-		if ctx.Err() != nil {
-			if client != nil {
-				client.Close()
-			}
-			return
+	var client *cnc.Client
+	err := s.calls.Run(ctx, "connect "+net.JoinHostPort(cfg.IP, strconv.Itoa(int(cfg.Port))), func() error {
+		var err error
+		client, err = cnc.Connect(cfg)
+		return err
+	}, func() {
+		if client != nil {
+			client.Close()
 		}
-
-		resultCh <- connectResult{client: client, err: err}
-	}()
-
-	select {
-	case res := <-resultCh:
-		if res.err != nil {
-			return nil, res.err
-		}
-		return res.client, nil
-	case <-ctx.Done():
-		return nil, fmt.Errorf("hard timeout: failed to connect within %v", HardConnectionTimeout)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return client, nil
 }
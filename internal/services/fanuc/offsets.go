@@ -0,0 +1,531 @@
+package fanuc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+)
+
+// toolOffsetType is a tool offset type and its cnc_rdtofsr type code.
+type toolOffsetType struct {
+	name string
+	code int16
+	tip  bool // imaginary tool tip direction, an integer 0-9
+}
+
+// millOffsetTypes are the tool offset types of a mill by offset memory.
+var millOffsetTypes = [][]toolOffsetType{
+	// A: one value for wear and geometry, length and radius.
+	{{name: "offset", code: 0}},
+	// B: geometry and wear, length and radius.
+	{{name: "wear", code: 0}, {name: "geometry", code: 1}},
+	// C: geometry and wear, separate length and radius.
+	{
+		{name: "radius_wear", code: 0}, {name: "radius_geometry", code: 1},
+		{name: "length_wear", code: 2}, {name: "length_geometry", code: 3},
+	},
+}
+
+// latheOffsetTypes are the tool offset types of a lathe. Y offsets need an
+// option and are left out when the CNC rejects them.
+var latheOffsetTypes = []toolOffsetType{
+	{name: "x_wear", code: 0}, {name: "x_geometry", code: 1},
+	{name: "z_wear", code: 2}, {name: "z_geometry", code: 3},
+	{name: "radius_wear", code: 4}, {name: "radius_geometry", code: 5},
+	{name: "tip_wear", code: 6, tip: true}, {name: "tip_geometry", code: 7, tip: true},
+	{name: "y_wear", code: 8}, {name: "y_geometry", code: 9},
+}
+
+// minWorkOffsets is G54-G59, always present.
+const minWorkOffsets = 6
+
+// toolOffsetLayout describes the tool offsets of a machine.
+type toolOffsetLayout struct {
+	turning  bool
+	memory   string
+	count    int
+	decimals int
+	types    []toolOffsetType
+}
+
+func (s *Service) ReadToolOffsets(ctx context.Context, id string) (*models.ToolOffsets, error) {
+	var result *models.ToolOffsets
+	err := s.interactiveCall(ctx, id, "read tool offsets "+id, func(c *cnc.Client) error {
+		var err error
+		result, err = readToolOffsets(c)
+		return err
+	})
+	return result, err
+}
+
+func (s *Service) ReadWorkOffsets(ctx context.Context, id string) (*models.WorkOffsets, error) {
+	var result *models.WorkOffsets
+	err := s.interactiveCall(ctx, id, "read work offsets "+id, func(c *cnc.Client) error {
+		var err error
+		result, err = readWorkOffsets(c)
+		return err
+	})
+	return result, err
+}
+
+// WriteToolOffset sets one tool offset value and returns it as read back. An
+// incremental write adds the value to the current one, like the +INPUT key.
+// Like WriteData, the value written must be allowed by a tool_offset write
+// rule and the write is refused in automatic operation unless req.Override is
+// set.
+func (s *Service) WriteToolOffset(ctx context.Context, req models.ToolOffsetWriteRequest) (*models.OffsetChange, error) {
+	req.Type = normalizeOffsetName(entities.DataToolOffset, req.Type)
+	if req.Number < 1 {
+		return nil, fmt.Errorf("%w: number must be positive", models.ErrBadRequest)
+	}
+	if err := checkOffsetValue(req.Value); err != nil {
+		return nil, err
+	}
+	machine, err := s.repo.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &models.OffsetChange{Number: req.Number, Offset: req.Type}
+	err = s.interactiveCall(ctx, req.ID, "write tool offset "+req.ID, func(c *cnc.Client) error {
+		status, err := c.ReadStatus()
+		if err != nil {
+			return err
+		}
+		if change.Overridden, err = CheckInterlock(status, req.Override); err != nil {
+			return err
+		}
+
+		layout, err := readToolOffsetLayout(c)
+		if err != nil {
+			return err
+		}
+		t, ok := layout.find(req.Type)
+		if !ok {
+			return fmt.Errorf("%w: unknown tool offset type %q, the machine has %s", models.ErrBadRequest, req.Type, strings.Join(layout.names(), ", "))
+		}
+		if req.Number > layout.count {
+			return fmt.Errorf("%w: tool offset number must be between 1 and %d", models.ErrBadRequest, layout.count)
+		}
+		decimals := layout.decimals
+		if t.tip {
+			decimals = 0
+		}
+
+		previous, err := c.ReadToolOffsets(t.code, t.tip, req.Number, 1)
+		if err != nil {
+			return asBadRequest(err)
+		}
+		change.Previous = scaleOffset(previous[0], decimals)
+
+		value := req.Value
+		if req.Incremental {
+			value += change.Previous
+		}
+		if t.tip && (value != math.Trunc(value) || value < 0 || value > 9) {
+			return fmt.Errorf("%w: tip direction must be an integer between 0 and 9", models.ErrBadRequest)
+		}
+		if err := CheckOffsetRule(machine.WriteRules, entities.DataToolOffset, req.Number, req.Type, value); err != nil {
+			return err
+		}
+		raw, err := rawOffset(value, decimals)
+		if err != nil {
+			return err
+		}
+		if err := c.WriteToolOffset(req.Number, t.code, raw); err != nil {
+			return asBadRequest(err)
+		}
+
+		current, err := c.ReadToolOffsets(t.code, t.tip, req.Number, 1)
+		if err != nil {
+			return err
+		}
+		change.Value = scaleOffset(current[0], decimals)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logOffsetChange(fmt.Sprintf("Tool offset %d %s", change.Number, change.Offset), change, req.ID)
+	return change, nil
+}
+
+// WriteWorkOffset sets the value of one axis of a work coordinate system and
+// returns it as read back. The write rules and the interlock apply like in
+// WriteToolOffset, with work_offset rules.
+func (s *Service) WriteWorkOffset(ctx context.Context, req models.WorkOffsetWriteRequest) (*models.OffsetChange, error) {
+	if req.Number < 0 {
+		return nil, fmt.Errorf("%w: number must not be negative", models.ErrBadRequest)
+	}
+	if err := checkOffsetValue(req.Value); err != nil {
+		return nil, err
+	}
+	machine, err := s.repo.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &models.OffsetChange{Number: req.Number, Name: workOffsetName(req.Number)}
+	err = s.interactiveCall(ctx, req.ID, "write work offset "+req.ID, func(c *cnc.Client) error {
+		status, err := c.ReadStatus()
+		if err != nil {
+			return err
+		}
+		if change.Overridden, err = CheckInterlock(status, req.Override); err != nil {
+			return err
+		}
+
+		axes, err := c.Axes()
+		if err != nil {
+			return err
+		}
+		axis := -1
+		for i, a := range axes {
+			if strings.EqualFold(a.Name, strings.TrimSpace(req.Axis)) {
+				axis = i
+				break
+			}
+		}
+		if axis < 0 {
+			return fmt.Errorf("%w: unknown axis %q", models.ErrBadRequest, req.Axis)
+		}
+		change.Offset = axes[axis].Name
+
+		count, err := workOffsetCount(c)
+		if err != nil {
+			return err
+		}
+		if req.Number > count {
+			return fmt.Errorf("%w: work offset number must be between 0 and %d", models.ErrBadRequest, count)
+		}
+
+		previous, err := c.ReadWorkOffset(req.Number, len(axes))
+		if err != nil {
+			return asBadRequest(err)
+		}
+		decimals := axes[axis].Decimals
+		change.Previous = scaleOffset(previous[axis], decimals)
+
+		value := req.Value
+		if req.Incremental {
+			value += change.Previous
+		}
+		if err := CheckOffsetRule(machine.WriteRules, entities.DataWorkOffset, req.Number, change.Offset, value); err != nil {
+			return err
+		}
+		raw, err := rawOffset(value, decimals)
+		if err != nil {
+			return err
+		}
+		if err := c.WriteWorkOffset(req.Number, axis+1, raw); err != nil {
+			return asBadRequest(err)
+		}
+
+		current, err := c.ReadWorkOffset(req.Number, len(axes))
+		if err != nil {
+			return err
+		}
+		change.Value = scaleOffset(current[axis], decimals)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logOffsetChange(fmt.Sprintf("Work offset %s %s", change.Name, change.Offset), change, req.ID)
+	return change, nil
+}
+
+// CheckOffsetRule requires a write rule of class (tool_offset or work_offset)
+// that covers the offset number and its type or axis, and value to lie within
+// its range.
+func CheckOffsetRule(rules []entities.WriteRule, class string, number int, offset string, value float64) error {
+	for _, r := range rules {
+		if r.Class != class || number < r.Number || number > ruleEnd(r, r.Type) {
+			continue
+		}
+		if r.Offset != "" && !strings.EqualFold(r.Offset, offset) {
+			continue
+		}
+		if value < r.Min || value > r.Max {
+			return fmt.Errorf("%w: %s %d %s value %v is outside the allowed range %v..%v", models.ErrForbidden, class, number, offset, value, r.Min, r.Max)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s %d %s is not allowed for writing", models.ErrForbidden, class, number, offset)
+}
+
+// normalizeOffsetName lowers tool offset types and raises axis names.
+func normalizeOffsetName(class, name string) string {
+	name = strings.TrimSpace(name)
+	if class == entities.DataWorkOffset {
+		return strings.ToUpper(name)
+	}
+	return strings.ToLower(name)
+}
+
+func (s *Service) logOffsetChange(what string, change *models.OffsetChange, id string) {
+	if change.Overridden {
+		s.logger.Warnf("%s of machine %s set with the interlock overridden: %v -> %v", what, id, change.Previous, change.Value)
+		return
+	}
+	s.logger.Infof("%s of machine %s set: %v -> %v", what, id, change.Previous, change.Value)
+}
+
+func readToolOffsetLayout(c *cnc.Client) (*toolOffsetLayout, error) {
+	status, err := c.ReadStatus()
+	if err != nil {
+		return nil, err
+	}
+	memory, count, err := c.ToolOffsetInfo()
+	if err != nil {
+		return nil, err
+	}
+	axes, err := c.Axes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Tool offsets are in the input unit of the first axis.
+	layout := &toolOffsetLayout{turning: status.Turning(), count: count, decimals: 3}
+	if len(axes) > 0 {
+		layout.decimals = axes[0].Decimals
+	}
+	if layout.turning {
+		layout.types = latheOffsetTypes
+	} else {
+		if memory < 0 || memory >= len(millOffsetTypes) {
+			return nil, fmt.Errorf("unknown tool offset memory %d", memory)
+		}
+		layout.memory = string(rune('A' + memory))
+		layout.types = millOffsetTypes[memory]
+	}
+	return layout, nil
+}
+
+func (l *toolOffsetLayout) find(name string) (toolOffsetType, bool) {
+	for _, t := range l.types {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return toolOffsetType{}, false
+}
+
+func (l *toolOffsetLayout) names() []string {
+	names := make([]string, 0, len(l.types))
+	for _, t := range l.types {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// readToolOffsets reads the whole tool offset table. Types the CNC rejects
+// (a missing option) are left out.
+func readToolOffsets(c *cnc.Client) (*models.ToolOffsets, error) {
+	layout, err := readToolOffsetLayout(c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ToolOffsets{
+		Turning: layout.turning,
+		Memory:  layout.memory,
+		Types:   []string{},
+		Offsets: make([]models.ToolOffset, layout.count),
+	}
+	for i := range result.Offsets {
+		result.Offsets[i] = models.ToolOffset{Number: i + 1, Values: map[string]float64{}}
+	}
+	if layout.count == 0 {
+		return result, nil
+	}
+
+	for _, t := range layout.types {
+		raw, err := c.ReadToolOffsets(t.code, t.tip, 1, layout.count)
+		if rejected(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		decimals := layout.decimals
+		if t.tip {
+			decimals = 0
+		}
+		result.Types = append(result.Types, t.name)
+		for i, v := range raw {
+			result.Offsets[i].Values[t.name] = scaleOffset(v, decimals)
+		}
+	}
+	return result, nil
+}
+
+// readWorkOffsets reads the external offset, G54-G59 and the extended work
+// coordinate systems.
+func readWorkOffsets(c *cnc.Client) (*models.WorkOffsets, error) {
+	axes, err := c.Axes()
+	if err != nil {
+		return nil, err
+	}
+	count, err := workOffsetCount(c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WorkOffsets{Axes: make([]string, 0, len(axes)), Offsets: make([]models.WorkOffset, 0, count+1)}
+	for _, a := range axes {
+		result.Axes = append(result.Axes, a.Name)
+	}
+	for n := 0; n <= count; n++ {
+		raw, err := c.ReadWorkOffset(n, len(axes))
+		if rejected(err) && n > minWorkOffsets {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		offset := models.WorkOffset{Number: n, Name: workOffsetName(n), Values: make(map[string]float64, len(axes))}
+		for i, a := range axes {
+			offset.Values[a.Name] = scaleOffset(raw[i], a.Decimals)
+		}
+		result.Offsets = append(result.Offsets, offset)
+	}
+	return result, nil
+}
+
+// workOffsetCount is the number of work coordinate systems without the
+// external offset. Controls without cnc_rdzofsinfo have G54-G59 only.
+func workOffsetCount(c *cnc.Client) (int, error) {
+	count, err := c.WorkOffsetCount()
+	if rejected(err) {
+		return minWorkOffsets, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return max(count, minWorkOffsets), nil
+}
+
+// workOffsetName is the G code of work offset number n.
+func workOffsetName(n int) string {
+	switch {
+	case n == 0:
+		return "EXT"
+	case n <= minWorkOffsets:
+		return fmt.Sprintf("G%d", 53+n)
+	default:
+		return fmt.Sprintf("G54.1 P%d", n-minWorkOffsets)
+	}
+}
+
+func checkOffsetValue(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: value must be a number", models.ErrBadRequest)
+	}
+	return nil
+}
+
+func scaleOffset(raw int32, decimals int) float64 {
+	return float64(raw) / math.Pow(10, float64(decimals))
+}
+
+// rawOffset converts v to the least input increment of an offset.
+func rawOffset(v float64, decimals int) (int32, error) {
+	raw := math.Round(v * math.Pow(10, float64(decimals)))
+	if raw < math.MinInt32 || raw > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: value %v is out of range", models.ErrBadRequest, v)
+	}
+	return int32(raw), nil
+}
+
+// rejected reports whether the CNC refused a request, see cnc.Error.Rejected.
+func rejected(err error) bool {
+	var rc *cnc.Error
+	return errors.As(err, &rc) && rc.Rejected()
+}
+
+// asBadRequest makes a request the CNC refused a bad request.
+func asBadRequest(err error) error {
+	if rejected(err) {
+		return fmt.Errorf("%w: %v", models.ErrBadRequest, err)
+	}
+	return err
+}
+
+// offsetTables are the offsets of a machine seen by the previous poll.
+type offsetTables struct {
+	tool *models.ToolOffsets
+	work *models.WorkOffsets
+}
+
+// readOffsetTables reads both offset tables for offset watching.
+func readOffsetTables(c *cnc.Client) (*offsetTables, error) {
+	tool, err := readToolOffsets(c)
+	if err != nil {
+		return nil, err
+	}
+	work, err := readWorkOffsets(c)
+	if err != nil {
+		return nil, err
+	}
+	return &offsetTables{tool: tool, work: work}, nil
+}
+
+// publishOffsetEvents emits an event for every offset value that differs
+// between two polls. The first poll of a run only sets the baseline.
+func (s *Service) publishOffsetEvents(ctx context.Context, m *entities.Machine, previous, current *offsetTables, at time.Time) {
+	if previous == nil || current == nil {
+		return
+	}
+	send := func(eventType string, number int, name, offset string, before, after float64) {
+		event := &models.OffsetEvent{
+			Type:      eventType,
+			MachineID: m.ID,
+			Endpoint:  m.Endpoint,
+			Labels:    m.Labels,
+			Number:    number,
+			Name:      name,
+			Offset:    offset,
+			Previous:  before,
+			Current:   after,
+			Timestamp: at,
+		}
+		if err := s.kafkaProducer.SendOffsetEvent(ctx, event); err != nil {
+			s.logger.Errorf("Failed to send offset event to Kafka for %s: %v", m.ID, err)
+		}
+	}
+
+	for i, offset := range current.tool.Offsets {
+		if i >= len(previous.tool.Offsets) {
+			break
+		}
+		for _, t := range current.tool.Types {
+			before, ok := previous.tool.Offsets[i].Values[t]
+			if after := offset.Values[t]; ok && before != after {
+				send(models.ToolOffsetChanged, offset.Number, "", t, before, after)
+			}
+		}
+	}
+	for i, offset := range current.work.Offsets {
+		if i >= len(previous.work.Offsets) {
+			break
+		}
+		for _, axis := range current.work.Axes {
+			before, ok := previous.work.Offsets[i].Values[axis]
+			if after := offset.Values[axis]; ok && before != after {
+				send(models.WorkOffsetChanged, offset.Number, offset.Name, axis, before, after)
+			}
+		}
+	}
+}
@@ -2,15 +2,34 @@ package fanuc
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	adapter "github.com/iwtcode/fanucAdapter"
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucService"
 	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
 )
 
-func (s *Service) StartPolling(ctx context.Context, machineID string, intervalMs int) error {
+// errReconnect makes the poll loop wait before retrying an unreachable machine.
+var errReconnect = errors.New("machine unreachable")
+
+func (s *Service) StartPolling(ctx context.Context, req models.StartPollingRequest) error {
+	machineID, intervalMs := req.ID, req.Interval
+	reads, err := NormalizeReads(req.Reads)
+	if err != nil {
+		return err
+	}
+	req.Reads = reads
+
+	if !s.membership.Owns(machineID) {
+		return s.requestMode(machineID, entities.ModePolling, &req)
+	}
+
 	if _, exists := s.pollingCancel.Load(machineID); exists {
 		return fmt.Errorf("polling already active for machine %s", machineID)
 	}
@@ -21,6 +40,7 @@ func (s *Service) StartPolling(ctx context.Context, machineID string, intervalMs
 	}
 
 	s.updateInterval(machine, intervalMs)
+	s.updatePollOptions(machine, req.Reads, req.WatchOffsets)
 	s.updateMode(machine, entities.ModePolling)
 	s.startPollingInternal(machineID, intervalMs)
 
@@ -28,17 +48,20 @@ func (s *Service) StartPolling(ctx context.Context, machineID string, intervalMs
 }
 
 func (s *Service) StopPolling(ctx context.Context, machineID string) error {
-	val, ok := s.pollingCancel.Load(machineID)
-	if !ok {
+	if !s.membership.Owns(machineID) {
+		return s.requestMode(machineID, entities.ModeStatic, nil)
+	}
+
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	if _, ok := s.pollingCancel.Load(machineID); !ok {
 		if machine, err := s.repo.GetByID(machineID); err == nil {
 			s.updateMode(machine, entities.ModeStatic)
 		}
 		return fmt.Errorf("polling not active for machine %s", machineID)
 	}
-
-	cancel := val.(context.CancelFunc)
-	cancel()
-	s.pollingCancel.Delete(machineID)
+	s.stopPollingLocked(machineID)
 
 	machine, err := s.repo.GetByID(machineID)
 	if err == nil {
@@ -49,64 +72,197 @@ func (s *Service) StopPolling(ctx context.Context, machineID string) error {
 	return nil
 }
 
+// requestMode persists the polling mode of a machine served by another
+// instance; its owner starts or stops polling on the next heartbeat. start
+// carries the polling settings when mode is polling.
+func (s *Service) requestMode(machineID, mode string, start *models.StartPollingRequest) error {
+	machine, err := s.repo.GetByID(machineID)
+	if err != nil {
+		return err
+	}
+	if start != nil {
+		s.updateInterval(machine, start.Interval)
+		s.updatePollOptions(machine, start.Reads, start.WatchOffsets)
+	}
+	s.updateMode(machine, mode)
+	s.logger.Infof("Polling mode %s of machine %s handed to its owner", mode, machineID)
+	return nil
+}
+
 func (s *Service) startPollingInternal(machineID string, intervalMs int) {
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+	s.startPollingLocked(machineID, intervalMs)
+}
+
+// startPollingLocked starts the poll routine unless it runs already. pollMu
+// must be held.
+func (s *Service) startPollingLocked(machineID string, intervalMs int) {
 	if intervalMs <= 0 {
 		intervalMs = 1000
 	}
+	if s.closing {
+		return
+	}
+	if _, exists := s.pollingCancel.Load(machineID); exists {
+		return
+	}
 
 	pollCtx, cancel := context.WithCancel(context.Background())
 	s.pollingCancel.Store(machineID, cancel)
 
+	s.pollers.Add(1)
 	go s.pollRoutine(pollCtx, machineID, time.Duration(intervalMs)*time.Millisecond)
 }
 
 func (s *Service) pollRoutine(ctx context.Context, machineID string, interval time.Duration) {
+	defer s.pollers.Done()
 	s.logger.Infof("Polling routine started for machine %s with interval %v", machineID, interval)
 
 	timer := time.NewTimer(0)
 	defer timer.Stop()
 
+	// The queue of the polled data goes when polling stops.
+	var dataKey string
+	defer func() {
+		if dataKey != "" {
+			s.kafkaProducer.RemoveQueue(dataKey)
+		}
+	}()
+
+	var sequence uint64
+	var alarms []adapterModels.AlarmDetail
+	var offsets *offsetTables
+	var snapshotAt time.Time
+
 	for {
 		select {
 		case <-ctx.Done():
 			s.logger.Infof("Polling routine context cancelled for machine %s", machineID)
 			return
 		case <-timer.C:
+			if ctx.Err() != nil {
+				return
+			}
 			start := time.Now()
 
-			// 1. Get or Restore Client
-			client, err := s.getOrRestoreClient(machineID)
-			if err != nil {
-				s.logger.Warnf("Polling error for machine %s: %v. Status -> Reconnecting", machineID, err)
-				if m, dbErr := s.repo.GetByID(machineID); dbErr == nil {
-					s.updateStatus(m, entities.StatusReconnecting)
+			// 1-2. Get or restore the client and poll it on the machine worker,
+			// behind any queued interactive requests.
+			var (
+				machine   *entities.Machine
+				data      *adapterModels.AggregatedData
+				reads     []models.DataReading
+				tables    *offsetTables
+				program   *programSnapshot
+				exec      *execution
+				pollStart time.Time
+				finished  time.Time
+			)
+			err := s.workers.Do(ctx, machineID, workers.Polling, func() error {
+				client, err := s.getOrRestoreClient(ctx, machineID)
+				if err != nil {
+					if ctx.Err() != nil {
+						return err
+					}
+					s.logger.Warnf("Polling error for machine %s: %v. Status -> Reconnecting", machineID, err)
+					if m, dbErr := s.repo.GetByID(machineID); dbErr == nil {
+						s.updateStatus(m, entities.StatusReconnecting)
+					}
+					return errReconnect
+				}
+
+				m, dbErr := s.repo.GetByID(machineID)
+				if dbErr != nil {
+					s.logger.Errorf("Failed to load machine %s for polling, its data is sent without metadata: %v", machineID, dbErr)
+				} else if m.Status == entities.StatusReconnecting {
+					s.updateStatus(m, entities.StatusConnected)
+					s.logger.Infof("Machine %s reconnected during polling", machineID)
 				}
+				machine = m
+
+				pollStart = time.Now()
+				err = s.callClient(ctx, machineID, client, "poll "+machineID, func(c *cnc.Client) error {
+					var err error
+					data, err = c.GetCurrentData()
+					if err == nil {
+						if machine != nil {
+							reads = readAll(c, machine.Reads)
+						}
+						if machine != nil && machine.WatchOffsets {
+							var offsetErr error
+							if tables, offsetErr = readOffsetTables(c); offsetErr != nil {
+								s.logger.Warnf("Failed to read offsets of machine %s: %v", machineID, offsetErr)
+							}
+						}
+						if every := s.cfg.Programs.SnapshotInterval; every > 0 && time.Since(snapshotAt) >= every {
+							var programErr error
+							snapshotAt = time.Now()
+							if program, programErr = readProgramSnapshot(c); programErr != nil {
+								s.logger.Warnf("Failed to read the program of machine %s: %v", machineID, programErr)
+							}
+						}
+						if s.cfg.Programs.TrackPosition {
+							exec = trackExecution(c, data)
+							if t := s.tracker(machineID); program == nil && t.pollRead(exec.running) {
+								var programErr error
+								if program, programErr = readProgramSnapshot(c); programErr != nil {
+									t.readFailed(exec.running)
+									s.logger.Warnf("Failed to read the program of machine %s: %v", machineID, programErr)
+								}
+							}
+						}
+					}
+					return err
+				})
+				finished = time.Now()
+				if err != nil && ctx.Err() == nil {
+					s.logger.Errorf("Error getting data from machine %s: %v", machineID, err)
+					if machine != nil {
+						s.updateStatus(machine, entities.StatusReconnecting)
+					}
+					s.dropClient(machineID, client, err)
+				}
+				return err
+			})
+			if err != nil && ctx.Err() != nil {
+				// Stopping polling interrupts a poll waiting for the worker
+				// or for a hung call.
+				s.logger.Infof("Polling routine context cancelled for machine %s", machineID)
+				return
+			}
+			if errors.Is(err, errReconnect) {
 				timer.Reset(5 * time.Second)
 				continue
 			}
 
-			if m, dbErr := s.repo.GetByID(machineID); dbErr == nil && m.Status == entities.StatusReconnecting {
-				s.updateStatus(m, entities.StatusConnected)
-				s.logger.Infof("Machine %s reconnected during polling", machineID)
-			}
-
-			// 2. Execute Poll
-			data, err := client.GetCurrentData()
-			if err != nil {
-				s.logger.Errorf("Error getting data from machine %s: %v", machineID, err)
-				if m, dbErr := s.repo.GetByID(machineID); dbErr == nil {
-					s.updateStatus(m, entities.StatusReconnecting)
+			// 3. Send to Kafka. Data that was read is published even when
+			// polling stops meanwhile.
+			if err == nil {
+				pubCtx := context.WithoutCancel(ctx)
+				if machine == nil {
+					// The machine could not be loaded: the data goes out
+					// with the machine ID only.
+					machine = &entities.Machine{ID: machineID}
 				}
-				s.clients.Delete(machineID)
-			} else {
-				// 3. Send to Kafka
-				payload, err := json.Marshal(data)
-				if err != nil {
-					s.logger.Errorf("Failed to marshal polling data for %s: %v", machineID, err)
-				} else {
-					if err := s.kafkaProducer.Send(context.Background(), []byte(data.MachineID), payload); err != nil {
-						s.logger.Errorf("Failed to send polling data to Kafka for %s: %v", machineID, err)
-					}
+				var version *entities.ProgramVersion
+				if program != nil {
+					version = s.recordProgram(pubCtx, machineID, program.number, program.text, entities.ProgramSourceSnapshot)
+				}
+				sequence++
+				envelope := newEnvelope(machine, data, reads, sequence, pollStart, finished)
+				if exec != nil {
+					envelope.Position = s.trackProgram(pubCtx, machine, *exec, program, version, finished)
+				}
+				s.watchers.publish(envelope)
+				dataKey = data.MachineID
+				if err := s.kafkaProducer.SendEnvelope(pubCtx, []byte(dataKey), envelope); err != nil {
+					s.logger.Errorf("Failed to send polling data to Kafka for %s: %v", machineID, err)
+				}
+				s.publishAlarmEvents(pubCtx, machine, alarms, data.Alarms, finished)
+				alarms = data.Alarms
+				s.publishOffsetEvents(pubCtx, machine, offsets, tables, finished)
+				if tables != nil || !machine.WatchOffsets {
+					offsets = tables
 				}
 			}
 
@@ -121,9 +277,92 @@ func (s *Service) pollRoutine(ctx context.Context, machineID string, interval ti
 	}
 }
 
-func (s *Service) getOrRestoreClient(id string) (*adapter.Client, error) {
+// programSnapshot is the executing program read by a poll.
+type programSnapshot struct {
+	text   string
+	number int
+}
+
+func readProgramSnapshot(c *cnc.Client) (*programSnapshot, error) {
+	text, number, err := executingProgram(c)
+	if err != nil {
+		return nil, err
+	}
+	return &programSnapshot{text: text, number: number}, nil
+}
+
+// trackExecution takes the executing program and block from the polled data
+// and adds the sequence number, which the data does not carry.
+func trackExecution(c *cnc.Client, data *adapterModels.AggregatedData) *execution {
+	exec := &execution{
+		running: int(data.CurrentProgram.ProgramNumber),
+		block:   data.CurrentProgram.GCodeLine,
+	}
+	exec.sequence, _ = c.SequenceNumber()
+	return exec
+}
+
+func newEnvelope(m *entities.Machine, data *adapterModels.AggregatedData, reads []models.DataReading, seq uint64, started, finished time.Time) *models.MachineDataEnvelope {
+	return &models.MachineDataEnvelope{
+		SchemaVersion:    models.DataSchemaVersion,
+		ServiceVersion:   fanucService.Version,
+		MachineID:        m.ID,
+		AdapterMachineID: data.MachineID,
+		Endpoint:         m.Endpoint,
+		Model:            m.Model,
+		Series:           m.Series,
+		Labels:           m.Labels,
+		Sequence:         seq,
+		PollStartedAt:    started,
+		PollFinishedAt:   finished,
+		PollLatencyMs:    finished.Sub(started).Milliseconds(),
+		Data:             data,
+		Reads:            reads,
+	}
+}
+
+// publishAlarmEvents compares the alarms of two consecutive polls and emits a
+// raised event for every new alarm and a cleared event for every gone one.
+// The first poll of a run reports all active alarms as raised.
+func (s *Service) publishAlarmEvents(ctx context.Context, m *entities.Machine, previous, current []adapterModels.AlarmDetail, at time.Time) {
+	send := func(eventType string, alarm adapterModels.AlarmDetail) {
+		event := &models.AlarmEvent{
+			Type:      eventType,
+			MachineID: m.ID,
+			Endpoint:  m.Endpoint,
+			Labels:    m.Labels,
+			Alarm:     alarm,
+			Timestamp: at,
+		}
+		if err := s.kafkaProducer.SendAlarmEvent(ctx, event); err != nil {
+			s.logger.Errorf("Failed to send alarm event to Kafka for %s: %v", m.ID, err)
+		}
+	}
+
+	for _, alarm := range current {
+		if !containsAlarm(previous, alarm) {
+			send(models.AlarmRaised, alarm)
+		}
+	}
+	for _, alarm := range previous {
+		if !containsAlarm(current, alarm) {
+			send(models.AlarmCleared, alarm)
+		}
+	}
+}
+
+func containsAlarm(alarms []adapterModels.AlarmDetail, alarm adapterModels.AlarmDetail) bool {
+	for _, a := range alarms {
+		if a == alarm {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) getOrRestoreClient(ctx context.Context, id string) (*cnc.Client, error) {
 	if val, ok := s.clients.Load(id); ok {
-		return val.(*adapter.Client), nil
+		return val.(*cnc.Client), nil
 	}
 
 	machine, err := s.repo.GetByID(id)
@@ -144,7 +383,7 @@ func (s *Service) getOrRestoreClient(id string) (*adapter.Client, error) {
 		LogLevel:    s.cfg.Logger.AdapterLevel,
 	}
 
-	client, err := s.connectWithTimeout(cfg)
+	client, err := s.connectWithTimeout(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,234 @@
+package fanuc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/gcode"
+)
+
+// execution is what the CNC reports about the block it executes.
+type execution struct {
+	running  int    // O number of the executing program, 0 if none
+	sequence int    // N number executed last
+	block    string // text of the executing block
+}
+
+// readExecution asks the CNC for the executing program and block. A CNC that
+// does not report sequence numbers is located by the block text alone.
+func readExecution(c *cnc.Client) (execution, error) {
+	running, block, err := c.ExecutingBlock()
+	if err != nil {
+		return execution{}, err
+	}
+	sequence, _ := c.SequenceNumber()
+	return execution{running: running, sequence: sequence, block: block}, nil
+}
+
+// programTracker follows the executing block of a machine in the cached text
+// of its program.
+type programTracker struct {
+	mu       sync.Mutex
+	running  int // program number the CNC reported when the text was read
+	number   int // O number of the text
+	hash     string
+	version  uint
+	lines    []string
+	program  *gcode.Program
+	index    int // block located last, -1 if none
+	position *models.ProgramPosition
+	unread   int // running program polling failed to read, retried with the next snapshot
+}
+
+func (s *Service) tracker(machineID string) *programTracker {
+	t, _ := s.trackers.LoadOrStore(machineID, &programTracker{index: -1})
+	return t.(*programTracker)
+}
+
+// stale reports whether the cached text is not the one of the running program.
+func (t *programTracker) stale(running int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return running != 0 && (t.program == nil || t.running != running)
+}
+
+// pollRead reports whether polling should read the text of the running
+// program: it is not cached and reading it has not failed before.
+func (t *programTracker) pollRead(running int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return running != 0 && running != t.unread && (t.program == nil || t.running != running)
+}
+
+func (t *programTracker) readFailed(running int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unread = running
+}
+
+// current returns the last position with lines lines of context, nil if none.
+func (t *programTracker) current(lines int) *models.ProgramPosition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.position == nil {
+		return nil
+	}
+	return t.withLines(t.position, lines)
+}
+
+// withLines returns a copy of position with n lines of the cached text before
+// and after its line. t.mu must be held.
+func (t *programTracker) withLines(position *models.ProgramPosition, n int) *models.ProgramPosition {
+	result := *position
+	if position.Line == 0 || n < 0 {
+		return &result
+	}
+	for line := max(position.Line-n, 1); line <= min(position.Line+n, len(t.lines)); line++ {
+		result.Lines = append(result.Lines, models.ProgramLine{
+			Line:    line,
+			Text:    t.lines[line-1],
+			Current: line == position.Line,
+		})
+	}
+	return &result
+}
+
+// trackProgram locates the executing block in the cached program text,
+// replacing the cache first when program was read, and publishes the
+// program_changed and program_restarted events. version is the stored version
+// of program. It returns nil when no program executes or its text has not
+// been read yet.
+func (s *Service) trackProgram(ctx context.Context, m *entities.Machine, exec execution, program *programSnapshot, version *entities.ProgramVersion, at time.Time) *models.ProgramPosition {
+	t := s.tracker(m.ID)
+	t.mu.Lock()
+
+	var events []*models.ProgramEvent
+	if program != nil && exec.running != 0 {
+		if hash := programHash(normalizeProgram(program.text)); hash != t.hash {
+			// The first program seen is no change.
+			if t.hash != "" {
+				events = append(events, &models.ProgramEvent{
+					Type:           models.ProgramChanged,
+					Number:         program.number,
+					Hash:           hash,
+					Previous:       t.hash,
+					PreviousNumber: t.number,
+				})
+			}
+			t.number, t.hash, t.version = program.number, hash, 0
+			t.lines = strings.Split(strings.ReplaceAll(program.text, "\r\n", "\n"), "\n")
+			t.program = gcode.Parse(program.text)
+			t.index = -1
+		}
+		t.running, t.unread = exec.running, 0
+		if version != nil {
+			t.version = version.ID
+		}
+	}
+
+	if t.program == nil || exec.running == 0 || exec.running != t.running {
+		t.position = nil
+		t.mu.Unlock()
+		s.publishProgramEvents(ctx, m, events, at)
+		return nil
+	}
+
+	position := &models.ProgramPosition{
+		MachineID: m.ID,
+		Number:    t.number,
+		Version:   t.version,
+		Sequence:  exec.sequence,
+		Block:     exec.block,
+		Timestamp: at,
+	}
+	if index, matched := t.program.Locate(exec.sequence, exec.block, t.index); index >= 0 {
+		block := t.program.Blocks[index]
+		if head := t.program.Head(); t.index > head && index <= head {
+			events = append(events, &models.ProgramEvent{
+				Type:     models.ProgramRestarted,
+				Number:   t.number,
+				Hash:     t.hash,
+				Line:     block.Line,
+				Sequence: exec.sequence,
+			})
+		}
+		t.index = index
+		position.Line, position.Matched = block.Line, matched
+	}
+	for _, event := range events {
+		event.Version = t.version
+	}
+	t.position = position
+	result := t.withLines(position, s.cfg.Programs.ContextLines)
+	t.mu.Unlock()
+
+	s.publishProgramEvents(ctx, m, events, at)
+	return result
+}
+
+func (s *Service) publishProgramEvents(ctx context.Context, m *entities.Machine, events []*models.ProgramEvent, at time.Time) {
+	for _, event := range events {
+		event.MachineID = m.ID
+		event.Endpoint = m.Endpoint
+		event.Labels = m.Labels
+		event.Timestamp = at
+		s.logger.Infof("Program O%04d of machine %s: %s", event.Number, m.ID, event.Type)
+		if err := s.kafkaProducer.SendProgramEvent(ctx, event); err != nil {
+			s.logger.Errorf("Failed to send program event to Kafka for %s: %v", m.ID, err)
+		}
+	}
+}
+
+// ProgramPosition returns the block the machine executes in its program with
+// lines lines of context. Polling with program tracking answers from its last
+// poll; otherwise the CNC is asked and the program text read if it is not
+// cached.
+func (s *Service) ProgramPosition(ctx context.Context, id string, lines int) (*models.ProgramPosition, error) {
+	if lines < 0 {
+		lines = s.cfg.Programs.ContextLines
+	}
+	t := s.tracker(id)
+	if _, polling := s.pollingCancel.Load(id); polling && s.cfg.Programs.TrackPosition {
+		if position := t.current(lines); position != nil {
+			return position, nil
+		}
+	}
+
+	var (
+		exec    execution
+		program *programSnapshot
+	)
+	err := s.interactiveCall(ctx, id, "read position "+id, func(c *cnc.Client) error {
+		var err error
+		if exec, err = readExecution(c); err != nil || !t.stale(exec.running) {
+			return err
+		}
+		program, err = readProgramSnapshot(c)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program position: %w", err)
+	}
+	if exec.running == 0 {
+		return nil, fmt.Errorf("%w: machine %s executes no program", models.ErrNotFound, id)
+	}
+
+	machine, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	var version *entities.ProgramVersion
+	if program != nil {
+		version = s.recordProgram(ctx, id, program.number, program.text, entities.ProgramSourceDownload)
+	}
+	if s.trackProgram(ctx, machine, exec, program, version, time.Now()) == nil {
+		return nil, fmt.Errorf("%w: program of machine %s", models.ErrNotFound, id)
+	}
+	return t.current(lines), nil
+}
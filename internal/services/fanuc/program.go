@@ -3,27 +3,343 @@ package fanuc
 import (
 	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
 )
 
+// programNumber finds the O number at the start of a program.
+var programNumber = regexp.MustCompile(`^[%\s]*O(\d{1,4})\b`)
+
 func (s *Service) GetControlProgram(ctx context.Context, id string) (string, error) {
-	client, err := s.getOrRestoreClient(id)
+	release, err := s.acquireMachine(id)
 	if err != nil {
-		if m, dbErr := s.repo.GetByID(id); dbErr == nil {
-			s.updateStatus(m, entities.StatusReconnecting)
+		return "", err
+	}
+	defer release()
+
+	var (
+		program string
+		number  int
+	)
+	err = s.workers.Do(ctx, id, workers.Interactive, func() error {
+		client, err := s.interactiveClient(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = s.callClient(ctx, id, client, "read program "+id, func(c *cnc.Client) error {
+			var err error
+			program, number, err = executingProgram(c)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to download program: %w", err)
 		}
-		return "", fmt.Errorf("machine unreachable: %w", err)
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	if m, dbErr := s.repo.GetByID(id); dbErr == nil && m.Status == entities.StatusReconnecting {
-		s.updateStatus(m, entities.StatusConnected)
+	s.recordProgram(ctx, id, number, program, entities.ProgramSourceDownload)
+	return program, nil
+}
+
+// ListPrograms reads the program directory of a machine with the numbers of
+// the running and the main program.
+func (s *Service) ListPrograms(ctx context.Context, id string) (*models.ProgramDirectory, error) {
+	dir := &models.ProgramDirectory{}
+	err := s.interactiveCall(ctx, id, "list programs "+id, func(c *cnc.Client) error {
+		programs, err := c.ProgramDirectory()
+		if err != nil {
+			return err
+		}
+		if dir.Running, dir.Main, err = c.ProgramNumbers(); err != nil {
+			return err
+		}
+
+		dir.Programs = make([]models.ProgramInfo, 0, len(programs))
+		for _, p := range programs {
+			dir.Programs = append(dir.Programs, programInfo(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
+// ReadProgram downloads program number from CNC memory.
+func (s *Service) ReadProgram(ctx context.Context, id string, number int) (string, error) {
+	if err := checkProgramNumber(number); err != nil {
+		return "", err
 	}
 
-	program, err := client.GetControlProgram()
+	// The sink only bounds the text by PROGRAM_MAX_SIZE and keeps it.
+	sink := newProgramSink(io.Discard, 0, -1, s.cfg.Programs.MaxSize, 0)
+	op := fmt.Sprintf("read program O%04d %s", number, id)
+	err := s.interactiveCallTimeout(ctx, id, op, s.cfg.Programs.TransferTimeout, func(c *cnc.Client) error {
+		err := c.UploadProgram(number, sink)
+		if rejected(err) {
+			return fmt.Errorf("%w: program O%04d: %v", models.ErrNotFound, number, err)
+		}
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to download program: %w", err)
+		return "", err
 	}
 
+	program := sink.text.String()
+	s.recordProgram(ctx, id, number, program, entities.ProgramSourceDownload)
 	return program, nil
 }
+
+// UploadProgram stores a program in CNC memory. A program with the same
+// number is replaced only with req.Overwrite, never while it is the main
+// program of a machine in automatic operation, and kept if the CNC refuses
+// the new text.
+func (s *Service) UploadProgram(ctx context.Context, req models.ProgramUploadRequest) (*models.ProgramInfo, error) {
+	match := programNumber.FindStringSubmatch(req.Program)
+	if match == nil {
+		return nil, fmt.Errorf("%w: program must start with its O number", models.ErrBadRequest)
+	}
+	number, _ := strconv.Atoi(match[1])
+	if err := checkProgramNumber(number); err != nil {
+		return nil, err
+	}
+	if limit := s.cfg.Programs.MaxSize; limit > 0 && int64(len(req.Program)) > limit {
+		return nil, fmt.Errorf("%w: program has %d bytes, the limit is %d", models.ErrTooLarge, len(req.Program), limit)
+	}
+
+	var info models.ProgramInfo
+	op := fmt.Sprintf("upload program O%04d %s", number, req.ID)
+	err := s.interactiveCallTimeout(ctx, req.ID, op, s.cfg.Programs.TransferTimeout, func(c *cnc.Client) error {
+		programs, err := c.ProgramDirectory()
+		if err != nil {
+			return err
+		}
+		if _, exists := findProgram(programs, number); exists {
+			if !req.Overwrite {
+				return fmt.Errorf("%w: program O%04d, set overwrite to replace it", models.ErrAlreadyExists, number)
+			}
+			if err := checkProgramIdle(c, number); err != nil {
+				return err
+			}
+			if err := ReplaceProgram(c, number, req.Program); err != nil {
+				return err
+			}
+		} else if err := c.WriteProgram(req.Program); err != nil {
+			return asBadRequest(err)
+		}
+
+		if programs, err = c.ProgramDirectory(); err != nil {
+			return err
+		}
+		p, _ := findProgram(programs, number)
+		info = programInfo(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.forgetDownloads(req.ID, number)
+	s.logger.Infof("Program %s uploaded to machine %s (%d bytes)", info.Name, req.ID, len(req.Program))
+	s.recordProgram(ctx, req.ID, number, req.Program, entities.ProgramSourceUpload)
+	return &info, nil
+}
+
+// ProgramMemory is the program memory of a CNC as ReplaceProgram uses it.
+type ProgramMemory interface {
+	UploadProgram(number int, w io.Writer) error
+	DeleteProgram(number int) error
+	WriteProgram(text string) error
+}
+
+// ReplaceProgram replaces program number with text. The CNC stores no program
+// under a number in use, so the old text is read first and written back when
+// the new one is refused; a failed overwrite leaves the old program in memory.
+func ReplaceProgram(m ProgramMemory, number int, text string) error {
+	var old strings.Builder
+	if err := m.UploadProgram(number, &old); err != nil {
+		return fmt.Errorf("failed to read program O%04d before replacing it: %w", number, err)
+	}
+	if err := m.DeleteProgram(number); err != nil {
+		return asBadRequest(err)
+	}
+
+	err := m.WriteProgram(text)
+	if err == nil {
+		return nil
+	}
+	// A download refused at its end may leave part of the program behind.
+	_ = m.DeleteProgram(number)
+	if restoreErr := m.WriteProgram(old.String()); restoreErr != nil {
+		return fmt.Errorf("program O%04d was deleted and not restored: %v (upload: %v)", number, restoreErr, err)
+	}
+	return asBadRequest(err)
+}
+
+// DeleteProgram deletes program number from CNC memory. The main or running
+// program is not deleted while the machine is in automatic operation.
+func (s *Service) DeleteProgram(ctx context.Context, id string, number int) error {
+	if err := checkProgramNumber(number); err != nil {
+		return err
+	}
+
+	err := s.interactiveCall(ctx, id, fmt.Sprintf("delete program O%04d %s", number, id), func(c *cnc.Client) error {
+		if err := checkProgramExists(c, number); err != nil {
+			return err
+		}
+		if err := checkProgramIdle(c, number); err != nil {
+			return err
+		}
+		return asBadRequest(c.DeleteProgram(number))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.forgetDownloads(id, number)
+	s.logger.Infof("Program O%04d deleted from machine %s", number, id)
+	return nil
+}
+
+// SelectProgram makes program number the main program of a machine. It is
+// refused while the machine is in automatic operation.
+func (s *Service) SelectProgram(ctx context.Context, id string, number int) error {
+	if err := checkProgramNumber(number); err != nil {
+		return err
+	}
+
+	err := s.interactiveCall(ctx, id, fmt.Sprintf("select program O%04d %s", number, id), func(c *cnc.Client) error {
+		if err := checkProgramExists(c, number); err != nil {
+			return err
+		}
+		status, err := c.ReadStatus()
+		if err != nil {
+			return err
+		}
+		if status.Automatic() {
+			return fmt.Errorf("%w (%s)", models.ErrInterlocked, status)
+		}
+		return asBadRequest(c.SelectProgram(number))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Program O%04d selected as main program of machine %s", number, id)
+	return nil
+}
+
+func checkProgramNumber(number int) error {
+	if number < 1 || number > cnc.MaxProgramNumber {
+		return fmt.Errorf("%w: program number must be between 1 and %d", models.ErrBadRequest, cnc.MaxProgramNumber)
+	}
+	return nil
+}
+
+func checkProgramExists(c *cnc.Client, number int) error {
+	programs, err := c.ProgramDirectory()
+	if err != nil {
+		return err
+	}
+	if _, ok := findProgram(programs, number); !ok {
+		return fmt.Errorf("%w: program O%04d", models.ErrNotFound, number)
+	}
+	return nil
+}
+
+// checkProgramIdle refuses changing the main or running program while the
+// machine is in automatic operation.
+func checkProgramIdle(c *cnc.Client, number int) error {
+	status, err := c.ReadStatus()
+	if err != nil {
+		return err
+	}
+	if !status.Automatic() {
+		return nil
+	}
+	running, main, err := c.ProgramNumbers()
+	if err != nil {
+		return err
+	}
+	if number == running || number == main {
+		return fmt.Errorf("%w (%s), O%04d is in use", models.ErrInterlocked, status, number)
+	}
+	return nil
+}
+
+func findProgram(programs []cnc.Program, number int) (cnc.Program, bool) {
+	for _, p := range programs {
+		if p.Number == number {
+			return p, true
+		}
+	}
+	return cnc.Program{Number: number}, false
+}
+
+func programInfo(p cnc.Program) models.ProgramInfo {
+	info := models.ProgramInfo{
+		Number:  p.Number,
+		Name:    fmt.Sprintf("O%04d", p.Number),
+		Size:    p.Size,
+		Comment: p.Comment,
+	}
+	if !p.Modified.IsZero() {
+		modified := p.Modified
+		info.ModifiedAt = &modified
+	}
+	return info
+}
+
+// interactiveCall runs fn on the machine handle as an interactive request.
+func (s *Service) interactiveCall(ctx context.Context, id, op string, fn func(c *cnc.Client) error) error {
+	return s.interactiveCallTimeout(ctx, id, op, s.cfg.Focas.CallTimeout, fn)
+}
+
+// interactiveCallTimeout is interactiveCall with another deadline than the
+// call timeout, for a call that moves a whole program.
+func (s *Service) interactiveCallTimeout(ctx context.Context, id, op string, timeout time.Duration, fn func(c *cnc.Client) error) error {
+	release, err := s.acquireMachine(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.workers.Do(ctx, id, workers.Interactive, func() error {
+		client, err := s.interactiveClient(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.callClientTimeout(ctx, id, client, op, timeout, fn)
+	})
+}
+
+// interactiveClient returns the handle of a machine for an API call, connecting
+// if needed, and keeps the machine status in line. It must run on the machine
+// worker.
+func (s *Service) interactiveClient(ctx context.Context, id string) (*cnc.Client, error) {
+	client, err := s.getOrRestoreClient(ctx, id)
+	if err != nil {
+		if m, dbErr := s.repo.GetByID(id); dbErr == nil {
+			s.updateStatus(m, entities.StatusReconnecting)
+		}
+		return nil, fmt.Errorf("machine unreachable: %w", err)
+	}
+
+	if m, dbErr := s.repo.GetByID(id); dbErr == nil && m.Status == entities.StatusReconnecting {
+		s.updateStatus(m, entities.StatusConnected)
+	}
+	return client, nil
+}
@@ -0,0 +1,173 @@
+package fanuc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
+)
+
+const (
+	// MaxReadCount limits the values of one DataAddress.
+	MaxReadCount = 100
+	// MaxPollReads limits the addresses read with every poll.
+	MaxPollReads = 32
+	maxAxis      = 32
+)
+
+func (s *Service) ReadData(ctx context.Context, id string, addr entities.DataAddress) (*models.DataReading, error) {
+	addr, err := NormalizeAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquireMachine(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var values []models.DataValue
+	err = s.workers.Do(ctx, id, workers.Interactive, func() error {
+		client, err := s.interactiveClient(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.callClient(ctx, id, client, "read "+addr.Class+" "+id, func(c *cnc.Client) error {
+			var err error
+			values, err = readData(c, addr)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DataReading{DataAddress: addr, Values: values}, nil
+}
+
+// NormalizeReads normalizes the polling addresses of a machine, see
+// NormalizeAddress; at most MaxPollReads are allowed.
+func NormalizeReads(reads []entities.DataAddress) ([]entities.DataAddress, error) {
+	if len(reads) > MaxPollReads {
+		return nil, fmt.Errorf("%w: at most %d reads are allowed", models.ErrBadRequest, MaxPollReads)
+	}
+	normalized := make([]entities.DataAddress, len(reads))
+	for i, read := range reads {
+		addr, err := NormalizeAddress(read)
+		if err != nil {
+			return nil, fmt.Errorf("reads[%d]: %w", i, err)
+		}
+		normalized[i] = addr
+	}
+	return normalized, nil
+}
+
+// NormalizeAddress applies the defaults of addr and checks it against the
+// limits of its data class.
+func NormalizeAddress(addr entities.DataAddress) (entities.DataAddress, error) {
+	if addr.Count == 0 {
+		addr.Count = 1
+	}
+	if addr.Count < 0 || addr.Count > MaxReadCount {
+		return addr, fmt.Errorf("%w: count must be between 1 and %d", models.ErrBadRequest, MaxReadCount)
+	}
+
+	size, last := 1, math.MaxInt16
+	switch addr.Class {
+	case entities.DataMacro:
+		addr.Type, addr.Axis, addr.Area = entities.DataReal, 0, ""
+	case entities.DataParameter, entities.DataDiagnosis:
+		if addr.Type == "" {
+			return addr, fmt.Errorf("%w: type is required for %s", models.ErrBadRequest, addr.Class)
+		}
+		if addr.Axis < 0 || addr.Axis > maxAxis {
+			return addr, fmt.Errorf("%w: axis must be between 0 and %d", models.ErrBadRequest, maxAxis)
+		}
+		addr.Area = ""
+	case entities.DataPMC:
+		addr.Area = strings.ToUpper(addr.Area)
+		if !cnc.ValidPMCArea(addr.Area) {
+			return addr, fmt.Errorf("%w: unknown PMC area %q", models.ErrBadRequest, addr.Area)
+		}
+		if addr.Type == "" {
+			addr.Type = entities.DataByte
+		}
+		addr.Axis, last = 0, math.MaxUint16
+	default:
+		return addr, fmt.Errorf("%w: unknown data class %q", models.ErrBadRequest, addr.Class)
+	}
+
+	t, err := cnc.ParseDataType(addr.Type)
+	if err != nil {
+		return addr, fmt.Errorf("%w: %v", models.ErrBadRequest, err)
+	}
+	if addr.Class == entities.DataPMC {
+		size = t.PMCSize()
+	}
+	if addr.Number < 0 || addr.Number+addr.Count*size-1 > last {
+		return addr, fmt.Errorf("%w: %s %d (count %d) is out of range", models.ErrBadRequest, addr.Class, addr.Number, addr.Count)
+	}
+	return addr, nil
+}
+
+// readData reads a normalized address on the machine handle. A request the
+// CNC rejects (unknown number, wrong type) is a bad request.
+func readData(c *cnc.Client, addr entities.DataAddress) ([]models.DataValue, error) {
+	t, err := cnc.ParseDataType(addr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []cnc.Value
+	switch addr.Class {
+	case entities.DataParameter:
+		values, err = c.ReadParameters(addr.Number, addr.Count, addr.Axis, t)
+	case entities.DataDiagnosis:
+		values, err = c.ReadDiagnoses(addr.Number, addr.Count, addr.Axis, t)
+	case entities.DataMacro:
+		values, err = c.ReadMacros(addr.Number, addr.Count)
+	case entities.DataPMC:
+		values, err = c.ReadPMC(addr.Area, addr.Number, addr.Count, t)
+	}
+	if err != nil {
+		var rc *cnc.Error
+		if errors.As(err, &rc) && rc.Rejected() {
+			return nil, fmt.Errorf("%w: %v", models.ErrBadRequest, err)
+		}
+		return nil, err
+	}
+
+	result := make([]models.DataValue, 0, len(values))
+	for _, v := range values {
+		result = append(result, models.DataValue{Number: v.Number, Value: v.Value, Vacant: v.Vacant})
+	}
+	return result, nil
+}
+
+// readAll reads the polling addresses of a machine. An address that fails is
+// reported with its error and does not fail the poll.
+func readAll(c *cnc.Client, addrs []entities.DataAddress) []models.DataReading {
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	readings := make([]models.DataReading, 0, len(addrs))
+	for _, addr := range addrs {
+		reading := models.DataReading{DataAddress: addr}
+		values, err := readData(c, addr)
+		if err != nil {
+			reading.Error = err.Error()
+		} else {
+			reading.Values = values
+		}
+		readings = append(readings, reading)
+	}
+	return readings
+}
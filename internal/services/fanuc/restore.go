@@ -1,11 +1,21 @@
 package fanuc
 
 import (
+	"context"
+	"errors"
+
 	adapter "github.com/iwtcode/fanucAdapter"
 	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/workers"
 )
 
 func (s *Service) RestoreConnections() error {
+	if s.membership.Enabled() {
+		s.logger.Info("Cluster mode: machines are restored by their owners")
+		return nil
+	}
+
 	machines, err := s.repo.GetAll()
 	if err != nil {
 		return err
@@ -41,10 +51,34 @@ func (s *Service) checkOneOnce(machine entities.Machine) {
 		LogLevel:    s.cfg.Logger.AdapterLevel,
 	}
 
-	client, err := s.connectWithTimeout(cfg)
+	// The machine may have been connected or handed over to another
+	// instance since the check was scheduled; only a handle of an owned
+	// machine without one is stored.
+	ctx := context.Background()
+	err = s.workers.Do(ctx, machine.ID, workers.Polling, func() error {
+		if !s.membership.Owns(machine.ID) {
+			return s.membership.NotOwnerError(machine.ID)
+		}
+		if _, connected := s.clients.Load(machine.ID); connected {
+			return nil
+		}
+		client, err := s.connectWithTimeout(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if !s.membership.Owns(machine.ID) {
+			client.Close()
+			return s.membership.NotOwnerError(machine.ID)
+		}
+		s.clients.Store(machine.ID, client)
+		return nil
+	})
 
+	if errors.Is(err, models.ErrNotOwner) {
+		s.logger.Infof("Machine %s is no longer served by this instance: %v", machine.ID, err)
+		return
+	}
 	if err == nil {
-		s.clients.Store(machine.ID, client)
 		s.logger.Infof("Restored connection to %s (Static mode)", machine.Endpoint)
 		s.updateStatus(&machine, entities.StatusConnected)
 	} else {
@@ -0,0 +1,72 @@
+package fanuc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
+)
+
+// StopAllPolling cancels every poll routine, interrupting the polls in flight,
+// and waits for the routines to publish the data already read and return.
+// Unlike StopPolling it keeps the persisted Mode, so polling resumes on the
+// next start. No new routines start afterwards.
+func (s *Service) StopAllPolling(ctx context.Context) error {
+	s.pollMu.Lock()
+	s.closing = true
+	s.pollMu.Unlock()
+
+	count := 0
+	s.pollingCancel.Range(func(key, value interface{}) bool {
+		value.(context.CancelFunc)()
+		s.pollingCancel.Delete(key)
+		count++
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.pollers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Infof("Stopped %d polling routines", count)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("polling routines did not stop: %w", ctx.Err())
+	}
+}
+
+// CloseConnections closes all FOCAS handles on their machine workers, so no
+// operation is using them, and stops the workers.
+func (s *Service) CloseConnections(ctx context.Context) error {
+	var ids []string
+	s.clients.Range(func(key, value interface{}) bool {
+		ids = append(ids, key.(string))
+		return true
+	})
+
+	var failed int
+	for _, id := range ids {
+		err := s.workers.Do(ctx, id, workers.Interactive, func() error {
+			if val, ok := s.clients.LoadAndDelete(id); ok {
+				val.(*cnc.Client).Close()
+			}
+			return nil
+		})
+		if err != nil {
+			failed++
+			s.logger.Warnf("Failed to close FOCAS handle of machine %s: %v", id, err)
+		}
+	}
+	s.workers.StopAll()
+
+	s.logger.Infof("Closed %d FOCAS connections", len(ids)-failed)
+	if failed > 0 {
+		return fmt.Errorf("%d FOCAS connections were not closed", failed)
+	}
+	return nil
+}
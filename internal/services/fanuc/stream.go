@@ -0,0 +1,274 @@
+package fanuc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
+)
+
+// errRangeSent stops an upload once the requested byte range is written.
+var errRangeSent = errors.New("requested range sent")
+
+// programKey identifies a program of a machine.
+type programKey struct {
+	machineID string
+	number    int
+}
+
+// programDownload is what a full download of a program found out. It holds
+// while the directory entry of the program is unchanged.
+type programDownload struct {
+	size     int // directory size
+	modified time.Time
+	length   int64 // bytes of the framed text
+	etag     string
+}
+
+// matches reports whether p is still the program downloaded. A CNC that keeps
+// no modification date cannot tell a same-size edit, so nothing matches then.
+func (d programDownload) matches(p cnc.Program) bool {
+	return !p.Modified.IsZero() && p.Size == d.size && p.Modified.Equal(d.modified)
+}
+
+// forgetDownloads drops what was learned from downloads of program number of
+// a machine, of all its programs for 0.
+func (s *Service) forgetDownloads(machineID string, number int) {
+	s.downloads.Range(func(key, _ any) bool {
+		k := key.(programKey)
+		if k.machineID == machineID && (number == 0 || k.number == number) {
+			s.downloads.Delete(key)
+		}
+		return true
+	})
+}
+
+// StatProgram prepares streaming program number, 0 for the executing program:
+// it resolves the number, checks the program exists and is within
+// PROGRAM_MAX_SIZE, and returns the length and ETag of its text when an
+// earlier download of the unchanged program knows them.
+func (s *Service) StatProgram(ctx context.Context, id string, number int) (*models.ProgramStat, error) {
+	if number != 0 {
+		if err := checkProgramNumber(number); err != nil {
+			return nil, err
+		}
+	}
+
+	stat := &models.ProgramStat{Number: number, Length: -1}
+	var entry cnc.Program
+	err := s.interactiveCall(ctx, id, "stat program "+id, func(c *cnc.Client) error {
+		if stat.Number == 0 {
+			running, main, err := c.ProgramNumbers()
+			if err != nil {
+				return err
+			}
+			if stat.Number = running; stat.Number == 0 {
+				stat.Number = main
+			}
+			if stat.Number == 0 {
+				// A program known by its name only, read as a whole.
+				return nil
+			}
+		}
+
+		var (
+			found bool
+			err   error
+		)
+		if entry, found, err = c.ProgramEntry(stat.Number); err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: program O%04d", models.ErrNotFound, stat.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stat.Number == 0 {
+		return stat, nil
+	}
+
+	if limit := s.cfg.Programs.MaxSize; limit > 0 && int64(entry.Size) > limit {
+		return nil, fmt.Errorf("%w: program O%04d has %d bytes, the limit is %d", models.ErrTooLarge, stat.Number, entry.Size, limit)
+	}
+	if val, ok := s.downloads.Load(programKey{id, stat.Number}); ok {
+		if d := val.(programDownload); d.matches(entry) {
+			stat.Length, stat.ETag = d.length, d.etag
+		}
+	}
+	return stat, nil
+}
+
+// StreamProgram writes length bytes from offset of the text of program number
+// to w while the CNC uploads it; length below 0 writes up to the end. Number
+// 0 is an executing program without an O number, which the CNC only hands
+// over as a whole. The transfer is bounded by PROGRAM_TRANSFER_TIMEOUT rather
+// than the call timeout. A full download is remembered for StatProgram and
+// recorded as a version when it is within PROGRAM_VERSION_MAX_SIZE.
+func (s *Service) StreamProgram(ctx context.Context, id string, number int, w io.Writer, offset, length int64) error {
+	if number != 0 {
+		if err := checkProgramNumber(number); err != nil {
+			return err
+		}
+	}
+	release, err := s.acquireMachine(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// An upload abandoned on its deadline keeps running on the machine worker
+	// and must not write to w once the caller has it back.
+	out := &callWriter{w: w}
+	defer out.close()
+	sink := newProgramSink(out, offset, length, s.cfg.Programs.MaxSize, s.cfg.Programs.VersionMaxSize)
+	var entry cnc.Program
+	err = s.workers.Do(ctx, id, workers.Interactive, func() error {
+		client, err := s.interactiveClient(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		op := fmt.Sprintf("stream program O%04d %s", number, id)
+		return s.callClientTimeout(ctx, id, client, op, s.cfg.Programs.TransferTimeout, func(c *cnc.Client) error {
+			if number == 0 {
+				text, n, err := executingProgram(c)
+				if err != nil {
+					return err
+				}
+				number = n
+				_, err = io.WriteString(sink, text)
+				return err
+			}
+
+			var (
+				found bool
+				err   error
+			)
+			if entry, found, err = c.ProgramEntry(number); err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("%w: program O%04d", models.ErrNotFound, number)
+			}
+			err = c.UploadProgram(number, sink)
+			if rejected(err) {
+				return fmt.Errorf("%w: program O%04d: %v", models.ErrNotFound, number, err)
+			}
+			return err
+		})
+	})
+	if errors.Is(err, errRangeSent) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download program: %w", err)
+	}
+
+	if number > 0 && entry.Number == number {
+		s.downloads.Store(programKey{id, number}, programDownload{
+			size:     entry.Size,
+			modified: entry.Modified,
+			length:   sink.n,
+			etag:     `"` + hex.EncodeToString(sink.hash.Sum(nil)) + `"`,
+		})
+	}
+	if sink.overflow {
+		s.logger.Debugf("Program O%04d of machine %s has %d bytes, no version stored", number, id, sink.n)
+		return nil
+	}
+	s.recordProgram(ctx, id, number, sink.text.String(), entities.ProgramSourceDownload)
+	return nil
+}
+
+// programSink receives a program text as the CNC uploads it. It counts and
+// hashes the text, keeps it for the version store while it is small enough
+// and passes the requested byte range on.
+type programSink struct {
+	w        io.Writer
+	offset   int64
+	end      int64 // end of the range, -1 for the end of the text
+	limit    int64 // largest text, 0 for no limit
+	keep     int64 // largest text kept, 0 for no limit
+	n        int64
+	hash     hash.Hash
+	text     bytes.Buffer
+	overflow bool // the text outgrew keep and is not kept
+}
+
+func newProgramSink(w io.Writer, offset, length, limit, keep int64) *programSink {
+	end := int64(-1)
+	if length >= 0 {
+		end = offset + length
+	}
+	return &programSink{w: w, offset: offset, end: end, limit: limit, keep: keep, hash: sha256.New()}
+}
+
+func (p *programSink) Write(b []byte) (int, error) {
+	if p.limit > 0 && p.n+int64(len(b)) > p.limit {
+		return 0, fmt.Errorf("%w: program exceeds %d bytes", models.ErrTooLarge, p.limit)
+	}
+	start := p.n
+	p.n += int64(len(b))
+	p.hash.Write(b)
+	if !p.overflow {
+		if p.keep > 0 && int64(p.text.Len()+len(b)) > p.keep {
+			p.overflow = true
+			p.text = bytes.Buffer{}
+		} else {
+			p.text.Write(b)
+		}
+	}
+
+	lo, hi := max(p.offset-start, 0), int64(len(b))
+	if p.end >= 0 {
+		hi = min(hi, p.end-start)
+	}
+	if lo < hi {
+		if _, err := p.w.Write(b[lo:hi]); err != nil {
+			return 0, err
+		}
+	}
+	if p.end >= 0 && p.n >= p.end {
+		return len(b), errRangeSent
+	}
+	return len(b), nil
+}
+
+// callWriter passes writes on until it is closed. A FOCAS call abandoned on
+// its deadline, or left running on the machine worker when its caller gave
+// up, keeps running and must not write to its caller afterwards. Close waits
+// for a write in progress.
+type callWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closed bool
+}
+
+func (c *callWriter) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return c.w.Write(b)
+}
+
+func (c *callWriter) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
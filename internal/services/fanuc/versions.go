@@ -0,0 +1,155 @@
+package fanuc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+)
+
+// SnapshotProgram reads the program the machine executes and records it in
+// the version store.
+func (s *Service) SnapshotProgram(ctx context.Context, id string) (*entities.ProgramVersion, error) {
+	var (
+		text   string
+		number int
+	)
+	err := s.interactiveCall(ctx, id, "read program "+id, func(c *cnc.Client) error {
+		var err error
+		text, number, err = executingProgram(c)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download program: %w", err)
+	}
+
+	version := s.recordProgram(ctx, id, number, text, entities.ProgramSourceDownload)
+	if version == nil {
+		return nil, fmt.Errorf("failed to store program O%04d of machine %s", number, id)
+	}
+	return version, nil
+}
+
+// executingProgram reads the text and the number of the program the CNC
+// executes. The number is the O number of the text, else the number of the
+// running or the main program.
+func executingProgram(c *cnc.Client) (string, int, error) {
+	text, err := c.GetControlProgram()
+	if err != nil {
+		return "", 0, err
+	}
+	if match := programNumber.FindStringSubmatch(text); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return text, number, nil
+	}
+
+	running, main, err := c.ProgramNumbers()
+	if err != nil || running != 0 {
+		return text, running, nil
+	}
+	return text, main, nil
+}
+
+// recordProgram stores a program text read from or written to a machine as a
+// new version unless it equals the latest version of the program. Failures
+// are logged and never fail the operation that moved the program; nil is
+// returned then.
+func (s *Service) recordProgram(ctx context.Context, machineID string, number int, text, source string) *entities.ProgramVersion {
+	if number <= 0 {
+		s.logger.Debugf("Program of machine %s has no number, no version stored", machineID)
+		return nil
+	}
+
+	version, previous, err := RecordProgram(s.versions, machineID, number, text, source, time.Now())
+	if err != nil {
+		s.logger.Errorf("Failed to store version of program O%04d of machine %s: %v", number, machineID, err)
+		return nil
+	}
+	if version == previous {
+		return version
+	}
+
+	s.logger.Infof("Program O%04d of machine %s recorded as version %d (%s)", number, machineID, version.ID, source)
+	s.publishProgramVersion(ctx, version, previous)
+	return version
+}
+
+// RecordProgram records the normalized text of program number of a machine,
+// seen at now, in versions. previous is the latest version before, nil for
+// the first one; an unchanged text returns previous as version, with SeenAt
+// moved to now.
+func RecordProgram(versions interfaces.ProgramVersionRepository, machineID string, number int, text, source string, now time.Time) (version, previous *entities.ProgramVersion, err error) {
+	content := normalizeProgram(text)
+	version = &entities.ProgramVersion{
+		MachineID: machineID,
+		Number:    number,
+		Hash:      programHash(content),
+		Size:      len(content),
+		Source:    source,
+		Content:   content,
+		CreatedAt: now,
+		SeenAt:    now,
+	}
+	if previous, err = versions.Record(version); err != nil {
+		return nil, nil, err
+	}
+	if previous != nil && previous.Hash == version.Hash {
+		return previous, previous, nil
+	}
+	return version, previous, nil
+}
+
+// publishProgramVersion announces a new version and whether it differs from
+// the approved one.
+func (s *Service) publishProgramVersion(ctx context.Context, version, previous *entities.ProgramVersion) {
+	machine, err := s.repo.GetByID(version.MachineID)
+	if err != nil {
+		return
+	}
+
+	event := &models.ProgramEvent{
+		Type:      models.ProgramVersionAdded,
+		MachineID: machine.ID,
+		Endpoint:  machine.Endpoint,
+		Labels:    machine.Labels,
+		Number:    version.Number,
+		Version:   version.ID,
+		Hash:      version.Hash,
+		Source:    version.Source,
+		Timestamp: version.CreatedAt,
+	}
+	if previous != nil {
+		event.Previous = previous.Hash
+	}
+	if approved, err := s.versions.Approved(version.MachineID, version.Number); err == nil {
+		event.Approved = approved.Hash
+		event.Drifted = approved.Hash != version.Hash
+	}
+	if event.Drifted {
+		s.logger.Warnf("Program O%04d of machine %s differs from its approved version", version.Number, version.MachineID)
+	}
+
+	if err := s.kafkaProducer.SendProgramEvent(ctx, event); err != nil {
+		s.logger.Errorf("Failed to send program event to Kafka for %s: %v", machine.ID, err)
+	}
+}
+
+// programHash identifies a normalized program text.
+func programHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeProgram strips the '%' framing and unifies line endings, so a
+// program hashes the same whether it was read or uploaded.
+func normalizeProgram(text string) string {
+	return strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), " \t\n%")
+}
@@ -0,0 +1,115 @@
+package fanuc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
+
+// watchBuffer is the number of snapshots a watcher may lag behind before
+// new snapshots are dropped for it.
+const watchBuffer = 16
+
+// watchHub fans polling snapshots out to in-process subscribers (gRPC streams).
+type watchHub struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan *models.MachineDataEnvelope]struct{}
+	closed   bool
+}
+
+// subscribe returns nil once the hub is closed.
+func (h *watchHub) subscribe(machineID string) chan *models.MachineDataEnvelope {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	ch := make(chan *models.MachineDataEnvelope, watchBuffer)
+
+	if h.watchers == nil {
+		h.watchers = make(map[string]map[chan *models.MachineDataEnvelope]struct{})
+	}
+	if h.watchers[machineID] == nil {
+		h.watchers[machineID] = make(map[chan *models.MachineDataEnvelope]struct{})
+	}
+	h.watchers[machineID][ch] = struct{}{}
+	return ch
+}
+
+// unsubscribe closes ch unless closeAll did.
+func (h *watchHub) unsubscribe(machineID string, ch chan *models.MachineDataEnvelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.watchers[machineID][ch]; !ok {
+		return
+	}
+	delete(h.watchers[machineID], ch)
+	if len(h.watchers[machineID]) == 0 {
+		delete(h.watchers, machineID)
+	}
+	close(ch)
+}
+
+// closeAll closes every channel, ending the streams that read them, and
+// refuses new subscribers.
+func (h *watchHub) closeAll() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	count := 0
+	for _, chans := range h.watchers {
+		for ch := range chans {
+			close(ch)
+			count++
+		}
+	}
+	h.watchers = nil
+	return count
+}
+
+func (h *watchHub) publish(env *models.MachineDataEnvelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.watchers[env.MachineID] {
+		select {
+		case ch <- env:
+		default:
+		}
+	}
+}
+
+// WatchMachineData subscribes to the polling snapshots of the machine. The
+// channel is closed when ctx is done or on shutdown by CloseWatchers.
+func (s *Service) WatchMachineData(ctx context.Context, machineID string) (<-chan *models.MachineDataEnvelope, error) {
+	if _, err := s.repo.GetByID(machineID); err != nil {
+		return nil, err
+	}
+	if !s.membership.Owns(machineID) {
+		return nil, s.membership.NotOwnerError(machineID)
+	}
+
+	ch := s.watchers.subscribe(machineID)
+	if ch == nil {
+		return nil, fmt.Errorf("%w: service is stopping", models.ErrUnavailable)
+	}
+	go func() {
+		<-ctx.Done()
+		s.watchers.unsubscribe(machineID, ch)
+	}()
+	return ch, nil
+}
+
+// CloseWatchers ends every WatchMachineData subscription, so the streams
+// reading them return and the gRPC server can stop gracefully. Later
+// subscriptions are refused.
+func (s *Service) CloseWatchers() {
+	if n := s.watchers.closeAll(); n > 0 {
+		s.logger.Infof("Closed %d data watchers", n)
+	}
+}
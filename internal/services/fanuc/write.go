@@ -0,0 +1,264 @@
+package fanuc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/workers"
+)
+
+// readOnlyPMCAreas are driven by the machine (X) and the CNC (F).
+var readOnlyPMCAreas = map[string]bool{"X": true, "F": true}
+
+// WriteData writes macro variables or PMC values allowed by the write rules
+// of the machine. The values before the write and the values read back after
+// it are returned. The write is refused while the CNC is in automatic operation
+// unless req.Override is set; a dry run makes every check and reads the current
+// values but writes nothing.
+func (s *Service) WriteData(ctx context.Context, req models.WriteDataRequest) (*models.WriteResult, error) {
+	addr, err := normalizeWrite(req)
+	if err != nil {
+		return nil, err
+	}
+
+	machine, err := s.repo.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkWriteRules(machine.WriteRules, addr, req.Values); err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquireMachine(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result := &models.WriteResult{DataAddress: addr, DryRun: req.DryRun}
+	err = s.workers.Do(ctx, req.ID, workers.Interactive, func() error {
+		client, err := s.interactiveClient(ctx, req.ID)
+		if err != nil {
+			return err
+		}
+		return s.callClient(ctx, req.ID, client, "write "+addr.Class+" "+req.ID, func(c *cnc.Client) error {
+			status, err := c.ReadStatus()
+			if err != nil {
+				return err
+			}
+			if result.Overridden, err = CheckInterlock(status, req.Override); err != nil {
+				return err
+			}
+
+			if result.Previous, err = readData(c, addr); err != nil {
+				return err
+			}
+			if req.DryRun {
+				result.Values = requestedValues(addr, req.Values)
+				return nil
+			}
+
+			if err := writeData(c, addr, req.Values); err != nil {
+				return err
+			}
+			result.Values, err = readData(c, addr)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		if result.Overridden {
+			s.logger.Warnf("Wrote %s %s%d (%d values) on machine %s with the interlock overridden", addr.Class, addr.Area, addr.Number, addr.Count, req.ID)
+		} else {
+			s.logger.Infof("Wrote %s %s%d (%d values) on machine %s", addr.Class, addr.Area, addr.Number, addr.Count, req.ID)
+		}
+	}
+	return result, nil
+}
+
+// CheckInterlock refuses a write while the CNC is in automatic operation
+// unless override is set, and reports whether the interlock was overridden.
+func CheckInterlock(status cnc.Status, override bool) (overridden bool, err error) {
+	if !status.Automatic() {
+		return false, nil
+	}
+	if !override {
+		return false, fmt.Errorf("%w (%s), set override to write anyway", models.ErrInterlocked, status)
+	}
+	return true, nil
+}
+
+// normalizeWrite checks a write request and returns the address it writes.
+func normalizeWrite(req models.WriteDataRequest) (entities.DataAddress, error) {
+	if req.Class != entities.DataMacro && req.Class != entities.DataPMC {
+		return entities.DataAddress{}, fmt.Errorf("%w: only macro and pmc can be written", models.ErrBadRequest)
+	}
+	if len(req.Values) == 0 {
+		return entities.DataAddress{}, fmt.Errorf("%w: values are required", models.ErrBadRequest)
+	}
+
+	addr, err := NormalizeAddress(entities.DataAddress{
+		Class:  req.Class,
+		Number: req.Number,
+		Count:  len(req.Values),
+		Type:   req.Type,
+		Area:   req.Area,
+	})
+	if err != nil {
+		return addr, err
+	}
+	if readOnlyPMCAreas[addr.Area] {
+		return addr, fmt.Errorf("%w: PMC area %s is read-only", models.ErrBadRequest, addr.Area)
+	}
+
+	t, _ := cnc.ParseDataType(addr.Type)
+	for i, v := range req.Values {
+		if addr.Class == entities.DataMacro {
+			if _, _, err := cnc.MacroValue(v); err != nil {
+				return addr, fmt.Errorf("%w: values[%d]: %v", models.ErrBadRequest, i, err)
+			}
+		} else if !cnc.PMCRange(t, v) {
+			return addr, fmt.Errorf("%w: values[%d]: %v is not a PMC %s", models.ErrBadRequest, i, v, addr.Type)
+		}
+	}
+	return addr, nil
+}
+
+// SetWriteRules replaces the write rules of a machine. An empty list forbids
+// all writes.
+func (s *Service) SetWriteRules(ctx context.Context, id string, rules []entities.WriteRule) (*entities.Machine, error) {
+	rules, err := normalizeWriteRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	machine, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	machine.WriteRules = rules
+	machine.UpdatedAt = time.Now()
+	if err := s.repo.Update(machine); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Write rules of machine %s set (%d rules)", id, len(rules))
+	return machine, nil
+}
+
+// normalizeWriteRules applies the defaults of write rules and checks them like
+// write addresses.
+func normalizeWriteRules(rules []entities.WriteRule) ([]entities.WriteRule, error) {
+	for i, r := range rules {
+		switch r.Class {
+		case entities.DataMacro, entities.DataPMC, entities.DataToolOffset, entities.DataWorkOffset:
+		default:
+			return nil, fmt.Errorf("%w: rules[%d]: only macro, pmc, tool_offset and work_offset can be written", models.ErrBadRequest, i)
+		}
+		if r.Count == 0 {
+			r.Count = 1
+		}
+		if r.Count < 0 {
+			return nil, fmt.Errorf("%w: rules[%d]: count must be positive", models.ErrBadRequest, i)
+		}
+		if r.Min > r.Max {
+			return nil, fmt.Errorf("%w: rules[%d]: min is greater than max", models.ErrBadRequest, i)
+		}
+		if r.Class == entities.DataToolOffset || r.Class == entities.DataWorkOffset {
+			if r.Number < 0 || (r.Class == entities.DataToolOffset && r.Number < 1) {
+				return nil, fmt.Errorf("%w: rules[%d]: %s %d is out of range", models.ErrBadRequest, i, r.Class, r.Number)
+			}
+			r.Type, r.Area = "", ""
+			r.Offset = normalizeOffsetName(r.Class, r.Offset)
+			rules[i] = r
+			continue
+		}
+		r.Offset = ""
+
+		// Check both ends of the rule; its count is not limited like a read.
+		first, err := NormalizeAddress(entities.DataAddress{Class: r.Class, Number: r.Number, Type: r.Type, Area: r.Area})
+		if err == nil {
+			_, err = NormalizeAddress(entities.DataAddress{Class: r.Class, Number: ruleEnd(r, first.Type), Type: r.Type, Area: r.Area})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: rules[%d]: %s %d (count %d) is out of range", models.ErrBadRequest, i, r.Class, r.Number, r.Count)
+		}
+		if readOnlyPMCAreas[first.Area] {
+			return nil, fmt.Errorf("%w: rules[%d]: PMC area %s is read-only", models.ErrBadRequest, i, first.Area)
+		}
+		r.Type, r.Area = first.Type, first.Area
+		rules[i] = r
+	}
+	return rules, nil
+}
+
+// ruleEnd is the number of the last value a rule covers.
+func ruleEnd(r entities.WriteRule, dataType string) int {
+	if r.Class != entities.DataPMC {
+		return r.Number + r.Count - 1
+	}
+	t, _ := cnc.ParseDataType(dataType)
+	return r.Number + (r.Count-1)*t.PMCSize()
+}
+
+// checkWriteRules requires every written value to be covered by a rule of the
+// same class, area and type and to lie within its range.
+func checkWriteRules(rules []entities.WriteRule, addr entities.DataAddress, values []float64) error {
+	step := 1
+	if addr.Class == entities.DataPMC {
+		t, _ := cnc.ParseDataType(addr.Type)
+		step = t.PMCSize()
+	}
+
+	for i, v := range values {
+		n := addr.Number + i*step
+		var matched *entities.WriteRule
+		for j := range rules {
+			r := &rules[j]
+			if r.Class == addr.Class && r.Area == addr.Area && r.Type == addr.Type && n >= r.Number && n <= ruleEnd(*r, r.Type) {
+				matched = r
+				break
+			}
+		}
+		if matched == nil {
+			return fmt.Errorf("%w: %s %s%d is not allowed for writing", models.ErrForbidden, addr.Class, addr.Area, n)
+		}
+		if v < matched.Min || v > matched.Max {
+			return fmt.Errorf("%w: %s %s%d value %v is outside the allowed range %v..%v", models.ErrForbidden, addr.Class, addr.Area, n, v, matched.Min, matched.Max)
+		}
+	}
+	return nil
+}
+
+func writeData(c *cnc.Client, addr entities.DataAddress, values []float64) error {
+	var err error
+	if addr.Class == entities.DataMacro {
+		err = c.WriteMacros(addr.Number, values)
+	} else {
+		t, _ := cnc.ParseDataType(addr.Type)
+		err = c.WritePMC(addr.Area, addr.Number, t, values)
+	}
+	return asBadRequest(err)
+}
+
+// requestedValues numbers the values of a write like a reading of addr.
+func requestedValues(addr entities.DataAddress, values []float64) []models.DataValue {
+	step := 1
+	if addr.Class == entities.DataPMC {
+		t, _ := cnc.ParseDataType(addr.Type)
+		step = t.PMCSize()
+	}
+
+	result := make([]models.DataValue, 0, len(values))
+	for i, v := range values {
+		result = append(result, models.DataValue{Number: addr.Number + i*step, Value: v})
+	}
+	return result
+}
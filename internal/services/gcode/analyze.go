@@ -0,0 +1,297 @@
+package gcode
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Analysis summarizes a program for review.
+type Analysis struct {
+	Number      int        `json:"number,omitempty"`
+	Blocks      int        `json:"blocks"`
+	Units       string     `json:"units"` // mm or inch, as selected by G20/G21
+	Tools       []ToolUse  `json:"tools"`
+	WorkOffsets []string   `json:"work_offsets"` // e.g. G54, G54.1 P3, in order of first use
+	Calls       []Call     `json:"calls,omitempty"`
+	Path        PathLength `json:"path"`
+	Feed        *Range     `json:"feed,omitempty"`
+	Speed       *Range     `json:"speed,omitempty"`
+	Warnings    []Warning  `json:"warnings"`
+	Program     *Program   `json:"program,omitempty"` // parsed blocks, on request
+}
+
+// ToolUse is a tool called by the program.
+type ToolUse struct {
+	Tool  string `json:"tool"` // T word as written
+	Line  int    `json:"line"` // first call
+	Calls int    `json:"calls"`
+}
+
+// PathLength estimates the tool path in program units. Positions come from
+// X, Y and Z only; canned cycles count their positioning moves, not the hole
+// depths, and moves with macro expressions are left out.
+type PathLength struct {
+	Feed  float64 `json:"feed"`
+	Rapid float64 `json:"rapid"`
+	Total float64 `json:"total"`
+}
+
+// Range is the lowest and the highest value programmed.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+const (
+	motionNone  = -1
+	motionCycle = 80
+)
+
+// nonMotion are the G codes whose axis words are not a move of the tool path.
+var nonMotion = map[string]bool{
+	"G04": true, "G10": true, "G28": true, "G30": true, "G50": true,
+	"G52": true, "G53": true, "G65": true, "G92": true,
+}
+
+var axes = [3]string{"X", "Y", "Z"}
+
+// state is the modal state of the control while the blocks are walked.
+type state struct {
+	motion      int
+	plane       int // 17, 18, 19
+	incremental bool
+	pos         [3]float64
+	known       [3]bool // the position of an axis is known after its first move
+	feed        bool    // a feed rate was programmed
+}
+
+// Analyze walks the blocks with the modal state of the control and
+// summarizes the program.
+func Analyze(p *Program) *Analysis {
+	a := &Analysis{
+		Number:      p.Number,
+		Blocks:      len(p.Blocks),
+		Units:       "mm",
+		Tools:       []ToolUse{},
+		WorkOffsets: []string{},
+	}
+	warnings := append([]Warning(nil), p.Warnings...)
+	warn := func(line int, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{line, fmt.Sprintf(format, args...)})
+	}
+
+	st := state{motion: motionNone, plane: 17}
+	tools := make(map[string]int)
+	offsets := make(map[string]bool)
+	ended, feedWarned := false, false
+
+	for i := range p.Blocks {
+		b := &p.Blocks[i]
+		if b.Macro {
+			continue
+		}
+
+		skipAxes, motions := false, 0
+		for _, g := range b.G {
+			switch g {
+			case "G00", "G01", "G02", "G03":
+				st.motion = int(g[2] - '0')
+				motions++
+			case "G73", "G74", "G76", "G81", "G82", "G83", "G84", "G85", "G86", "G87", "G88", "G89":
+				st.motion = motionCycle
+				motions++
+			case "G80":
+				st.motion = motionNone
+			case "G17", "G18", "G19":
+				st.plane = int(g[2]-'0') + 10
+			case "G90":
+				st.incremental = false
+			case "G91":
+				st.incremental = true
+			case "G20":
+				a.Units = "inch"
+			case "G21":
+				a.Units = "mm"
+			case "G54", "G55", "G56", "G57", "G58", "G59":
+				addOffset(a, offsets, g)
+			case "G54.1":
+				if n, ok := b.word("P"); ok {
+					addOffset(a, offsets, fmt.Sprintf("G54.1 P%d", int(n)))
+				} else {
+					warn(b.Line, "G54.1 without P number")
+				}
+			default:
+				skipAxes = skipAxes || nonMotion[g]
+			}
+		}
+		if motions > 1 {
+			warn(b.Line, "more than one motion code in a block")
+		}
+
+		if b.Feed != nil {
+			a.Feed = widen(a.Feed, *b.Feed)
+			st.feed = st.feed || *b.Feed > 0
+		}
+		if b.Speed != nil {
+			a.Speed = widen(a.Speed, *b.Speed)
+		}
+		if b.Tool != "" {
+			if k, ok := tools[b.Tool]; ok {
+				a.Tools[k].Calls++
+			} else {
+				tools[b.Tool] = len(a.Tools)
+				a.Tools = append(a.Tools, ToolUse{Tool: b.Tool, Line: b.Line, Calls: 1})
+			}
+		}
+		for _, m := range b.M {
+			if m == 2 || m == 30 || m == 99 {
+				ended = true
+			}
+		}
+		if b.Call != nil {
+			a.Calls = append(a.Calls, *b.Call)
+		}
+
+		if skipAxes || st.motion == motionNone {
+			continue
+		}
+		from, moved := st.pos, false
+		for k, axis := range axes {
+			v, ok := b.word(axis)
+			if !ok || (st.motion == motionCycle && k == cycleDepthAxis(st.plane)) {
+				continue
+			}
+			if st.incremental {
+				v += st.pos[k]
+			}
+			if !st.known[k] {
+				from[k] = v
+				st.known[k] = true
+			}
+			st.pos[k], moved = v, true
+		}
+		arc := st.motion == 2 || st.motion == 3
+		if !moved && !(arc && b.hasCenter()) {
+			continue
+		}
+
+		switch st.motion {
+		case 0, motionCycle:
+			a.Path.Rapid += distance(from, st.pos)
+		case 1:
+			a.Path.Feed += distance(from, st.pos)
+		case 2, 3:
+			length, err := arcLength(b, st, from)
+			if err != "" {
+				warn(b.Line, "%s", err)
+			}
+			a.Path.Feed += length
+		}
+		if (st.motion == 1 || arc) && !st.feed && !feedWarned {
+			warn(b.Line, "feed motion without a feed rate")
+			feedWarned = true
+		}
+	}
+
+	if len(p.Blocks) == 0 {
+		warn(0, "program is empty")
+	} else if !ended {
+		warn(p.Blocks[len(p.Blocks)-1].Line, "program has no end (M02, M30 or M99)")
+	}
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	a.Warnings = warnings
+	if a.Warnings == nil {
+		a.Warnings = []Warning{}
+	}
+
+	a.Path.Feed = round(a.Path.Feed)
+	a.Path.Rapid = round(a.Path.Rapid)
+	a.Path.Total = round(a.Path.Feed + a.Path.Rapid)
+	return a
+}
+
+func addOffset(a *Analysis, seen map[string]bool, name string) {
+	if !seen[name] {
+		seen[name] = true
+		a.WorkOffsets = append(a.WorkOffsets, name)
+	}
+}
+
+func widen(r *Range, v float64) *Range {
+	if r == nil {
+		return &Range{Min: v, Max: v}
+	}
+	r.Min, r.Max = math.Min(r.Min, v), math.Max(r.Max, v)
+	return r
+}
+
+// cycleDepthAxis is the axis a canned cycle drills along; its word is the
+// hole bottom, not a position.
+func cycleDepthAxis(plane int) int {
+	switch plane {
+	case 18:
+		return 1
+	case 19:
+		return 0
+	}
+	return 2
+}
+
+func distance(a, b [3]float64) float64 {
+	return math.Sqrt((b[0]-a[0])*(b[0]-a[0]) + (b[1]-a[1])*(b[1]-a[1]) + (b[2]-a[2])*(b[2]-a[2]))
+}
+
+// arcLength is the length of a G02/G03 move from R or from the I, J, K
+// center; the axis normal to the plane makes it helical.
+func arcLength(b *Block, st state, from [3]float64) (float64, string) {
+	// u, v are the plane axes in the order that makes G02 clockwise, n the
+	// normal axis; centers are I, J, K for X, Y, Z.
+	u, v, n := 0, 1, 2
+	switch st.plane {
+	case 18:
+		u, v, n = 2, 0, 1
+	case 19:
+		u, v, n = 1, 2, 0
+	}
+	centers := [3]string{"I", "J", "K"}
+	su, sv := from[u], from[v]
+	eu, ev := st.pos[u], st.pos[v]
+	height := st.pos[n] - from[n]
+
+	var radius, angle float64
+	if r, ok := b.word("R"); ok {
+		if r == 0 {
+			return distance(from, st.pos), "arc radius R0"
+		}
+		radius = math.Abs(r)
+		chord := math.Hypot(eu-su, ev-sv)
+		angle = 2 * math.Asin(math.Min(1, chord/(2*radius)))
+		if r < 0 {
+			angle = 2*math.Pi - angle
+		}
+	} else {
+		ci, okU := b.word(centers[u])
+		cj, okV := b.word(centers[v])
+		if !okU && !okV {
+			return distance(from, st.pos), "arc without R or I/J/K"
+		}
+		cu, cv := su+ci, sv+cj
+		radius = math.Hypot(su-cu, sv-cv)
+		start := math.Atan2(sv-cv, su-cu)
+		end := math.Atan2(ev-cv, eu-cu)
+		if st.motion == 2 {
+			angle = start - end
+		} else {
+			angle = end - start
+		}
+		for angle <= 1e-9 {
+			angle += 2 * math.Pi
+		}
+	}
+	return math.Hypot(radius*angle, height), ""
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,81 @@
+package gcode
+
+import "strings"
+
+// Ways a block is located by Locate.
+const (
+	MatchBlock    = "block"    // the executing block text was found
+	MatchSequence = "sequence" // only the block with the sequence number was found
+)
+
+// Locate finds the block the control executes from the sequence number it
+// reports (the N word executed last) and the text of the executing block,
+// which may follow the numbered block without an N word of its own. Either
+// may be unknown, 0 or empty. The search starts at from, the block located
+// before, so repeated numbers and texts resolve to the occurrence execution
+// reaches next. It returns -1 if nothing matches.
+func (p *Program) Locate(sequence int, block string, from int) (int, string) {
+	n := len(p.Blocks)
+	if n == 0 {
+		return -1, ""
+	}
+	from = min(max(from, 0), n-1)
+	text := normalizeBlock(block)
+
+	if sequence > 0 {
+		// The numbered block of the block located before comes first.
+		start := from
+		for start > 0 && p.Blocks[start].Sequence == 0 {
+			start--
+		}
+		for k := 0; k < n; k++ {
+			i := (start + k) % n
+			if p.Blocks[i].Sequence != sequence {
+				continue
+			}
+			for j := i; text != "" && j < n && (j == i || p.Blocks[j].Sequence == 0); j++ {
+				if normalizeBlock(p.Blocks[j].Text) == text {
+					return j, MatchBlock
+				}
+			}
+			return i, MatchSequence
+		}
+	}
+
+	if text != "" {
+		for k := 0; k < n; k++ {
+			i := (from + k) % n
+			if normalizeBlock(p.Blocks[i].Text) == text {
+				return i, MatchBlock
+			}
+		}
+	}
+	return -1, ""
+}
+
+// Head returns the index of the first block with a motion code (G00-G03); the
+// blocks before it set the program up. Execution going back there is a
+// restart. It is len(Blocks) if the program does not move.
+func (p *Program) Head() int {
+	for i := range p.Blocks {
+		for _, g := range p.Blocks[i].G {
+			switch g {
+			case "G00", "G01", "G02", "G03":
+				return i
+			}
+		}
+	}
+	return len(p.Blocks)
+}
+
+// normalizeBlock drops blanks and the end of block so texts compare the way
+// the control reads them.
+func normalizeBlock(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.ToUpper(strings.TrimRight(s, ";"))
+}
@@ -0,0 +1,296 @@
+// Package gcode parses Fanuc NC programs into blocks and analyzes them.
+package gcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Program is a parsed NC program.
+type Program struct {
+	Number   int       `json:"number,omitempty"` // O number, 0 if none
+	Blocks   []Block   `json:"blocks"`
+	Warnings []Warning `json:"warnings,omitempty"` // syntax problems
+}
+
+// Block is a line of a program with its words sorted out. Empty lines and '%'
+// lines are not blocks.
+type Block struct {
+	Line     int      `json:"line"` // 1-based line in the text
+	Text     string   `json:"text"`
+	Sequence int      `json:"sequence,omitempty"` // N number
+	Skip     bool     `json:"skip,omitempty"`     // optional block skip '/'
+	Macro    bool     `json:"macro,omitempty"`    // custom macro statement, words are not parsed
+	Comments []string `json:"comments,omitempty"`
+	Words    []Word   `json:"words,omitempty"`
+	G        []string `json:"g,omitempty"` // e.g. G01, G54.1
+	M        []int    `json:"m,omitempty"`
+	Tool     string   `json:"tool,omitempty"` // T word as written, e.g. T0101
+	Feed     *float64 `json:"feed,omitempty"`
+	Speed    *float64 `json:"speed,omitempty"`
+	Call     *Call    `json:"call,omitempty"`
+}
+
+// Word is an address with its value. Expr words carry a macro expression
+// such as #101 or [#1+2.] in Text and no value.
+type Word struct {
+	Letter string  `json:"letter"`
+	Text   string  `json:"text"`
+	Value  float64 `json:"value"`
+	Expr   bool    `json:"expr,omitempty"`
+}
+
+// Call is a subprogram (M98, M198) or macro (G65) call.
+type Call struct {
+	Line    int  `json:"line"`
+	Program int  `json:"program"`
+	Repeat  int  `json:"repeat"`
+	Macro   bool `json:"macro,omitempty"` // G65
+}
+
+// Warning is a problem found in a program line.
+type Warning struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// macroStatements start custom macro blocks.
+var macroStatements = []string{"IF", "WHILE", "GOTO", "END", "DO", "POPEN", "PCLOS", "DPRNT", "BPRNT", "SETVN"}
+
+// Parse splits a program into blocks. It never fails; problems are reported
+// as warnings.
+func Parse(text string) *Program {
+	p := &Program{}
+	sequences := make(map[int]int)
+	for i, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		b, warnings, ok := parseBlock(i+1, raw)
+		p.Warnings = append(p.Warnings, warnings...)
+		if !ok {
+			continue
+		}
+
+		if len(p.Blocks) == 0 && len(b.Words) > 0 && b.Words[0].Letter == "O" {
+			p.Number = int(b.Words[0].Value)
+		}
+		if b.Sequence != 0 {
+			if first, dup := sequences[b.Sequence]; dup {
+				p.Warnings = append(p.Warnings, Warning{b.Line, fmt.Sprintf("sequence number N%d repeats line %d", b.Sequence, first)})
+			} else {
+				sequences[b.Sequence] = b.Line
+			}
+		}
+		p.Blocks = append(p.Blocks, b)
+	}
+	return p
+}
+
+func parseBlock(line int, raw string) (Block, []Warning, bool) {
+	b := Block{Line: line, Text: strings.TrimSpace(raw)}
+	var warnings []Warning
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, Warning{line, fmt.Sprintf(format, args...)})
+	}
+
+	// Comments are taken out first, they may contain any character.
+	var code strings.Builder
+	for rest := b.Text; rest != ""; {
+		i := strings.IndexByte(rest, '(')
+		if i < 0 {
+			code.WriteString(rest)
+			break
+		}
+		code.WriteString(rest[:i])
+		j := strings.IndexByte(rest[i:], ')')
+		if j < 0 {
+			b.Comments = append(b.Comments, strings.TrimSpace(rest[i+1:]))
+			warn("comment is not closed")
+			break
+		}
+		b.Comments = append(b.Comments, strings.TrimSpace(rest[i+1:i+j]))
+		rest = rest[i+j+1:]
+	}
+
+	s := strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, code.String()))
+	s = strings.TrimRight(s, ";")
+	if s == "%" || (s == "" && len(b.Comments) == 0) {
+		return b, warnings, false
+	}
+
+	if strings.HasPrefix(s, "/") {
+		b.Skip = true
+		s = strings.TrimLeft(s[1:], "123456789")
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		statement := len(b.Words) == 0 || (len(b.Words) == 1 && b.Words[0].Letter == "N")
+		if statement && (c == '#' || isMacroStatement(s[i:])) {
+			b.Macro = true
+			break
+		}
+		if c < 'A' || c > 'Z' {
+			warn("unexpected character %q", c)
+			i++
+			continue
+		}
+
+		w := Word{Letter: string(c)}
+		j := i + 1
+		if j < len(s) && (s[j] == '#' || s[j] == '[') {
+			j = exprEnd(s, j)
+			w.Text, w.Expr = s[i+1:j], true
+		} else {
+			for j < len(s) && (s[j] == '+' || s[j] == '-' || s[j] == '.' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			w.Text = s[i+1 : j]
+			if w.Text == "" {
+				warn("address %s has no value", w.Letter)
+				i = j
+				continue
+			}
+			v, err := strconv.ParseFloat(w.Text, 64)
+			if err != nil {
+				warn("invalid value %s%s", w.Letter, w.Text)
+				i = j
+				continue
+			}
+			w.Value = v
+		}
+		b.Words = append(b.Words, w)
+		i = j
+	}
+
+	for k, w := range b.Words {
+		if w.Expr {
+			continue
+		}
+		switch w.Letter {
+		case "N":
+			if k == 0 {
+				b.Sequence = int(w.Value)
+			}
+		case "G":
+			b.G = append(b.G, gCode(w.Value))
+		case "M":
+			b.M = append(b.M, int(w.Value))
+		case "T":
+			b.Tool = "T" + w.Text
+		case "F":
+			v := w.Value
+			b.Feed = &v
+		case "S":
+			v := w.Value
+			b.Speed = &v
+		}
+	}
+	b.Call = blockCall(&b)
+	return b, warnings, true
+}
+
+// exprEnd returns the end of a macro expression starting at s[i]: a variable
+// like #101 or #[#1+1], or a bracketed expression.
+func exprEnd(s string, i int) int {
+	for i < len(s) && s[i] == '#' {
+		i++
+	}
+	if i < len(s) && s[i] == '[' {
+		for depth := 0; i < len(s); i++ {
+			if s[i] == '[' {
+				depth++
+			} else if s[i] == ']' {
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	}
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+func isMacroStatement(s string) bool {
+	for _, kw := range macroStatements {
+		if strings.HasPrefix(s, kw) && (len(s) == len(kw) || s[len(kw)] < 'A' || s[len(kw)] > 'Z') {
+			return true
+		}
+	}
+	return false
+}
+
+// gCode formats a G code value the way programs write it: G01, G54.1.
+func gCode(v float64) string {
+	text := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := strings.IndexByte(text, '.'); i == 1 || (i < 0 && len(text) == 1) {
+		text = "0" + text
+	}
+	return "G" + text
+}
+
+// blockCall finds a subprogram or macro call. M98 P takes the repeat count
+// from L or, in the old format, from the digits in front of the 4-digit
+// program number.
+func blockCall(b *Block) *Call {
+	var call *Call
+	for _, m := range b.M {
+		if m == 98 || m == 198 {
+			call = &Call{Line: b.Line}
+		}
+	}
+	for _, g := range b.G {
+		if g == "G65" {
+			call = &Call{Line: b.Line, Macro: true}
+		}
+	}
+	if call == nil {
+		return nil
+	}
+
+	call.Repeat = 1
+	for _, w := range b.Words {
+		if w.Expr {
+			continue
+		}
+		switch w.Letter {
+		case "P":
+			call.Program = int(w.Value)
+			if digits := strings.TrimLeft(w.Text, "+"); !call.Macro && len(digits) > 4 && !strings.Contains(digits, ".") {
+				call.Repeat, _ = strconv.Atoi(digits[:len(digits)-4])
+				call.Program, _ = strconv.Atoi(digits[len(digits)-4:])
+				call.Repeat = max(call.Repeat, 1)
+			}
+		case "L":
+			call.Repeat = int(w.Value)
+		}
+	}
+	return call
+}
+
+// word returns the value of the first plain word with letter.
+func (b *Block) word(letter string) (float64, bool) {
+	for _, w := range b.Words {
+		if w.Letter == letter && !w.Expr {
+			return w.Value, true
+		}
+	}
+	return 0, false
+}
+
+// hasCenter reports whether the block gives an arc center.
+func (b *Block) hasCenter() bool {
+	for _, letter := range []string{"I", "J", "K"} {
+		if _, ok := b.word(letter); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,382 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// progressInterval bounds how often the progress of a job is stored.
+	progressInterval = time.Second
+	// sweepInterval is how often cancellations requested through other
+	// instances are picked up and expired jobs deleted.
+	sweepInterval = 5 * time.Second
+)
+
+// Func runs a job under ctx. It reports its progress through report and
+// returns the result and the text output, if any.
+type Func func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error)
+
+// Runner runs jobs on a bounded pool of workers. Jobs wait in a queue in the
+// order they were submitted; a job of a machine waits while another job of
+// the same machine runs, so the jobs of a machine run one after the other.
+// Every change of a job is stored, so any instance answers for it and its
+// result outlives it for JOB_RETENTION.
+type Runner struct {
+	cfg      fanucService.JobsConfig
+	repo     interfaces.JobRepository
+	instance string
+	logger   *logrus.Logger
+
+	mu       sync.Mutex
+	wake     *sync.Cond
+	queue    []*task
+	running  map[string]*task // job ID -> task
+	busy     map[string]bool  // machines with a running job
+	pending  int              // submitted jobs being stored, counted against the queue size
+	stopping bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// task is a job of this instance that did not finish.
+type task struct {
+	fn     Func
+	ctx    context.Context // carries the caller of the submitting request
+	cancel context.CancelFunc
+
+	mu        sync.Mutex // guards job once the task was queued
+	job       *entities.Job
+	saved     time.Time
+	cancelled bool // by the caller, not by a shutdown
+}
+
+func NewRunner(cfg *fanucService.Config, repo interfaces.JobRepository, membership *cluster.Membership, logger *logrus.Logger) *Runner {
+	r := &Runner{
+		cfg:      cfg.Jobs,
+		repo:     repo,
+		instance: membership.Self().ID,
+		logger:   logger,
+		running:  make(map[string]*task),
+		busy:     make(map[string]bool),
+	}
+	r.wake = sync.NewCond(&r.mu)
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	return r
+}
+
+// Start fails the jobs an earlier run of this instance left unfinished and
+// starts the workers.
+func (r *Runner) Start() error {
+	if n, err := r.repo.FailActive(r.instance, "interrupted by a restart", time.Now()); err != nil {
+		return fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	} else if n > 0 {
+		r.logger.Warnf("Failed %d jobs interrupted by a restart", n)
+	}
+
+	for i := 0; i < max(r.cfg.Workers, 1); i++ {
+		r.wg.Add(1)
+		go r.work()
+	}
+	r.wg.Add(1)
+	go r.sweep()
+	return nil
+}
+
+// Stop cancels the running jobs and waits for them until ctx ends. Queued
+// and interrupted jobs are failed.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopping = true
+	queued := r.queue
+	r.queue = nil
+	r.wake.Broadcast()
+	r.mu.Unlock()
+	r.cancel()
+
+	for _, t := range queued {
+		r.finish(t, nil, "", errors.New("service stopped"))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		_, err := r.repo.FailActive(r.instance, "service stopped", time.Now())
+		return errors.Join(ctx.Err(), err)
+	}
+}
+
+// Submit stores job and queues fn to run it. ctx only passes the caller on;
+// the job does not end with the request that submitted it. The job is stored
+// outside r.mu, so a slow database does not hold up the workers; its place in
+// the queue is reserved meanwhile.
+func (r *Runner) Submit(ctx context.Context, job *entities.Job, fn Func) error {
+	r.mu.Lock()
+	if r.stopping {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: service is stopping", models.ErrUnavailable)
+	}
+	if queued := len(r.queue) + r.pending; r.cfg.QueueSize > 0 && queued >= r.cfg.QueueSize {
+		r.mu.Unlock()
+		return &models.RateLimitError{Reason: fmt.Sprintf("%d jobs are queued", queued), RetryAfter: 10 * time.Second}
+	}
+	r.pending++
+	r.mu.Unlock()
+
+	job.Instance = r.instance
+	job.Status = entities.JobQueued
+	job.CreatedAt = time.Now()
+	err := r.repo.Create(job)
+
+	taskCtx := r.ctx
+	if p := models.PrincipalFromContext(ctx); p != nil {
+		taskCtx = models.WithPrincipal(taskCtx, p)
+	}
+	t := &task{fn: fn, ctx: taskCtx, job: job}
+
+	r.mu.Lock()
+	r.pending--
+	stopping := r.stopping
+	if err == nil && !stopping {
+		r.queue = append(r.queue, t)
+		r.wake.Signal()
+		r.logger.Infof("Job %s (%s) queued", job.ID, job.Type)
+	}
+	r.mu.Unlock()
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to store job: %w", err)
+	case stopping:
+		r.finish(t, nil, "", errors.New("service stopped"))
+		return fmt.Errorf("%w: service is stopping", models.ErrUnavailable)
+	}
+	return nil
+}
+
+// Get returns a job: the live state of a job of this instance, else the
+// stored one.
+func (r *Runner) Get(id string) (*entities.Job, error) {
+	if t := r.find(id); t != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		job := *t.job
+		job.Output = ""
+		return &job, nil
+	}
+	return r.repo.GetByID(id)
+}
+
+// Cancel cancels a job. A job of another instance is flagged for that
+// instance to cancel within sweepInterval.
+func (r *Runner) Cancel(id string) (*entities.Job, error) {
+	r.mu.Lock()
+	for i, t := range r.queue {
+		if t.job.ID == id {
+			r.queue = append(r.queue[:i], r.queue[i+1:]...)
+			r.mu.Unlock()
+			t.mu.Lock()
+			t.cancelled = true
+			t.mu.Unlock()
+			r.finish(t, nil, "", context.Canceled)
+			return r.Get(id)
+		}
+	}
+	t := r.running[id]
+	r.mu.Unlock()
+
+	if t != nil {
+		t.mu.Lock()
+		t.cancelled = true
+		t.job.CancelRequested = true
+		t.mu.Unlock()
+		t.cancel()
+		if err := r.repo.RequestCancel(id); err != nil {
+			r.logger.Errorf("Failed to store the cancellation of job %s: %v", id, err)
+		}
+		return r.Get(id)
+	}
+
+	job, err := r.repo.GetByID(id)
+	if err != nil || job.Finished() {
+		return job, err
+	}
+	if err := r.repo.RequestCancel(id); err != nil {
+		return nil, err
+	}
+	job.CancelRequested = true
+	return job, nil
+}
+
+func (r *Runner) find(id string) *task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t := r.running[id]; t != nil {
+		return t
+	}
+	for _, t := range r.queue {
+		if t.job.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (r *Runner) work() {
+	defer r.wg.Done()
+	for {
+		t := r.next()
+		if t == nil {
+			return
+		}
+		r.run(t)
+
+		r.mu.Lock()
+		delete(r.running, t.job.ID)
+		delete(r.busy, t.job.MachineID)
+		r.wake.Broadcast()
+		r.mu.Unlock()
+	}
+}
+
+// next takes the first queued job whose machine has no running job, waiting
+// for one. It returns nil when the runner stops.
+func (r *Runner) next() *task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		if r.stopping {
+			return nil
+		}
+		for i, t := range r.queue {
+			machine := t.job.MachineID
+			if machine != "" && r.busy[machine] {
+				continue
+			}
+			r.queue = append(r.queue[:i], r.queue[i+1:]...)
+			if machine != "" {
+				r.busy[machine] = true
+			}
+			r.running[t.job.ID] = t
+			t.ctx, t.cancel = r.jobContext(t.ctx)
+			return t
+		}
+		r.wake.Wait()
+	}
+}
+
+func (r *Runner) jobContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.cfg.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.cfg.Timeout)
+}
+
+func (r *Runner) run(t *task) {
+	defer t.cancel()
+
+	now := time.Now()
+	t.mu.Lock()
+	t.job.Status = entities.JobRunning
+	t.job.StartedAt = &now
+	r.save(t)
+	t.mu.Unlock()
+	r.logger.Infof("Job %s (%s) started", t.job.ID, t.job.Type)
+
+	result, output, err := t.fn(t.ctx, func(done, total int64) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.job.Finished() {
+			return
+		}
+		t.job.Done, t.job.Total = done, total
+		if time.Since(t.saved) >= progressInterval {
+			r.save(t)
+		}
+	})
+	r.finish(t, result, output, err)
+}
+
+// finish stores the end of a job.
+func (r *Runner) finish(t *task, result *entities.JobResult, output string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	job := t.job
+	job.FinishedAt = &now
+	switch {
+	case err == nil:
+		job.Status = entities.JobSucceeded
+		job.Result, job.Output = result, output
+	case t.cancelled:
+		job.Status = entities.JobCancelled
+	default:
+		job.Status = entities.JobFailed
+		job.Error = err.Error()
+		job.Result = result
+	}
+	r.save(t)
+	r.logger.Infof("Job %s (%s) %s", job.ID, job.Type, job.Status)
+}
+
+// save stores the job of t. t.mu must be held.
+func (r *Runner) save(t *task) {
+	t.saved = time.Now()
+	if err := r.repo.Update(t.job); err != nil {
+		r.logger.Errorf("Failed to store job %s: %v", t.job.ID, err)
+	}
+}
+
+// sweep picks up cancellations requested through other instances and deletes
+// the jobs that finished more than JOB_RETENTION ago.
+func (r *Runner) sweep() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ids, err := r.repo.CancelRequested(r.instance)
+		if err != nil {
+			r.logger.Errorf("Failed to read job cancellations: %v", err)
+		}
+		for _, id := range ids {
+			if r.find(id) != nil {
+				_, _ = r.Cancel(id)
+			}
+		}
+
+		if r.cfg.Retention > 0 {
+			if n, err := r.repo.DeleteFinished(time.Now().Add(-r.cfg.Retention)); err != nil {
+				r.logger.Errorf("Failed to delete expired jobs: %v", err)
+			} else if n > 0 {
+				r.logger.Debugf("Deleted %d expired jobs", n)
+			}
+		}
+	}
+}
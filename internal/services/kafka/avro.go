@@ -0,0 +1,291 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
+
+type AvroEncoder struct {
+	registry *SchemaRegistry
+	schema   avro.Schema
+}
+
+func NewAvroEncoder(registry *SchemaRegistry) (*AvroEncoder, error) {
+	schema, err := avro.Parse(fanucv1.MachineDataAvro)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	return &AvroEncoder{registry: registry, schema: schema}, nil
+}
+
+func (e *AvroEncoder) Encode(ctx context.Context, topic string, env *models.MachineDataEnvelope) ([]byte, error) {
+	schemaID, err := e.registry.Register(ctx, valueSubject(topic), SchemaTypeAvro, e.schema.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to register avro schema: %w", err)
+	}
+
+	payload, err := avro.Marshal(e.schema, envelopeToAvro(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal avro payload: %w", err)
+	}
+
+	return frame(schemaID, nil, payload), nil
+}
+
+func (e *AvroEncoder) ContentType() string {
+	return "avro/binary"
+}
+
+// --- Avro records mirroring machine_data.avsc ---
+
+type avroEnvelope struct {
+	SchemaVersion    string            `avro:"schema_version"`
+	ServiceVersion   string            `avro:"service_version"`
+	MachineID        string            `avro:"machine_id"`
+	AdapterMachineID string            `avro:"adapter_machine_id"`
+	Endpoint         string            `avro:"endpoint"`
+	Model            string            `avro:"model"`
+	Series           string            `avro:"series"`
+	Labels           map[string]string `avro:"labels"`
+	Sequence         int64             `avro:"sequence"`
+	PollStartedAt    time.Time         `avro:"poll_started_at"`
+	PollFinishedAt   time.Time         `avro:"poll_finished_at"`
+	PollLatencyMs    int64             `avro:"poll_latency_ms"`
+	Data             avroAggregated    `avro:"data"`
+	Reads            []avroReading     `avro:"reads"`
+	Position         *avroPosition     `avro:"position"`
+}
+
+type avroAggregated struct {
+	MachineID          string             `avro:"machine_id"`
+	Timestamp          time.Time          `avro:"timestamp"`
+	IsEnabled          bool               `avro:"is_enabled"`
+	IsEmergency        bool               `avro:"is_emergency"`
+	MachineState       string             `avro:"machine_state"`
+	ProgramMode        string             `avro:"program_mode"`
+	TmMode             string             `avro:"tm_mode"`
+	AxisMovementStatus string             `avro:"axis_movement_status"`
+	MstbStatus         string             `avro:"mstb_status"`
+	EmergencyStatus    string             `avro:"emergency_status"`
+	AlarmStatus        string             `avro:"alarm_status"`
+	EditStatus         string             `avro:"edit_status"`
+	AxisInfos          []avroAxis         `avro:"axis_infos"`
+	HasAlarms          bool               `avro:"has_alarms"`
+	Alarms             []avroAlarm        `avro:"alarms"`
+	CurrentProgram     avroCurrentProgram `avro:"current_program"`
+	SpindleInfos       []avroSpindle      `avro:"spindle_infos"`
+	ContourFeedRate    int32              `avro:"contour_feed_rate"`
+	ActualFeedRate     int32              `avro:"actual_feed_rate"`
+	FeedOverride       int32              `avro:"feed_override"`
+	JogOverride        int32              `avro:"jog_override"`
+	PartsCount         int64              `avro:"parts_count"`
+	PowerOnTime        string             `avro:"power_on_time"`
+	OperatingTime      string             `avro:"operating_time"`
+	CycleTime          string             `avro:"cycle_time"`
+	CuttingTime        string             `avro:"cutting_time"`
+}
+
+type avroAxis struct {
+	Name             string  `avro:"name"`
+	Position         float64 `avro:"position"`
+	LoadPercent      float64 `avro:"load_percent"`
+	ServoTemperature int32   `avro:"servo_temperature"`
+	CoderTemperature int32   `avro:"coder_temperature"`
+	PowerConsumption int32   `avro:"power_consumption"`
+	Diag301          float64 `avro:"diag_301"`
+}
+
+type avroAlarm struct {
+	ErrorCode            string `avro:"error_code"`
+	ErrorTypeDescription string `avro:"error_type_description"`
+	ErrorMessage         string `avro:"error_message"`
+}
+
+type avroCurrentProgram struct {
+	ProgramName   string `avro:"program_name"`
+	ProgramNumber int64  `avro:"program_number"`
+	GCodeLine     string `avro:"g_code_line"`
+}
+
+type avroSpindle struct {
+	Number           int32   `avro:"number"`
+	SpeedRPM         int32   `avro:"speed_rpm"`
+	LoadPercent      float64 `avro:"load_percent"`
+	OverridePercent  int32   `avro:"override_percent"`
+	PowerConsumption int32   `avro:"power_consumption"`
+	Diag411Value     int32   `avro:"diag_411_value"`
+}
+
+type avroReading struct {
+	Class  string      `avro:"class"`
+	Number int32       `avro:"number"`
+	Count  int32       `avro:"count"`
+	Type   string      `avro:"type"`
+	Axis   int32       `avro:"axis"`
+	Area   string      `avro:"area"`
+	Values []avroValue `avro:"values"`
+	Error  string      `avro:"error"`
+}
+
+type avroValue struct {
+	Number int32   `avro:"number"`
+	Value  float64 `avro:"value"`
+	Vacant bool    `avro:"vacant"`
+}
+
+type avroPosition struct {
+	Number   int32      `avro:"number"`
+	Version  int64      `avro:"version"`
+	Sequence int32      `avro:"sequence"`
+	Block    string     `avro:"block"`
+	Line     int32      `avro:"line"`
+	Matched  string     `avro:"matched"`
+	Lines    []avroLine `avro:"lines"`
+}
+
+type avroLine struct {
+	Line    int32  `avro:"line"`
+	Text    string `avro:"text"`
+	Current bool   `avro:"current"`
+}
+
+func envelopeToAvro(env *models.MachineDataEnvelope) *avroEnvelope {
+	labels := env.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return &avroEnvelope{
+		SchemaVersion:    env.SchemaVersion,
+		ServiceVersion:   env.ServiceVersion,
+		MachineID:        env.MachineID,
+		AdapterMachineID: env.AdapterMachineID,
+		Endpoint:         env.Endpoint,
+		Model:            env.Model,
+		Series:           env.Series,
+		Labels:           labels,
+		Sequence:         int64(env.Sequence),
+		PollStartedAt:    env.PollStartedAt,
+		PollFinishedAt:   env.PollFinishedAt,
+		PollLatencyMs:    env.PollLatencyMs,
+		Data:             aggregatedToAvro(env.Data),
+		Reads:            readingsToAvro(env.Reads),
+		Position:         positionToAvro(env.Position),
+	}
+}
+
+func positionToAvro(p *models.ProgramPosition) *avroPosition {
+	if p == nil {
+		return nil
+	}
+	result := &avroPosition{
+		Number:   int32(p.Number),
+		Version:  int64(p.Version),
+		Sequence: int32(p.Sequence),
+		Block:    p.Block,
+		Line:     int32(p.Line),
+		Matched:  p.Matched,
+		Lines:    []avroLine{},
+	}
+	for _, l := range p.Lines {
+		result.Lines = append(result.Lines, avroLine{Line: int32(l.Line), Text: l.Text, Current: l.Current})
+	}
+	return result
+}
+
+func readingsToAvro(readings []models.DataReading) []avroReading {
+	result := []avroReading{}
+	for _, r := range readings {
+		reading := avroReading{
+			Class:  r.Class,
+			Number: int32(r.Number),
+			Count:  int32(r.Count),
+			Type:   r.Type,
+			Axis:   int32(r.Axis),
+			Area:   r.Area,
+			Values: []avroValue{},
+			Error:  r.Error,
+		}
+		for _, v := range r.Values {
+			reading.Values = append(reading.Values, avroValue{Number: int32(v.Number), Value: v.Value, Vacant: v.Vacant})
+		}
+		result = append(result, reading)
+	}
+	return result
+}
+
+func aggregatedToAvro(d *adapterModels.AggregatedData) avroAggregated {
+	if d == nil {
+		return avroAggregated{}
+	}
+
+	result := avroAggregated{
+		MachineID:          d.MachineID,
+		Timestamp:          d.Timestamp,
+		IsEnabled:          d.IsEnabled,
+		IsEmergency:        d.IsEmergency,
+		MachineState:       d.MachineState,
+		ProgramMode:        d.ProgramMode,
+		TmMode:             d.TmMode,
+		AxisMovementStatus: d.AxisMovementStatus,
+		MstbStatus:         d.MstbStatus,
+		EmergencyStatus:    d.EmergencyStatus,
+		AlarmStatus:        d.AlarmStatus,
+		EditStatus:         d.EditStatus,
+		HasAlarms:          d.HasAlarms,
+		CurrentProgram: avroCurrentProgram{
+			ProgramName:   d.CurrentProgram.ProgramName,
+			ProgramNumber: d.CurrentProgram.ProgramNumber,
+			GCodeLine:     d.CurrentProgram.GCodeLine,
+		},
+		ContourFeedRate: d.ContourFeedRate,
+		ActualFeedRate:  d.ActualFeedRate,
+		FeedOverride:    int32(d.FeedOverride),
+		JogOverride:     d.JogOverride,
+		PartsCount:      d.PartsCount,
+		PowerOnTime:     d.PowerOnTime,
+		OperatingTime:   d.OperatingTime,
+		CycleTime:       d.CycleTime,
+		CuttingTime:     d.CuttingTime,
+		AxisInfos:       []avroAxis{},
+		Alarms:          []avroAlarm{},
+		SpindleInfos:    []avroSpindle{},
+	}
+
+	for _, a := range d.AxisInfos {
+		result.AxisInfos = append(result.AxisInfos, avroAxis{
+			Name:             a.Name,
+			Position:         a.Position,
+			LoadPercent:      a.LoadPercent,
+			ServoTemperature: a.ServoTemperature,
+			CoderTemperature: a.CoderTemperature,
+			PowerConsumption: a.PowerConsumption,
+			Diag301:          a.Diag301,
+		})
+	}
+	for _, a := range d.Alarms {
+		result.Alarms = append(result.Alarms, avroAlarm{
+			ErrorCode:            a.ErrorCode,
+			ErrorTypeDescription: a.ErrorTypeDescription,
+			ErrorMessage:         a.ErrorMessage,
+		})
+	}
+	for _, s := range d.SpindleInfos {
+		result.SpindleInfos = append(result.SpindleInfos, avroSpindle{
+			Number:           int32(s.Number),
+			SpeedRPM:         s.SpeedRPM,
+			LoadPercent:      s.LoadPercent,
+			OverridePercent:  int32(s.OverridePercent),
+			PowerConsumption: s.PowerConsumption,
+			Diag411Value:     s.Diag411Value,
+		})
+	}
+
+	return result
+}
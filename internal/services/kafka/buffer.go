@@ -0,0 +1,612 @@
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DropOldest discards the oldest buffered segments to make room for new messages.
+	DropOldest = "drop_oldest"
+	// DropNewest rejects new messages while the buffer is full.
+	DropNewest = "drop_newest"
+
+	segmentMaxBytes = 16 << 20
+	segmentExt      = ".seg"
+	headFileName    = "head"
+	recordHeaderLen = 8 // body length + crc32
+)
+
+var ErrBufferFull = errors.New("kafka buffer is full")
+
+// Message is a Kafka record as stored in the disk buffer.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+	Time    time.Time
+}
+
+// BufferStats describes the current state of the disk buffer.
+type BufferStats struct {
+	Messages int
+	Bytes    int64
+	Oldest   time.Time
+	Dropped  uint64
+}
+
+// Position points at a record boundary inside the buffer.
+type Position struct {
+	segment uint64
+	offset  int64 // byte offset inside the segment
+	index   int   // number of records before offset inside the segment
+}
+
+type segment struct {
+	id    uint64
+	path  string
+	size  int64 // bytes of valid records
+	count int   // number of valid records
+}
+
+// DiskBuffer is an append-only write-ahead log of undelivered Kafka messages.
+// Messages are stored in numbered segment files and replayed in order; the
+// position of the first undelivered record is persisted in the "head" file.
+type DiskBuffer struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	policy   string
+
+	mu           sync.Mutex
+	segments     []*segment // ordered by id, the last one is written to
+	active       *os.File
+	headOffset   int64 // read offset inside segments[0]
+	headConsumed int   // records already delivered from segments[0]
+	count        int
+	dropped      uint64
+}
+
+// OpenDiskBuffer opens (or creates) the buffer in dir and recovers its state.
+// A truncated record at the end of the last segment, left by a crash, is cut off.
+func OpenDiskBuffer(dir string, maxBytes int64, maxAge time.Duration, policy string) (*DiskBuffer, error) {
+	if policy != DropOldest && policy != DropNewest {
+		return nil, fmt.Errorf("unknown kafka buffer drop policy %q", policy)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create kafka buffer dir: %w", err)
+	}
+
+	b := &DiskBuffer{dir: dir, maxBytes: maxBytes, maxAge: maxAge, policy: policy}
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *DiskBuffer) load() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		b.segments = append(b.segments, &segment{id: id, path: filepath.Join(b.dir, name)})
+	}
+	sort.Slice(b.segments, func(i, j int) bool { return b.segments[i].id < b.segments[j].id })
+
+	for _, seg := range b.segments {
+		if err := scanSegment(seg); err != nil {
+			return fmt.Errorf("failed to scan kafka buffer segment %s: %w", seg.path, err)
+		}
+		b.count += seg.count
+	}
+
+	headID, headOffset := b.readHead()
+	for len(b.segments) > 0 && b.segments[0].id < headID {
+		b.count -= b.segments[0].count
+		os.Remove(b.segments[0].path)
+		b.segments = b.segments[1:]
+	}
+	if len(b.segments) > 0 && b.segments[0].id == headID && headOffset > 0 {
+		if headOffset > b.segments[0].size {
+			headOffset = b.segments[0].size
+		}
+		consumed, err := countRecords(b.segments[0].path, headOffset)
+		if err != nil {
+			return err
+		}
+		b.headOffset = headOffset
+		b.headConsumed = consumed
+		b.count -= consumed
+	}
+
+	nextID := headID
+	if nextID == 0 {
+		nextID = 1
+	}
+	if len(b.segments) > 0 {
+		last := b.segments[len(b.segments)-1]
+		// Drop a torn record written during a crash.
+		if err := os.Truncate(last.path, last.size); err != nil {
+			return err
+		}
+		nextID = last.id
+		if last.size >= segmentMaxBytes {
+			nextID++
+		}
+	}
+	return b.openActive(nextID)
+}
+
+func (b *DiskBuffer) openActive(id uint64) error {
+	var seg *segment
+	if n := len(b.segments); n > 0 && b.segments[n-1].id == id {
+		seg = b.segments[n-1]
+	} else {
+		seg = &segment{id: id, path: filepath.Join(b.dir, fmt.Sprintf("%020d%s", id, segmentExt))}
+		b.segments = append(b.segments, seg)
+	}
+
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open kafka buffer segment: %w", err)
+	}
+	b.active = f
+	return nil
+}
+
+// Append stores the message at the end of the buffer and syncs it to disk.
+func (b *DiskBuffer) Append(msg Message) error {
+	record := encodeRecord(msg)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxBytes > 0 && b.pendingBytes()+int64(len(record)) > b.maxBytes {
+		if b.policy == DropNewest {
+			b.dropped++
+			return ErrBufferFull
+		}
+		for b.pendingBytes()+int64(len(record)) > b.maxBytes && b.count > 0 {
+			if err := b.dropHeadSegment(); err != nil {
+				return err
+			}
+		}
+	}
+
+	active := b.segments[len(b.segments)-1]
+	if active.size >= segmentMaxBytes {
+		if err := b.roll(); err != nil {
+			return err
+		}
+		active = b.segments[len(b.segments)-1]
+	}
+
+	if _, err := b.active.Write(record); err != nil {
+		return fmt.Errorf("failed to write kafka buffer: %w", err)
+	}
+	if err := b.active.Sync(); err != nil {
+		return fmt.Errorf("failed to sync kafka buffer: %w", err)
+	}
+
+	active.size += int64(len(record))
+	active.count++
+	b.count++
+	return nil
+}
+
+// Peek reads up to n undelivered messages from the head of the buffer.
+// Expired messages at the head (older than the max age) are discarded on the way.
+// The returned position must be passed to Commit once the messages are delivered.
+func (b *DiskBuffer) Peek(n int) ([]Message, Position, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []Message
+	var pos Position
+	expired := false
+
+	for i, seg := range b.segments {
+		offset, index := int64(0), 0
+		if i == 0 {
+			offset, index = b.headOffset, b.headConsumed
+		}
+		if offset >= seg.size {
+			continue
+		}
+
+		msgs, sizes, err := readRecords(seg.path, offset, seg.size, n-len(result))
+		if err != nil {
+			return nil, pos, err
+		}
+
+		for j, msg := range msgs {
+			offset += sizes[j]
+			index++
+			pos = Position{segment: seg.id, offset: offset, index: index}
+			if len(result) == 0 && b.maxAge > 0 && time.Since(msg.Time) > b.maxAge {
+				b.advance(pos)
+				b.dropped++
+				expired = true
+				continue
+			}
+			result = append(result, msg)
+		}
+
+		if len(result) >= n {
+			break
+		}
+	}
+
+	if expired {
+		if err := b.writeHead(); err != nil {
+			return nil, pos, err
+		}
+	}
+	return result, pos, nil
+}
+
+// Commit marks everything up to pos as delivered.
+func (b *DiskBuffer) Commit(pos Position) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(pos)
+	return b.writeHead()
+}
+
+func (b *DiskBuffer) advance(pos Position) {
+	if len(b.segments) == 0 || pos.segment < b.segments[0].id ||
+		(pos.segment == b.segments[0].id && pos.offset <= b.headOffset) {
+		// Nothing new, or the records were already dropped by the size cap.
+		return
+	}
+
+	for len(b.segments) > 1 && b.segments[0].id < pos.segment {
+		head := b.segments[0]
+		b.count -= head.count - b.headConsumed
+		os.Remove(head.path)
+		b.segments = b.segments[1:]
+		b.headOffset, b.headConsumed = 0, 0
+	}
+
+	if b.segments[0].id == pos.segment {
+		b.count -= pos.index - b.headConsumed
+		b.headConsumed = pos.index
+		b.headOffset = pos.offset
+	}
+}
+
+// Len returns the number of undelivered messages.
+func (b *DiskBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+func (b *DiskBuffer) Stats() BufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BufferStats{
+		Messages: b.count,
+		Bytes:    b.pendingBytes(),
+		Dropped:  b.dropped,
+	}
+	offset := b.headOffset
+	for _, seg := range b.segments {
+		if b.count == 0 {
+			break
+		}
+		if offset < seg.size {
+			if msgs, _, err := readRecords(seg.path, offset, seg.size, 1); err == nil && len(msgs) > 0 {
+				stats.Oldest = msgs[0].Time
+			}
+			break
+		}
+		offset = 0
+	}
+	return stats
+}
+
+func (b *DiskBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.active == nil {
+		return nil
+	}
+	err := b.active.Close()
+	b.active = nil
+	return err
+}
+
+func (b *DiskBuffer) pendingBytes() int64 {
+	var total int64
+	for _, seg := range b.segments {
+		total += seg.size
+	}
+	return total - b.headOffset
+}
+
+func (b *DiskBuffer) roll() error {
+	if err := b.active.Close(); err != nil {
+		return err
+	}
+	return b.openActive(b.segments[len(b.segments)-1].id + 1)
+}
+
+// dropHeadSegment discards the oldest segment. If it is also the active one,
+// a fresh segment is started in its place.
+func (b *DiskBuffer) dropHeadSegment() error {
+	head := b.segments[0]
+	dropped := head.count - b.headConsumed
+	b.count -= dropped
+	b.dropped += uint64(dropped)
+	b.headOffset, b.headConsumed = 0, 0
+
+	if len(b.segments) == 1 {
+		b.active.Close()
+		os.Remove(head.path)
+		b.segments = nil
+		if err := b.openActive(head.id + 1); err != nil {
+			return err
+		}
+	} else {
+		os.Remove(head.path)
+		b.segments = b.segments[1:]
+	}
+	return b.writeHead()
+}
+
+func (b *DiskBuffer) readHead() (uint64, int64) {
+	data, err := os.ReadFile(filepath.Join(b.dir, headFileName))
+	if err != nil {
+		return 0, 0
+	}
+	parts := strings.Fields(string(data))
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	id, err1 := strconv.ParseUint(parts[0], 10, 64)
+	offset, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0
+	}
+	return id, offset
+}
+
+// writeHead persists the head position atomically (write + rename).
+func (b *DiskBuffer) writeHead() error {
+	var id uint64
+	if len(b.segments) > 0 {
+		id = b.segments[0].id
+	}
+	tmp := filepath.Join(b.dir, headFileName+".tmp")
+	content := fmt.Sprintf("%d %d\n", id, b.headOffset)
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(b.dir, headFileName))
+}
+
+// --- Record format ---
+//
+//	uint32 body length | uint32 crc32(body) | body
+//	body: int64 unix nanos | uint32 len + key | uint32 len + value |
+//	      uint16 header count | (uint16 len + name | uint32 len + value)... |
+//	      uint16 len + topic (optional, absent in records written before topic routing)
+
+func encodeRecord(msg Message) []byte {
+	body := make([]byte, 0, 20+len(msg.Key)+len(msg.Value))
+	body = binary.BigEndian.AppendUint64(body, uint64(msg.Time.UnixNano()))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(msg.Key)))
+	body = append(body, msg.Key...)
+	body = binary.BigEndian.AppendUint32(body, uint32(len(msg.Value)))
+	body = append(body, msg.Value...)
+
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	body = binary.BigEndian.AppendUint16(body, uint16(len(keys)))
+	for _, k := range keys {
+		body = binary.BigEndian.AppendUint16(body, uint16(len(k)))
+		body = append(body, k...)
+		body = binary.BigEndian.AppendUint32(body, uint32(len(msg.Headers[k])))
+		body = append(body, msg.Headers[k]...)
+	}
+	body = binary.BigEndian.AppendUint16(body, uint16(len(msg.Topic)))
+	body = append(body, msg.Topic...)
+
+	record := make([]byte, 0, recordHeaderLen+len(body))
+	record = binary.BigEndian.AppendUint32(record, uint32(len(body)))
+	record = binary.BigEndian.AppendUint32(record, crc32.ChecksumIEEE(body))
+	return append(record, body...)
+}
+
+var errCorruptRecord = errors.New("corrupt kafka buffer record")
+
+func readRecord(r io.Reader) (Message, int64, error) {
+	var header [recordHeaderLen]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, 0, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	if length > segmentMaxBytes*4 {
+		return Message{}, 0, errCorruptRecord
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return Message{}, 0, err
+	}
+	if crc32.ChecksumIEEE(body) != checksum {
+		return Message{}, 0, errCorruptRecord
+	}
+
+	msg, err := decodeBody(body)
+	return msg, int64(recordHeaderLen) + int64(length), err
+}
+
+func decodeBody(body []byte) (Message, error) {
+	var msg Message
+	read := func(n int) ([]byte, bool) {
+		if len(body) < n {
+			return nil, false
+		}
+		chunk := body[:n]
+		body = body[n:]
+		return chunk, true
+	}
+
+	ts, ok := read(8)
+	if !ok {
+		return msg, errCorruptRecord
+	}
+	msg.Time = time.Unix(0, int64(binary.BigEndian.Uint64(ts)))
+
+	for _, dst := range []*[]byte{&msg.Key, &msg.Value} {
+		l, ok := read(4)
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		data, ok := read(int(binary.BigEndian.Uint32(l)))
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		*dst = append([]byte(nil), data...)
+	}
+
+	n, ok := read(2)
+	if !ok {
+		return msg, errCorruptRecord
+	}
+	count := int(binary.BigEndian.Uint16(n))
+	if count > 0 {
+		msg.Headers = make(map[string]string, count)
+	}
+	for i := 0; i < count; i++ {
+		kl, ok := read(2)
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		k, ok := read(int(binary.BigEndian.Uint16(kl)))
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		vl, ok := read(4)
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		v, ok := read(int(binary.BigEndian.Uint32(vl)))
+		if !ok {
+			return msg, errCorruptRecord
+		}
+		msg.Headers[string(k)] = string(v)
+	}
+
+	if len(body) == 0 {
+		return msg, nil
+	}
+	tl, ok := read(2)
+	if !ok {
+		return msg, errCorruptRecord
+	}
+	topic, ok := read(int(binary.BigEndian.Uint16(tl)))
+	if !ok {
+		return msg, errCorruptRecord
+	}
+	msg.Topic = string(topic)
+	return msg, nil
+}
+
+// scanSegment counts the valid records of a segment; anything after the first
+// torn or corrupt record is ignored.
+func scanSegment(seg *segment) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		_, size, err := readRecord(r)
+		if err != nil {
+			return nil
+		}
+		seg.size += size
+		seg.count++
+	}
+}
+
+func countRecords(path string, limit int64) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	count := 0
+	for offset < limit {
+		_, size, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		offset += size
+		count++
+	}
+	return count, nil
+}
+
+func readRecords(path string, offset, limit int64, n int) ([]Message, []int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	r := bufio.NewReader(f)
+	var msgs []Message
+	var sizes []int64
+	for len(msgs) < n && offset < limit {
+		msg, size, err := readRecord(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read kafka buffer: %w", err)
+		}
+		offset += size
+		msgs = append(msgs, msg)
+		sizes = append(sizes, size)
+	}
+	return msgs, sizes, nil
+}
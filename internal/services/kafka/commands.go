@@ -0,0 +1,281 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+const commandTimeout = 30 * time.Second
+
+// CommandReader is the part of kafka.Reader the command consumer uses.
+type CommandReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// ReplySender publishes command replies; the Producer is one.
+type ReplySender interface {
+	Send(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+}
+
+// CommandConsumer reads control commands from the command topic, runs them
+// through the usecases and publishes a reply with the same ID to the response
+// topic. Commands are processed one at a time in topic order; the offset is
+// committed after the reply is queued, so a crash leads to a redelivery rather
+// than a lost command.
+type CommandConsumer struct {
+	reader      CommandReader
+	replies     ReplySender
+	connections interfaces.ConnectionUsecase
+	polling     interfaces.PollingUsecase
+	programs    interfaces.ProgramUsecase
+	audit       interfaces.AuditUsecase
+	cfg         fanucService.KafkaConfig
+	logger      *logrus.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewCommandConsumer(
+	cfg *fanucService.Config,
+	producer *Producer,
+	connections interfaces.ConnectionUsecase,
+	polling interfaces.PollingUsecase,
+	programs interfaces.ProgramUsecase,
+	audit interfaces.AuditUsecase,
+	logger *logrus.Logger,
+) (*CommandConsumer, error) {
+	var reader CommandReader
+	if cfg.Kafka.CommandTopic != "" {
+		dialer, err := newDialer(cfg.Kafka)
+		if err != nil {
+			return nil, err
+		}
+		reader = kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Kafka.Brokers,
+			GroupID: cfg.Kafka.CommandGroup,
+			Topic:   cfg.Kafka.CommandTopic,
+			Dialer:  dialer,
+		})
+	}
+	return NewCommandConsumerWith(cfg, reader, producer, connections, polling, programs, audit, logger), nil
+}
+
+// NewCommandConsumerWith builds a consumer of reader that sends its replies
+// through replies. A nil reader consumes nothing.
+func NewCommandConsumerWith(
+	cfg *fanucService.Config,
+	reader CommandReader,
+	replies ReplySender,
+	connections interfaces.ConnectionUsecase,
+	polling interfaces.PollingUsecase,
+	programs interfaces.ProgramUsecase,
+	audit interfaces.AuditUsecase,
+	logger *logrus.Logger,
+) *CommandConsumer {
+	return &CommandConsumer{
+		reader:      reader,
+		replies:     replies,
+		connections: connections,
+		polling:     polling,
+		programs:    programs,
+		audit:       audit,
+		cfg:         cfg.Kafka,
+		logger:      logger,
+	}
+}
+
+// Start launches the consume loop. It does nothing when no command topic is configured.
+func (c *CommandConsumer) Start() {
+	if c.reader == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go c.run(ctx)
+	c.logger.Infof("Kafka command consumer started on topic %s", c.cfg.CommandTopic)
+}
+
+// Stop waits for the command in progress and closes the reader.
+func (c *CommandConsumer) Stop() error {
+	if c.reader == nil {
+		return nil
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	return c.reader.Close()
+}
+
+func (c *CommandConsumer) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Errorf("Failed to read Kafka command: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(replayInterval):
+			}
+			continue
+		}
+
+		c.handle(ctx, msg)
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			c.logger.Errorf("Failed to commit Kafka command offset: %v", err)
+		}
+	}
+}
+
+func (c *CommandConsumer) handle(ctx context.Context, msg kafka.Message) {
+	var cmd models.Command
+	if err := json.Unmarshal(msg.Value, &cmd); err != nil {
+		cmd.ID = correlationID(msg)
+		c.reply(ctx, &cmd, "", nil, fmt.Errorf("invalid command: %w", err))
+		return
+	}
+	if cmd.ID == "" {
+		cmd.ID = correlationID(msg)
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	started := time.Now()
+	key, data, err := c.execute(cmdCtx, &cmd)
+	c.reply(ctx, &cmd, key, data, err)
+	c.recordAudit(ctx, &cmd, key, msg.Value, started, err)
+}
+
+var commandActions = map[string]string{
+	models.CommandConnect:      entities.AuditConnect,
+	models.CommandDelete:       entities.AuditDelete,
+	models.CommandStartPolling: entities.AuditPollingStart,
+	models.CommandStopPolling:  entities.AuditPollingStop,
+	models.CommandGetProgram:   entities.AuditProgramRead,
+}
+
+// recordAudit writes the audit entry of a known command. Commands have no
+// authenticated caller; the actor is the command consumer itself.
+func (c *CommandConsumer) recordAudit(ctx context.Context, cmd *models.Command, machineID string, payload []byte, started time.Time, cmdErr error) {
+	action, ok := commandActions[cmd.Type]
+	if !ok {
+		return
+	}
+
+	entry := &entities.AuditEntry{
+		Timestamp: started,
+		Actor:     "kafka:" + c.cfg.CommandTopic,
+		Transport: "kafka",
+		Action:    action,
+		MachineID: machineID,
+		Payload:   entities.TruncatePayload(string(payload)),
+		Result:    entities.AuditResultOK,
+	}
+	if cmdErr != nil {
+		entry.Result = entities.AuditResultError
+		entry.Error = cmdErr.Error()
+	}
+	c.audit.Record(ctx, entry)
+}
+
+// execute dispatches the command and returns the reply key (machine ID) and payload.
+func (c *CommandConsumer) execute(ctx context.Context, cmd *models.Command) (string, interface{}, error) {
+	if cmd.Type != models.CommandConnect && cmd.MachineID == "" {
+		return "", nil, errors.New("machine_id is required")
+	}
+
+	switch cmd.Type {
+	case models.CommandConnect:
+		if cmd.Connection == nil || cmd.Connection.Endpoint == "" {
+			return "", nil, errors.New("connection.endpoint is required")
+		}
+		machine, err := c.connections.Create(ctx, *cmd.Connection)
+		if err != nil {
+			return "", nil, err
+		}
+		return machine.ID, machine, nil
+	case models.CommandDelete:
+		return cmd.MachineID, nil, c.connections.Delete(ctx, cmd.MachineID)
+	case models.CommandStartPolling:
+		req := models.StartPollingRequest{ID: cmd.MachineID, Interval: cmd.Interval, Reads: cmd.Reads, WatchOffsets: cmd.WatchOffsets}
+		return cmd.MachineID, nil, c.polling.Start(ctx, req)
+	case models.CommandStopPolling:
+		req := models.StopPollingRequest{ID: cmd.MachineID}
+		return cmd.MachineID, nil, c.polling.Stop(ctx, req)
+	case models.CommandGetProgram:
+		// Unlike HTTP it is not forwarded: another instance replies with ErrNotOwner.
+		program, err := c.programs.GetProgram(ctx, cmd.MachineID)
+		if err != nil {
+			return cmd.MachineID, nil, err
+		}
+		return cmd.MachineID, map[string]string{"program": program}, nil
+	default:
+		return cmd.MachineID, nil, fmt.Errorf("unknown command type %q", cmd.Type)
+	}
+}
+
+func (c *CommandConsumer) reply(ctx context.Context, cmd *models.Command, key string, data interface{}, cmdErr error) {
+	reply := models.CommandReply{
+		ID:        cmd.ID,
+		Type:      cmd.Type,
+		Status:    "ok",
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+	if cmdErr != nil {
+		reply.Status = "error"
+		reply.Message = cmdErr.Error()
+		c.logger.Warnf("Kafka command %s (%s) failed: %v", cmd.ID, cmd.Type, cmdErr)
+	}
+
+	if c.cfg.ResponseTopic == "" {
+		return
+	}
+
+	value, err := json.Marshal(reply)
+	if err != nil {
+		c.logger.Errorf("Failed to marshal reply to command %s: %v", cmd.ID, err)
+		return
+	}
+
+	headers := map[string]string{
+		models.HeaderCorrelationID: cmd.ID,
+		models.HeaderContentType:   jsonEncoder{}.ContentType(),
+	}
+	if err := c.replies.Send(ctx, c.cfg.ResponseTopic, []byte(key), value, headers); err != nil {
+		c.logger.Errorf("Failed to send reply to command %s: %v", cmd.ID, err)
+	}
+}
+
+func correlationID(msg kafka.Message) string {
+	for _, h := range msg.Headers {
+		if h.Key == models.HeaderCorrelationID {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
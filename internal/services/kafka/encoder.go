@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+)
+
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+	EncodingAvro     = "avro"
+)
+
+// Encoder serializes polling envelopes into Kafka message values. Schemas are
+// registered per topic under the "<topic>-value" subject.
+type Encoder interface {
+	Encode(ctx context.Context, topic string, env *models.MachineDataEnvelope) ([]byte, error)
+	ContentType() string
+}
+
+// NewEncoder builds the encoder selected by KAFKA_ENCODING. Protobuf and Avro
+// payloads are framed in the Confluent wire format and require a schema registry.
+func NewEncoder(cfg *fanucService.Config) (Encoder, error) {
+	switch cfg.Kafka.Encoding {
+	case "", EncodingJSON:
+		return jsonEncoder{}, nil
+	case EncodingProtobuf, EncodingAvro:
+		if cfg.Kafka.SchemaRegistryURL == "" {
+			return nil, fmt.Errorf("kafka encoding %q requires KAFKA_SCHEMA_REGISTRY_URL", cfg.Kafka.Encoding)
+		}
+		registry := NewSchemaRegistry(cfg.Kafka.SchemaRegistryURL, cfg.Kafka.SchemaRegistryUser, cfg.Kafka.SchemaRegistryPassword)
+		if cfg.Kafka.Encoding == EncodingProtobuf {
+			return NewProtobufEncoder(registry), nil
+		}
+		return NewAvroEncoder(registry)
+	default:
+		return nil, fmt.Errorf("unknown kafka encoding %q", cfg.Kafka.Encoding)
+	}
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(_ context.Context, _ string, env *models.MachineDataEnvelope) ([]byte, error) {
+	return json.Marshal(env)
+}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func valueSubject(topic string) string {
+	return topic + "-value"
+}
@@ -2,31 +2,469 @@ package kafka
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
 	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	replayInterval     = 2 * time.Second
+	defaultQueueSize   = 100
+	defaultBatchSize   = 100
+	defaultSendTimeout = 10 * time.Second
 )
 
 type Producer struct {
-	writer *kafka.Writer
+	writer  *kafka.Writer
+	encoder Encoder
+	buffer  *DiskBuffer
+	router  *TopicRouter
+	cfg     fanucService.KafkaConfig
+	logger  *logrus.Logger
+
+	mu     sync.Mutex
+	queues map[string]*machineQueue
+	closed bool
+	drains sync.WaitGroup
+
+	done chan struct{}
+	wg   sync.WaitGroup
 }
 
-func NewProducer(cfg *fanucService.Config) *Producer {
+func NewProducer(cfg *fanucService.Config, logger *logrus.Logger) (*Producer, error) {
+	kafkaCfg := cfg.Kafka
+	if kafkaCfg.QueueSize <= 0 {
+		kafkaCfg.QueueSize = defaultQueueSize
+	}
+	if kafkaCfg.BatchSize <= 0 {
+		kafkaCfg.BatchSize = defaultBatchSize
+	}
+	if kafkaCfg.SendTimeout <= 0 {
+		kafkaCfg.SendTimeout = defaultSendTimeout
+	}
+
+	encoder, err := NewEncoder(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := newWriter(kafkaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := NewTopicRouter(kafkaCfg.Topic, kafkaCfg.TopicRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kafkaCfg.QueuePolicy {
+	case DropOldest, DropNewest, Block:
+	default:
+		return nil, fmt.Errorf("unknown kafka queue policy %q", kafkaCfg.QueuePolicy)
+	}
+
+	p := &Producer{
+		writer:  writer,
+		encoder: encoder,
+		router:  router,
+		cfg:     kafkaCfg,
+		logger:  logger,
+		queues:  make(map[string]*machineQueue),
+		done:    make(chan struct{}),
+	}
+
+	if cfg.Kafka.BufferDir != "" {
+		buffer, err := OpenDiskBuffer(cfg.Kafka.BufferDir, cfg.Kafka.BufferMaxBytes, cfg.Kafka.BufferMaxAge, cfg.Kafka.BufferDropPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if pending := buffer.Len(); pending > 0 {
+			logger.Infof("Kafka buffer contains %d undelivered messages, replay scheduled", pending)
+		}
+		p.buffer = buffer
+		p.wg.Add(1)
+		go p.replayLoop()
+	}
+
+	return p, nil
+}
+
+// newWriter builds a writer without a fixed topic: every message carries its
+// own topic chosen by the router or the event type.
+func newWriter(cfg fanucService.KafkaConfig) (*kafka.Writer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers configured")
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(cfg.Kafka.Broker),
-		Topic:    cfg.Kafka.Topic,
-		Balancer: &kafka.LeastBytes{},
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Transport:    transport,
+		Balancer:     &kafka.LeastBytes{},
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: cfg.Linger,
+		WriteTimeout: cfg.SendTimeout,
+		MaxAttempts:  cfg.MaxAttempts,
+	}
+
+	switch cfg.Compression {
+	case "", "none":
+	case "gzip":
+		writer.Compression = kafka.Gzip
+	case "snappy":
+		writer.Compression = kafka.Snappy
+	case "lz4":
+		writer.Compression = kafka.Lz4
+	case "zstd":
+		writer.Compression = kafka.Zstd
+	default:
+		return nil, fmt.Errorf("unknown kafka compression %q", cfg.Compression)
+	}
+
+	switch cfg.RequiredAcks {
+	case "", "all":
+		writer.RequiredAcks = kafka.RequireAll
+	case "one":
+		writer.RequiredAcks = kafka.RequireOne
+	case "none":
+		writer.RequiredAcks = kafka.RequireNone
+	default:
+		return nil, fmt.Errorf("unknown kafka required acks %q", cfg.RequiredAcks)
+	}
+
+	// kafka-go has no idempotent producer. The closest guarantee is acks=all
+	// with a single in-flight batch per machine (see drain), which keeps the
+	// per-machine order; duplicates after retries are detectable by consumers
+	// through the machine-id and sequence headers.
+	if cfg.Idempotent {
+		writer.RequiredAcks = kafka.RequireAll
 	}
-	return &Producer{writer: writer}
+
+	return writer, nil
 }
 
-func (p *Producer) Send(ctx context.Context, key, value []byte) error {
-	return p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   key,
-		Value: value,
-	})
+// SendEnvelope encodes the envelope with the configured encoding and publishes
+// it together with its metadata headers to the topic routed by machine labels.
+func (p *Producer) SendEnvelope(ctx context.Context, key []byte, env *models.MachineDataEnvelope) error {
+	topic := p.router.Topic(env.Labels)
+
+	value, err := p.encoder.Encode(ctx, topic, env)
+	if err != nil {
+		return err
+	}
+
+	headers := env.Headers()
+	headers[models.HeaderContentType] = p.encoder.ContentType()
+
+	return p.Send(ctx, topic, key, value, headers)
 }
 
+// SendAlarmEvent publishes an alarm raise/clear event as JSON to the alarm
+// topic. It does nothing when no alarm topic is configured.
+func (p *Producer) SendAlarmEvent(ctx context.Context, event *models.AlarmEvent) error {
+	return p.sendEvent(ctx, p.cfg.AlarmTopic, event.MachineID, event)
+}
+
+// SendStatusEvent publishes a connection status or polling mode change as JSON
+// to the status topic. It does nothing when no status topic is configured.
+func (p *Producer) SendStatusEvent(ctx context.Context, event *models.StatusEvent) error {
+	return p.sendEvent(ctx, p.cfg.StatusTopic, event.MachineID, event)
+}
+
+// SendOffsetEvent publishes a tool or work offset change as JSON to the offset
+// topic. It does nothing when no offset topic is configured.
+func (p *Producer) SendOffsetEvent(ctx context.Context, event *models.OffsetEvent) error {
+	return p.sendEvent(ctx, p.cfg.OffsetTopic, event.MachineID, event)
+}
+
+// SendProgramEvent publishes a new program version as JSON to the program
+// topic. It does nothing when no program topic is configured.
+func (p *Producer) SendProgramEvent(ctx context.Context, event *models.ProgramEvent) error {
+	return p.sendEvent(ctx, p.cfg.ProgramTopic, event.MachineID, event)
+}
+
+// SendAuditEntry publishes an audit entry as JSON to the audit topic. It does
+// nothing when no audit topic is configured.
+func (p *Producer) SendAuditEntry(ctx context.Context, entry *entities.AuditEntry) error {
+	return p.sendEvent(ctx, p.cfg.AuditTopic, entry.MachineID, entry)
+}
+
+func (p *Producer) sendEvent(ctx context.Context, topic, machineID string, event interface{}) error {
+	if topic == "" {
+		return nil
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal kafka event: %w", err)
+	}
+
+	headers := map[string]string{
+		models.HeaderMachineID:   machineID,
+		models.HeaderContentType: jsonEncoder{}.ContentType(),
+	}
+	return p.Send(ctx, topic, []byte(machineID), value, headers)
+}
+
+// Send puts the message into the send queue of its key (one queue per machine)
+// and returns without waiting for Kafka. When the queue is full the configured
+// backpressure policy applies; the block policy waits at most the send timeout.
+// A send in progress counts in drains, so Close waits for it and for the drain
+// it starts.
+func (p *Producer) Send(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
+	msg := Message{Topic: topic, Key: key, Value: value, Headers: headers, Time: time.Now()}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrProducerClosed
+	}
+	p.drains.Add(1)
+	defer p.drains.Done()
+	q, ok := p.queues[string(key)]
+	if !ok {
+		q = newMachineQueue(string(key), p.cfg.QueueSize, p.cfg.QueuePolicy)
+		p.queues[string(key)] = q
+	} else {
+		q.setEvict(false)
+	}
+	p.mu.Unlock()
+
+	if p.cfg.QueuePolicy == Block && p.cfg.SendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.SendTimeout)
+		defer cancel()
+	}
+
+	start, err := q.push(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if start {
+		p.drains.Add(1)
+		go p.drain(q)
+	}
+	return nil
+}
+
+// drain delivers the queue batch by batch until it is empty. There is at most
+// one drain goroutine per queue, so each machine has one batch in flight.
+func (p *Producer) drain(q *machineQueue) {
+	defer p.drains.Done()
+
+	for {
+		batch := q.take(p.cfg.BatchSize)
+		if batch == nil {
+			p.mu.Lock()
+			if p.queues[q.key] == q && q.evicting() {
+				delete(p.queues, q.key)
+			}
+			p.mu.Unlock()
+			return
+		}
+		p.deliver(batch)
+	}
+}
+
+// RemoveQueue removes the send queue of a key, of a machine that was deleted
+// or stopped polling. Messages still queued are delivered first; a message
+// sent meanwhile keeps the queue.
+func (p *Producer) RemoveQueue(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if q, ok := p.queues[key]; ok && q.setEvict(true) {
+		delete(p.queues, key)
+	}
+}
+
+// deliver writes the batch to Kafka. With the disk buffer enabled, a batch that
+// cannot be delivered is stored and replayed later; while the buffer is not
+// empty new batches are appended to it as well to preserve ordering.
+func (p *Producer) deliver(batch []Message) {
+	if p.buffer != nil && p.buffer.Len() > 0 {
+		p.bufferBatch(batch)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.SendTimeout)
+	err := p.write(ctx, batch...)
+	cancel()
+	if err == nil {
+		return
+	}
+
+	if p.buffer == nil {
+		p.logger.Errorf("Failed to deliver %d messages to Kafka: %v", len(batch), err)
+		return
+	}
+	p.logger.Warnf("Kafka unavailable, %d messages buffered on disk: %v", len(batch), err)
+	p.bufferBatch(batch)
+}
+
+func (p *Producer) bufferBatch(batch []Message) {
+	for _, msg := range batch {
+		if err := p.buffer.Append(msg); err != nil {
+			p.logger.Errorf("Failed to buffer Kafka message: %v", err)
+		}
+	}
+}
+
+// QueueStats reports the state of every per-machine send queue.
+func (p *Producer) QueueStats() []QueueStats {
+	p.mu.Lock()
+	queues := make([]*machineQueue, 0, len(p.queues))
+	for _, q := range p.queues {
+		queues = append(queues, q)
+	}
+	p.mu.Unlock()
+
+	result := make([]QueueStats, 0, len(queues))
+	for _, q := range queues {
+		result = append(result, q.stats())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// BufferStats reports the disk buffer depth and limits.
+func (p *Producer) BufferStats() (stats models.KafkaBufferStats) {
+	stats.Enabled = p.buffer != nil
+	stats.Dir = p.cfg.BufferDir
+	stats.MaxBytes = p.cfg.BufferMaxBytes
+	stats.MaxAge = p.cfg.BufferMaxAge.String()
+	stats.DropPolicy = p.cfg.BufferDropPolicy
+	if p.buffer == nil {
+		return stats
+	}
+
+	s := p.buffer.Stats()
+	stats.Messages = s.Messages
+	stats.Bytes = s.Bytes
+	stats.Dropped = s.Dropped
+	if !s.Oldest.IsZero() {
+		oldest := s.Oldest
+		stats.OldestAt = &oldest
+	}
+	return stats
+}
+
+// Close stops accepting messages, flushes the send queues and stops the replay loop.
 func (p *Producer) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.drains.Wait()
+
+	close(p.done)
+	p.wg.Wait()
+
+	if p.buffer != nil {
+		if err := p.buffer.Close(); err != nil {
+			p.logger.Errorf("Failed to close kafka buffer: %v", err)
+		}
+	}
 	return p.writer.Close()
 }
+
+func (p *Producer) write(ctx context.Context, msgs ...Message) error {
+	records := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		topic := m.Topic
+		if topic == "" {
+			// Records buffered before topic routing existed.
+			topic = p.cfg.Topic
+		}
+		records = append(records, kafka.Message{
+			Topic:   topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: toHeaders(m.Headers),
+			Time:    m.Time,
+		})
+	}
+	return p.writer.WriteMessages(ctx, records...)
+}
+
+func (p *Producer) replayLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(replayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.replay()
+		}
+	}
+}
+
+// replay drains the disk buffer batch by batch until it is empty or Kafka
+// rejects a write.
+func (p *Producer) replay() {
+	for {
+		msgs, pos, err := p.buffer.Peek(p.cfg.BatchSize)
+		if err != nil {
+			p.logger.Errorf("Failed to read kafka buffer: %v", err)
+			return
+		}
+		if len(msgs) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.SendTimeout)
+		err = p.write(ctx, msgs...)
+		cancel()
+		if err != nil {
+			p.logger.Debugf("Kafka buffer replay postponed: %v", err)
+			return
+		}
+
+		if err := p.buffer.Commit(pos); err != nil {
+			p.logger.Errorf("Failed to commit kafka buffer position: %v", err)
+			return
+		}
+		p.logger.Infof("Replayed %d buffered messages to Kafka, %d left", len(msgs), p.buffer.Len())
+
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+	}
+}
+
+// toHeaders converts a header map into Kafka headers ordered by key,
+// so that identical metadata always produces identical records.
+func toHeaders(headers map[string]string) []kafka.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]kafka.Header, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, kafka.Header{Key: k, Value: []byte(headers[k])})
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"google.golang.org/protobuf/proto"
+)
+
+// firstMessageIndex is the Confluent encoding of message index [0]:
+// MachineDataEnvelope is the first message in machine_data.proto.
+var firstMessageIndex = []byte{0}
+
+type ProtobufEncoder struct {
+	registry *SchemaRegistry
+}
+
+func NewProtobufEncoder(registry *SchemaRegistry) *ProtobufEncoder {
+	return &ProtobufEncoder{registry: registry}
+}
+
+func (e *ProtobufEncoder) Encode(ctx context.Context, topic string, env *models.MachineDataEnvelope) ([]byte, error) {
+	schemaID, err := e.registry.Register(ctx, valueSubject(topic), SchemaTypeProtobuf, fanucv1.MachineDataProto)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register protobuf schema: %w", err)
+	}
+
+	payload, err := proto.Marshal(EnvelopeToProto(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal protobuf payload: %w", err)
+	}
+
+	return frame(schemaID, firstMessageIndex, payload), nil
+}
+
+func (e *ProtobufEncoder) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// EnvelopeToProto converts an envelope into its Protobuf representation.
+func EnvelopeToProto(env *models.MachineDataEnvelope) *fanucv1.MachineDataEnvelope {
+	return &fanucv1.MachineDataEnvelope{
+		SchemaVersion:    env.SchemaVersion,
+		ServiceVersion:   env.ServiceVersion,
+		MachineId:        env.MachineID,
+		AdapterMachineId: env.AdapterMachineID,
+		Endpoint:         env.Endpoint,
+		Model:            env.Model,
+		Series:           env.Series,
+		Labels:           env.Labels,
+		Sequence:         env.Sequence,
+		PollStartedAtMs:  env.PollStartedAt.UnixMilli(),
+		PollFinishedAtMs: env.PollFinishedAt.UnixMilli(),
+		PollLatencyMs:    env.PollLatencyMs,
+		Data:             aggregatedToProto(env.Data),
+		Reads:            readingsToProto(env.Reads),
+		Position:         PositionToProto(env.Position),
+	}
+}
+
+// PositionToProto converts a program position into its Protobuf
+// representation.
+func PositionToProto(p *models.ProgramPosition) *fanucv1.ProgramPosition {
+	if p == nil {
+		return nil
+	}
+	result := &fanucv1.ProgramPosition{
+		Number:   int32(p.Number),
+		Version:  uint64(p.Version),
+		Sequence: int32(p.Sequence),
+		Block:    p.Block,
+		Line:     int32(p.Line),
+		Matched:  p.Matched,
+	}
+	for _, l := range p.Lines {
+		result.Lines = append(result.Lines, &fanucv1.ProgramLine{Line: int32(l.Line), Text: l.Text, Current: l.Current})
+	}
+	return result
+}
+
+func readingsToProto(readings []models.DataReading) []*fanucv1.DataReading {
+	if len(readings) == 0 {
+		return nil
+	}
+	result := make([]*fanucv1.DataReading, 0, len(readings))
+	for i := range readings {
+		result = append(result, DataReadingToProto(&readings[i]))
+	}
+	return result
+}
+
+// DataReadingToProto converts a data reading into its Protobuf representation.
+func DataReadingToProto(r *models.DataReading) *fanucv1.DataReading {
+	result := &fanucv1.DataReading{
+		Address: &fanucv1.DataAddress{
+			Class:  r.Class,
+			Number: int32(r.Number),
+			Count:  int32(r.Count),
+			Type:   r.Type,
+			Axis:   int32(r.Axis),
+			Area:   r.Area,
+		},
+		Error: r.Error,
+	}
+	for _, v := range r.Values {
+		result.Values = append(result.Values, &fanucv1.DataValue{Number: int32(v.Number), Value: v.Value, Vacant: v.Vacant})
+	}
+	return result
+}
+
+func aggregatedToProto(d *adapterModels.AggregatedData) *fanucv1.AggregatedData {
+	if d == nil {
+		return nil
+	}
+
+	result := &fanucv1.AggregatedData{
+		MachineId:          d.MachineID,
+		TimestampMs:        d.Timestamp.UnixMilli(),
+		IsEnabled:          d.IsEnabled,
+		IsEmergency:        d.IsEmergency,
+		MachineState:       d.MachineState,
+		ProgramMode:        d.ProgramMode,
+		TmMode:             d.TmMode,
+		AxisMovementStatus: d.AxisMovementStatus,
+		MstbStatus:         d.MstbStatus,
+		EmergencyStatus:    d.EmergencyStatus,
+		AlarmStatus:        d.AlarmStatus,
+		EditStatus:         d.EditStatus,
+		HasAlarms:          d.HasAlarms,
+		CurrentProgram: &fanucv1.CurrentProgramInfo{
+			ProgramName:   d.CurrentProgram.ProgramName,
+			ProgramNumber: d.CurrentProgram.ProgramNumber,
+			GCodeLine:     d.CurrentProgram.GCodeLine,
+		},
+		ContourFeedRate: d.ContourFeedRate,
+		ActualFeedRate:  d.ActualFeedRate,
+		FeedOverride:    int32(d.FeedOverride),
+		JogOverride:     d.JogOverride,
+		PartsCount:      d.PartsCount,
+		PowerOnTime:     d.PowerOnTime,
+		OperatingTime:   d.OperatingTime,
+		CycleTime:       d.CycleTime,
+		CuttingTime:     d.CuttingTime,
+	}
+
+	for _, a := range d.AxisInfos {
+		result.AxisInfos = append(result.AxisInfos, &fanucv1.AxisInfo{
+			Name:             a.Name,
+			Position:         a.Position,
+			LoadPercent:      a.LoadPercent,
+			ServoTemperature: a.ServoTemperature,
+			CoderTemperature: a.CoderTemperature,
+			PowerConsumption: a.PowerConsumption,
+			Diag_301:         a.Diag301,
+		})
+	}
+	for _, a := range d.Alarms {
+		result.Alarms = append(result.Alarms, &fanucv1.AlarmDetail{
+			ErrorCode:            a.ErrorCode,
+			ErrorTypeDescription: a.ErrorTypeDescription,
+			ErrorMessage:         a.ErrorMessage,
+		})
+	}
+	for _, s := range d.SpindleInfos {
+		result.SpindleInfos = append(result.SpindleInfos, &fanucv1.SpindleInfo{
+			Number:           int32(s.Number),
+			SpeedRpm:         s.SpeedRPM,
+			LoadPercent:      s.LoadPercent,
+			OverridePercent:  int32(s.OverridePercent),
+			PowerConsumption: s.PowerConsumption,
+			Diag_411Value:    s.Diag411Value,
+		})
+	}
+
+	return result
+}
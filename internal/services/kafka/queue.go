@@ -0,0 +1,139 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Block makes the sender wait for free space in the queue (bounded by the send timeout).
+const Block = "block"
+
+var (
+	ErrQueueFull      = errors.New("kafka queue is full")
+	ErrProducerClosed = errors.New("kafka producer is closed")
+)
+
+// QueueStats describes a single per-machine send queue.
+type QueueStats struct {
+	Key      string
+	Length   int
+	Capacity int
+	Dropped  uint64
+}
+
+// machineQueue is a bounded FIFO of messages for one message key (one machine).
+// A drain goroutine runs only while the queue has messages, so idle machines
+// cost nothing.
+type machineQueue struct {
+	key      string
+	capacity int
+	policy   string
+
+	mu      sync.Mutex
+	items   []Message
+	running bool
+	evict   bool // remove the queue once it is drained
+	dropped uint64
+	space   chan struct{} // signalled when the drain goroutine takes messages
+}
+
+func newMachineQueue(key string, capacity int, policy string) *machineQueue {
+	return &machineQueue{
+		key:      key,
+		capacity: capacity,
+		policy:   policy,
+		space:    make(chan struct{}, 1),
+	}
+}
+
+// push adds the message according to the backpressure policy. It reports
+// whether a drain goroutine has to be started.
+func (q *machineQueue) push(ctx context.Context, msg Message) (bool, error) {
+	for {
+		q.mu.Lock()
+		if len(q.items) < q.capacity {
+			q.items = append(q.items, msg)
+			start := !q.running
+			q.running = true
+			q.mu.Unlock()
+			return start, nil
+		}
+
+		switch q.policy {
+		case DropOldest:
+			q.items = append(q.items[1:], msg)
+			q.dropped++
+			q.mu.Unlock()
+			return false, nil
+		case DropNewest:
+			q.dropped++
+			q.mu.Unlock()
+			return false, ErrQueueFull
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.space:
+		case <-ctx.Done():
+			q.mu.Lock()
+			q.dropped++
+			q.mu.Unlock()
+			return false, fmt.Errorf("%w: %v", ErrQueueFull, ctx.Err())
+		}
+	}
+}
+
+// take removes up to n messages from the head. When the queue is empty it
+// marks the drain goroutine as stopped and returns nil.
+func (q *machineQueue) take(n int) []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		q.running = false
+		return nil
+	}
+	if n > len(q.items) {
+		n = len(q.items)
+	}
+
+	batch := make([]Message, n)
+	copy(batch, q.items[:n])
+	q.items = q.items[n:]
+
+	select {
+	case q.space <- struct{}{}:
+	default:
+	}
+	return batch
+}
+
+// setEvict marks the queue to be removed once it is drained, or clears the
+// mark when it is used again. It reports whether the queue is drained.
+func (q *machineQueue) setEvict(evict bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.evict = evict
+	return !q.running && len(q.items) == 0
+}
+
+// evicting reports whether the queue is drained and marked to be removed.
+func (q *machineQueue) evicting() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.evict && !q.running && len(q.items) == 0
+}
+
+func (q *machineQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return QueueStats{
+		Key:      q.key,
+		Length:   len(q.items),
+		Capacity: q.capacity,
+		Dropped:  q.dropped,
+	}
+}
@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+
+	// magicByte starts every message in the Confluent wire format.
+	magicByte byte = 0
+)
+
+// SchemaRegistry is a minimal Confluent Schema Registry client. It registers
+// a schema under a subject once and caches the returned ID.
+type SchemaRegistry struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func NewSchemaRegistry(baseURL, username, password string) *SchemaRegistry {
+	return &SchemaRegistry{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 10 * time.Second},
+		ids:      make(map[string]int),
+	}
+}
+
+type registerRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type registerResponse struct {
+	ID int `json:"id"`
+}
+
+// Register registers the schema under the subject (idempotent on the registry
+// side) and returns its global ID. The registry is asked without holding the
+// lock, so a slow registry does not hold up callers of cached subjects;
+// concurrent first calls for a subject may all ask it.
+func (r *SchemaRegistry) Register(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	r.mu.Lock()
+	id, ok := r.ids[subject]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, err := r.register(ctx, subject, schemaType, schema)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	r.ids[subject] = id
+	r.mu.Unlock()
+	return id, nil
+}
+
+func (r *SchemaRegistry) register(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	reqBody := registerRequest{Schema: schema}
+	// The registry treats a missing schemaType as AVRO.
+	if schemaType != SchemaTypeAvro {
+		reqBody.SchemaType = schemaType
+	}
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/subjects/%s/versions", r.baseURL, url.PathEscape(subject))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("schema registry error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result registerResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return 0, fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	return result.ID, nil
+}
+
+// frame prepends the Confluent wire-format header (magic byte and schema ID)
+// and the optional Protobuf message indexes to the payload.
+func frame(schemaID int, indexes []byte, payload []byte) []byte {
+	buf := make([]byte, 0, 5+len(indexes)+len(payload))
+	buf = append(buf, magicByte)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(schemaID))
+	buf = append(buf, indexes...)
+	return append(buf, payload...)
+}
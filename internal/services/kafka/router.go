@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+)
+
+type topicRoute struct {
+	label string
+	value string // "*" matches any value
+	topic string // "{value}" is replaced by the label value
+}
+
+// TopicRouter picks the topic for machine data by machine labels. Rules are
+// checked in order; the first match wins, otherwise the default topic is used.
+//
+// Rule syntax: "label=value:topic", comma separated. A value of "*" matches any
+// value of the label and "{value}" in the topic is replaced by it, so
+// "line=*:fanuc_line_{value}" gives every production line its own topic.
+type TopicRouter struct {
+	defaultTopic string
+	routes       []topicRoute
+}
+
+func NewTopicRouter(defaultTopic, rules string) (*TopicRouter, error) {
+	router := &TopicRouter{defaultTopic: defaultTopic}
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		match, topic, ok := strings.Cut(rule, ":")
+		if !ok || topic == "" {
+			return nil, fmt.Errorf("invalid kafka topic route %q: expected label=value:topic", rule)
+		}
+		label, value, ok := strings.Cut(match, "=")
+		if !ok || label == "" || value == "" {
+			return nil, fmt.Errorf("invalid kafka topic route %q: expected label=value:topic", rule)
+		}
+
+		router.routes = append(router.routes, topicRoute{
+			label: strings.TrimSpace(label),
+			value: strings.TrimSpace(value),
+			topic: strings.TrimSpace(topic),
+		})
+	}
+
+	return router, nil
+}
+
+// Topic returns the data topic for a machine with the given labels.
+func (r *TopicRouter) Topic(labels map[string]string) string {
+	for _, route := range r.routes {
+		value, ok := labels[route.label]
+		if !ok || (route.value != "*" && route.value != value) {
+			continue
+		}
+		return strings.ReplaceAll(route.topic, "{value}", value)
+	}
+	return r.defaultTopic
+}
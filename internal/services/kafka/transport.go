@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// newTransport builds the connection settings shared by all brokers:
+// client ID, TLS and SASL authentication.
+func newTransport(cfg fanucService.KafkaConfig) (*kafka.Transport, error) {
+	transport := &kafka.Transport{ClientID: cfg.ClientID}
+
+	if cfg.TLSEnabled {
+		tlsCfg, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLS = tlsCfg
+	}
+
+	if cfg.SASLMechanism != "" {
+		mechanism, err := newSASLMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+
+	return transport, nil
+}
+
+// newDialer builds the dialer used by consumers with the same TLS and SASL
+// settings as the producer transport.
+func newDialer(cfg fanucService.KafkaConfig) (*kafka.Dialer, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		ClientID:      transport.ClientID,
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           transport.TLS,
+		SASLMechanism: transport.SASL,
+	}, nil
+}
+
+func newTLSConfig(cfg fanucService.KafkaConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kafka CA file %s contains no certificates", cfg.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func newSASLMechanism(cfg fanucService.KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.SASLMechanism) {
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unknown kafka SASL mechanism %q", cfg.SASLMechanism)
+	}
+}
@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"golang.org/x/time/rate"
+)
+
+// busyRetryAfter is suggested to callers rejected because all CNC sessions
+// of the machine are in use; those calls usually take well under a second.
+const busyRetryAfter = time.Second
+
+// minCallerSweep is the number of tracked callers from which idle ones are dropped.
+const minCallerSweep = 1024
+
+// Limiter protects controllers from request floods: it caps the number of
+// parallel FOCAS calls and the request rate per machine, and the request rate
+// per API caller. A zero limit disables the corresponding check.
+type Limiter struct {
+	cfg fanucService.LimitsConfig
+
+	mu       sync.Mutex
+	machines map[string]*machineLimit
+	callers  map[string]*rate.Limiter
+	sweepAt  int // caller count at which idle callers are dropped
+}
+
+type machineLimit struct {
+	slots chan struct{}
+	rate  *rate.Limiter
+}
+
+func NewLimiter(cfg *fanucService.Config) *Limiter {
+	return &Limiter{
+		cfg:      cfg.Limits,
+		machines: make(map[string]*machineLimit),
+		callers:  make(map[string]*rate.Limiter),
+		sweepAt:  minCallerSweep,
+	}
+}
+
+// AcquireMachine reserves a slot for a call to the controller. The returned
+// release must be called when the call is done. It fails with a
+// *models.RateLimitError without waiting when the machine is saturated. The
+// slot is taken before the rate token, so a call refused as busy leaves the
+// rate budget alone.
+func (l *Limiter) AcquireMachine(machineID string) (func(), error) {
+	m := l.machine(machineID)
+
+	release := func() {}
+	if m.slots != nil {
+		select {
+		case m.slots <- struct{}{}:
+			release = func() { <-m.slots }
+		default:
+			return nil, &models.RateLimitError{
+				Reason:     "machine " + machineID + " is busy",
+				RetryAfter: busyRetryAfter,
+			}
+		}
+	}
+
+	if m.rate != nil {
+		if err := allow(m.rate, "machine "+machineID+" request rate exceeded"); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// AllowCaller consumes one request of the caller's rate budget.
+func (l *Limiter) AllowCaller(caller string) error {
+	if l.cfg.KeyRate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	limiter, ok := l.callers[caller]
+	if !ok {
+		if len(l.callers) >= l.sweepAt {
+			l.sweepCallers(time.Now())
+		}
+		limiter = rate.NewLimiter(rate.Limit(l.cfg.KeyRate), burst(l.cfg.KeyRate, l.cfg.KeyBurst))
+		l.callers[caller] = limiter
+	}
+	l.mu.Unlock()
+
+	return allow(limiter, "request rate exceeded")
+}
+
+// Callers returns the number of callers whose rate is tracked.
+func (l *Limiter) Callers() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.callers)
+}
+
+// sweepCallers drops the callers whose budget is full again: they are no
+// different from a caller seen for the first time. The next sweep runs when
+// the number of callers has doubled, so the map stays within twice the number
+// of active callers at an amortized constant cost. l.mu must be held.
+func (l *Limiter) sweepCallers(now time.Time) {
+	for caller, limiter := range l.callers {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.callers, caller)
+		}
+	}
+	l.sweepAt = max(minCallerSweep, 2*len(l.callers))
+}
+
+// Forget drops the state of a deleted machine.
+func (l *Limiter) Forget(machineID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.machines, machineID)
+}
+
+func (l *Limiter) machine(id string) *machineLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m, ok := l.machines[id]
+	if !ok {
+		m = &machineLimit{}
+		if l.cfg.MachineConcurrency > 0 {
+			m.slots = make(chan struct{}, l.cfg.MachineConcurrency)
+		}
+		if l.cfg.MachineRate > 0 {
+			m.rate = rate.NewLimiter(rate.Limit(l.cfg.MachineRate), burst(l.cfg.MachineRate, l.cfg.MachineBurst))
+		}
+		l.machines[id] = m
+	}
+	return m
+}
+
+// allow takes a token, or reports how long until one is available.
+func allow(limiter *rate.Limiter, reason string) error {
+	now := time.Now()
+	r := limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return &models.RateLimitError{Reason: reason, RetryAfter: delay}
+	}
+	return nil
+}
+
+// burst defaults to one second worth of requests, at least one.
+func burst(perSecond float64, configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return int(math.Max(1, math.Ceil(perSecond)))
+}
@@ -0,0 +1,199 @@
+// Package workers runs the operations of each machine on a dedicated
+// goroutine, so a FOCAS handle is never used by two callers at once.
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// queueSize bounds the operations waiting for one machine per priority.
+const queueSize = 16
+
+var ErrMachineRemoved = errors.New("machine connection was removed")
+
+// Priority of an operation. Interactive calls (API, commands) always run
+// before queued polling.
+type Priority int
+
+const (
+	Interactive Priority = iota
+	Polling
+)
+
+// Pool holds one worker per machine. The zero value is ready to use.
+type Pool struct {
+	mu      sync.Mutex
+	workers map[string]*worker
+}
+
+type worker struct {
+	interactive chan *operation
+	polling     chan *operation
+	stop        chan struct{}
+	done        chan struct{}
+
+	// mu is held for reading while an operation is queued and for writing
+	// when the worker stops, so nothing is queued after the queues are drained.
+	mu      sync.RWMutex
+	stopped bool
+}
+
+type operation struct {
+	ctx    context.Context
+	fn     func() error
+	result chan error
+}
+
+// Do runs fn on the machine worker and waits for it. Operations whose context
+// ends while queued are dropped without running; operations of a stopped
+// worker fail with ErrMachineRemoved.
+func (p *Pool) Do(ctx context.Context, machineID string, prio Priority, fn func() error) error {
+	op := &operation{ctx: ctx, fn: fn, result: make(chan error, 1)}
+	if err := p.worker(machineID).enqueue(op, prio); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-op.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop ends the worker of a deleted machine after the operation in progress;
+// the queued operations fail.
+func (p *Pool) Stop(machineID string) {
+	p.mu.Lock()
+	w, ok := p.workers[machineID]
+	delete(p.workers, machineID)
+	p.mu.Unlock()
+
+	if ok {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+// StopAll ends all workers, on shutdown.
+func (p *Pool) StopAll() {
+	p.mu.Lock()
+	ids := make([]string, 0, len(p.workers))
+	for id := range p.workers {
+		ids = append(ids, id)
+	}
+	p.mu.Unlock()
+
+	for _, id := range ids {
+		p.Stop(id)
+	}
+}
+
+func (p *Pool) worker(machineID string) *worker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.workers == nil {
+		p.workers = make(map[string]*worker)
+	}
+	w, ok := p.workers[machineID]
+	if !ok {
+		w = &worker{
+			interactive: make(chan *operation, queueSize),
+			polling:     make(chan *operation, queueSize),
+			stop:        make(chan struct{}),
+			done:        make(chan struct{}),
+		}
+		p.workers[machineID] = w
+		go w.run()
+	}
+	return w
+}
+
+// enqueue waits for room in the queue of prio. It fails once the worker is
+// stopping, also for a caller that took the worker before Stop.
+func (w *worker) enqueue(op *operation, prio Priority) error {
+	queue := w.interactive
+	if prio == Polling {
+		queue = w.polling
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return ErrMachineRemoved
+	}
+	select {
+	case queue <- op:
+		return nil
+	case <-w.stop:
+		return ErrMachineRemoved
+	case <-op.ctx.Done():
+		return op.ctx.Err()
+	}
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+
+	for {
+		// Stop before running anything queued.
+		select {
+		case <-w.stop:
+			w.close()
+			return
+		default:
+		}
+
+		// Drain interactive operations first.
+		select {
+		case op := <-w.interactive:
+			op.run()
+			continue
+		default:
+		}
+
+		select {
+		case op := <-w.interactive:
+			op.run()
+		case op := <-w.polling:
+			op.run()
+		case <-w.stop:
+			w.close()
+			return
+		}
+	}
+}
+
+// close marks the worker stopped once no caller is enqueueing and fails the
+// operations still waiting.
+func (w *worker) close() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	w.cancelQueued()
+}
+
+// cancelQueued fails the operations still waiting when the worker stops.
+func (w *worker) cancelQueued() {
+	for {
+		select {
+		case op := <-w.interactive:
+			op.result <- ErrMachineRemoved
+		case op := <-w.polling:
+			op.result <- ErrMachineRemoved
+		default:
+			return
+		}
+	}
+}
+
+func (op *operation) run() {
+	if err := op.ctx.Err(); err != nil {
+		op.result <- err
+		return
+	}
+	op.result <- op.fn()
+}
@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+// authorizeMachine checks the machine restrictions of the calling API key.
+// Calls without a principal are internal and always allowed.
+func authorizeMachine(ctx context.Context, repo interfaces.Repository, id string) error {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil || !p.Restricted() {
+		return nil
+	}
+
+	machine, err := repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !p.CanAccess(machine.ID, machine.Labels) {
+		return fmt.Errorf("%w: key %s has no access to machine %s", models.ErrForbidden, p.Name, id)
+	}
+	return nil
+}
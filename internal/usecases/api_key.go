@@ -0,0 +1,264 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+)
+
+const (
+	apiKeyPrefix    = "fsk_"
+	apiKeyBytes     = 32
+	apiKeyShownPart = len(apiKeyPrefix) + 8
+)
+
+var knownScopes = map[string]bool{
+	entities.ScopeRead:         true,
+	entities.ScopeControl:      true,
+	entities.ScopeProgram:      true,
+	entities.ScopeProgramWrite: true,
+	entities.ScopeWrite:        true,
+	entities.ScopeAdmin:        true,
+}
+
+type apiKeyUsecase struct {
+	cfg  *fanucService.Config
+	repo interfaces.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(cfg *fanucService.Config, repo interfaces.APIKeyRepository) interfaces.APIKeyUsecase {
+	return &apiKeyUsecase{cfg: cfg, repo: repo}
+}
+
+// Bootstrap stores API_KEY as the admin key named "bootstrap" and fails when
+// neither API_KEY nor any stored key is available: the service must never run
+// with an open API. With AUTH_MODE=jwt keys are not used and not required.
+func (u *apiKeyUsecase) Bootstrap(ctx context.Context) error {
+	if u.cfg.Auth.Mode == auth.ModeJWT {
+		return nil
+	}
+
+	if u.cfg.App.APIKey == "" {
+		count, err := u.repo.CountActive()
+		if err != nil {
+			return fmt.Errorf("failed to count api keys: %w", err)
+		}
+		if count == 0 {
+			return errors.New("no API keys configured: set API_KEY to create the bootstrap admin key")
+		}
+		return nil
+	}
+
+	hash := hashAPIKey(u.cfg.App.APIKey)
+	if existing, err := u.repo.GetByHash(hash); err == nil {
+		// API_KEY is stored already. A revoked one stays revoked; the
+		// service then needs another active key.
+		if existing.RevokedAt == nil {
+			return nil
+		}
+		count, err := u.repo.CountActive()
+		if err != nil {
+			return fmt.Errorf("failed to count api keys: %w", err)
+		}
+		if count == 0 {
+			return errors.New("API_KEY is revoked and no other API key is active: set a new API_KEY")
+		}
+		return nil
+	}
+
+	key, err := u.repo.GetByName(entities.BootstrapKeyName)
+	if errors.Is(err, models.ErrNotFound) {
+		return u.repo.Create(&entities.APIKey{
+			ID:     uuid.New().String(),
+			Name:   entities.BootstrapKeyName,
+			Prefix: shownPart(u.cfg.App.APIKey),
+			Hash:   hash,
+			Scopes: []string{entities.ScopeAdmin},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if key.Hash != hash {
+		key.Hash = hash
+		key.Prefix = shownPart(u.cfg.App.APIKey)
+		return u.repo.Update(key)
+	}
+	return nil
+}
+
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, key string) (*models.Principal, error) {
+	if key == "" {
+		return nil, models.ErrUnauthorized
+	}
+
+	k, err := u.repo.GetByHash(hashAPIKey(key))
+	if err != nil || k.RevokedAt != nil {
+		return nil, models.ErrUnauthorized
+	}
+
+	return &models.Principal{
+		KeyID:      k.ID,
+		Name:       k.Name,
+		AuthMethod: models.AuthMethodAPIKey,
+		Scopes:     k.Scopes,
+		MachineIDs: k.MachineIDs,
+		Labels:     k.Labels,
+	}, nil
+}
+
+func (u *apiKeyUsecase) Create(ctx context.Context, req models.APIKeyRequest) (*models.APIKeyCreated, error) {
+	if req.Name == entities.BootstrapKeyName {
+		return nil, fmt.Errorf("%w: name %q is reserved", models.ErrBadRequest, req.Name)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", models.ErrBadRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !knownScopes[scope] {
+			return nil, fmt.Errorf("%w: unknown scope %q", models.ErrBadRequest, scope)
+		}
+	}
+	if err := checkGrant(ctx, req.Scopes, req.MachineIDs, req.Labels); err != nil {
+		return nil, err
+	}
+
+	plain, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &entities.APIKey{
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		Prefix:     shownPart(plain),
+		Hash:       hashAPIKey(plain),
+		Scopes:     req.Scopes,
+		MachineIDs: req.MachineIDs,
+		Labels:     req.Labels,
+	}
+	if err := u.repo.Create(key); err != nil {
+		return nil, fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return &models.APIKeyCreated{APIKey: key, Key: plain}, nil
+}
+
+// List returns the keys within the rights of the caller, the ones it may
+// rotate and revoke.
+func (u *apiKeyUsecase) List(ctx context.Context) ([]entities.APIKey, error) {
+	all, err := u.repo.GetAll()
+	p := models.PrincipalFromContext(ctx)
+	if err != nil || p == nil {
+		return all, err
+	}
+
+	list := make([]entities.APIKey, 0, len(all))
+	for _, key := range all {
+		if p.Grants(key.Scopes, key.MachineIDs, key.Labels) {
+			list = append(list, key)
+		}
+	}
+	return list, nil
+}
+
+// Rotate replaces the secret of an active key, keeping its name, scopes and
+// restrictions. The old secret stops working immediately.
+func (u *apiKeyUsecase) Rotate(ctx context.Context, id string) (*models.APIKeyCreated, error) {
+	key, err := u.activeKey(id)
+	if err != nil {
+		return nil, err
+	}
+	// The caller receives the new secret, so the key must not exceed its rights.
+	if err := checkGrant(ctx, key.Scopes, key.MachineIDs, key.Labels); err != nil {
+		return nil, err
+	}
+
+	plain, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key.Prefix = shownPart(plain)
+	key.Hash = hashAPIKey(plain)
+	if err := u.repo.Update(key); err != nil {
+		return nil, fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return &models.APIKeyCreated{APIKey: key, Key: plain}, nil
+}
+
+func (u *apiKeyUsecase) Revoke(ctx context.Context, id string) error {
+	key, err := u.activeKey(id)
+	if err != nil {
+		return err
+	}
+
+	if p := models.PrincipalFromContext(ctx); p != nil && p.KeyID == key.ID {
+		return fmt.Errorf("%w: a key cannot revoke itself", models.ErrBadRequest)
+	}
+	// A caller restricted to some machines only revokes keys within them.
+	if err := checkGrant(ctx, key.Scopes, key.MachineIDs, key.Labels); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	return u.repo.Update(key)
+}
+
+func (u *apiKeyUsecase) activeKey(id string) (*entities.APIKey, error) {
+	key, err := u.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key %s is revoked", models.ErrBadRequest, id)
+	}
+	return key, nil
+}
+
+// checkGrant refuses a key with rights the caller does not hold itself: more
+// scopes, or machines beyond its own restrictions.
+func checkGrant(ctx context.Context, scopes, machineIDs []string, labels map[string]string) error {
+	p := models.PrincipalFromContext(ctx)
+	if p == nil || p.Grants(scopes, machineIDs, labels) {
+		return nil
+	}
+	return fmt.Errorf("%w: the key would have rights beyond those of the caller", models.ErrForbidden)
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashAPIKey uses plain SHA-256: keys are 256-bit random values, so a slow
+// password hash adds nothing, and a deterministic hash allows an indexed lookup.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func shownPart(key string) string {
+	if len(key) > apiKeyShownPart {
+		return key[:apiKeyShownPart]
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditUsecase struct {
+	repo     interfaces.AuditRepository
+	producer *kafka.Producer
+	logger   *logrus.Logger
+}
+
+func NewAuditUsecase(repo interfaces.AuditRepository, producer *kafka.Producer, logger *logrus.Logger) interfaces.AuditUsecase {
+	return &auditUsecase{repo: repo, producer: producer, logger: logger}
+}
+
+// Record fills the caller from the context and stores the entry. Failures are
+// logged and never fail the audited operation.
+func (u *auditUsecase) Record(ctx context.Context, entry *entities.AuditEntry) {
+	u.fill(ctx, entry)
+	if err := u.repo.Create(entry); err != nil {
+		u.logger.Errorf("Failed to store audit entry %s (%s): %v", entry.Action, entry.MachineID, err)
+	}
+	u.publish(ctx, entry)
+}
+
+// Begin stores a pending entry before an operation that must not run
+// unaudited. The operation is refused when it returns an error.
+func (u *auditUsecase) Begin(ctx context.Context, entry *entities.AuditEntry) error {
+	u.fill(ctx, entry)
+	entry.Result = entities.AuditResultPending
+	if err := u.repo.Create(entry); err != nil {
+		u.logger.Errorf("Failed to store audit entry %s (%s), refusing the operation: %v", entry.Action, entry.MachineID, err)
+		return fmt.Errorf("%w: %v", models.ErrAuditFailed, err)
+	}
+	return nil
+}
+
+// Finish stores the result of an entry started with Begin and publishes it.
+func (u *auditUsecase) Finish(ctx context.Context, entry *entities.AuditEntry) {
+	if err := u.repo.Update(entry); err != nil {
+		u.logger.Errorf("Failed to store the result of audit entry %s (%s): %v", entry.ID, entry.Action, err)
+	}
+	u.publish(ctx, entry)
+}
+
+func (u *auditUsecase) fill(ctx context.Context, entry *entities.AuditEntry) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if p := models.PrincipalFromContext(ctx); p != nil {
+		entry.Actor = p.Name
+		entry.AuthMethod = p.AuthMethod
+		entry.KeyID = p.KeyID
+	}
+}
+
+func (u *auditUsecase) publish(ctx context.Context, entry *entities.AuditEntry) {
+	if err := u.producer.SendAuditEntry(ctx, entry); err != nil {
+		u.logger.Errorf("Failed to send audit entry to Kafka: %v", err)
+	}
+}
+
+func (u *auditUsecase) List(ctx context.Context, filter models.AuditFilter) ([]entities.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	return u.repo.Find(filter)
+}
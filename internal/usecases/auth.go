@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+)
+
+type authUsecase struct {
+	mode  string
+	keys  interfaces.APIKeyUsecase
+	jwt   *auth.JWTVerifier
+	certs *auth.CertMapper
+}
+
+func NewAuthUsecase(cfg *fanucService.Config, keys interfaces.APIKeyUsecase, jwt *auth.JWTVerifier, certs *auth.CertMapper) interfaces.AuthUsecase {
+	mode := cfg.Auth.Mode
+	if mode == "" {
+		mode = auth.ModeAPIKey
+	}
+	return &authUsecase{mode: mode, keys: keys, jwt: jwt, certs: certs}
+}
+
+// Authenticate accepts the credential types allowed by AUTH_MODE. A bearer
+// token takes precedence over an API key when both are sent; a verified client
+// certificate is only used when neither is present.
+func (u *authUsecase) Authenticate(ctx context.Context, creds models.Credentials) (*models.Principal, error) {
+	if creds.BearerToken != "" && u.jwt != nil {
+		return u.jwt.Verify(ctx, creds.BearerToken)
+	}
+	if creds.BearerToken == "" && creds.APIKey == "" && creds.ClientCertificate != nil && u.certs != nil {
+		return u.certs.Map(creds.ClientCertificate)
+	}
+	if u.mode == auth.ModeJWT {
+		return nil, models.ErrUnauthorized
+	}
+	return u.keys.Authenticate(ctx, creds.APIKey)
+}
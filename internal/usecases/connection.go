@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/iwtcode/fanucService/internal/domain/entities"
 	"github.com/iwtcode/fanucService/internal/domain/models"
@@ -10,24 +11,53 @@ import (
 
 type connectionUsecase struct {
 	service interfaces.FanucService
+	repo    interfaces.Repository
 }
 
-func NewConnectionUsecase(service interfaces.FanucService) interfaces.ConnectionUsecase {
-	return &connectionUsecase{service: service}
+func NewConnectionUsecase(service interfaces.FanucService, repo interfaces.Repository) interfaces.ConnectionUsecase {
+	return &connectionUsecase{service: service, repo: repo}
 }
 
 func (u *connectionUsecase) Create(ctx context.Context, req models.ConnectionRequest) (*entities.Machine, error) {
+	// A restricted key may only create machines that fall under its label
+	// restriction; keys limited to machine IDs cannot create new ones.
+	if p := models.PrincipalFromContext(ctx); p != nil && p.Restricted() && !p.CanAccess("", req.Labels) {
+		return nil, fmt.Errorf("%w: key %s cannot create machines outside its restriction", models.ErrForbidden, p.Name)
+	}
+
 	return u.service.CreateConnection(ctx, req)
 }
 
 func (u *connectionUsecase) List(ctx context.Context) ([]entities.Machine, error) {
-	return u.service.GetConnections(ctx)
+	machines, err := u.service.GetConnections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := models.PrincipalFromContext(ctx)
+	if p == nil || !p.Restricted() {
+		return machines, nil
+	}
+
+	allowed := make([]entities.Machine, 0, len(machines))
+	for _, m := range machines {
+		if p.CanAccess(m.ID, m.Labels) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed, nil
 }
 
 func (u *connectionUsecase) Delete(ctx context.Context, id string) error {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return err
+	}
 	return u.service.DeleteConnection(ctx, id)
 }
 
 func (u *connectionUsecase) Check(ctx context.Context, id string) (*entities.Machine, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
 	return u.service.CheckConnection(ctx, id)
 }
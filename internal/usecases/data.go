@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+type dataUsecase struct {
+	service interfaces.FanucService
+	repo    interfaces.Repository
+}
+
+func NewDataUsecase(service interfaces.FanucService, repo interfaces.Repository) interfaces.DataUsecase {
+	return &dataUsecase{service: service, repo: repo}
+}
+
+func (u *dataUsecase) Read(ctx context.Context, id string, addr entities.DataAddress) (*models.DataReading, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.ReadData(ctx, id, addr)
+}
+
+func (u *dataUsecase) Write(ctx context.Context, req models.WriteDataRequest) (*models.WriteResult, error) {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return nil, err
+	}
+	return u.service.WriteData(ctx, req)
+}
+
+func (u *dataUsecase) SetWriteRules(ctx context.Context, req models.WriteRulesRequest) (*entities.Machine, error) {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return nil, err
+	}
+	return u.service.SetWriteRules(ctx, req.ID, req.Rules)
+}
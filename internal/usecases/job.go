@@ -0,0 +1,293 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/jobs"
+)
+
+const (
+	defaultJobLimit = 100
+	maxJobLimit     = 1000
+)
+
+// jobScopes are the scopes a caller needs to start a job of a type, the ones
+// of the API calls the job makes.
+var jobScopes = map[string]string{
+	entities.JobProgramDownload: entities.ScopeProgram,
+	entities.JobConnectionCheck: entities.ScopeRead,
+	entities.JobProgramDrift:    entities.ScopeProgram,
+}
+
+type jobUsecase struct {
+	cfg         fanucService.JobsConfig
+	runner      *jobs.Runner
+	jobs        interfaces.JobRepository
+	repo        interfaces.Repository
+	programs    interfaces.ProgramUsecase
+	connections interfaces.ConnectionUsecase
+	versions    interfaces.ProgramVersionUsecase
+}
+
+func NewJobUsecase(
+	cfg *fanucService.Config,
+	runner *jobs.Runner,
+	jobRepo interfaces.JobRepository,
+	repo interfaces.Repository,
+	programs interfaces.ProgramUsecase,
+	connections interfaces.ConnectionUsecase,
+	versions interfaces.ProgramVersionUsecase,
+) interfaces.JobUsecase {
+	return &jobUsecase{
+		cfg:         cfg.Jobs,
+		runner:      runner,
+		jobs:        jobRepo,
+		repo:        repo,
+		programs:    programs,
+		connections: connections,
+		versions:    versions,
+	}
+}
+
+// Create checks the request against the caller and queues the job. The job
+// runs with the rights of the caller.
+func (u *jobUsecase) Create(ctx context.Context, req models.JobRequest) (*entities.Job, error) {
+	scope, ok := jobScopes[req.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown job type %q", models.ErrBadRequest, req.Type)
+	}
+	job := &entities.Job{ID: uuid.NewString(), Type: req.Type}
+	if p := models.PrincipalFromContext(ctx); p != nil {
+		if !p.HasScope(scope) {
+			return nil, fmt.Errorf("%w: a %s job needs the %s scope", models.ErrForbidden, req.Type, scope)
+		}
+		job.Owner, job.CreatedBy = p.CallerID(), p.Name
+	}
+
+	var fn jobs.Func
+	switch req.Type {
+	case entities.JobProgramDownload:
+		if req.ID == "" || req.Number < 0 {
+			return nil, fmt.Errorf("%w: id and a program number or 0 are required", models.ErrBadRequest)
+		}
+		if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+			return nil, err
+		}
+		job.MachineID, job.Number = req.ID, req.Number
+		fn = u.download(req.ID, req.Number)
+	default:
+		ids, err := u.machines(ctx, req.IDs)
+		if err != nil {
+			return nil, err
+		}
+		job.MachineIDs = ids
+		if req.Type == entities.JobConnectionCheck {
+			fn = eachMachine(ids, u.check)
+		} else {
+			fn = eachMachine(ids, u.drift)
+		}
+	}
+
+	if err := u.runner.Submit(ctx, job, fn); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Get returns a job of the caller.
+func (u *jobUsecase) Get(ctx context.Context, id string) (*entities.Job, error) {
+	job, err := u.runner.Get(id)
+	if err != nil || !visible(ctx, job) {
+		return nil, fmt.Errorf("%w: job %s", models.ErrNotFound, id)
+	}
+	return job, nil
+}
+
+// Output returns the text a succeeded job produced, the program of a
+// program_download job.
+func (u *jobUsecase) Output(ctx context.Context, id string) (string, error) {
+	job, err := u.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if job.Type != entities.JobProgramDownload || job.Status != entities.JobSucceeded {
+		return "", fmt.Errorf("%w: job %s (%s, %s) has no output", models.ErrNotFound, id, job.Type, job.Status)
+	}
+	return u.jobs.Output(id)
+}
+
+func (u *jobUsecase) Cancel(ctx context.Context, id string) (*entities.Job, error) {
+	if _, err := u.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return u.runner.Cancel(id)
+}
+
+func (u *jobUsecase) List(ctx context.Context, filter models.JobFilter) ([]entities.Job, error) {
+	if p := models.PrincipalFromContext(ctx); p != nil && !p.HasScope(entities.ScopeAdmin) {
+		filter.Owner = p.CallerID()
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultJobLimit
+	}
+	if filter.Limit > maxJobLimit {
+		filter.Limit = maxJobLimit
+	}
+	return u.jobs.Find(filter)
+}
+
+// visible reports whether the caller may see a job: its creator and admins
+// may.
+func visible(ctx context.Context, job *entities.Job) bool {
+	p := models.PrincipalFromContext(ctx)
+	return p == nil || p.HasScope(entities.ScopeAdmin) || job.Owner == p.CallerID()
+}
+
+// machines returns the machines of a bulk job: ids if the caller may access
+// them, else all machines the caller may access.
+func (u *jobUsecase) machines(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) > 0 {
+		for _, id := range ids {
+			if err := authorizeMachine(ctx, u.repo, id); err != nil {
+				return nil, err
+			}
+		}
+		return ids, nil
+	}
+
+	all, err := u.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	p := models.PrincipalFromContext(ctx)
+	for _, m := range all {
+		if p == nil || p.CanAccess(m.ID, m.Labels) {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no machines", models.ErrNotFound)
+	}
+	return ids, nil
+}
+
+// download reads a program into the job output, reporting the bytes read.
+// The output is stored in the job row, so a program over JOB_OUTPUT_MAX_SIZE
+// fails the job.
+func (u *jobUsecase) download(id string, number int) jobs.Func {
+	return func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error) {
+		stat, err := u.programs.Stat(ctx, id, number)
+		if err != nil {
+			return nil, "", err
+		}
+		limit := u.cfg.OutputMaxSize
+		if limit > 0 && stat.Length > limit {
+			return nil, "", outputTooLarge(stat.Number, limit)
+		}
+
+		var text strings.Builder
+		hash := sha256.New()
+		w := &progressWriter{w: io.MultiWriter(&text, hash), total: max(stat.Length, 0), report: report}
+		var out io.Writer = w
+		if limit > 0 {
+			out = &limitedWriter{w: w, n: limit, err: outputTooLarge(stat.Number, limit)}
+		}
+		if err := u.programs.Stream(ctx, id, stat.Number, out, 0, -1); err != nil {
+			return nil, "", err
+		}
+
+		program := &entities.JobProgram{
+			Number: stat.Number,
+			Length: int64(text.Len()),
+			SHA256: hex.EncodeToString(hash.Sum(nil)),
+		}
+		return &entities.JobResult{Program: program}, text.String(), nil
+	}
+}
+
+func (u *jobUsecase) check(ctx context.Context, id string) entities.JobMachineResult {
+	result := entities.JobMachineResult{MachineID: id}
+	machine, err := u.connections.Check(ctx, id)
+	if machine != nil {
+		result.Status = machine.Status
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (u *jobUsecase) drift(ctx context.Context, id string) entities.JobMachineResult {
+	result := entities.JobMachineResult{MachineID: id}
+	drift, err := u.versions.Drift(ctx, id)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Number, result.Drifted = drift.Number, &drift.Drifted
+	return result
+}
+
+// eachMachine runs fn for the machines one after the other. A machine that
+// fails is reported in the result; the job only fails when it is cancelled
+// or times out, with the results so far.
+func eachMachine(ids []string, fn func(ctx context.Context, id string) entities.JobMachineResult) jobs.Func {
+	return func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error) {
+		result := &entities.JobResult{Machines: make([]entities.JobMachineResult, 0, len(ids))}
+		report(0, int64(len(ids)))
+		for i, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return result, "", err
+			}
+			result.Machines = append(result.Machines, fn(ctx, id))
+			report(int64(i+1), int64(len(ids)))
+		}
+		return result, "", nil
+	}
+}
+
+func outputTooLarge(number int, limit int64) error {
+	return fmt.Errorf("%w: program O%04d is larger than the %d byte job output limit (JOB_OUTPUT_MAX_SIZE)", models.ErrTooLarge, number, limit)
+}
+
+// limitedWriter fails with err once more than n bytes were written through
+// it.
+type limitedWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (l *limitedWriter) Write(b []byte) (int, error) {
+	if int64(len(b)) > l.n {
+		return 0, l.err
+	}
+	n, err := l.w.Write(b)
+	l.n -= int64(n)
+	return n, err
+}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	w      io.Writer
+	done   int64
+	total  int64
+	report func(done, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.report(p.done, p.total)
+	return n, err
+}
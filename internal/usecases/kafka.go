@@ -0,0 +1,35 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+)
+
+type kafkaUsecase struct {
+	producer *kafka.Producer
+}
+
+func NewKafkaUsecase(producer *kafka.Producer) interfaces.KafkaUsecase {
+	return &kafkaUsecase{producer: producer}
+}
+
+func (u *kafkaUsecase) BufferStats(ctx context.Context) models.KafkaBufferStats {
+	return u.producer.BufferStats()
+}
+
+func (u *kafkaUsecase) QueueStats(ctx context.Context) []models.KafkaQueueStats {
+	stats := u.producer.QueueStats()
+	result := make([]models.KafkaQueueStats, 0, len(stats))
+	for _, q := range stats {
+		result = append(result, models.KafkaQueueStats{
+			Key:      q.Key,
+			Length:   q.Length,
+			Capacity: q.Capacity,
+			Dropped:  q.Dropped,
+		})
+	}
+	return result
+}
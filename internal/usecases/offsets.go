@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+)
+
+type offsetUsecase struct {
+	service interfaces.FanucService
+	repo    interfaces.Repository
+}
+
+func NewOffsetUsecase(service interfaces.FanucService, repo interfaces.Repository) interfaces.OffsetUsecase {
+	return &offsetUsecase{service: service, repo: repo}
+}
+
+func (u *offsetUsecase) ToolOffsets(ctx context.Context, id string) (*models.ToolOffsets, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.ReadToolOffsets(ctx, id)
+}
+
+func (u *offsetUsecase) WorkOffsets(ctx context.Context, id string) (*models.WorkOffsets, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.ReadWorkOffsets(ctx, id)
+}
+
+func (u *offsetUsecase) WriteToolOffset(ctx context.Context, req models.ToolOffsetWriteRequest) (*models.OffsetChange, error) {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return nil, err
+	}
+	return u.service.WriteToolOffset(ctx, req)
+}
+
+func (u *offsetUsecase) WriteWorkOffset(ctx context.Context, req models.WorkOffsetWriteRequest) (*models.OffsetChange, error) {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return nil, err
+	}
+	return u.service.WriteWorkOffset(ctx, req)
+}
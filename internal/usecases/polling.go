@@ -9,20 +9,35 @@ import (
 
 type pollingUsecase struct {
 	service interfaces.FanucService
+	repo    interfaces.Repository
 }
 
-func NewPollingUsecase(service interfaces.FanucService) interfaces.PollingUsecase {
-	return &pollingUsecase{service: service}
+func NewPollingUsecase(service interfaces.FanucService, repo interfaces.Repository) interfaces.PollingUsecase {
+	return &pollingUsecase{service: service, repo: repo}
 }
 
 func (u *pollingUsecase) Start(ctx context.Context, req models.StartPollingRequest) error {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return err
+	}
+
 	if req.Interval <= 0 {
 		req.Interval = 5000
 	}
 
-	return u.service.StartPolling(ctx, req.ID, req.Interval)
+	return u.service.StartPolling(ctx, req)
 }
 
 func (u *pollingUsecase) Stop(ctx context.Context, req models.StopPollingRequest) error {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return err
+	}
 	return u.service.StopPolling(ctx, req.ID)
 }
+
+func (u *pollingUsecase) Watch(ctx context.Context, id string) (<-chan *models.MachineDataEnvelope, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.WatchMachineData(ctx, id)
+}
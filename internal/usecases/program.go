@@ -2,18 +2,121 @@ package usecases
 
 import (
 	"context"
+	"io"
 
+	"github.com/iwtcode/fanucService/internal/domain/models"
 	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/gcode"
 )
 
+// maxPositionLines bounds the context lines before and after the executing
+// block.
+const maxPositionLines = 100
+
 type programUsecase struct {
 	service interfaces.FanucService
+	repo    interfaces.Repository
 }
 
-func NewProgramUsecase(service interfaces.FanucService) interfaces.ProgramUsecase {
-	return &programUsecase{service: service}
+func NewProgramUsecase(service interfaces.FanucService, repo interfaces.Repository) interfaces.ProgramUsecase {
+	return &programUsecase{service: service, repo: repo}
 }
 
 func (u *programUsecase) GetProgram(ctx context.Context, id string) (string, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return "", err
+	}
 	return u.service.GetControlProgram(ctx, id)
 }
+
+func (u *programUsecase) List(ctx context.Context, id string) (*models.ProgramDirectory, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.ListPrograms(ctx, id)
+}
+
+func (u *programUsecase) Download(ctx context.Context, id string, number int) (string, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return "", err
+	}
+	return u.service.ReadProgram(ctx, id, number)
+}
+
+// Stat prepares streaming program number, 0 for the executing program.
+func (u *programUsecase) Stat(ctx context.Context, id string, number int) (*models.ProgramStat, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.StatProgram(ctx, id, number)
+}
+
+// Stream writes a byte range of the text of program number, as resolved by
+// Stat, to w.
+func (u *programUsecase) Stream(ctx context.Context, id string, number int, w io.Writer, offset, length int64) error {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return err
+	}
+	return u.service.StreamProgram(ctx, id, number, w, offset, length)
+}
+
+func (u *programUsecase) Upload(ctx context.Context, req models.ProgramUploadRequest) (*models.ProgramInfo, error) {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return nil, err
+	}
+	return u.service.UploadProgram(ctx, req)
+}
+
+func (u *programUsecase) Delete(ctx context.Context, id string, number int) error {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return err
+	}
+	return u.service.DeleteProgram(ctx, id, number)
+}
+
+func (u *programUsecase) Select(ctx context.Context, req models.ProgramSelectRequest) error {
+	if err := authorizeMachine(ctx, u.repo, req.ID); err != nil {
+		return err
+	}
+	return u.service.SelectProgram(ctx, req.ID, req.Number)
+}
+
+// Analyze downloads program number, 0 for the executing program, and
+// analyzes it.
+func (u *programUsecase) Analyze(ctx context.Context, id string, number int, blocks bool) (*gcode.Analysis, error) {
+	var (
+		text string
+		err  error
+	)
+	if number == 0 {
+		text, err = u.GetProgram(ctx, id)
+	} else {
+		text, err = u.Download(ctx, id, number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return analyzeProgram(text, blocks), nil
+}
+
+func (u *programUsecase) AnalyzeText(ctx context.Context, req models.ProgramAnalyzeRequest) *gcode.Analysis {
+	return analyzeProgram(req.Program, req.Blocks)
+}
+
+// Position locates the executing block; lines below 0 take the configured
+// context, more than maxPositionLines are cut.
+func (u *programUsecase) Position(ctx context.Context, id string, lines int) (*models.ProgramPosition, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+	return u.service.ProgramPosition(ctx, id, min(lines, maxPositionLines))
+}
+
+func analyzeProgram(text string, blocks bool) *gcode.Analysis {
+	p := gcode.Parse(text)
+	a := gcode.Analyze(p)
+	if blocks {
+		a.Program = p
+	}
+	return a
+}
@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/diff"
+)
+
+const (
+	defaultVersionLimit = 100
+	maxVersionLimit     = 1000
+)
+
+type programVersionUsecase struct {
+	service  interfaces.FanucService
+	versions interfaces.ProgramVersionRepository
+	repo     interfaces.Repository
+}
+
+func NewProgramVersionUsecase(service interfaces.FanucService, versions interfaces.ProgramVersionRepository, repo interfaces.Repository) interfaces.ProgramVersionUsecase {
+	return &programVersionUsecase{service: service, versions: versions, repo: repo}
+}
+
+func (u *programVersionUsecase) List(ctx context.Context, filter models.ProgramVersionFilter) ([]entities.ProgramVersion, error) {
+	if err := authorizeMachine(ctx, u.repo, filter.MachineID); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultVersionLimit
+	}
+	if filter.Limit > maxVersionLimit {
+		filter.Limit = maxVersionLimit
+	}
+	return u.versions.Find(filter)
+}
+
+func (u *programVersionUsecase) Get(ctx context.Context, version uint) (*entities.ProgramVersion, error) {
+	v, err := u.versions.GetByID(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: program version %d", err, version)
+	}
+	if err := authorizeMachine(ctx, u.repo, v.MachineID); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Diff returns the unified diff between two versions; to 0 stands for the
+// latest version of the program of from.
+func (u *programVersionUsecase) Diff(ctx context.Context, from, to uint) (string, error) {
+	a, err := u.Get(ctx, from)
+	if err != nil {
+		return "", err
+	}
+
+	var b *entities.ProgramVersion
+	if to == 0 {
+		b, err = u.versions.Latest(a.MachineID, a.Number)
+	} else {
+		b, err = u.Get(ctx, to)
+	}
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified(versionName(a), versionName(b), a.Content, b.Content, diff.DefaultContext), nil
+}
+
+// Approve makes a version the approved one of its program on its machine.
+func (u *programVersionUsecase) Approve(ctx context.Context, req models.ProgramApproveRequest) (*entities.ProgramVersion, error) {
+	v, err := u.Get(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	v.Approved = true
+	v.ApprovedAt = &now
+	v.ApprovedBy = ""
+	if p := models.PrincipalFromContext(ctx); p != nil {
+		v.ApprovedBy = p.Name
+	}
+	if err := u.versions.Approve(v); err != nil {
+		return nil, err
+	}
+
+	v.Content = ""
+	return v, nil
+}
+
+// Drift reads the program the machine executes now and compares it with the
+// approved version of its number.
+func (u *programVersionUsecase) Drift(ctx context.Context, id string) (*models.ProgramDrift, error) {
+	if err := authorizeMachine(ctx, u.repo, id); err != nil {
+		return nil, err
+	}
+
+	current, err := u.service.SnapshotProgram(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &models.ProgramDrift{MachineID: id, Number: current.Number, Current: current}
+	if approved, err := u.versions.Approved(id, current.Number); err == nil {
+		drift.Approved = approved
+		drift.Drifted = approved.Hash != current.Hash
+		if drift.Drifted {
+			drift.Diff = diff.Unified(versionName(approved), versionName(current), approved.Content, current.Content, diff.DefaultContext)
+		}
+		approved.Content = ""
+	}
+	current.Content = ""
+	return drift, nil
+}
+
+// versionName labels a version in diff headers.
+func versionName(v *entities.ProgramVersion) string {
+	return fmt.Sprintf("%s/O%04d@%d\t%s", v.MachineID, v.Number, v.ID, v.CreatedAt.Format(time.RFC3339))
+}
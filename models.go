@@ -1,6 +1,9 @@
 package fanucService
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 // ConnectionRequest payload to create a connection
 type ConnectionRequest struct {
@@ -8,12 +11,16 @@ type ConnectionRequest struct {
 	Timeout  int    `json:"timeout"`                     // ms, default 5000
 	Model    string `json:"model"`                       // Human readable name, default "Unknown"
 	Series   string `json:"series"`                      // "0i", "31i", default "Unknown"
+
+	Labels map[string]string `json:"labels,omitempty"` // e.g. {"line": "A", "shop": "2"}
 }
 
 // StartPollingRequest payload to start polling
 type StartPollingRequest struct {
-	ID       string `json:"id" binding:"required"`
-	Interval int    `json:"interval"` // ms, default 5000
+	ID           string        `json:"id" binding:"required"`
+	Interval     int           `json:"interval"`                // ms, default 5000
+	Reads        []DataAddress `json:"reads,omitempty"`         // read with every poll into the snapshot
+	WatchOffsets bool          `json:"watch_offsets,omitempty"` // publish offset changes to the offset topic
 }
 
 // StopPollingRequest payload to stop polling
@@ -30,13 +37,351 @@ type ConnectionResponse struct {
 
 // MachineDTO represents the machine data sent to clients
 type MachineDTO struct {
-	ID        string    `json:"id"`
-	Endpoint  string    `json:"endpoint"`
-	Timeout   int       `json:"timeout"`
-	Model     string    `json:"model"`
-	Series    string    `json:"series"`
-	Interval  int       `json:"interval"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string            `json:"id"`
+	Endpoint     string            `json:"endpoint"`
+	Timeout      int               `json:"timeout"`
+	Model        string            `json:"model"`
+	Series       string            `json:"series"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Interval     int               `json:"interval"`
+	Reads        []DataAddress     `json:"reads,omitempty"`
+	WatchOffsets bool              `json:"watch_offsets,omitempty"`
+	Status       string            `json:"status"`
+	WriteRules   []WriteRule       `json:"write_rules,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// DataAddress selects Count consecutive values of one data class starting at
+// Number: parameter, macro variable or diagnostic numbers, or the byte address
+// within a PMC area.
+type DataAddress struct {
+	Class  string `json:"class"`           // parameter, macro, pmc, diagnosis
+	Number int    `json:"number"`          // first number or PMC address
+	Count  int    `json:"count,omitempty"` // default 1, max 100
+	Type   string `json:"type,omitempty"`  // byte, word, dword, real
+	Axis   int    `json:"axis,omitempty"`  // axis of a parameter or diagnosis, 0 - none
+	Area   string `json:"area,omitempty"`  // PMC area: G, F, Y, X, A, R, T, K, C, D, E
+}
+
+// DataReading is the result of reading a DataAddress
+type DataReading struct {
+	DataAddress
+	Values []DataValue `json:"values"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// DataValue is one value of a DataReading
+type DataValue struct {
+	Number int     `json:"number"`
+	Value  float64 `json:"value"`
+	Vacant bool    `json:"vacant,omitempty"` // macro variable without a value
+}
+
+// WriteRule allows writing Count consecutive macro variables, PMC values or
+// offset numbers starting at Number with values between Min and Max
+type WriteRule struct {
+	Class  string  `json:"class"`            // macro, pmc, tool_offset, work_offset
+	Number int     `json:"number"`           // first number or PMC address
+	Count  int     `json:"count,omitempty"`  // default 1
+	Type   string  `json:"type,omitempty"`   // PMC value type, default byte
+	Area   string  `json:"area,omitempty"`   // PMC area
+	Offset string  `json:"offset,omitempty"` // tool offset type or axis, empty allows all
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// WriteRulesRequest payload to replace the write rules of a machine
+type WriteRulesRequest struct {
+	ID    string      `json:"id"`
+	Rules []WriteRule `json:"rules"`
+}
+
+// WriteDataRequest writes Values to consecutive macro variables or PMC
+// addresses starting at Number
+type WriteDataRequest struct {
+	ID       string    `json:"id"`
+	Class    string    `json:"class"`          // macro, pmc
+	Number   int       `json:"number"`         // first number or PMC address
+	Type     string    `json:"type,omitempty"` // PMC value type, default byte
+	Area     string    `json:"area,omitempty"` // PMC area
+	Values   []float64 `json:"values"`
+	DryRun   bool      `json:"dry_run,omitempty"`  // check without writing
+	Override bool      `json:"override,omitempty"` // write even in automatic operation
+}
+
+// WriteResult reports the values before a write and read back after it
+type WriteResult struct {
+	DataAddress
+	Previous   []DataValue `json:"previous"`
+	Values     []DataValue `json:"values"`
+	DryRun     bool        `json:"dry_run,omitempty"`
+	Overridden bool        `json:"overridden,omitempty"`
+}
+
+// ToolOffsets is the tool offset table of a machine. Types lists the offset
+// types of the machine: x/z/radius/tip/y wear and geometry on a lathe, offset
+// (memory A), wear and geometry (B) or radius and length wear and geometry (C)
+// on a mill
+type ToolOffsets struct {
+	Turning bool         `json:"turning"`
+	Memory  string       `json:"memory,omitempty"` // A, B or C on a mill
+	Types   []string     `json:"types"`
+	Offsets []ToolOffset `json:"offsets"`
+}
+
+// ToolOffset holds the values of one tool offset number by offset type
+type ToolOffset struct {
+	Number int                `json:"number"`
+	Values map[string]float64 `json:"values"`
+}
+
+// WorkOffsets is the work coordinate system table of a machine
+type WorkOffsets struct {
+	Axes    []string     `json:"axes"`
+	Offsets []WorkOffset `json:"offsets"`
+}
+
+// WorkOffset holds the values of one work coordinate system by axis
+type WorkOffset struct {
+	Number int                `json:"number"` // 0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on
+	Name   string             `json:"name"`
+	Values map[string]float64 `json:"values"`
+}
+
+// ToolOffsetWriteRequest sets one tool offset value
+type ToolOffsetWriteRequest struct {
+	ID          string  `json:"id"`
+	Number      int     `json:"number"`
+	Type        string  `json:"type"` // one of ToolOffsets.Types
+	Value       float64 `json:"value"`
+	Incremental bool    `json:"incremental,omitempty"` // add Value to the current value
+	Override    bool    `json:"override,omitempty"`    // write even in automatic operation
+}
+
+// WorkOffsetWriteRequest sets the value of one axis of a work offset
+type WorkOffsetWriteRequest struct {
+	ID          string  `json:"id"`
+	Number      int     `json:"number"` // 0 EXT, 1-6 G54-G59, 7 on G54.1 P1 on
+	Axis        string  `json:"axis"`
+	Value       float64 `json:"value"`
+	Incremental bool    `json:"incremental,omitempty"` // add Value to the current value
+	Override    bool    `json:"override,omitempty"`    // write even in automatic operation
+}
+
+// OffsetChange reports the value of an offset before a write and read back
+// after it
+type OffsetChange struct {
+	Number   int     `json:"number"`
+	Name     string  `json:"name,omitempty"` // work offset name
+	Offset   string  `json:"offset"`         // tool offset type or axis
+	Previous float64 `json:"previous"`
+	Value    float64 `json:"value"`
+
+	Overridden bool `json:"overridden,omitempty"`
+}
+
+// ProgramDirectory lists the programs in CNC memory
+type ProgramDirectory struct {
+	Running  int           `json:"running"` // number of the running program, 0 if none
+	Main     int           `json:"main"`    // main program selected for automatic operation
+	Programs []ProgramInfo `json:"programs"`
+}
+
+// ProgramInfo is a program in CNC memory
+type ProgramInfo struct {
+	Number     int        `json:"number"`
+	Name       string     `json:"name"` // e.g. O1234
+	Size       int        `json:"size"` // bytes
+	Comment    string     `json:"comment,omitempty"`
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// ProgramRange resumes a program download: the text is read from Offset if
+// it still has ETag, else from the start
+type ProgramRange struct {
+	Offset int64
+	ETag   string // ETag of the part read before, empty to skip the check
+}
+
+// ProgramStream is a program text read while the CNC uploads it
+type ProgramStream struct {
+	io.ReadCloser
+	Offset int64  // first byte of the text in the stream
+	Length int64  // bytes in the stream, -1 if unknown
+	Size   int64  // bytes of the whole text, -1 if unknown
+	ETag   string // empty until the program was downloaded in full once
+}
+
+// ProgramUploadRequest stores Program in CNC memory under the O number it
+// starts with
+type ProgramUploadRequest struct {
+	ID        string `json:"id"`
+	Program   string `json:"program"`
+	Overwrite bool   `json:"overwrite,omitempty"` // replace a program with the same number
+}
+
+// ProgramSelectRequest makes a program the main program
+type ProgramSelectRequest struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+}
+
+// ProgramAnalyzeRequest analyzes a program text that is not on a machine
+type ProgramAnalyzeRequest struct {
+	Program string `json:"program"`
+}
+
+// ProgramAnalysis summarizes a program: tools, work offsets, subprogram calls,
+// feed and speed ranges, estimated path length and syntax warnings
+type ProgramAnalysis struct {
+	Number      int              `json:"number,omitempty"`
+	Blocks      int              `json:"blocks"`
+	Units       string           `json:"units"` // mm, inch
+	Tools       []ProgramTool    `json:"tools"`
+	WorkOffsets []string         `json:"work_offsets"` // e.g. G54, G54.1 P3
+	Calls       []ProgramCall    `json:"calls,omitempty"`
+	Path        ProgramPath      `json:"path"`
+	Feed        *ValueRange      `json:"feed,omitempty"`
+	Speed       *ValueRange      `json:"speed,omitempty"`
+	Warnings    []ProgramWarning `json:"warnings"`
+}
+
+// ProgramTool is a tool called by a program
+type ProgramTool struct {
+	Tool  string `json:"tool"` // T word as written, e.g. T0101
+	Line  int    `json:"line"` // first call
+	Calls int    `json:"calls"`
+}
+
+// ProgramCall is a subprogram (M98, M198) or macro (G65) call
+type ProgramCall struct {
+	Line    int  `json:"line"`
+	Program int  `json:"program"`
+	Repeat  int  `json:"repeat"`
+	Macro   bool `json:"macro,omitempty"`
+}
+
+// ProgramPath is the estimated tool path length in program units
+type ProgramPath struct {
+	Feed  float64 `json:"feed"`
+	Rapid float64 `json:"rapid"`
+	Total float64 `json:"total"`
+}
+
+// ValueRange is the lowest and the highest programmed value
+type ValueRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ProgramWarning is a problem found in a program line
+type ProgramWarning struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ProgramPosition is the executing block located in the program text
+type ProgramPosition struct {
+	MachineID string        `json:"machine_id"`
+	Number    int           `json:"number"`
+	Version   uint          `json:"version,omitempty"`  // stored version of the program text
+	Sequence  int           `json:"sequence,omitempty"` // N number executed last
+	Block     string        `json:"block,omitempty"`    // executing block as the CNC reports it
+	Line      int           `json:"line,omitempty"`     // 0 if the block is not found
+	Matched   string        `json:"matched,omitempty"`  // block, sequence
+	Lines     []ProgramLine `json:"lines,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ProgramLine is a line of a program text
+type ProgramLine struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Current bool   `json:"current,omitempty"`
+}
+
+// ProgramApproveRequest approves a recorded program version
+type ProgramApproveRequest struct {
+	Version uint `json:"version"`
+}
+
+// ProgramVersion is a distinct text of a program recorded for a machine
+type ProgramVersion struct {
+	ID         uint       `json:"id"`
+	MachineID  string     `json:"machine_id"`
+	Number     int        `json:"number"`
+	Hash       string     `json:"hash"` // sha256 of the text
+	Size       int        `json:"size"`
+	Source     string     `json:"source"` // download, upload, snapshot
+	Approved   bool       `json:"approved"`
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	SeenAt     time.Time  `json:"seen_at"`
+}
+
+// ProgramDrift compares the executing program with its approved version
+type ProgramDrift struct {
+	MachineID string          `json:"machine_id"`
+	Number    int             `json:"number"`
+	Current   *ProgramVersion `json:"current"`
+	Approved  *ProgramVersion `json:"approved,omitempty"`
+	Drifted   bool            `json:"drifted"`
+	Diff      string          `json:"diff,omitempty"` // unified diff approved -> current
+}
+
+// JobRequest starts a background job
+type JobRequest struct {
+	Type   string   `json:"type"`             // program_download, connection_check, program_drift
+	ID     string   `json:"id,omitempty"`     // program_download
+	Number int      `json:"number,omitempty"` // program_download, 0 - the executing program
+	IDs    []string `json:"ids,omitempty"`    // connection_check, program_drift; empty - all machines
+}
+
+// Job is a long operation run in the background
+type Job struct {
+	ID              string     `json:"id"`
+	Type            string     `json:"type"`
+	MachineID       string     `json:"machine_id,omitempty"`
+	MachineIDs      []string   `json:"machine_ids,omitempty"`
+	Number          int        `json:"number,omitempty"`
+	CreatedBy       string     `json:"created_by,omitempty"`
+	Instance        string     `json:"instance,omitempty"`
+	Status          string     `json:"status"` // queued, running, succeeded, failed, cancelled
+	Done            int64      `json:"done"`   // bytes of the program or machines
+	Total           int64      `json:"total"`  // 0 - unknown
+	Result          *JobResult `json:"result,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	CancelRequested bool       `json:"cancel_requested,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job ended
+func (j *Job) Finished() bool {
+	return j.Status == "succeeded" || j.Status == "failed" || j.Status == "cancelled"
+}
+
+// JobResult is what a job produced
+type JobResult struct {
+	Program  *JobProgram        `json:"program,omitempty"`
+	Machines []JobMachineResult `json:"machines,omitempty"`
+}
+
+// JobProgram describes the program text a program_download job read
+type JobProgram struct {
+	Number int    `json:"number"`
+	Length int64  `json:"length"`
+	SHA256 string `json:"sha256"`
+}
+
+// JobMachineResult is the outcome of a job for one machine
+type JobMachineResult struct {
+	MachineID string `json:"machine_id"`
+	Status    string `json:"status,omitempty"`  // connection_check
+	Number    int    `json:"number,omitempty"`  // program_drift
+	Drifted   *bool  `json:"drifted,omitempty"` // program_drift
+	Error     string `json:"error,omitempty"`
 }
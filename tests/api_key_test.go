@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAPIKeys is an in-memory interfaces.APIKeyRepository. Hashes are
+// unique, as in the database.
+type memoryAPIKeys struct {
+	mu   sync.Mutex
+	keys []*entities.APIKey
+}
+
+func (r *memoryAPIKeys) Create(key *entities.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.Hash == key.Hash {
+			return errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *memoryAPIKeys) Update(key *entities.APIKey) error { return nil }
+
+func (r *memoryAPIKeys) find(match func(k *entities.APIKey) bool) (*entities.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if match(k) {
+			return k, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *memoryAPIKeys) GetByID(id string) (*entities.APIKey, error) {
+	return r.find(func(k *entities.APIKey) bool { return k.ID == id })
+}
+
+func (r *memoryAPIKeys) GetByHash(hash string) (*entities.APIKey, error) {
+	return r.find(func(k *entities.APIKey) bool { return k.Hash == hash })
+}
+
+func (r *memoryAPIKeys) GetByName(name string) (*entities.APIKey, error) {
+	return r.find(func(k *entities.APIKey) bool { return k.Name == name && k.RevokedAt == nil })
+}
+
+func (r *memoryAPIKeys) GetAll() ([]entities.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]entities.APIKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		list = append(list, *k)
+	}
+	return list, nil
+}
+
+func (r *memoryAPIKeys) CountActive() (int64, error) {
+	list, _ := r.GetAll()
+	var n int64
+	for _, k := range list {
+		if k.RevokedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// newAPIKeys returns a key usecase with "test-api-key" as the bootstrap admin key.
+func newAPIKeys(t *testing.T) interfaces.APIKeyUsecase {
+	cfg := &fanucService.Config{App: fanucService.AppConfig{APIKey: "test-api-key"}}
+	keys := usecases.NewAPIKeyUsecase(cfg, &memoryAPIKeys{})
+	require.NoError(t, keys.Bootstrap(context.Background()))
+	return keys
+}
+
+func TestAPIKeys_RefuseStartWithoutKey(t *testing.T) {
+	keys := usecases.NewAPIKeyUsecase(&fanucService.Config{}, &memoryAPIKeys{})
+	assert.Error(t, keys.Bootstrap(context.Background()))
+
+	_, err := keys.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+}
+
+func TestAPIKeys_CreateRotateRevoke(t *testing.T) {
+	keys := newAPIKeys(t)
+	ctx := context.Background()
+
+	created, err := keys.Create(ctx, models.APIKeyRequest{
+		Name:   "line-a",
+		Scopes: []string{entities.ScopeControl},
+		Labels: map[string]string{"line": "A"},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Key, created.Hash)
+
+	p, err := keys.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, p.HasScope(entities.ScopeControl))
+	assert.False(t, p.HasScope(entities.ScopeProgram))
+	assert.True(t, p.CanAccess("m1", map[string]string{"line": "A", "shop": "2"}))
+	assert.False(t, p.CanAccess("m2", map[string]string{"line": "B"}))
+
+	rotated, err := keys.Rotate(ctx, created.ID)
+	require.NoError(t, err)
+	_, err = keys.Authenticate(ctx, created.Key)
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+	_, err = keys.Authenticate(ctx, rotated.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Revoke(ctx, created.ID))
+	_, err = keys.Authenticate(ctx, rotated.Key)
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+
+	_, err = keys.Create(ctx, models.APIKeyRequest{Name: "bad", Scopes: []string{"root"}})
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+}
+
+func TestAPIKeys_BootstrapAfterRevokedKey(t *testing.T) {
+	cfg := &fanucService.Config{App: fanucService.AppConfig{APIKey: "test-api-key"}}
+	repo := &memoryAPIKeys{}
+	keys := usecases.NewAPIKeyUsecase(cfg, repo)
+	ctx := context.Background()
+	require.NoError(t, keys.Bootstrap(ctx))
+
+	other, err := keys.Create(ctx, models.APIKeyRequest{Name: "ops", Scopes: []string{entities.ScopeAdmin}})
+	require.NoError(t, err)
+	bootstrap, err := repo.GetByName(entities.BootstrapKeyName)
+	require.NoError(t, err)
+	require.NoError(t, keys.Revoke(ctx, bootstrap.ID))
+
+	// A restart with the revoked API_KEY neither fails nor revives it.
+	require.NoError(t, keys.Bootstrap(ctx))
+	_, err = keys.Authenticate(ctx, "test-api-key")
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+
+	// Without another active key the service refuses to start.
+	require.NoError(t, keys.Revoke(ctx, other.ID))
+	assert.Error(t, keys.Bootstrap(ctx))
+}
+
+func TestAPIKeys_RestrictedCallerCannotEscalate(t *testing.T) {
+	keys := newAPIKeys(t)
+	admin := models.WithPrincipal(context.Background(), &models.Principal{
+		KeyID:  "line-a-admin",
+		Scopes: []string{entities.ScopeAdmin},
+		Labels: map[string]string{"line": "A"},
+	})
+	operator := models.WithPrincipal(context.Background(), &models.Principal{
+		KeyID:      "operator",
+		Scopes:     []string{entities.ScopeRead},
+		MachineIDs: []string{"m1", "m2"},
+	})
+
+	for name, tc := range map[string]struct {
+		ctx     context.Context
+		req     models.APIKeyRequest
+		allowed bool
+	}{
+		"same labels":         {admin, models.APIKeyRequest{Scopes: []string{entities.ScopeControl}, Labels: map[string]string{"line": "A"}}, true},
+		"narrower labels":     {admin, models.APIKeyRequest{Scopes: []string{entities.ScopeAdmin}, Labels: map[string]string{"line": "A", "cell": "3"}}, true},
+		"unrestricted":        {admin, models.APIKeyRequest{Scopes: []string{entities.ScopeRead}}, false},
+		"other labels":        {admin, models.APIKeyRequest{Scopes: []string{entities.ScopeRead}, Labels: map[string]string{"line": "B"}}, false},
+		"unlisted machine":    {admin, models.APIKeyRequest{Scopes: []string{entities.ScopeRead}, MachineIDs: []string{"m9"}}, false},
+		"listed machine":      {operator, models.APIKeyRequest{Scopes: []string{entities.ScopeRead}, MachineIDs: []string{"m2"}}, true},
+		"machine beyond list": {operator, models.APIKeyRequest{Scopes: []string{entities.ScopeRead}, MachineIDs: []string{"m2", "m3"}}, false},
+		"more scopes":         {operator, models.APIKeyRequest{Scopes: []string{entities.ScopeRead, entities.ScopeWrite}, MachineIDs: []string{"m1"}}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.req.Name = name
+			_, err := keys.Create(tc.ctx, tc.req)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrForbidden)
+			}
+		})
+	}
+
+	// Rotating a key hands its secret to the caller.
+	wide, err := keys.Create(context.Background(), models.APIKeyRequest{Name: "wide", Scopes: []string{entities.ScopeWrite}})
+	require.NoError(t, err)
+	_, err = keys.Rotate(admin, wide.ID)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+
+	// Keys beyond the caller are neither revoked nor listed.
+	assert.ErrorIs(t, keys.Revoke(admin, wide.ID), models.ErrForbidden)
+	_, err = keys.Authenticate(context.Background(), wide.Key)
+	assert.NoError(t, err)
+
+	list, err := keys.List(admin)
+	require.NoError(t, err)
+	var names []string
+	for _, key := range list {
+		names = append(names, key.Name)
+	}
+	assert.ElementsMatch(t, []string{"same labels", "narrower labels"}, names)
+
+	all, err := keys.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 5) // bootstrap, wide and the three keys created above
+
+	narrower := list[0].ID
+	if list[0].Name != "narrower labels" {
+		narrower = list[1].ID
+	}
+	assert.NoError(t, keys.Revoke(admin, narrower))
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// memoryAudit is an in-memory interfaces.AuditUsecase. Begin fails with
+// beginErr when it is set.
+type memoryAudit struct {
+	mu       sync.Mutex
+	entries  []entities.AuditEntry
+	beginErr error
+}
+
+func (a *memoryAudit) Record(ctx context.Context, entry *entities.AuditEntry) {
+	if p := models.PrincipalFromContext(ctx); p != nil {
+		entry.Actor = p.Name
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, *entry)
+}
+
+func (a *memoryAudit) Begin(ctx context.Context, entry *entities.AuditEntry) error {
+	if a.beginErr != nil {
+		return a.beginErr
+	}
+	entry.Result = entities.AuditResultPending
+	a.Record(ctx, entry)
+	return nil
+}
+
+func (a *memoryAudit) Finish(ctx context.Context, entry *entities.AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries[len(a.entries)-1] = *entry
+}
+
+func (a *memoryAudit) List(ctx context.Context, filter models.AuditFilter) ([]entities.AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]entities.AuditEntry(nil), a.entries...), nil
+}
+
+func TestAudit_RecordsHTTPRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	audit := &memoryAudit{}
+	auth := usecases.NewAuthUsecase(&fanucService.Config{}, newAPIKeys(t), nil, nil)
+
+	r := gin.New()
+	r.POST("/polling/start", middleware.Auth(auth), middleware.Audit(audit, entities.AuditPollingStart), func(c *gin.Context) {
+		middleware.SetAuditMachine(c, "uuid-123")
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Status: "error", Message: "machine unreachable"})
+	})
+
+	body := `{"id": "uuid-123", "interval": 1000}`
+	req := httptest.NewRequest(http.MethodPost, "/polling/start", strings.NewReader(body))
+	req.Header.Set("X-API-Key", "test-api-key")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, audit.entries, 1)
+	entry := audit.entries[0]
+	assert.Equal(t, entities.BootstrapKeyName, entry.Actor)
+	assert.Equal(t, entities.AuditPollingStart, entry.Action)
+	assert.Equal(t, "uuid-123", entry.MachineID)
+	assert.Equal(t, body, entry.Payload)
+	assert.Equal(t, entities.AuditResultError, entry.Result)
+	assert.Equal(t, "machine unreachable", entry.Error)
+	assert.Equal(t, http.StatusInternalServerError, entry.Status)
+}
+
+func TestAudit_LargeBodyReachesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	audit := &memoryAudit{}
+	auth := usecases.NewAuthUsecase(&fanucService.Config{}, newAPIKeys(t), nil, nil)
+
+	var received string
+	r := gin.New()
+	r.POST("/programs", middleware.Auth(auth), middleware.Audit(audit, entities.AuditProgramUpload), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.JSON(http.StatusOK, models.APIResponse{Status: "ok"})
+	})
+
+	body := strings.Repeat("G01 X1.0\n", 1000)
+	req := httptest.NewRequest(http.MethodPost, "/programs", strings.NewReader(body))
+	req.Header.Set("X-API-Key", "test-api-key")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, body, received)
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, body[:entities.MaxAuditPayload]+"...", audit.entries[0].Payload)
+}
+
+func TestTruncatePayload_KeepsValidUTF8(t *testing.T) {
+	// A Cyrillic comment across byte MaxAuditPayload: "Ж" is 2 bytes, "€" 3.
+	prefix := strings.Repeat("G", entities.MaxAuditPayload-1)
+	tests := []struct {
+		name    string
+		summary string
+		want    string
+	}{
+		{name: "short", summary: "O1234(ДЕТАЛЬ)", want: "O1234(ДЕТАЛЬ)"},
+		{name: "fits", summary: prefix[1:] + "Ж", want: prefix[1:] + "Ж"},
+		{name: "two byte rune across the limit", summary: prefix + "Ж", want: prefix + "..."},
+		{name: "three byte rune across the limit", summary: prefix[1:] + "€", want: prefix[1:] + "..."},
+		{name: "rune ending at the limit", summary: prefix[1:] + "ЖЖ", want: prefix[1:] + "Ж..."},
+		{name: "invalid utf-8", summary: "O1234\xff\xfe", want: "O1234\uFFFD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := entities.TruncatePayload(tt.summary)
+			assert.True(t, utf8.ValidString(got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAudit_RequiredAuditRefusesUnauditedRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	audit := &memoryAudit{}
+	auth := usecases.NewAuthUsecase(&fanucService.Config{}, newAPIKeys(t), nil, nil)
+
+	var pending []entities.AuditEntry
+	r := gin.New()
+	r.POST("/write", middleware.Auth(auth), middleware.RequiredAudit(audit, entities.AuditDataWrite), func(c *gin.Context) {
+		pending = append(pending, audit.entries...)
+		c.JSON(http.StatusOK, models.APIResponse{Status: "ok"})
+	})
+	write := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader(`{"id": "uuid-123", "class": "macro", "number": 500, "values": [1.5]}`))
+		req.Header.Set("X-API-Key", "test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, write())
+	require.Len(t, pending, 1)
+	assert.Equal(t, entities.AuditResultPending, pending[0].Result)
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, entities.AuditResultOK, audit.entries[0].Result)
+
+	audit.beginErr = models.ErrAuditFailed
+	assert.Equal(t, http.StatusServiceUnavailable, write())
+	assert.Len(t, pending, 1, "handler must not run without an audit entry")
+}
+
+func TestAudit_RecordsGRPCCall(t *testing.T) {
+	audit := &memoryAudit{}
+	client := newAuditedGRPCClient(t, newAPIKeys(t), audit, stubPolling{})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key")
+
+	_, err := client.StartPolling(ctx, &fanucv1.StartPollingRequest{Id: "uuid-123", Interval: 1000})
+	require.NoError(t, err)
+	_, err = client.ListConnections(ctx, &fanucv1.ListConnectionsRequest{})
+	require.NoError(t, err)
+
+	require.Len(t, audit.entries, 1)
+	entry := audit.entries[0]
+	assert.Equal(t, entities.AuditPollingStart, entry.Action)
+	assert.Equal(t, "uuid-123", entry.MachineID)
+	assert.Equal(t, "grpc", entry.Transport)
+	assert.Equal(t, entities.AuditResultOK, entry.Result)
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/services/calls"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTracker_ReturnsResultOfFinishedCall(t *testing.T) {
+	tracker := calls.NewTracker(1, logrus.New())
+	failed := errors.New("EW_HANDLE")
+
+	assert.NoError(t, tracker.Run(context.Background(), "read", func() error { return nil }, nil))
+	assert.ErrorIs(t, tracker.Run(context.Background(), "read", func() error { return failed }, nil), failed)
+	assert.Zero(t, tracker.Hung())
+}
+
+func TestCallTracker_AbandonsHungCallsAndRefusesAtLimit(t *testing.T) {
+	tracker := calls.NewTracker(2, logrus.New())
+	release := make(chan struct{})
+	cleaned := make(chan struct{}, 2)
+	hang := func() error { <-release; return nil }
+	cleanup := func() { cleaned <- struct{}{} }
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		started := time.Now()
+		err := tracker.Run(ctx, "read", hang, cleanup)
+		cancel()
+		assert.ErrorIs(t, err, calls.ErrAbandoned)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(started), time.Second, "the caller returns at the deadline")
+	}
+	assert.Equal(t, int64(2), tracker.Hung())
+
+	ran := false
+	err := tracker.Run(context.Background(), "read", func() error { ran = true; return nil }, nil)
+	assert.ErrorContains(t, err, "2 FOCAS calls are still hung")
+	assert.False(t, ran)
+
+	// Hung calls that return are cleaned up and no longer counted.
+	close(release)
+	for i := 0; i < 2; i++ {
+		<-cleaned
+	}
+	require.Eventually(t, func() bool { return tracker.Hung() == 0 }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, tracker.Run(context.Background(), "read", func() error { return nil }, nil))
+}
@@ -152,6 +152,137 @@ func TestClient_StartPolling(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestClient_ReadData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/read", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+		q := r.URL.Query()
+		assert.Equal(t, "uuid-123", q.Get("id"))
+		assert.Equal(t, "pmc", q.Get("class"))
+		assert.Equal(t, "R", q.Get("area"))
+		assert.Equal(t, "100", q.Get("number"))
+		assert.Equal(t, "2", q.Get("count"))
+		assert.Empty(t, q.Get("axis"))
+
+		resp := apiResponse{
+			Status: "ok",
+			Data: fanucService.DataReading{
+				DataAddress: fanucService.DataAddress{Class: "pmc", Area: "R", Number: 100, Count: 2, Type: "byte"},
+				Values:      []fanucService.DataValue{{Number: 100, Value: 1}, {Number: 101, Value: 255}},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := fanucService.NewClient(server.URL, "test-api-key")
+	reading, err := client.ReadData(context.Background(), "uuid-123", fanucService.DataAddress{Class: "pmc", Area: "R", Number: 100, Count: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, "byte", reading.Type)
+	require.Len(t, reading.Values, 2)
+	assert.Equal(t, 255.0, reading.Values[1].Value)
+}
+
+func TestClient_WriteData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/write", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var reqBody fanucService.WriteDataRequest
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.Equal(t, "uuid-123", reqBody.ID)
+		assert.Equal(t, []float64{0.015}, reqBody.Values)
+		assert.True(t, reqBody.DryRun)
+
+		resp := apiResponse{
+			Status: "ok",
+			Data: fanucService.WriteResult{
+				DataAddress: fanucService.DataAddress{Class: "macro", Number: 500, Count: 1, Type: "real"},
+				Previous:    []fanucService.DataValue{{Number: 500, Value: 0.01}},
+				Values:      []fanucService.DataValue{{Number: 500, Value: 0.015}},
+				DryRun:      true,
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := fanucService.NewClient(server.URL, "test-api-key")
+	result, err := client.WriteData(context.Background(), fanucService.WriteDataRequest{
+		ID: "uuid-123", Class: "macro", Number: 500, Values: []float64{0.015}, DryRun: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 0.01, result.Previous[0].Value)
+	assert.Equal(t, 0.015, result.Values[0].Value)
+}
+
+func TestClient_WriteToolOffset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/offsets/tool", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var reqBody fanucService.ToolOffsetWriteRequest
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.Equal(t, 12, reqBody.Number)
+		assert.Equal(t, "length_wear", reqBody.Type)
+		assert.True(t, reqBody.Incremental)
+
+		resp := apiResponse{
+			Status: "ok",
+			Data:   fanucService.OffsetChange{Number: 12, Offset: "length_wear", Previous: -0.01, Value: -0.03},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := fanucService.NewClient(server.URL, "test-api-key")
+	change, err := client.WriteToolOffset(context.Background(), fanucService.ToolOffsetWriteRequest{
+		ID: "uuid-123", Number: 12, Type: "length_wear", Value: -0.02, Incremental: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, -0.01, change.Previous)
+	assert.Equal(t, -0.03, change.Value)
+}
+
+func TestClient_UploadAndDownloadProgram(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/programs":
+			var reqBody fanucService.ProgramUploadRequest
+			json.NewDecoder(r.Body).Decode(&reqBody)
+			assert.Equal(t, "uuid-123", reqBody.ID)
+			assert.True(t, reqBody.Overwrite)
+
+			json.NewEncoder(w).Encode(apiResponse{
+				Status: "ok",
+				Data:   fanucService.ProgramInfo{Number: 1234, Name: "O1234", Size: len(reqBody.Program)},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/programs/download":
+			assert.Equal(t, "uuid-123", r.URL.Query().Get("id"))
+			assert.Equal(t, "1234", r.URL.Query().Get("number"))
+			w.Write([]byte("%\nO1234\nM30\n%"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := fanucService.NewClient(server.URL, "test-api-key")
+	info, err := client.UploadProgram(context.Background(), fanucService.ProgramUploadRequest{
+		ID: "uuid-123", Program: "O1234\nM30\n", Overwrite: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "O1234", info.Name)
+
+	program, err := client.DownloadProgram(context.Background(), "uuid-123", 1234)
+	require.NoError(t, err)
+	assert.Equal(t, "%\nO1234\nM30\n%", program)
+}
+
 func TestClient_StopPolling(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/api/v1/polling/stop", r.URL.Path)
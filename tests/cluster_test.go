@@ -0,0 +1,322 @@
+package tests
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCluster is an in-memory interfaces.ClusterRepository.
+type memoryCluster struct {
+	mu        sync.Mutex
+	instances map[string]entities.Instance
+	leases    map[string]entities.MachineLease
+}
+
+func newMemoryCluster() *memoryCluster {
+	return &memoryCluster{instances: map[string]entities.Instance{}, leases: map[string]entities.MachineLease{}}
+}
+
+func (r *memoryCluster) Heartbeat(instance *entities.Instance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := *instance
+	i.HeartbeatAt = time.Now()
+	r.instances[i.ID] = i
+	return nil
+}
+
+func (r *memoryCluster) LiveInstances(ttl time.Duration) ([]entities.Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []entities.Instance
+	for _, i := range r.instances {
+		if time.Since(i.HeartbeatAt) < ttl {
+			list = append(list, i)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryCluster) RemoveInstance(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, id)
+	return nil
+}
+
+func (r *memoryCluster) AcquireLease(machineID, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.leases[machineID]; ok && l.OwnerID != owner && time.Now().Before(l.ExpiresAt) {
+		return false, nil
+	}
+	r.leases[machineID] = entities.MachineLease{MachineID: machineID, OwnerID: owner, ExpiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (r *memoryCluster) ReleaseLease(machineID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leases[machineID].OwnerID == owner {
+		delete(r.leases, machineID)
+	}
+	return nil
+}
+
+func (r *memoryCluster) ReleaseAll(owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, l := range r.leases {
+		if l.OwnerID == owner {
+			delete(r.leases, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryCluster) Leases() ([]entities.MachineLease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []entities.MachineLease
+	for _, l := range r.leases {
+		if time.Now().Before(l.ExpiresAt) {
+			list = append(list, l)
+		}
+	}
+	return list, nil
+}
+
+func (r *memoryCluster) owners() map[string]string {
+	leases, _ := r.Leases()
+	owners := map[string]string{}
+	for _, l := range leases {
+		owners[l.MachineID] = l.OwnerID
+	}
+	return owners
+}
+
+type stubMachines struct {
+	interfaces.Repository
+	machines []entities.Machine
+}
+
+func (r stubMachines) GetAll() ([]entities.Machine, error) {
+	return r.machines, nil
+}
+
+// clusterService records which machines an instance serves.
+type clusterService struct {
+	interfaces.FanucService
+	mu      sync.Mutex
+	serving map[string]bool
+}
+
+func (s *clusterService) AdoptMachine(ctx context.Context, m entities.Machine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serving[m.ID] = true
+}
+
+func (s *clusterService) SyncMachine(ctx context.Context, id string) {}
+
+func (s *clusterService) ReleaseMachine(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.serving, id)
+	return nil
+}
+
+func (s *clusterService) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.serving)
+}
+
+type testInstance struct {
+	membership  *cluster.Membership
+	coordinator *cluster.Coordinator
+	service     *clusterService
+}
+
+func startInstance(t *testing.T, id, address string, repo *memoryCluster, machines stubMachines) *testInstance {
+	cfg := &fanucService.Config{Cluster: fanucService.ClusterConfig{
+		Enabled:    true,
+		InstanceID: id,
+		Address:    address,
+		Heartbeat:  10 * time.Millisecond,
+		LeaseTTL:   200 * time.Millisecond,
+		Forward:    cluster.ForwardProxy,
+	}}
+	membership := cluster.NewMembership(cfg)
+	service := &clusterService{serving: map[string]bool{}}
+	coordinator := cluster.NewCoordinator(cfg, repo, machines, service, membership, logrus.New())
+	coordinator.Start()
+	return &testInstance{membership: membership, coordinator: coordinator, service: service}
+}
+
+func testMachines(n int) stubMachines {
+	var list []entities.Machine
+	for i := 0; i < n; i++ {
+		list = append(list, entities.Machine{ID: string(rune('a'+i)) + "-machine", Mode: entities.ModeStatic})
+	}
+	return stubMachines{machines: list}
+}
+
+func TestCluster_MachinesRebalanceOnJoinAndLeave(t *testing.T) {
+	repo := newMemoryCluster()
+	machines := testMachines(12)
+
+	a := startInstance(t, "a", "http://a", repo, machines)
+	defer a.coordinator.Stop()
+	assert.Equal(t, 12, a.service.count())
+
+	b := startInstance(t, "b", "http://b", repo, machines)
+
+	// Both serve a share, and every machine is served exactly once.
+	require.Eventually(t, func() bool {
+		return b.service.count() > 0 && a.service.count()+b.service.count() == 12 && len(repo.owners()) == 12
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for _, m := range machines.machines {
+		assert.NotEqual(t, a.membership.Owns(m.ID), b.membership.Owns(m.ID), m.ID)
+	}
+
+	require.NoError(t, b.coordinator.Stop())
+	require.Eventually(t, func() bool { return a.service.count() == 12 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestCluster_ForwardsToOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "a", r.Header.Get("X-Fanuc-Forwarded-By"))
+		w.Header().Set("X-Served-By", "owner")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer owner.Close()
+
+	repo := newMemoryCluster()
+	machines := testMachines(1)
+	id := machines.machines[0].ID
+
+	// Register b first with the machine lease, then start a.
+	require.NoError(t, repo.Heartbeat(&entities.Instance{ID: "b", Address: owner.URL}))
+	_, err := repo.AcquireLease(id, "b", time.Minute)
+	require.NoError(t, err)
+
+	a := startInstance(t, "a", "http://a", repo, machines)
+	defer a.coordinator.Stop()
+	require.False(t, a.membership.Owns(id))
+
+	peers, err := auth.NewClusterPeers(&fanucService.Config{}, logrus.New())
+	require.NoError(t, err)
+	r := gin.New()
+	r.GET("/api/v1/program", middleware.Forward(a.membership, cluster.ForwardProxy, peers, logrus.New()), func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+	r.GET("/redirect", middleware.Forward(a.membership, cluster.ForwardRedirect, peers, logrus.New()), func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+
+	// The reverse proxy needs a real connection, so serve the router.
+	front := httptest.NewServer(r)
+	defer front.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(front.URL + "/api/v1/program?id=" + id)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "owner", resp.Header.Get("X-Served-By"))
+
+	// A forwarded request is not forwarded again, so without mTLS it makes
+	// at most one hop, and a client cannot claim it to be served locally.
+	req, err := http.NewRequest(http.MethodGet, front.URL+"/api/v1/program?id="+id, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Fanuc-Forwarded-By", "b")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-Served-By"))
+
+	resp, err = client.Get(front.URL + "/redirect?id=" + id)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	assert.Equal(t, owner.URL+"/redirect?id="+id, resp.Header.Get("Location"))
+}
+
+func TestCluster_ForwardsToPeerOverMTLS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ca := newTestCA(t)
+	caFile := filepath.Join(ca.dir, "ca.pem")
+
+	// The owner trusts client certificates of the cluster unit only.
+	serverCert, serverKey := ca.issue(t, "owner", pkix.Name{CommonName: "b"}, x509.ExtKeyUsageServerAuth)
+	ownerCfg := &fanucService.Config{
+		TLS:     fanucService.TLSConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: caFile, ClientAuth: auth.ClientAuthRequest},
+		Cluster: fanucService.ClusterConfig{PeerSubject: "OU:fanuc-cluster"},
+	}
+	ownerTLS, err := auth.NewServerTLSConfig(ownerCfg, logrus.New())
+	require.NoError(t, err)
+	ownerPeers, err := auth.NewClusterPeers(ownerCfg, logrus.New())
+	require.NoError(t, err)
+	owner := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ownerPeers.Trusted(r.TLS) {
+			w.Header().Set("X-Served-By", "owner")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	owner.TLS = ownerTLS
+	owner.StartTLS()
+	defer owner.Close()
+
+	repo := newMemoryCluster()
+	machines := testMachines(1)
+	id := machines.machines[0].ID
+	require.NoError(t, repo.Heartbeat(&entities.Instance{ID: "b", Address: owner.URL}))
+	_, err = repo.AcquireLease(id, "b", time.Minute)
+	require.NoError(t, err)
+	a := startInstance(t, "a", "https://a", repo, machines)
+	defer a.coordinator.Stop()
+
+	clientCert, clientKey := ca.issue(t, "peer", pkix.Name{CommonName: "a", OrganizationalUnit: []string{"fanuc-cluster"}}, x509.ExtKeyUsageClientAuth)
+	peers, err := auth.NewClusterPeers(&fanucService.Config{Cluster: fanucService.ClusterConfig{
+		CAFile:   caFile,
+		CertFile: clientCert,
+		KeyFile:  clientKey,
+	}}, logrus.New())
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.GET("/api/v1/program", middleware.Forward(a.membership, cluster.ForwardProxy, peers, logrus.New()), func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+	front := httptest.NewServer(r)
+	defer front.Close()
+
+	resp, err := http.Get(front.URL + "/api/v1/program?id=" + id)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the owner certificate is verified with the cluster CA")
+	assert.Equal(t, "owner", resp.Header.Get("X-Served-By"), "the owner recognizes the peer certificate")
+}
@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// le encodes bytes and little-endian 16 and 32 bit words into one buffer.
+func le(words ...interface{}) []byte {
+	var buf []byte
+	for _, w := range words {
+		switch v := w.(type) {
+		case int16:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(v))
+		case int32:
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+		case byte:
+			buf = append(buf, v)
+		case float32:
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+		case []byte:
+			buf = append(buf, v...)
+		}
+	}
+	return buf
+}
+
+func TestDecodeNumbered(t *testing.T) {
+	header := []interface{}{int16(1320), int16(1)}
+	tests := []struct {
+		name string
+		t    cnc.DataType
+		data []interface{}
+		want float64
+	}{
+		{"byte", cnc.Byte, []interface{}{byte(200)}, 200},
+		{"word", cnc.Word, []interface{}{int16(-1234)}, -1234},
+		{"dword", cnc.DWord, []interface{}{int32(-100000)}, -100000},
+		{"real", cnc.Real, []interface{}{int32(123456), int32(3)}, 123.456},
+		{"real without places", cnc.Real, []interface{}{int32(-42), int32(0)}, -42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, cnc.DecodeNumbered(le(append(header, tt.data...)...), tt.t), 1e-9)
+		})
+	}
+}
+
+func TestDecodeMacro(t *testing.T) {
+	tests := []struct {
+		name       string
+		mantissa   int32
+		places     int16
+		want       float64
+		wantVacant bool
+	}{
+		{"value", 15, 1, 1.5, false},
+		{"negative", -2500, 3, -2.5, false},
+		{"zero", 0, 0, 0, false},
+		{"vacant", 0, -1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, vacant := cnc.DecodeMacro(le(int16(500), int16(0), tt.mantissa, tt.places))
+			assert.InDelta(t, tt.want, v, 1e-9)
+			assert.Equal(t, tt.wantVacant, vacant)
+		})
+	}
+}
+
+func TestDecodePMC(t *testing.T) {
+	header := []interface{}{int16(5), int16(0), int16(100), int16(0)}
+	tests := []struct {
+		name string
+		t    cnc.DataType
+		data []interface{}
+		want []cnc.Value
+	}{
+		{"bytes are unsigned", cnc.Byte, []interface{}{byte(1), byte(255)}, []cnc.Value{{Number: 100, Value: 1}, {Number: 101, Value: 255}}},
+		{"words", cnc.Word, []interface{}{int16(-2), int16(300)}, []cnc.Value{{Number: 100, Value: -2}, {Number: 102, Value: 300}}},
+		{"dwords", cnc.DWord, []interface{}{int32(70000)}, []cnc.Value{{Number: 100, Value: 70000}}},
+		{"floats", cnc.Real, []interface{}{float32(0.5), float32(-1.25)}, []cnc.Value{{Number: 100, Value: 0.5}, {Number: 104, Value: -1.25}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cnc.DecodePMC(le(append(header, tt.data...)...), 100, len(tt.want), tt.t))
+		})
+	}
+}
+
+func TestEncodePMC(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      cnc.DataType
+		values []float64
+		want   []byte
+	}{
+		{"bytes", cnc.Byte, []float64{1, 255}, le(int16(5), int16(0), int16(100), int16(101), byte(1), byte(255))},
+		{"words", cnc.Word, []float64{-2}, le(int16(5), int16(1), int16(100), int16(101), int16(-2))},
+		{"dwords", cnc.DWord, []float64{70000}, le(int16(5), int16(2), int16(100), int16(103), int32(70000))},
+		{"floats", cnc.Real, []float64{0.5, 2}, le(int16(5), int16(4), int16(100), int16(107), float32(0.5), float32(2))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := cnc.EncodePMC(5, tt.t, 100, tt.values)
+			assert.Equal(t, tt.want, buf)
+			if tt.t != cnc.Byte {
+				return
+			}
+			// What is written reads back the same.
+			assert.Equal(t, []cnc.Value{{Number: 100, Value: 1}, {Number: 101, Value: 255}}, cnc.DecodePMC(buf, 100, 2, tt.t))
+		})
+	}
+}
+
+func TestDecodeStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		buf       []byte
+		want      string
+		automatic bool
+		turning   bool
+	}{
+		{"mill in edit", le(int16(0), int16(1), int16(3), int16(0), make([]byte, 10)), "EDIT/RESET", false, false},
+		{"lathe in memory", le(int16(0), int16(0), int16(1), int16(0), make([]byte, 10)), "MEM/RESET", true, true},
+		{"program on hold in MDI", le(int16(0), int16(1), int16(0), int16(2), make([]byte, 10)), "MDI/HOLD", true, false},
+		{"remote start", le(int16(0), int16(1), int16(10), int16(3), make([]byte, 10)), "RMT/START", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := cnc.DecodeStatus(tt.buf)
+			assert.Equal(t, tt.want, status.String())
+			assert.Equal(t, tt.automatic, status.Automatic())
+			assert.Equal(t, tt.turning, status.Turning())
+		})
+	}
+}
+
+// prgdir3 builds a PRGDIR3 entry.
+func prgdir3(number, size int32, comment string, date ...int16) []byte {
+	buf := le(number, size, int32(1))
+	c := make([]byte, 52)
+	copy(c, comment)
+	buf = append(buf, c...)
+	dates := make([]int16, 12)
+	copy(dates, date)
+	for _, d := range dates {
+		buf = le(buf, d)
+	}
+	return buf
+}
+
+func TestDecodeProgramEntry(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want cnc.Program
+	}{
+		{
+			name: "comment and date",
+			buf:  prgdir3(1234, 2048, "(SHAFT 12) ", 2024, 3, 15, 14, 30),
+			want: cnc.Program{Number: 1234, Size: 2048, Comment: "SHAFT 12", Modified: time.Date(2024, 3, 15, 14, 30, 0, 0, time.Local)},
+		},
+		{
+			name: "no comment, no date",
+			buf:  prgdir3(1, 96, ""),
+			want: cnc.Program{Number: 1, Size: 96},
+		},
+		{
+			name: "comment without parentheses",
+			buf:  prgdir3(9999, 10, "  PART\x00(JUNK)"),
+			want: cnc.Program{Number: 9999, Size: 10, Comment: "PART"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Len(t, tt.buf, 88)
+			assert.Equal(t, tt.want, cnc.DecodeProgramEntry(tt.buf))
+		})
+	}
+}
+
+// poselm builds an ODBPOS entry of 48 bytes whose first POSELM names the axis.
+func poselm(name string, decimals int16) []byte {
+	buf := le(int32(0), decimals, int16(0), int16(0))
+	suffix := []byte{0, 0}
+	copy(suffix, name)
+	buf = append(buf, suffix...)
+	return append(buf, make([]byte, 48-len(buf))...)
+}
+
+func TestDecodeAxes(t *testing.T) {
+	buf := le(poselm("X", 3), poselm("Z1", 4), poselm("", 3), poselm("C ", 3))
+	assert.Equal(t, []cnc.Axis{{Name: "X", Decimals: 3}, {Name: "Z1", Decimals: 4}, {Name: "C", Decimals: 3}}, cnc.DecodeAxes(buf, 4))
+	assert.Equal(t, []cnc.Axis{{Name: "X", Decimals: 3}}, cnc.DecodeAxes(buf, 1))
+}
+
+func TestDecodeToolOffsets(t *testing.T) {
+	header := []interface{}{int16(1), int16(3), int16(3), int16(0)}
+	tests := []struct {
+		name string
+		tip  bool
+		data []interface{}
+		want []int32
+	}{
+		{"lengths", false, []interface{}{int32(125000), int32(-500), int32(0)}, []int32{125000, -500, 0}},
+		{"tip directions", true, []interface{}{int16(3), int16(0), int16(9)}, []int32{3, 0, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cnc.DecodeToolOffsets(le(append(header, tt.data...)...), 3, tt.tip))
+		})
+	}
+}
+
+func TestWorkOffsetBuffers(t *testing.T) {
+	buf := le(int16(1), int16(-1), int32(-150000), int32(2500), int32(0))
+	assert.Equal(t, []int32{-150000, 2500, 0}, cnc.DecodeWorkOffset(buf, 3))
+	assert.Equal(t, []int32{-150000}, cnc.DecodeWorkOffset(buf, 1))
+
+	assert.Equal(t, le(int16(7), int16(2), int32(-1234)), cnc.EncodeWorkOffset(7, 2, -1234))
+}
@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/iwtcode/fanucService/internal/services/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified_ChangedLine(t *testing.T) {
+	a := "O1000\nG0X0Z0\nT0101\nS1000M3\nG1X10F0.2\nG0X50\nM30\n"
+	b := "O1000\r\nG0X0Z0\r\nT0101\r\nS1200M3\r\nG1X10F0.2\r\nG0X50\r\nM30"
+
+	got := diff.Unified("v1", "v2", a, b, 2)
+	assert.Equal(t, "--- v1\n+++ v2\n"+
+		"@@ -2,5 +2,5 @@\n"+
+		" G0X0Z0\n T0101\n-S1000M3\n+S1200M3\n G1X10F0.2\n G0X50\n", got)
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	a := "O1\nA\nB\nC\nD\nE\nF\nG\nH\nM30"
+	b := "O1\nB\nC\nD\nE\nF\nG\nH\nX\nM30"
+
+	got := diff.Unified("a", "b", a, b, 1)
+	assert.Equal(t, "--- a\n+++ b\n"+
+		"@@ -1,3 +1,2 @@\n O1\n-A\n B\n"+
+		"@@ -9,2 +8,3 @@\n H\n+X\n M30\n", got)
+}
+
+func TestUnified_EqualAndEmpty(t *testing.T) {
+	assert.Empty(t, diff.Unified("a", "b", "O1\nM30\n", "O1\nM30", diff.DefaultContext))
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+O1\n+M30\n", diff.Unified("a", "b", "", "O1\nM30", diff.DefaultContext))
+}
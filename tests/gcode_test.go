@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/iwtcode/fanucService/internal/services/gcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const millProgram = `%
+O1000(TEST)
+N10 G21 G90 G17 G54
+N20 T1 M06
+N30 G00 X0 Y0 S1200 M03
+N40 Z5.
+N50 G01 Z-1. F100.
+N60 X10.
+N70 G02 X20. Y0 R5.
+N80 G03 X10. Y0 I-5. J0
+N90 G00 Z5.
+N100 G54.1 P2 X0 Y0
+N110 T2 M06
+N120 G81 X5. Y5. Z-3. R1. F80.
+N130 X10.
+N140 G80
+N150 M98 P30010
+N160 M30
+%`
+
+func TestGCode_Parse(t *testing.T) {
+	p := gcode.Parse(millProgram)
+	assert.Equal(t, 1000, p.Number)
+	require.Len(t, p.Blocks, 17)
+	assert.Empty(t, p.Warnings)
+
+	assert.Equal(t, []string{"TEST"}, p.Blocks[0].Comments)
+	b := p.Blocks[5] // N50
+	assert.Equal(t, 7, b.Line)
+	assert.Equal(t, 50, b.Sequence)
+	assert.Equal(t, []string{"G01"}, b.G)
+	require.NotNil(t, b.Feed)
+	assert.Equal(t, 100.0, *b.Feed)
+
+	assert.Equal(t, "T1", p.Blocks[2].Tool)
+	assert.Equal(t, []int{6}, p.Blocks[2].M)
+	assert.Equal(t, &gcode.Call{Line: 17, Program: 10, Repeat: 3}, p.Blocks[15].Call)
+}
+
+func TestGCode_Analyze(t *testing.T) {
+	a := gcode.Analyze(gcode.Parse(millProgram))
+
+	assert.Equal(t, "mm", a.Units)
+	assert.Equal(t, []gcode.ToolUse{{Tool: "T1", Line: 4, Calls: 1}, {Tool: "T2", Line: 13, Calls: 1}}, a.Tools)
+	assert.Equal(t, []string{"G54", "G54.1 P2"}, a.WorkOffsets)
+	assert.Equal(t, []gcode.Call{{Line: 17, Program: 10, Repeat: 3}}, a.Calls)
+	assert.Equal(t, &gcode.Range{Min: 80, Max: 100}, a.Feed)
+	assert.Equal(t, &gcode.Range{Min: 1200, Max: 1200}, a.Speed)
+
+	// Feed: 6 + 10 + two half circles of R5; rapid: 6 + 10 + cycle positioning.
+	assert.Equal(t, gcode.PathLength{Feed: 47.416, Rapid: 28.071, Total: 75.487}, a.Path)
+	assert.Empty(t, a.Warnings)
+}
+
+func TestGCode_Warnings(t *testing.T) {
+	a := gcode.Analyze(gcode.Parse("O2000\nN10 G01 X10. Y10.\nN10 G00 X0 Q\nN30 G02 X5. Y5.\n(unclosed\n#100=1.5\n"))
+
+	assert.Equal(t, []gcode.Warning{
+		{Line: 2, Message: "feed motion without a feed rate"},
+		{Line: 3, Message: "address Q has no value"},
+		{Line: 3, Message: "sequence number N10 repeats line 2"},
+		{Line: 4, Message: "arc without R or I/J/K"},
+		{Line: 5, Message: "comment is not closed"},
+		{Line: 6, Message: "program has no end (M02, M30 or M99)"},
+	}, a.Warnings)
+}
+
+func TestGCode_Locate(t *testing.T) {
+	p := gcode.Parse("O3000\nN10 G00 X0 Y0\nG01 X5. F100.\nX10.\nN20 G00 Z5.\nX10.\nM30")
+	assert.Equal(t, 1, p.Head())
+
+	i, matched := p.Locate(10, "X10.", 0)
+	assert.Equal(t, 3, i, "block after N10 found by its text")
+	assert.Equal(t, gcode.MatchBlock, matched)
+
+	i, matched = p.Locate(10, "", 0)
+	assert.Equal(t, 1, i)
+	assert.Equal(t, gcode.MatchSequence, matched)
+
+	i, _ = p.Locate(0, "n20 g00 z5.;", 0)
+	assert.Equal(t, 4, i, "text compared without blanks and case")
+
+	i, _ = p.Locate(20, "X10.", 4)
+	assert.Equal(t, 5, i, "repeated text resolved after the numbered block")
+
+	i, matched = p.Locate(99, "", 0)
+	assert.Equal(t, -1, i)
+	assert.Empty(t, matched)
+}
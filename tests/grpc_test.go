@@ -0,0 +1,291 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/iwtcode/fanucService"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/grpcapi"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/gcode"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type stubConnections struct{}
+
+func (stubConnections) Create(ctx context.Context, req models.ConnectionRequest) (*entities.Machine, error) {
+	return &entities.Machine{ID: "uuid-123", Endpoint: req.Endpoint, Labels: req.Labels}, nil
+}
+
+func (stubConnections) List(ctx context.Context) ([]entities.Machine, error) {
+	return []entities.Machine{{ID: "uuid-123", Endpoint: "192.168.1.10:8193"}}, nil
+}
+
+func (stubConnections) Delete(ctx context.Context, id string) error { return nil }
+
+func (stubConnections) Check(ctx context.Context, id string) (*entities.Machine, error) {
+	return &entities.Machine{ID: id}, nil
+}
+
+type stubPolling struct {
+	updates chan *models.MachineDataEnvelope
+}
+
+func (stubPolling) Start(ctx context.Context, req models.StartPollingRequest) error { return nil }
+
+func (stubPolling) Stop(ctx context.Context, req models.StopPollingRequest) error { return nil }
+
+func (p stubPolling) Watch(ctx context.Context, id string) (<-chan *models.MachineDataEnvelope, error) {
+	return p.updates, nil
+}
+
+type stubPrograms struct{}
+
+func (stubPrograms) GetProgram(ctx context.Context, id string) (string, error) {
+	return "O0001\nM30\n", nil
+}
+
+func (stubPrograms) List(ctx context.Context, id string) (*models.ProgramDirectory, error) {
+	return &models.ProgramDirectory{Main: 1, Programs: []models.ProgramInfo{{Number: 1, Name: "O0001", Size: 12}}}, nil
+}
+
+func (stubPrograms) Download(ctx context.Context, id string, number int) (string, error) {
+	return "O0001\nM30\n", nil
+}
+
+func (stubPrograms) Stat(ctx context.Context, id string, number int) (*models.ProgramStat, error) {
+	return &models.ProgramStat{Number: number, Length: -1}, nil
+}
+
+func (stubPrograms) Stream(ctx context.Context, id string, number int, w io.Writer, offset, length int64) error {
+	return nil
+}
+
+func (stubPrograms) Upload(ctx context.Context, req models.ProgramUploadRequest) (*models.ProgramInfo, error) {
+	return &models.ProgramInfo{Number: 1, Name: "O0001", Size: len(req.Program)}, nil
+}
+
+func (stubPrograms) Delete(ctx context.Context, id string, number int) error { return nil }
+
+func (stubPrograms) Select(ctx context.Context, req models.ProgramSelectRequest) error { return nil }
+
+func (stubPrograms) Analyze(ctx context.Context, id string, number int, blocks bool) (*gcode.Analysis, error) {
+	return gcode.Analyze(gcode.Parse("O0001\nM30\n")), nil
+}
+
+func (stubPrograms) AnalyzeText(ctx context.Context, req models.ProgramAnalyzeRequest) *gcode.Analysis {
+	return gcode.Analyze(gcode.Parse(req.Program))
+}
+
+func (stubPrograms) Position(ctx context.Context, id string, lines int) (*models.ProgramPosition, error) {
+	return &models.ProgramPosition{MachineID: id, Number: 1, Line: 2}, nil
+}
+
+type stubData struct{}
+
+func (stubData) Read(ctx context.Context, id string, addr entities.DataAddress) (*models.DataReading, error) {
+	if addr.Class != entities.DataPMC {
+		return nil, fmt.Errorf("%w: unknown data class %q", models.ErrBadRequest, addr.Class)
+	}
+	return &models.DataReading{DataAddress: addr, Values: []models.DataValue{{Number: addr.Number, Value: 3}}}, nil
+}
+
+func (stubData) Write(ctx context.Context, req models.WriteDataRequest) (*models.WriteResult, error) {
+	addr := entities.DataAddress{Class: req.Class, Number: req.Number, Count: len(req.Values), Type: entities.DataReal}
+	return &models.WriteResult{
+		DataAddress: addr,
+		Previous:    []models.DataValue{{Number: req.Number, Value: 1}},
+		Values:      []models.DataValue{{Number: req.Number, Value: req.Values[0]}},
+		DryRun:      req.DryRun,
+	}, nil
+}
+
+func (stubData) SetWriteRules(ctx context.Context, req models.WriteRulesRequest) (*entities.Machine, error) {
+	return &entities.Machine{ID: req.ID, WriteRules: req.Rules}, nil
+}
+
+type stubOffsets struct{}
+
+func (stubOffsets) ToolOffsets(ctx context.Context, id string) (*models.ToolOffsets, error) {
+	return &models.ToolOffsets{Memory: "C", Types: []string{"length_geometry"}, Offsets: []models.ToolOffset{{Number: 1, Values: map[string]float64{"length_geometry": 120.5}}}}, nil
+}
+
+func (stubOffsets) WorkOffsets(ctx context.Context, id string) (*models.WorkOffsets, error) {
+	return &models.WorkOffsets{Axes: []string{"X"}, Offsets: []models.WorkOffset{{Number: 1, Name: "G54", Values: map[string]float64{"X": -250}}}}, nil
+}
+
+func (stubOffsets) WriteToolOffset(ctx context.Context, req models.ToolOffsetWriteRequest) (*models.OffsetChange, error) {
+	value := req.Value
+	if req.Incremental {
+		value += 0.5
+	}
+	return &models.OffsetChange{Number: req.Number, Offset: req.Type, Previous: 0.5, Value: value}, nil
+}
+
+func (stubOffsets) WriteWorkOffset(ctx context.Context, req models.WorkOffsetWriteRequest) (*models.OffsetChange, error) {
+	return &models.OffsetChange{Number: req.Number, Name: "G54", Offset: req.Axis, Value: req.Value}, nil
+}
+
+func newGRPCClient(t *testing.T, keys interfaces.APIKeyUsecase, polling stubPolling) fanucv1.FanucServiceClient {
+	return newAuditedGRPCClient(t, keys, &memoryAudit{}, polling)
+}
+
+func newAuditedGRPCClient(t *testing.T, keys interfaces.APIKeyUsecase, audit interfaces.AuditUsecase, polling stubPolling) fanucv1.FanucServiceClient {
+	cfg := &fanucService.Config{}
+	auth := usecases.NewAuthUsecase(cfg, keys, nil, nil)
+	server := grpcapi.NewGRPCServer(auth, audit, ratelimit.NewLimiter(cfg), grpcapi.NewServer(stubConnections{}, polling, stubPrograms{}, stubData{}, stubOffsets{}), nil)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return fanucv1.NewFanucServiceClient(conn)
+}
+
+func TestGRPC_RequiresAPIKey(t *testing.T) {
+	client := newGRPCClient(t, newAPIKeys(t), stubPolling{})
+
+	_, err := client.ListConnections(context.Background(), &fanucv1.ListConnectionsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key")
+	resp, err := client.ListConnections(ctx, &fanucv1.ListConnectionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Machines, 1)
+	assert.Equal(t, "uuid-123", resp.Machines[0].Id)
+}
+
+func TestGRPC_WatchMachineData(t *testing.T) {
+	updates := make(chan *models.MachineDataEnvelope, 2)
+	updates <- &models.MachineDataEnvelope{MachineID: "uuid-123", Sequence: 1}
+	updates <- &models.MachineDataEnvelope{MachineID: "uuid-123", Sequence: 2}
+	close(updates)
+
+	client := newGRPCClient(t, newAPIKeys(t), stubPolling{updates: updates})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key")
+
+	stream, err := client.WatchMachineData(ctx, &fanucv1.WatchMachineDataRequest{Id: "uuid-123"})
+	require.NoError(t, err)
+
+	for seq := uint64(1); seq <= 2; seq++ {
+		env, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, seq, env.Sequence)
+	}
+}
+
+func TestGRPC_ScopeRequired(t *testing.T) {
+	keys := newAPIKeys(t)
+	admin := models.WithPrincipal(context.Background(), &models.Principal{Scopes: []string{entities.ScopeAdmin}})
+	reader, err := keys.Create(admin, models.APIKeyRequest{Name: "dashboard", Scopes: []string{entities.ScopeRead}})
+	require.NoError(t, err)
+
+	client := newGRPCClient(t, keys, stubPolling{})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", reader.Key)
+
+	_, err = client.ListConnections(ctx, &fanucv1.ListConnectionsRequest{})
+	require.NoError(t, err)
+
+	_, err = client.StartPolling(ctx, &fanucv1.StartPollingRequest{Id: "uuid-123"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_ReadData(t *testing.T) {
+	client := newGRPCClient(t, newAPIKeys(t), stubPolling{})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key")
+
+	reading, err := client.ReadData(ctx, &fanucv1.ReadDataRequest{
+		Id:      "uuid-123",
+		Address: &fanucv1.DataAddress{Class: entities.DataPMC, Area: "R", Number: 100, Type: entities.DataByte},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "R", reading.Address.Area)
+	require.Len(t, reading.Values, 1)
+	assert.Equal(t, int32(100), reading.Values[0].Number)
+	assert.Equal(t, 3.0, reading.Values[0].Value)
+
+	_, err = client.ReadData(ctx, &fanucv1.ReadDataRequest{Id: "uuid-123", Address: &fanucv1.DataAddress{Class: "tool"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_WriteDataRequiresWriteScope(t *testing.T) {
+	keys := newAPIKeys(t)
+	admin := models.WithPrincipal(context.Background(), &models.Principal{Scopes: []string{entities.ScopeAdmin}})
+	operator, err := keys.Create(admin, models.APIKeyRequest{Name: "operator", Scopes: []string{entities.ScopeRead, entities.ScopeControl}})
+	require.NoError(t, err)
+	writer, err := keys.Create(admin, models.APIKeyRequest{Name: "compensation", Scopes: []string{entities.ScopeWrite}})
+	require.NoError(t, err)
+
+	audit := &memoryAudit{}
+	client := newAuditedGRPCClient(t, keys, audit, stubPolling{})
+	req := &fanucv1.WriteDataRequest{Id: "uuid-123", Class: entities.DataMacro, Number: 500, Values: []float64{1.25}}
+
+	_, err = client.WriteData(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", operator.Key), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := client.WriteData(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", writer.Key), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Values, 1)
+	assert.Equal(t, 1.25, resp.Values[0].Value)
+	assert.Equal(t, 1.0, resp.Previous[0].Value)
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, entities.AuditDataWrite, audit.entries[0].Action)
+	assert.Equal(t, entities.AuditResultOK, audit.entries[0].Result)
+
+	audit.beginErr = models.ErrAuditFailed
+	_, err = client.WriteData(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", writer.Key), req)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestGRPC_WriteToolOffsetIsAudited(t *testing.T) {
+	keys := newAPIKeys(t)
+	admin := models.WithPrincipal(context.Background(), &models.Principal{Scopes: []string{entities.ScopeAdmin}})
+	reader, err := keys.Create(admin, models.APIKeyRequest{Name: "presetter", Scopes: []string{entities.ScopeRead}})
+	require.NoError(t, err)
+
+	audit := &memoryAudit{}
+	client := newAuditedGRPCClient(t, keys, audit, stubPolling{})
+	readerCtx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", reader.Key)
+
+	offsets, err := client.ReadToolOffsets(readerCtx, &fanucv1.ReadOffsetsRequest{Id: "uuid-123"})
+	require.NoError(t, err)
+	require.Len(t, offsets.Offsets, 1)
+	assert.Equal(t, 120.5, offsets.Offsets[0].Values["length_geometry"])
+
+	req := &fanucv1.WriteToolOffsetRequest{Id: "uuid-123", Number: 1, Type: "length_wear", Value: -0.02, Incremental: true}
+	_, err = client.WriteToolOffset(readerCtx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	change, err := client.WriteToolOffset(metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key"), req)
+	require.NoError(t, err)
+	assert.Equal(t, "length_wear", change.Offset)
+	assert.Equal(t, 0.5, change.Previous)
+	assert.InDelta(t, 0.48, change.Value, 1e-9)
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, entities.AuditToolOffset, audit.entries[0].Action)
+	assert.Equal(t, entities.AuditResultOK, audit.entries[0].Result)
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/iwtcode/fanucService/internal/services/jobs"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryJobs is an in-memory interfaces.JobRepository. Like the database
+// one, Update leaves cancel_requested to RequestCancel.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs map[string]entities.Job
+}
+
+func (r *memoryJobs) Create(job *entities.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryJobs) Update(job *entities.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *job
+	stored.CancelRequested = r.jobs[job.ID].CancelRequested
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *memoryJobs) GetByID(id string) (*entities.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	job.Output = ""
+	return &job, nil
+}
+
+func (r *memoryJobs) Output(id string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id].Output, nil
+}
+
+func (r *memoryJobs) Find(filter models.JobFilter) ([]entities.Job, error) { return nil, nil }
+func (r *memoryJobs) CancelRequested(instance string) ([]string, error)    { return nil, nil }
+
+func (r *memoryJobs) RequestCancel(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[id]; ok && !job.Finished() {
+		job.CancelRequested = true
+		r.jobs[id] = job
+	}
+	return nil
+}
+func (r *memoryJobs) FailActive(instance, reason string, at time.Time) (int64, error) {
+	return 0, nil
+}
+func (r *memoryJobs) DeleteFinished(before time.Time) (int64, error) { return 0, nil }
+
+func newRunner(t *testing.T, workers int) *jobs.Runner {
+	runner, _ := newRunnerWithRepo(t, workers)
+	return runner
+}
+
+func newRunnerWithRepo(t *testing.T, workers int) (*jobs.Runner, *memoryJobs) {
+	cfg := &fanucService.Config{Jobs: fanucService.JobsConfig{Workers: workers, QueueSize: 10, Timeout: time.Minute}}
+	repo := &memoryJobs{jobs: map[string]entities.Job{}}
+	runner := jobs.NewRunner(cfg, repo, cluster.NewMembership(cfg), logrus.New())
+	require.NoError(t, runner.Start())
+	t.Cleanup(func() { _ = runner.Stop(context.Background()) })
+	return runner, repo
+}
+
+func waitJob(t *testing.T, runner *jobs.Runner, id string) *entities.Job {
+	var job *entities.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = runner.Get(id)
+		return err == nil && job.Finished()
+	}, 2*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobRunner_SerializesJobsOfAMachine(t *testing.T) {
+	runner := newRunner(t, 3)
+
+	var (
+		mu      sync.Mutex
+		running = map[string]int{}
+		overlap bool
+	)
+	fn := func(machine string) jobs.Func {
+		return func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error) {
+			mu.Lock()
+			running[machine]++
+			overlap = overlap || running[machine] > 1
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			running[machine]--
+			mu.Unlock()
+			return nil, machine, nil
+		}
+	}
+
+	var ids []string
+	for _, machine := range []string{"m1", "m1", "m2", "m1"} {
+		job := &entities.Job{ID: uuid.NewString(), Type: entities.JobProgramDownload, MachineID: machine}
+		require.NoError(t, runner.Submit(context.Background(), job, fn(machine)))
+		ids = append(ids, job.ID)
+	}
+	for _, id := range ids {
+		assert.Equal(t, entities.JobSucceeded, waitJob(t, runner, id).Status)
+	}
+	assert.False(t, overlap, "jobs of a machine ran at the same time")
+}
+
+func TestJobRunner_Cancel(t *testing.T) {
+	runner := newRunner(t, 1)
+
+	started := make(chan struct{})
+	blocking := func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error) {
+		report(1, 10)
+		close(started)
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	}
+	running := &entities.Job{ID: uuid.NewString(), Type: entities.JobProgramDownload, MachineID: "m1"}
+	require.NoError(t, runner.Submit(context.Background(), running, blocking))
+	queued := &entities.Job{ID: uuid.NewString(), Type: entities.JobProgramDownload, MachineID: "m2"}
+	require.NoError(t, runner.Submit(context.Background(), queued, blocking))
+	<-started
+
+	job, err := runner.Cancel(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.JobCancelled, job.Status)
+	assert.Nil(t, job.StartedAt)
+
+	job, err = runner.Get(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.JobRunning, job.Status)
+	assert.Equal(t, int64(1), job.Done)
+
+	_, err = runner.Cancel(running.ID)
+	require.NoError(t, err)
+	job = waitJob(t, runner, running.ID)
+	assert.Equal(t, entities.JobCancelled, job.Status)
+	assert.Empty(t, job.Error)
+}
+
+func TestJobRunner_ProgressKeepsRequestedCancel(t *testing.T) {
+	runner, repo := newRunnerWithRepo(t, 1)
+
+	started, progress := make(chan struct{}), make(chan struct{})
+	fn := func(ctx context.Context, report func(done, total int64)) (*entities.JobResult, string, error) {
+		close(started)
+		<-progress
+		report(5, 10)
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	}
+	job := &entities.Job{ID: uuid.NewString(), Type: entities.JobProgramDownload, MachineID: "m1"}
+	require.NoError(t, runner.Submit(context.Background(), job, fn))
+	<-started
+
+	// Another instance flags the job; the progress stored next keeps the flag.
+	require.NoError(t, repo.RequestCancel(job.ID))
+	time.Sleep(1100 * time.Millisecond) // past progressInterval, so the progress is stored
+	close(progress)
+	require.Eventually(t, func() bool {
+		stored, _ := repo.GetByID(job.ID)
+		return stored.Done == 5
+	}, 2*time.Second, 10*time.Millisecond)
+	stored, err := repo.GetByID(job.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.CancelRequested)
+
+	_, err = runner.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.JobCancelled, waitJob(t, runner, job.ID).Status)
+	stored, _ = repo.GetByID(job.ID)
+	assert.True(t, stored.CancelRequested)
+}
+
+func TestJobRunner_SubmitWhileStopping(t *testing.T) {
+	runner := newRunner(t, 1)
+	require.NoError(t, runner.Stop(context.Background()))
+
+	job := &entities.Job{ID: uuid.NewString(), Type: entities.JobConnectionCheck}
+	err := runner.Submit(context.Background(), job, nil)
+	assert.ErrorIs(t, err, models.ErrUnavailable)
+	assert.NotErrorIs(t, err, models.ErrNotOwner)
+}
+
+func TestJobUsecase_DownloadOutputLimit(t *testing.T) {
+	runner, repo := newRunnerWithRepo(t, 1)
+	download := func(limit int64, programs interfaces.ProgramUsecase) *entities.Job {
+		cfg := &fanucService.Config{Jobs: fanucService.JobsConfig{OutputMaxSize: limit}}
+		usecase := usecases.NewJobUsecase(cfg, runner, repo, nil, programs, nil, nil)
+		job, err := usecase.Create(context.Background(), models.JobRequest{Type: entities.JobProgramDownload, ID: "m1", Number: 1234})
+		require.NoError(t, err)
+		return waitJob(t, runner, job.ID)
+	}
+	size := int64(len(streamedProgram))
+
+	job := download(size, streamPrograms{})
+	assert.Equal(t, entities.JobSucceeded, job.Status)
+	output, err := repo.Output(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, streamedProgram, output)
+
+	// An unknown length is bounded while reading, a known one before.
+	for _, programs := range []streamPrograms{{}, {etag: "etag"}} {
+		job = download(size-1, programs)
+		assert.Equal(t, entities.JobFailed, job.Status)
+		assert.Contains(t, job.Error, "JOB_OUTPUT_MAX_SIZE")
+		output, _ = repo.Output(job.ID)
+		assert.Empty(t, output)
+	}
+
+	assert.Equal(t, entities.JobSucceeded, download(0, streamPrograms{}).Status)
+}
@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJWKS stores the public part of key as a local JWKS file, followed by
+// the extra keys.
+func writeJWKS(t *testing.T, key *rsa.PrivateKey, kid string, extra ...map[string]string) string {
+	jwks := map[string]interface{}{
+		"keys": append([]map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}, extra...),
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func jwtConfig(t *testing.T, key *rsa.PrivateKey, mode string) *fanucService.Config {
+	return &fanucService.Config{Auth: fanucService.AuthConfig{
+		Mode:         mode,
+		JWKSFile:     writeJWKS(t, key, "test-key"),
+		Issuer:       "https://idp.example.com",
+		Audience:     "fanuc-service",
+		SubjectClaim: "preferred_username",
+		RolesClaim:   "realm_access.roles",
+		RoleMapping:  "fanuc-operator=read+control",
+	}}
+}
+
+func TestJWTVerifier_MapsRolesToScopes(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	verifier, err := auth.NewJWTVerifier(jwtConfig(t, key, auth.ModeJWT), logrus.New())
+	require.NoError(t, err)
+
+	token := signToken(t, key, "test-key", jwt.MapClaims{
+		"iss":                "https://idp.example.com",
+		"aud":                "fanuc-service",
+		"sub":                "0b6f2c",
+		"preferred_username": "operator1",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"realm_access":       map[string]interface{}{"roles": []string{"fanuc-operator", "program", "offline_access"}},
+	})
+
+	p, err := verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "operator1", p.Name)
+	assert.Equal(t, models.AuthMethodJWT, p.AuthMethod)
+	// "program" is named like a scope but is not mapped, so it grants nothing.
+	assert.ElementsMatch(t, []string{entities.ScopeRead, entities.ScopeControl}, p.Scopes)
+
+	token = signToken(t, key, "test-key", jwt.MapClaims{
+		"aud":          "fanuc-service",
+		"iss":          "https://idp.example.com",
+		"sub":          "someone",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]interface{}{"roles": []string{"admin"}},
+	})
+	p, err = verifier.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Empty(t, p.Scopes)
+}
+
+func TestJWTVerifier_RequiresAudience(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cfg := jwtConfig(t, key, auth.ModeBoth)
+	cfg.Auth.Audience = ""
+	_, err = auth.NewJWTVerifier(cfg, logrus.New())
+	assert.ErrorContains(t, err, "JWT_AUDIENCE")
+}
+
+func TestKeySet_SkipsUnusableKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := writeJWKS(t, key, "test-key",
+		map[string]string{"kty": "EC", "kid": "secp256k1", "crv": "secp256k1", "x": "AA", "y": "AA"},
+		map[string]string{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	)
+	keys, err := auth.NewKeySet(path, "", 0, logrus.New())
+	require.NoError(t, err)
+	got, err := keys.Key(context.Background(), "test-key")
+	require.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, got)
+	_, err = keys.Key(context.Background(), "hmac")
+	assert.Error(t, err)
+
+	unusable := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(unusable, []byte(`{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}]}`), 0o600))
+	_, err = auth.NewKeySet(unusable, "", 0, logrus.New())
+	assert.ErrorContains(t, err, "no usable signing keys")
+}
+
+func TestJWTVerifier_RejectsInvalidTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	verifier, err := auth.NewJWTVerifier(jwtConfig(t, key, auth.ModeJWT), logrus.New())
+	require.NoError(t, err)
+
+	valid := jwt.MapClaims{
+		"iss": "https://idp.example.com",
+		"aud": "fanuc-service",
+		"sub": "operator1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	expired := jwt.MapClaims{
+		"iss": "https://idp.example.com",
+		"aud": "fanuc-service",
+		"sub": "operator1",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}
+	wrongAudience := jwt.MapClaims{
+		"iss": "https://idp.example.com",
+		"aud": "other-service",
+		"sub": "operator1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	for name, token := range map[string]string{
+		"foreign key":    signToken(t, other, "test-key", valid),
+		"expired":        signToken(t, key, "test-key", expired),
+		"wrong audience": signToken(t, key, "test-key", wrongAudience),
+		"garbage":        "not-a-token",
+	} {
+		_, err := verifier.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, models.ErrUnauthorized, name)
+	}
+}
+
+func TestAuthUsecase_JWTModeRejectsAPIKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cfg := jwtConfig(t, key, auth.ModeJWT)
+	verifier, err := auth.NewJWTVerifier(cfg, logrus.New())
+	require.NoError(t, err)
+
+	authUsecase := usecases.NewAuthUsecase(cfg, newAPIKeys(t), verifier, nil)
+	_, err = authUsecase.Authenticate(context.Background(), models.Credentials{APIKey: "test-api-key"})
+	assert.ErrorIs(t, err, models.ErrUnauthorized)
+}
+
+func TestKeySet_ServesCachedKeysWhileJWKSFails(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := os.ReadFile(writeJWKS(t, key, "test-key"))
+	require.NoError(t, err)
+
+	var requests, failing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() != 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	keys, err := auth.NewKeySet("", server.URL, time.Nanosecond, logrus.New())
+	require.NoError(t, err)
+	failing.Store(1)
+
+	for i := 0; i < 5; i++ {
+		got, err := keys.Key(context.Background(), "test-key")
+		require.NoError(t, err)
+		assert.Equal(t, &key.PublicKey, got)
+	}
+	_, err = keys.Key(context.Background(), "rotated-key")
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(2), requests.Load(), "a failed refresh is not retried on every request")
+}
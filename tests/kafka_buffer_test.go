@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bufferMessage(i int) kafka.Message {
+	return kafka.Message{
+		Topic:   "fanuc_data",
+		Key:     []byte("machine"),
+		Value:   []byte(fmt.Sprintf("payload-%d", i)),
+		Headers: map[string]string{"sequence": fmt.Sprint(i)},
+		Time:    time.Now(),
+	}
+}
+
+func TestDiskBuffer_ReplayInOrderAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	buf, err := kafka.OpenDiskBuffer(dir, 0, 0, kafka.DropOldest)
+	require.NoError(t, err)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, buf.Append(bufferMessage(i)))
+	}
+
+	msgs, pos, err := buf.Peek(2)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "payload-1", string(msgs[0].Value))
+	assert.Equal(t, "2", msgs[1].Headers["sequence"])
+	assert.Equal(t, "fanuc_data", msgs[1].Topic)
+	require.NoError(t, buf.Commit(pos))
+	assert.Equal(t, 3, buf.Len())
+	require.NoError(t, buf.Close())
+
+	buf, err = kafka.OpenDiskBuffer(dir, 0, 0, kafka.DropOldest)
+	require.NoError(t, err)
+	defer buf.Close()
+	assert.Equal(t, 3, buf.Len())
+
+	require.NoError(t, buf.Append(bufferMessage(6)))
+	msgs, pos, err = buf.Peek(10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 4)
+	assert.Equal(t, "payload-3", string(msgs[0].Value))
+	assert.Equal(t, "payload-6", string(msgs[3].Value))
+	require.NoError(t, buf.Commit(pos))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestDiskBuffer_DropPolicies(t *testing.T) {
+	record := len(bufferMessage(1).Value) + 64
+
+	newest, err := kafka.OpenDiskBuffer(t.TempDir(), int64(record*2), 0, kafka.DropNewest)
+	require.NoError(t, err)
+	defer newest.Close()
+	var full error
+	for i := 1; i <= 5; i++ {
+		if err := newest.Append(bufferMessage(i)); err != nil {
+			full = err
+		}
+	}
+	assert.ErrorIs(t, full, kafka.ErrBufferFull)
+	assert.NotZero(t, newest.Stats().Dropped)
+
+	expiring, err := kafka.OpenDiskBuffer(t.TempDir(), 0, time.Minute, kafka.DropOldest)
+	require.NoError(t, err)
+	defer expiring.Close()
+	old := bufferMessage(1)
+	old.Time = time.Now().Add(-time.Hour)
+	require.NoError(t, expiring.Append(old))
+	require.NoError(t, expiring.Append(bufferMessage(2)))
+
+	msgs, _, err := expiring.Peek(10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "payload-2", string(msgs[0].Value))
+	assert.Equal(t, uint64(1), expiring.Stats().Dropped)
+}
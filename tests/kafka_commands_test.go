@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commandTopic hands out queued messages and records the commits.
+type commandTopic struct {
+	messages chan kafkago.Message
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func (r *commandTopic) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return kafkago.Message{}, ctx.Err()
+	}
+}
+
+func (r *commandTopic) CommitMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *commandTopic) Close() error { return nil }
+
+func (r *commandTopic) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// sentReply is a reply the consumer sent.
+type sentReply struct {
+	topic   string
+	key     string
+	reply   models.CommandReply
+	headers map[string]string
+}
+
+type replyRecorder struct {
+	mu      sync.Mutex
+	replies []sentReply
+}
+
+func (r *replyRecorder) Send(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
+	var reply models.CommandReply
+	if err := json.Unmarshal(value, &reply); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replies = append(r.replies, sentReply{topic: topic, key: string(key), reply: reply, headers: headers})
+	return nil
+}
+
+func (r *replyRecorder) sent() []sentReply {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sentReply(nil), r.replies...)
+}
+
+// failingPolling refuses to start polling and records the requests.
+type failingPolling struct {
+	stubPolling
+	mu       sync.Mutex
+	requests []models.StartPollingRequest
+}
+
+func (p *failingPolling) Start(ctx context.Context, req models.StartPollingRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+	return errors.New("machine unreachable")
+}
+
+// runCommands feeds the messages through a running consumer and returns
+// once all of them are committed.
+func runCommands(t *testing.T, polling *failingPolling, audit *memoryAudit, msgs ...kafkago.Message) []sentReply {
+	topic := &commandTopic{messages: make(chan kafkago.Message, len(msgs))}
+	replies := &replyRecorder{}
+	cfg := &fanucService.Config{Kafka: fanucService.KafkaConfig{CommandTopic: "fanuc_commands", ResponseTopic: "fanuc_replies"}}
+	consumer := kafka.NewCommandConsumerWith(cfg, topic, replies, stubConnections{}, polling, stubPrograms{}, audit, logrus.New())
+
+	for i := range msgs {
+		msgs[i].Offset = int64(i)
+		topic.messages <- msgs[i]
+	}
+	consumer.Start()
+	require.Eventually(t, func() bool { return len(topic.commits()) == len(msgs) }, time.Second, 5*time.Millisecond)
+	require.NoError(t, consumer.Stop())
+
+	for i, offset := range topic.commits() {
+		assert.Equal(t, int64(i), offset, "offsets are committed in order")
+	}
+	return replies.sent()
+}
+
+func commandMessage(t *testing.T, cmd models.Command) kafkago.Message {
+	value, err := json.Marshal(cmd)
+	require.NoError(t, err)
+	return kafkago.Message{Value: value}
+}
+
+func TestCommandConsumer_DispatchesAndReplies(t *testing.T) {
+	audit := &memoryAudit{}
+	polling := &failingPolling{}
+	sent := runCommands(t, polling, audit,
+		commandMessage(t, models.Command{ID: "c1", Type: models.CommandConnect, Connection: &models.ConnectionRequest{Endpoint: "192.168.1.10:8193"}}),
+		commandMessage(t, models.Command{ID: "c2", Type: models.CommandGetProgram, MachineID: "uuid-123"}),
+		commandMessage(t, models.Command{ID: "c3", Type: models.CommandStartPolling, MachineID: "uuid-123", Interval: 500}),
+	)
+	require.Len(t, sent, 3)
+
+	connect := sent[0]
+	assert.Equal(t, "fanuc_replies", connect.topic)
+	assert.Equal(t, "uuid-123", connect.key)
+	assert.Equal(t, "c1", connect.headers[models.HeaderCorrelationID])
+	assert.Equal(t, "ok", connect.reply.Status)
+	assert.Equal(t, "192.168.1.10:8193", connect.reply.Data.(map[string]interface{})["endpoint"])
+
+	assert.Equal(t, "ok", sent[1].reply.Status)
+	assert.Equal(t, "O0001\nM30\n", sent[1].reply.Data.(map[string]interface{})["program"])
+
+	// A failed command is answered with the error and still committed.
+	failed := sent[2]
+	assert.Equal(t, "c3", failed.reply.ID)
+	assert.Equal(t, "error", failed.reply.Status)
+	assert.Equal(t, "machine unreachable", failed.reply.Message)
+	require.Len(t, polling.requests, 1)
+	assert.Equal(t, models.StartPollingRequest{ID: "uuid-123", Interval: 500}, polling.requests[0])
+
+	entries, _ := audit.List(context.Background(), models.AuditFilter{})
+	require.Len(t, entries, 3)
+	assert.Equal(t, entities.AuditConnect, entries[0].Action)
+	assert.Equal(t, "kafka:fanuc_commands", entries[0].Actor)
+	assert.Equal(t, entities.AuditPollingStart, entries[2].Action)
+	assert.Equal(t, entities.AuditResultError, entries[2].Result)
+	assert.Equal(t, "machine unreachable", entries[2].Error)
+}
+
+func TestCommandConsumer_RejectsMalformedCommands(t *testing.T) {
+	audit := &memoryAudit{}
+	polling := &failingPolling{}
+	sent := runCommands(t, polling, audit,
+		kafkago.Message{Value: []byte("{not json"), Headers: []kafkago.Header{{Key: models.HeaderCorrelationID, Value: []byte("h1")}}},
+		commandMessage(t, models.Command{ID: "c2", Type: models.CommandStopPolling}),
+		commandMessage(t, models.Command{ID: "c3", Type: "reboot", MachineID: "uuid-123"}),
+		commandMessage(t, models.Command{ID: "c4", Type: models.CommandConnect}),
+	)
+	require.Len(t, sent, 4)
+
+	assert.Equal(t, "h1", sent[0].reply.ID, "the correlation header identifies an unreadable command")
+	assert.Contains(t, sent[0].reply.Message, "invalid command")
+	assert.Equal(t, "machine_id is required", sent[1].reply.Message)
+	assert.Equal(t, `unknown command type "reboot"`, sent[2].reply.Message)
+	assert.Equal(t, "connection.endpoint is required", sent[3].reply.Message)
+	for _, s := range sent {
+		assert.Equal(t, "error", s.reply.Status)
+	}
+
+	assert.Empty(t, polling.requests)
+	entries, _ := audit.List(context.Background(), models.AuditFilter{})
+	// Rejected known commands are audited, unreadable and unknown ones are not.
+	require.Len(t, entries, 2)
+	assert.Equal(t, entities.AuditPollingStop, entries[0].Action)
+	assert.Equal(t, entities.AuditConnect, entries[1].Action)
+}
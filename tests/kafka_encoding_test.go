@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	adapterModels "github.com/iwtcode/fanucAdapter/models"
+	"github.com/iwtcode/fanucService"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/gcode"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newStubRegistry(t *testing.T, schemaID int, expectedType string, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/subjects/fanuc_data-value/versions", r.URL.Path)
+
+		var body struct {
+			Schema     string `json:"schema"`
+			SchemaType string `json:"schemaType"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, expectedType, body.SchemaType)
+		assert.NotEmpty(t, body.Schema)
+
+		json.NewEncoder(w).Encode(map[string]int{"id": schemaID})
+	}))
+}
+
+func testEnvelope() *models.MachineDataEnvelope {
+	now := time.Now().Truncate(time.Millisecond)
+	return &models.MachineDataEnvelope{
+		SchemaVersion:    models.DataSchemaVersion,
+		ServiceVersion:   fanucService.Version,
+		MachineID:        "uuid-123",
+		AdapterMachineID: "10.0.0.1:8193",
+		Endpoint:         "10.0.0.1:8193",
+		Model:            "FS0i-D",
+		Series:           "0i",
+		Labels:           map[string]string{"line": "A"},
+		Sequence:         7,
+		PollStartedAt:    now,
+		PollFinishedAt:   now.Add(40 * time.Millisecond),
+		PollLatencyMs:    40,
+		Data: &adapterModels.AggregatedData{
+			MachineID:    "10.0.0.1:8193",
+			Timestamp:    now,
+			MachineState: "START",
+			AxisInfos:    []adapterModels.AxisInfo{{Name: "X", Position: 12.5}},
+		},
+		Reads: []models.DataReading{{
+			DataAddress: entities.DataAddress{Class: entities.DataMacro, Number: 500, Count: 2, Type: entities.DataReal},
+			Values:      []models.DataValue{{Number: 500, Value: 1.5}, {Number: 501, Vacant: true}},
+		}},
+		Position: &models.ProgramPosition{
+			MachineID: "uuid-123",
+			Number:    1234,
+			Sequence:  20,
+			Block:     "G01X10.F100.",
+			Line:      4,
+			Matched:   gcode.MatchBlock,
+			Lines:     []models.ProgramLine{{Line: 3, Text: "N20"}, {Line: 4, Text: "G01 X10. F100.", Current: true}},
+			Timestamp: now,
+		},
+	}
+}
+
+func kafkaConfig(encoding, registryURL string) *fanucService.Config {
+	return &fanucService.Config{Kafka: fanucService.KafkaConfig{
+		Topic:             "fanuc_data",
+		Encoding:          encoding,
+		SchemaRegistryURL: registryURL,
+	}}
+}
+
+func TestEncoder_Protobuf(t *testing.T) {
+	var calls int32
+	registry := newStubRegistry(t, 42, "PROTOBUF", &calls)
+	defer registry.Close()
+
+	encoder, err := kafka.NewEncoder(kafkaConfig("protobuf", registry.URL))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		value, err := encoder.Encode(context.Background(), "fanuc_data", testEnvelope())
+		require.NoError(t, err)
+
+		assert.Equal(t, byte(0), value[0])
+		assert.Equal(t, uint32(42), binary.BigEndian.Uint32(value[1:5]))
+		assert.Equal(t, byte(0), value[5]) // message index [0]
+
+		var decoded fanucv1.MachineDataEnvelope
+		require.NoError(t, proto.Unmarshal(value[6:], &decoded))
+		assert.Equal(t, "uuid-123", decoded.MachineId)
+		assert.Equal(t, uint64(7), decoded.Sequence)
+		assert.Equal(t, "A", decoded.Labels["line"])
+		assert.Equal(t, "X", decoded.Data.AxisInfos[0].Name)
+		require.Len(t, decoded.Reads, 1)
+		assert.Equal(t, int32(500), decoded.Reads[0].Address.Number)
+		assert.Equal(t, 1.5, decoded.Reads[0].Values[0].Value)
+		assert.True(t, decoded.Reads[0].Values[1].Vacant)
+		assert.Equal(t, int32(4), decoded.Position.Line)
+		assert.True(t, decoded.Position.Lines[1].Current)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "schema must be registered once")
+}
+
+func TestEncoder_Avro(t *testing.T) {
+	var calls int32
+	registry := newStubRegistry(t, 7, "", &calls)
+	defer registry.Close()
+
+	encoder, err := kafka.NewEncoder(kafkaConfig("avro", registry.URL))
+	require.NoError(t, err)
+
+	env := testEnvelope()
+	value, err := encoder.Encode(context.Background(), "fanuc_data", env)
+	require.NoError(t, err)
+
+	assert.Equal(t, byte(0), value[0])
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(value[1:5]))
+
+	schema := avro.MustParse(fanucv1.MachineDataAvro)
+	var decoded map[string]interface{}
+	require.NoError(t, avro.Unmarshal(schema, value[5:], &decoded))
+	assert.Equal(t, "uuid-123", decoded["machine_id"])
+	assert.Equal(t, int64(7), decoded["sequence"])
+	assert.True(t, env.PollStartedAt.Equal(decoded["poll_started_at"].(time.Time)))
+
+	reads := decoded["reads"].([]interface{})
+	require.Len(t, reads, 1)
+	reading := reads[0].(map[string]interface{})
+	assert.Equal(t, "macro", reading["class"])
+	assert.Len(t, reading["values"], 2)
+
+	// A union decodes into a map keyed by the branch name.
+	position := decoded["position"].(map[string]interface{})["fanuc.v1.ProgramPosition"].(map[string]interface{})
+	assert.Equal(t, 4, position["line"])
+	assert.Len(t, position["lines"], 2)
+}
+
+func TestEncoder_RequiresRegistry(t *testing.T) {
+	_, err := kafka.NewEncoder(kafkaConfig("avro", ""))
+	require.Error(t, err)
+
+	_, err = kafka.NewEncoder(kafkaConfig("xml", ""))
+	require.Error(t, err)
+}
+
+func TestSchemaRegistry_SlowRegistrationDoesNotBlockCachedSubjects(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/subjects/slow/versions" {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]int{"id": 1})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	registry := kafka.NewSchemaRegistry(server.URL, "", "")
+	ctx := context.Background()
+	_, err := registry.Register(ctx, "cached", kafka.SchemaTypeAvro, `"string"`)
+	require.NoError(t, err)
+
+	go registry.Register(ctx, "slow", kafka.SchemaTypeAvro, `"string"`)
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		id, err := registry.Register(ctx, "cached", kafka.SchemaTypeAvro, `"string"`)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, id)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a cached subject waited for a registration in flight")
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// silentBroker accepts connections and never answers, so a batch in flight
+// waits for the send timeout.
+func silentBroker(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func queueProducer(t *testing.T) *kafka.Producer {
+	cfg := &fanucService.Config{Kafka: fanucService.KafkaConfig{
+		Brokers:     []string{silentBroker(t)},
+		Topic:       "fanuc_data",
+		QueueSize:   1,
+		QueuePolicy: kafka.DropNewest,
+		SendTimeout: 300 * time.Millisecond,
+		MaxAttempts: 1,
+	}}
+	producer, err := kafka.NewProducer(cfg, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { producer.Close() })
+	return producer
+}
+
+// queueLength returns the length of the queue of key, -1 if there is none.
+func queueLength(p *kafka.Producer, key string) int {
+	for _, q := range p.QueueStats() {
+		if q.Key == key {
+			return q.Length
+		}
+	}
+	return -1
+}
+
+func TestProducer_FullQueueReturnsBackpressureError(t *testing.T) {
+	producer := queueProducer(t)
+	ctx := context.Background()
+
+	// The first message is taken by the drain goroutine and stays in flight.
+	require.NoError(t, producer.Send(ctx, "fanuc_data", []byte("m1"), []byte("1"), nil))
+	require.Eventually(t, func() bool { return queueLength(producer, "m1") == 0 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, producer.Send(ctx, "fanuc_data", []byte("m1"), []byte("2"), nil))
+	err := producer.Send(ctx, "fanuc_data", []byte("m1"), []byte("3"), nil)
+	assert.ErrorIs(t, err, kafka.ErrQueueFull)
+
+	stats := producer.QueueStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Length)
+	assert.Equal(t, uint64(1), stats[0].Dropped)
+
+	// Another machine has a queue of its own.
+	assert.NoError(t, producer.Send(ctx, "fanuc_data", []byte("m2"), []byte("1"), nil))
+}
+
+func TestProducer_RemoveQueueAfterDrain(t *testing.T) {
+	producer := queueProducer(t)
+	ctx := context.Background()
+
+	require.NoError(t, producer.Send(ctx, "fanuc_data", []byte("m1"), []byte("1"), nil))
+	producer.RemoveQueue("m1")
+	assert.NotEqual(t, -1, queueLength(producer, "m1"), "a busy queue is removed once drained")
+	require.Eventually(t, func() bool { return queueLength(producer, "m1") == -1 }, 2*time.Second, 10*time.Millisecond)
+
+	producer.RemoveQueue("unknown")
+	assert.Empty(t, producer.QueueStats())
+}
+
+func TestProducer_SendRacingClose(t *testing.T) {
+	cfg := &fanucService.Config{Kafka: fanucService.KafkaConfig{
+		Brokers:     []string{silentBroker(t)},
+		Topic:       "fanuc_data",
+		QueueSize:   10,
+		QueuePolicy: kafka.DropNewest,
+		SendTimeout: 50 * time.Millisecond,
+		MaxAttempts: 1,
+	}}
+	producer, err := kafka.NewProducer(cfg, logrus.New())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				err := producer.Send(context.Background(), "fanuc_data", []byte(key), []byte("1"), nil)
+				if err != nil && !errors.Is(err, kafka.ErrProducerClosed) && !errors.Is(err, kafka.ErrQueueFull) {
+					t.Errorf("unexpected send error: %v", err)
+				}
+			}
+		}(fmt.Sprintf("m%d", i))
+	}
+	producer.Close()
+	wg.Wait()
+
+	err = producer.Send(context.Background(), "fanuc_data", []byte("m1"), []byte("1"), nil)
+	assert.ErrorIs(t, err, kafka.ErrProducerClosed)
+}
@@ -0,0 +1,24 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/iwtcode/fanucService/internal/services/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicRouter_FirstMatchingRuleWins(t *testing.T) {
+	router, err := kafka.NewTopicRouter("fanuc_data", "shop=2:fanuc_shop_2, line=*:fanuc_line_{value}")
+	require.NoError(t, err)
+
+	assert.Equal(t, "fanuc_shop_2", router.Topic(map[string]string{"shop": "2", "line": "A"}))
+	assert.Equal(t, "fanuc_line_A", router.Topic(map[string]string{"shop": "1", "line": "A"}))
+	assert.Equal(t, "fanuc_data", router.Topic(map[string]string{"shop": "1"}))
+	assert.Equal(t, "fanuc_data", router.Topic(nil))
+}
+
+func TestTopicRouter_InvalidRule(t *testing.T) {
+	_, err := kafka.NewTopicRouter("fanuc_data", "line:fanuc_line")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamedProgram = "%\nO1234\nG00 X0 Y0\nM30\n%"
+
+// streamPrograms streams streamedProgram; its length and ETag are known when
+// etag is set.
+type streamPrograms struct {
+	stubPrograms
+	etag string
+}
+
+func (p streamPrograms) Stat(ctx context.Context, id string, number int) (*models.ProgramStat, error) {
+	if p.etag == "" {
+		return &models.ProgramStat{Number: number, Length: -1}, nil
+	}
+	return &models.ProgramStat{Number: number, Length: int64(len(streamedProgram)), ETag: p.etag}, nil
+}
+
+func (p streamPrograms) Stream(ctx context.Context, id string, number int, w io.Writer, offset, length int64) error {
+	end := int64(len(streamedProgram))
+	if length >= 0 {
+		end = offset + length
+	}
+	_, err := io.WriteString(w, streamedProgram[offset:end])
+	return err
+}
+
+func programServer(t *testing.T, usecase streamPrograms) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handlers.NewProgramHandler(usecase, &fanucService.Config{})
+	router.GET("/api/v1/programs/download", h.Download)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProgramDownload_Range(t *testing.T) {
+	server := programServer(t, streamPrograms{etag: `"abc"`})
+	get := func(header ...string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/programs/download?id=m1&number=1234", nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, streamedProgram, string(body))
+	assert.Equal(t, int64(len(streamedProgram)), resp.ContentLength)
+	assert.Equal(t, `"abc"`, resp.Header.Get("ETag"))
+	assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+
+	resp = get("Range", "bytes=2-6")
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "O1234", string(body))
+	assert.Equal(t, "bytes 2-6/23", resp.Header.Get("Content-Range"))
+
+	resp = get("Range", "bytes=-2")
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "\n%", string(body))
+
+	// A changed program is sent in full.
+	resp = get("Range", "bytes=2-", "If-Range", `"old"`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = get("Range", "bytes=100-")
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	assert.Equal(t, "bytes */23", resp.Header.Get("Content-Range"))
+
+	resp = get("If-None-Match", `"abc"`)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestProgramDownload_UnknownLengthIgnoresRange(t *testing.T) {
+	server := programServer(t, streamPrograms{})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/programs/download?id=m1&number=1234", nil)
+	req.Header.Set("Range", "bytes=2-")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "none", resp.Header.Get("Accept-Ranges"))
+	assert.Empty(t, resp.Header.Get("ETag"))
+	assert.Equal(t, streamedProgram, string(body))
+}
+
+func TestClient_OpenProgramResumes(t *testing.T) {
+	for name, etag := range map[string]string{"range": `"abc"`, "whole text": ""} {
+		t.Run(name, func(t *testing.T) {
+			server := programServer(t, streamPrograms{etag: etag})
+			client := fanucService.NewClient(server.URL, "")
+
+			stream, err := client.OpenProgram(context.Background(), "m1", 1234, fanucService.ProgramRange{Offset: 2})
+			require.NoError(t, err)
+			defer stream.Close()
+			body, err := io.ReadAll(stream)
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(2), stream.Offset)
+			assert.Equal(t, streamedProgram[2:], string(body))
+			assert.Equal(t, etag, stream.ETag)
+		})
+	}
+}
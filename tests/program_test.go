@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/handlers"
+	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPrograms is a CNC program memory. A download containing refuse is
+// refused at its end and leaves its first half behind, like a CNC that finds
+// an error in the program after storing part of it.
+type memoryPrograms struct {
+	programs map[int]string
+	refuse   string
+}
+
+func (m *memoryPrograms) UploadProgram(number int, w io.Writer) error {
+	text, ok := m.programs[number]
+	if !ok {
+		return fmt.Errorf("program O%04d not found", number)
+	}
+	_, err := io.WriteString(w, "%\n"+text+"\n%\n")
+	return err
+}
+
+func (m *memoryPrograms) DeleteProgram(number int) error {
+	if _, ok := m.programs[number]; !ok {
+		return fmt.Errorf("program O%04d not found", number)
+	}
+	delete(m.programs, number)
+	return nil
+}
+
+func (m *memoryPrograms) WriteProgram(text string) error {
+	text = strings.Trim(text, " \t\r\n%")
+	var number int
+	if _, err := fmt.Sscanf(text, "O%d", &number); err != nil {
+		return err
+	}
+	if _, ok := m.programs[number]; ok {
+		return fmt.Errorf("program O%04d exists", number)
+	}
+	if m.refuse != "" && strings.Contains(text, m.refuse) {
+		m.programs[number] = text[:len(text)/2]
+		return errors.New("program refused")
+	}
+	m.programs[number] = text
+	return nil
+}
+
+const oldProgram = "O1234(OLD)\nG00 X0\nM30"
+
+func TestReplaceProgram(t *testing.T) {
+	memory := &memoryPrograms{programs: map[int]string{1234: oldProgram}}
+
+	require.NoError(t, fanuc.ReplaceProgram(memory, 1234, "%\nO1234(NEW)\nG01 X1\nM30\n%"))
+	assert.Equal(t, "O1234(NEW)\nG01 X1\nM30", memory.programs[1234])
+}
+
+func TestReplaceProgram_RefusedKeepsOldProgram(t *testing.T) {
+	memory := &memoryPrograms{programs: map[int]string{1234: oldProgram}, refuse: "G99"}
+
+	err := fanuc.ReplaceProgram(memory, 1234, "O1234(NEW)\nG99 X1\nM30")
+	require.ErrorContains(t, err, "program refused")
+	assert.Equal(t, oldProgram, memory.programs[1234])
+}
+
+func TestReplaceProgram_ReportsLostProgram(t *testing.T) {
+	memory := &memoryPrograms{programs: map[int]string{1234: oldProgram}, refuse: "M30"}
+
+	err := fanuc.ReplaceProgram(memory, 1234, "O1234(NEW)\nM30")
+	require.ErrorContains(t, err, "was deleted and not restored")
+	assert.ErrorContains(t, err, "program refused")
+}
+
+func TestReplaceProgram_UnreadableProgramIsNotDeleted(t *testing.T) {
+	memory := &memoryPrograms{programs: map[int]string{1234: oldProgram}}
+
+	err := fanuc.ReplaceProgram(memory, 5678, "O5678\nM30")
+	require.ErrorContains(t, err, "before replacing it")
+	assert.Equal(t, map[int]string{1234: oldProgram}, memory.programs)
+}
+
+func TestProgramUpload_BodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handlers.NewProgramHandler(stubPrograms{}, &fanucService.Config{Programs: fanucService.ProgramsConfig{MaxSize: 1024}})
+	router.POST("/api/v1/programs", h.Upload)
+	router.POST("/api/v1/programs/analyze", h.Analyze)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	body := fmt.Sprintf(`{"id": "uuid-123", "program": "O1234\n%s"}`, strings.Repeat(`G01 X1\n`, 100<<10))
+	for _, path := range []string{"/api/v1/programs", "/api/v1/programs/analyze"} {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode, path)
+	}
+
+	resp, err := http.Post(server.URL+"/api/v1/programs", "application/json", strings.NewReader(`{"id": "uuid-123", "program": "O1234\nM30"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
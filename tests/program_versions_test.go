@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryVersions struct {
+	mu       sync.Mutex
+	versions []entities.ProgramVersion
+}
+
+func (r *memoryVersions) Record(version *entities.ProgramVersion) (*entities.ProgramVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var previous *entities.ProgramVersion
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if v := r.versions[i]; v.MachineID == version.MachineID && v.Number == version.Number {
+			if v.Hash == version.Hash {
+				r.versions[i].SeenAt = version.SeenAt
+				v.SeenAt = version.SeenAt
+			}
+			previous = &v
+			break
+		}
+	}
+	if previous == nil || previous.Hash != version.Hash {
+		version.ID = uint(len(r.versions) + 1)
+		r.versions = append(r.versions, *version)
+	}
+	return previous, nil
+}
+
+func (r *memoryVersions) GetByID(id uint) (*entities.ProgramVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == 0 || int(id) > len(r.versions) {
+		return nil, models.ErrNotFound
+	}
+	v := r.versions[id-1]
+	return &v, nil
+}
+
+func (r *memoryVersions) Latest(machineID string, number int) (*entities.ProgramVersion, error) {
+	list, _ := r.Find(models.ProgramVersionFilter{MachineID: machineID, Number: number, Limit: 1})
+	if len(list) == 0 {
+		return nil, models.ErrNotFound
+	}
+	return r.GetByID(list[0].ID)
+}
+
+func (r *memoryVersions) Approved(machineID string, number int) (*entities.ProgramVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.versions {
+		if v.MachineID == machineID && v.Number == number && v.Approved {
+			return &v, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *memoryVersions) Approve(version *entities.ProgramVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.versions {
+		if v.MachineID == version.MachineID && v.Number == version.Number {
+			r.versions[i].Approved, r.versions[i].ApprovedBy, r.versions[i].ApprovedAt = false, "", nil
+		}
+		if v.ID == version.ID {
+			r.versions[i].Approved, r.versions[i].ApprovedBy, r.versions[i].ApprovedAt = true, version.ApprovedBy, version.ApprovedAt
+		}
+	}
+	return nil
+}
+
+func (r *memoryVersions) Find(filter models.ProgramVersionFilter) ([]entities.ProgramVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []entities.ProgramVersion
+	for _, v := range r.versions {
+		if v.MachineID == filter.MachineID && (filter.Number == 0 || v.Number == filter.Number) {
+			v.Content = ""
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
+
+func TestRecordProgram(t *testing.T) {
+	versions := &memoryVersions{}
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	first, previous, err := fanuc.RecordProgram(versions, "m1", 1234, "%\nO1234\nG00 X0\nM30\n%", entities.ProgramSourceDownload, start)
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, "O1234\nG00 X0\nM30", first.Content)
+	assert.Equal(t, len(first.Content), first.Size)
+
+	// The same text with other framing and line endings is the same version.
+	seen := start.Add(time.Hour)
+	again, previous, err := fanuc.RecordProgram(versions, "m1", 1234, "O1234\r\nG00 X0\r\nM30\r\n", entities.ProgramSourceUpload, seen)
+	require.NoError(t, err)
+	assert.Same(t, previous, again)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, first.Hash, again.Hash)
+	assert.Equal(t, entities.ProgramSourceDownload, again.Source)
+	assert.Equal(t, seen, again.SeenAt)
+	stored, _ := versions.GetByID(first.ID)
+	assert.Equal(t, seen, stored.SeenAt)
+	assert.Equal(t, start, stored.CreatedAt)
+
+	changed, previous, err := fanuc.RecordProgram(versions, "m1", 1234, "O1234\nG00 X1\nM30", entities.ProgramSourceUpload, seen)
+	require.NoError(t, err)
+	require.NotNil(t, previous)
+	assert.Equal(t, first.ID, previous.ID)
+	assert.Equal(t, uint(2), changed.ID)
+	assert.NotEqual(t, first.Hash, changed.Hash)
+
+	// Going back to an older text is a change again.
+	back, previous, err := fanuc.RecordProgram(versions, "m1", 1234, "O1234\nG00 X0\nM30", entities.ProgramSourceDownload, seen)
+	require.NoError(t, err)
+	assert.Equal(t, changed.ID, previous.ID)
+	assert.Equal(t, uint(3), back.ID)
+	assert.Equal(t, first.Hash, back.Hash)
+
+	// Other programs and machines have their own history.
+	other, previous, err := fanuc.RecordProgram(versions, "m2", 1234, "O1234\nG00 X0\nM30", entities.ProgramSourceDownload, seen)
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+	assert.Equal(t, uint(4), other.ID)
+}
+
+// snapshotService records the program text it is set to like
+// SnapshotProgram.
+type snapshotService struct {
+	interfaces.FanucService
+	versions *memoryVersions
+	text     string
+}
+
+func (s *snapshotService) SnapshotProgram(ctx context.Context, id string) (*entities.ProgramVersion, error) {
+	version, _, err := fanuc.RecordProgram(s.versions, id, 1234, s.text, entities.ProgramSourceDownload, time.Now())
+	return version, err
+}
+
+func TestProgramVersions_ApproveAndDrift(t *testing.T) {
+	versions := &memoryVersions{}
+	service := &snapshotService{versions: versions, text: "O1234\nG00 X0\nM30"}
+	usecase := usecases.NewProgramVersionUsecase(service, versions, nil)
+	ctx := models.WithPrincipal(context.Background(), &models.Principal{Name: "qa"})
+
+	drift, err := usecase.Drift(ctx, "m1")
+	require.NoError(t, err)
+	assert.Nil(t, drift.Approved)
+	assert.False(t, drift.Drifted)
+	assert.Equal(t, 1234, drift.Number)
+	assert.Empty(t, drift.Current.Content)
+
+	approved, err := usecase.Approve(ctx, models.ProgramApproveRequest{Version: drift.Current.ID})
+	require.NoError(t, err)
+	assert.True(t, approved.Approved)
+	assert.Equal(t, "qa", approved.ApprovedBy)
+	require.NotNil(t, approved.ApprovedAt)
+	assert.Empty(t, approved.Content)
+
+	drift, err = usecase.Drift(ctx, "m1")
+	require.NoError(t, err)
+	require.NotNil(t, drift.Approved)
+	assert.False(t, drift.Drifted)
+	assert.Empty(t, drift.Diff)
+
+	service.text = "O1234\nG00 X5\nM30"
+	drift, err = usecase.Drift(ctx, "m1")
+	require.NoError(t, err)
+	assert.True(t, drift.Drifted)
+	assert.Equal(t, approved.ID, drift.Approved.ID)
+	assert.NotEqual(t, approved.ID, drift.Current.ID)
+	assert.Contains(t, drift.Diff, "-G00 X0")
+	assert.Contains(t, drift.Diff, "+G00 X5")
+
+	// Approving the new text leaves a single approved version.
+	_, err = usecase.Approve(ctx, models.ProgramApproveRequest{Version: drift.Current.ID})
+	require.NoError(t, err)
+	list, err := usecase.List(ctx, models.ProgramVersionFilter{MachineID: "m1"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.True(t, list[0].Approved)
+	assert.False(t, list[1].Approved)
+
+	drift, err = usecase.Drift(ctx, "m1")
+	require.NoError(t, err)
+	assert.False(t, drift.Drifted)
+	assert.Equal(t, drift.Current.ID, drift.Approved.ID)
+
+	_, err = usecase.Approve(ctx, models.ProgramApproveRequest{Version: 99})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/handlers"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_MachineConcurrency(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&fanucService.Config{Limits: fanucService.LimitsConfig{MachineConcurrency: 1}})
+
+	release, err := limiter.AcquireMachine("m1")
+	require.NoError(t, err)
+
+	_, err = limiter.AcquireMachine("m1")
+	var limited *models.RateLimitError
+	require.True(t, errors.As(err, &limited))
+	assert.ErrorIs(t, err, models.ErrRateLimited)
+	assert.Equal(t, time.Second, limited.RetryAfter)
+
+	// Other machines are not affected.
+	releaseOther, err := limiter.AcquireMachine("m2")
+	require.NoError(t, err)
+	releaseOther()
+
+	release()
+	release, err = limiter.AcquireMachine("m1")
+	require.NoError(t, err)
+	release()
+}
+
+func TestLimiter_MachineRate(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&fanucService.Config{Limits: fanucService.LimitsConfig{MachineRate: 1, MachineBurst: 2}})
+
+	for i := 0; i < 2; i++ {
+		release, err := limiter.AcquireMachine("m1")
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err := limiter.AcquireMachine("m1")
+	var limited *models.RateLimitError
+	require.True(t, errors.As(err, &limited))
+	assert.Greater(t, limited.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, limited.RetryAfter, time.Second)
+}
+
+func TestLimiter_BusyMachineKeepsRateBudget(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&fanucService.Config{Limits: fanucService.LimitsConfig{MachineConcurrency: 1, MachineRate: 0.1, MachineBurst: 2}})
+
+	release, err := limiter.AcquireMachine("m1")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = limiter.AcquireMachine("m1")
+		assert.ErrorContains(t, err, "is busy")
+	}
+	release()
+
+	// The refused calls took no tokens, so the second one of the burst is left.
+	release, err = limiter.AcquireMachine("m1")
+	require.NoError(t, err)
+	release()
+
+	// A call refused by the rate frees its slot: the next one is refused by
+	// the rate again, not as busy.
+	for i := 0; i < 2; i++ {
+		_, err = limiter.AcquireMachine("m1")
+		assert.ErrorContains(t, err, "request rate exceeded")
+	}
+}
+
+func TestLimiter_DropsIdleCallers(t *testing.T) {
+	limiter := ratelimit.NewLimiter(&fanucService.Config{Limits: fanucService.LimitsConfig{KeyRate: 1000, KeyBurst: 1}})
+
+	for i := 0; i < 1024; i++ {
+		require.NoError(t, limiter.AllowCaller(fmt.Sprintf("key-%d", i)))
+	}
+	assert.Equal(t, 1024, limiter.Callers())
+
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, limiter.AllowCaller("new"))
+	assert.Equal(t, 1, limiter.Callers(), "callers with a full budget are dropped")
+
+	require.NoError(t, limiter.AllowCaller("new2"))
+	assert.Equal(t, 2, limiter.Callers())
+}
+
+func TestRateLimit_CallerGets429WithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &fanucService.Config{Limits: fanucService.LimitsConfig{KeyRate: 0.1, KeyBurst: 1}}
+	authUsecase := usecases.NewAuthUsecase(cfg, newAPIKeys(t), nil, nil)
+
+	r := gin.New()
+	cfg.Cluster.PeerSubject = "OU:fanuc-cluster"
+	peers, err := auth.NewClusterPeers(cfg, logrus.New())
+	require.NoError(t, err)
+	r.GET("/connect", middleware.Auth(authUsecase), middleware.RateLimit(ratelimit.NewLimiter(cfg), peers), func(c *gin.Context) {
+		handlers.RespondMessage(c, "ok")
+	})
+
+	send := func(forwardedBy string, peer *x509.Certificate) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/connect", nil)
+		req.Header.Set("X-API-Key", "test-api-key")
+		if forwardedBy != "" {
+			req.Header.Set("X-Fanuc-Forwarded-By", forwardedBy)
+		}
+		if peer != nil {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{peer}}}
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("", nil).Code)
+
+	w := send("", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+
+	// The forwarding instance counted the request already; a client that only
+	// claims to be one is counted.
+	peer := &x509.Certificate{Subject: pkix.Name{CommonName: "a", OrganizationalUnit: []string{"fanuc-cluster"}}}
+	assert.Equal(t, http.StatusOK, send("a", peer).Code)
+	assert.Equal(t, http.StatusTooManyRequests, send("a", nil).Code)
+}
+
+func TestRespondFailure_MachineBusy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handlers.RespondFailure(c, &models.RateLimitError{Reason: "machine m1 is busy", RetryAfter: 1500 * time.Millisecond}, http.StatusInternalServerError)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
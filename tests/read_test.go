@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    entities.DataAddress
+		want    entities.DataAddress
+		wantErr string
+	}{
+		{
+			name: "macro defaults",
+			addr: entities.DataAddress{Class: entities.DataMacro, Number: 500, Type: "byte", Axis: 2, Area: "R"},
+			want: entities.DataAddress{Class: entities.DataMacro, Number: 500, Count: 1, Type: entities.DataReal},
+		},
+		{
+			name: "parameter of an axis",
+			addr: entities.DataAddress{Class: entities.DataParameter, Number: 1320, Count: 3, Type: "dword", Axis: 1, Area: "R"},
+			want: entities.DataAddress{Class: entities.DataParameter, Number: 1320, Count: 3, Type: "dword", Axis: 1},
+		},
+		{
+			name: "pmc area and type defaults",
+			addr: entities.DataAddress{Class: entities.DataPMC, Area: "r", Number: 100, Axis: 3},
+			want: entities.DataAddress{Class: entities.DataPMC, Area: "R", Number: 100, Count: 1, Type: entities.DataByte},
+		},
+		{
+			name: "last pmc word",
+			addr: entities.DataAddress{Class: entities.DataPMC, Area: "D", Number: 65534, Type: "word"},
+			want: entities.DataAddress{Class: entities.DataPMC, Area: "D", Number: 65534, Count: 1, Type: "word"},
+		},
+		{name: "unknown class", addr: entities.DataAddress{Class: "tool"}, wantErr: `unknown data class "tool"`},
+		{name: "count too large", addr: entities.DataAddress{Class: entities.DataMacro, Count: fanuc.MaxReadCount + 1}, wantErr: "count must be between"},
+		{name: "negative count", addr: entities.DataAddress{Class: entities.DataMacro, Count: -1}, wantErr: "count must be between"},
+		{name: "parameter without type", addr: entities.DataAddress{Class: entities.DataParameter, Number: 1}, wantErr: "type is required"},
+		{name: "axis out of range", addr: entities.DataAddress{Class: entities.DataDiagnosis, Type: "byte", Axis: 33}, wantErr: "axis must be between"},
+		{name: "unknown pmc area", addr: entities.DataAddress{Class: entities.DataPMC, Area: "Q"}, wantErr: `unknown PMC area "Q"`},
+		{name: "unknown type", addr: entities.DataAddress{Class: entities.DataDiagnosis, Type: "long"}, wantErr: `unknown data type "long"`},
+		{name: "negative number", addr: entities.DataAddress{Class: entities.DataMacro, Number: -1}, wantErr: "out of range"},
+		{name: "pmc range past the area", addr: entities.DataAddress{Class: entities.DataPMC, Area: "D", Number: 65535, Type: "word"}, wantErr: "out of range"},
+		{name: "macro range past the last number", addr: entities.DataAddress{Class: entities.DataMacro, Number: 32767, Count: 2}, wantErr: "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fanuc.NormalizeAddress(tt.addr)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, models.ErrBadRequest)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeReads(t *testing.T) {
+	reads, err := fanuc.NormalizeReads([]entities.DataAddress{{Class: entities.DataMacro, Number: 500}, {Class: entities.DataPMC, Area: "x"}})
+	require.NoError(t, err)
+	assert.Equal(t, "X", reads[1].Area)
+	assert.Equal(t, 1, reads[0].Count)
+
+	_, err = fanuc.NormalizeReads([]entities.DataAddress{{Class: entities.DataMacro}, {Class: "tool"}})
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+	assert.ErrorContains(t, err, "reads[1]")
+
+	tooMany := make([]entities.DataAddress, fanuc.MaxPollReads+1)
+	for i := range tooMany {
+		tooMany[i] = entities.DataAddress{Class: entities.DataMacro, Number: 500 + i}
+	}
+	_, err = fanuc.NormalizeReads(tooMany)
+	assert.ErrorIs(t, err, models.ErrBadRequest)
+	assert.ErrorContains(t, err, "at most 32 reads")
+
+	reads, err = fanuc.NormalizeReads(tooMany[:fanuc.MaxPollReads])
+	require.NoError(t, err)
+	assert.Len(t, reads, fanuc.MaxPollReads)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService"
+	fanucv1 "github.com/iwtcode/fanucService/api/fanuc/v1"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/grpcapi"
+	"github.com/iwtcode/fanucService/internal/services/cluster"
+	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/iwtcode/fanucService/internal/services/ratelimit"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestShutdown_ClosedWatchersLetGRPCStopGracefully(t *testing.T) {
+	cfg := &fanucService.Config{}
+	repo := &ruleMachines{machine: entities.Machine{ID: "uuid-123"}}
+	service := fanuc.NewService(cfg, repo, nil, nil, ratelimit.NewLimiter(cfg), cluster.NewMembership(cfg), logrus.New())
+
+	auth := usecases.NewAuthUsecase(cfg, newAPIKeys(t), nil, nil)
+	api := grpcapi.NewServer(stubConnections{}, usecases.NewPollingUsecase(service, repo), stubPrograms{}, stubData{}, stubOffsets{})
+	server := grpcapi.NewGRPCServer(auth, &memoryAudit{}, ratelimit.NewLimiter(cfg), api, nil)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := fanucv1.NewFanucServiceClient(conn)
+
+	// A watch stream only ends with its client or its channel.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "test-api-key")
+	stream, err := client.WatchMachineData(ctx, &fanucv1.WatchMachineDataRequest{Id: "uuid-123"})
+	require.NoError(t, err)
+	ended := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		ended <- err
+	}()
+	select {
+	case err := <-ended:
+		t.Fatalf("stream ended before shutdown: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	service.CloseWatchers()
+	select {
+	case err := <-ended:
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch stream did not end")
+	}
+
+	// Watches opened during the shutdown are refused.
+	refused, err := client.WatchMachineData(ctx, &fanucv1.WatchMachineDataRequest{Id: "uuid-123"})
+	require.NoError(t, err)
+	_, err = refused.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("GracefulStop waited for the watch stream")
+	}
+	_, err = service.WatchMachineData(context.Background(), "uuid-123")
+	assert.ErrorIs(t, err, models.ErrUnavailable)
+}
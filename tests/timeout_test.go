@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService/internal/handlers"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout_RequestDeadlineEndsWork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/program", middleware.Timeout(20*time.Millisecond, nil), func(c *gin.Context) {
+		ctx := c.Request.Context()
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+
+		select {
+		case <-ctx.Done():
+			handlers.RespondFailure(c, ctx.Err(), http.StatusInternalServerError)
+		case <-time.After(time.Second):
+			handlers.RespondMessage(c, "too late")
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/program", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestTimeout_RoutesGetTheirOwnDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	v1 := r.Group("/api/v1", middleware.Timeout(20*time.Millisecond, map[string]time.Duration{
+		"GET /api/v1/programs/download": time.Hour,
+		"POST /api/v1/programs":         0,
+	}))
+	deadline := func(c *gin.Context) {
+		if d, ok := c.Request.Context().Deadline(); ok {
+			c.String(http.StatusOK, time.Until(d).String())
+			return
+		}
+		c.String(http.StatusOK, "none")
+	}
+	v1.GET("/programs", deadline)
+	v1.POST("/programs", deadline)
+	v1.GET("/programs/download", deadline)
+
+	get := func(method, path string) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Body.String()
+	}
+
+	left, err := time.ParseDuration(get(http.MethodGet, "/api/v1/programs"))
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, left, 20*time.Millisecond)
+
+	left, err = time.ParseDuration(get(http.MethodGet, "/api/v1/programs/download"))
+	assert.NoError(t, err)
+	assert.Greater(t, left, 59*time.Minute)
+
+	assert.Equal(t, "none", get(http.MethodPost, "/api/v1/programs"))
+}
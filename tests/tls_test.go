@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/middleware"
+	"github.com/iwtcode/fanucService/internal/services/auth"
+	"github.com/iwtcode/fanucService/internal/usecases"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	ca.writePEM(t, "ca.pem", "CERTIFICATE", der)
+	return ca
+}
+
+// issue writes a certificate signed by the CA and returns the cert and key paths.
+func (ca *testCA) issue(t *testing.T, name string, subject pkix.Name, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return ca.writePEM(t, name+".pem", "CERTIFICATE", der), ca.writePEM(t, name+"-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func (ca *testCA) writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(ca.dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func newMTLSServer(t *testing.T, ca *testCA) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	certFile, keyFile := ca.issue(t, "server", pkix.Name{CommonName: "fanucService"}, x509.ExtKeyUsageServerAuth)
+
+	cfg := &fanucService.Config{TLS: fanucService.TLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: time.Minute,
+		ClientCAFile:   filepath.Join(ca.dir, "ca.pem"),
+		ClientAuth:     auth.ClientAuthRequest,
+		ClientRoles:    "CN:hmi-line-a=read,OU:plant-admins=admin",
+	}}
+	tlsCfg, err := auth.NewServerTLSConfig(cfg, logrus.New())
+	require.NoError(t, err)
+	certs, err := auth.NewCertMapper(cfg)
+	require.NoError(t, err)
+	authUsecase := usecases.NewAuthUsecase(cfg, newAPIKeys(t), nil, certs)
+
+	r := gin.New()
+	r.GET("/api/v1/connect", middleware.Auth(authUsecase), middleware.RequireScope(entities.ScopeRead), func(c *gin.Context) {
+		principal := models.PrincipalFromContext(c.Request.Context())
+		machines := []fanucService.MachineDTO{{ID: principal.AuthMethod + ":" + principal.Name}}
+		c.JSON(http.StatusOK, models.APIResponse{Status: "ok", Data: machines})
+	})
+
+	server := httptest.NewUnstartedServer(r)
+	server.TLS = tlsCfg
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTLS_ClientCertificateMappedToScopes(t *testing.T) {
+	ca := newTestCA(t)
+	server := newMTLSServer(t, ca)
+
+	certFile, keyFile := ca.issue(t, "hmi", pkix.Name{CommonName: "hmi-line-a"}, x509.ExtKeyUsageClientAuth)
+	tlsCfg, err := fanucService.NewTLSConfig(filepath.Join(ca.dir, "ca.pem"), certFile, keyFile)
+	require.NoError(t, err)
+
+	client := fanucService.NewClient(server.URL, "", fanucService.WithTLSConfig(tlsCfg))
+	machines, err := client.GetConnections(context.Background())
+	require.NoError(t, err)
+	require.Len(t, machines, 1)
+	assert.Equal(t, "mtls:hmi-line-a", machines[0].ID)
+}
+
+func TestTLS_UnmappedOrMissingClientCertificateRejected(t *testing.T) {
+	ca := newTestCA(t)
+	server := newMTLSServer(t, ca)
+
+	certFile, keyFile := ca.issue(t, "other", pkix.Name{CommonName: "unknown"}, x509.ExtKeyUsageClientAuth)
+	tlsCfg, err := fanucService.NewTLSConfig(filepath.Join(ca.dir, "ca.pem"), certFile, keyFile)
+	require.NoError(t, err)
+
+	_, err = fanucService.NewClient(server.URL, "", fanucService.WithTLSConfig(tlsCfg)).GetConnections(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	// Without a certificate the API key still works.
+	tlsCfg, err = fanucService.NewTLSConfig(filepath.Join(ca.dir, "ca.pem"), "", "")
+	require.NoError(t, err)
+	_, err = fanucService.NewClient(server.URL, "test-api-key", fanucService.WithTLSConfig(tlsCfg)).GetConnections(context.Background())
+	require.NoError(t, err)
+}
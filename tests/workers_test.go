@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iwtcode/fanucService/internal/services/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockWorker occupies the worker of machineID until the returned release is
+// called.
+func blockWorker(t *testing.T, pool *workers.Pool, machineID string) func() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go pool.Do(context.Background(), machineID, workers.Interactive, func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	return func() { close(release) }
+}
+
+func TestWorkerPool_SerializesOperationsOfAMachine(t *testing.T) {
+	var pool workers.Pool
+	defer pool.StopAll()
+
+	var running, overlap atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Do(context.Background(), "m1", workers.Interactive, func() error {
+				if running.Add(1) > 1 {
+					overlap.Add(1)
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Zero(t, overlap.Load())
+}
+
+func TestWorkerPool_InteractiveRunsBeforePolling(t *testing.T) {
+	var pool workers.Pool
+	defer pool.StopAll()
+
+	release := blockWorker(t, &pool, "m1")
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	queue := func(name string, prio workers.Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = pool.Do(context.Background(), "m1", prio, func() error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	queue("poll", workers.Polling)
+	time.Sleep(10 * time.Millisecond)
+	queue("api", workers.Interactive)
+	time.Sleep(10 * time.Millisecond)
+
+	release()
+	wg.Wait()
+	assert.Equal(t, []string{"api", "poll"}, order)
+}
+
+func TestWorkerPool_CancelWhileQueued(t *testing.T) {
+	var pool workers.Pool
+	defer pool.StopAll()
+
+	release := blockWorker(t, &pool, "m1")
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	result := make(chan error, 1)
+	go func() {
+		result <- pool.Do(ctx, "m1", workers.Interactive, func() error {
+			ran = true
+			return nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+
+	release()
+	// The next operation runs after the dropped one.
+	require.NoError(t, pool.Do(context.Background(), "m1", workers.Interactive, func() error { return nil }))
+	assert.False(t, ran, "a cancelled operation must not run")
+}
+
+func TestWorkerPool_StopFailsQueuedAndLateOperations(t *testing.T) {
+	var pool workers.Pool
+
+	release := blockWorker(t, &pool, "m1")
+	// Fill the queue so the next callers wait to enqueue while Stop runs.
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- pool.Do(context.Background(), "m1", workers.Polling, func() error { return nil })
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.Stop("m1")
+		close(stopped)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	<-stopped
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("operations queued on a stopped worker hang")
+	}
+	close(errs)
+	for err := range errs {
+		assert.ErrorIs(t, err, workers.ErrMachineRemoved)
+	}
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iwtcode/fanucService"
+	"github.com/iwtcode/fanucService/internal/domain/entities"
+	"github.com/iwtcode/fanucService/internal/domain/models"
+	"github.com/iwtcode/fanucService/internal/interfaces"
+	"github.com/iwtcode/fanucService/internal/services/cnc"
+	"github.com/iwtcode/fanucService/internal/services/fanuc"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// odbst builds a cnc_statinfo buffer with the given mode (aut) and run state.
+func odbst(mode, run int16) cnc.Status {
+	return cnc.DecodeStatus(le(int16(0), int16(1), mode, run, int16(0), int16(0), int16(0), int16(0), int16(0)))
+}
+
+func TestCheckInterlock(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         cnc.Status
+		override       bool
+		wantOverridden bool
+		wantErr        bool
+	}{
+		{name: "mdi", status: odbst(0, 0)},
+		{name: "edit", status: odbst(3, 0)},
+		{name: "mem", status: odbst(1, 0), wantErr: true},
+		{name: "rmt", status: odbst(10, 0), wantErr: true},
+		{name: "running in mdi", status: odbst(0, 3), wantErr: true},
+		{name: "hold", status: odbst(5, 2), wantErr: true},
+		{name: "mem with override", status: odbst(1, 3), override: true, wantOverridden: true},
+		{name: "override in mdi", status: odbst(0, 0), override: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overridden, err := fanuc.CheckInterlock(tt.status, tt.override)
+			if tt.wantErr {
+				require.ErrorIs(t, err, models.ErrInterlocked)
+				assert.Contains(t, err.Error(), "set override")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOverridden, overridden)
+		})
+	}
+}
+
+func TestCheckOffsetRule(t *testing.T) {
+	rules := []entities.WriteRule{
+		{Class: entities.DataMacro, Number: 1, Count: 100, Min: -10, Max: 10},
+		{Class: entities.DataToolOffset, Number: 1, Count: 20, Offset: "length_wear", Min: -1, Max: 1},
+		{Class: entities.DataWorkOffset, Number: 1, Count: 6, Min: -1000, Max: 1000},
+	}
+	tests := []struct {
+		name    string
+		class   string
+		number  int
+		offset  string
+		value   float64
+		wantErr string
+	}{
+		{name: "tool offset in range", class: entities.DataToolOffset, number: 20, offset: "length_wear", value: -0.02},
+		{name: "tool offset past the rule", class: entities.DataToolOffset, number: 21, offset: "length_wear", wantErr: "not allowed"},
+		{name: "other tool offset type", class: entities.DataToolOffset, number: 1, offset: "length_geometry", wantErr: "not allowed"},
+		{name: "tool offset out of range", class: entities.DataToolOffset, number: 1, offset: "length_wear", value: 1.5, wantErr: "outside the allowed range"},
+		{name: "any axis", class: entities.DataWorkOffset, number: 1, offset: "Z", value: -501.33},
+		{name: "external offset", class: entities.DataWorkOffset, number: 0, offset: "X", wantErr: "not allowed"},
+		{name: "macro rule does not cover offsets", class: entities.DataToolOffset, number: 50, offset: "length_wear", wantErr: "not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fanuc.CheckOffsetRule(rules, tt.class, tt.number, tt.offset, tt.value)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, models.ErrForbidden)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.ErrorIs(t, fanuc.CheckOffsetRule(nil, entities.DataWorkOffset, 1, "X", 0), models.ErrForbidden)
+}
+
+// ruleMachines keeps one machine for SetWriteRules.
+type ruleMachines struct {
+	interfaces.Repository
+	machine entities.Machine
+}
+
+func (r *ruleMachines) GetByID(id string) (*entities.Machine, error) {
+	m := r.machine
+	return &m, nil
+}
+
+func (r *ruleMachines) Update(m *entities.Machine) error {
+	r.machine = *m
+	return nil
+}
+
+func TestSetWriteRules_OffsetRules(t *testing.T) {
+	repo := &ruleMachines{machine: entities.Machine{ID: "uuid-123"}}
+	service := fanuc.NewService(&fanucService.Config{}, repo, nil, nil, nil, nil, logrus.New())
+
+	machine, err := service.SetWriteRules(context.Background(), "uuid-123", []entities.WriteRule{
+		{Class: entities.DataToolOffset, Number: 1, Offset: " Length_Wear ", Type: "word", Min: -1, Max: 1},
+		{Class: entities.DataWorkOffset, Number: 0, Count: 7, Offset: "z", Min: -1000, Max: 1000},
+		{Class: entities.DataMacro, Number: 500, Offset: "x", Max: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []entities.WriteRule{
+		{Class: entities.DataToolOffset, Number: 1, Count: 1, Offset: "length_wear", Min: -1, Max: 1},
+		{Class: entities.DataWorkOffset, Number: 0, Count: 7, Offset: "Z", Min: -1000, Max: 1000},
+		{Class: entities.DataMacro, Number: 500, Count: 1, Type: entities.DataReal, Max: 1},
+	}, machine.WriteRules)
+
+	for _, rule := range []entities.WriteRule{
+		{Class: entities.DataToolOffset, Number: 0, Max: 1},
+		{Class: entities.DataWorkOffset, Number: -1, Max: 1},
+		{Class: "parameter", Number: 1, Max: 1},
+	} {
+		_, err := service.SetWriteRules(context.Background(), "uuid-123", []entities.WriteRule{rule})
+		assert.ErrorIs(t, err, models.ErrBadRequest, rule.Class)
+	}
+}
@@ -0,0 +1,7 @@
+package fanucService
+
+// Version is the service release version. It is reported in Kafka message
+// headers and can be overridden at build time:
+//
+//	go build -ldflags "-X github.com/iwtcode/fanucService.Version=1.2.0" ./cmd/app
+var Version = "1.0.0"